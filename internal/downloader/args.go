@@ -0,0 +1,268 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// optionArity lists the yt-dlp options the app passes that take a value.
+var optionArity = map[string]bool{
+	"-o": true, "-P": true, "-f": true,
+	"--audio-format": true, "--audio-quality": true, "--ffmpeg-location": true, "--merge-output-format": true,
+	"--sub-lang": true, "--sub-langs": true, "--convert-subs": true,
+	"--postprocessor-args": true, "--trim-filenames": true, "--download-sections": true,
+	"--retries": true, "--fragment-retries": true, "--download-archive": true,
+	"--playlist-items": true, "--max-downloads": true, "--dateafter": true, "--match-filter": true,
+	"--extractor-args": true, "--source-address": true, "--convert-thumbnails": true, "--encoding": true,
+}
+
+// knownSwitches lists the yt-dlp flags without a value the app passes.
+// Options rejects flags found in neither list rather than guessing whether
+// the next argument is their value.
+var knownSwitches = map[string]bool{
+	"-x": true, "--no-warnings": true, "--skip-download": true, "--restrict-filenames": true,
+	"--yes-playlist": true, "--no-playlist": true, "--playlist-reverse": true,
+	"--force-overwrites": true, "--no-overwrites": true, "--continue": true, "--no-continue": true,
+	"--force-ipv4": true, "--force-ipv6": true,
+	"--embed-subs": true, "--write-subs": true, "--write-auto-subs": true,
+	"--embed-chapters": true, "--embed-metadata": true,
+	"--write-info-json": true, "--write-description": true, "--write-thumbnail": true, "--write-comments": true,
+}
+
+// repeatable options may appear more than once; any other option given
+// twice is a conflict.
+var repeatable = map[string]bool{
+	"-P":                   true,
+	"--postprocessor-args": true,
+	"--extractor-args":     true,
+}
+
+// conflictingSwitches are pairs yt-dlp would silently resolve by order.
+var conflictingSwitches = [][2]string{
+	{"--yes-playlist", "--no-playlist"},
+	{"--force-overwrites", "--no-overwrites"},
+	{"--continue", "--no-continue"},
+	{"--force-ipv4", "--force-ipv6"},
+}
+
+var subtitleConvertFormats = map[string]bool{"srt": true, "ass": true, "vtt": true, "lrc": true}
+
+// ArgsBuilder composes a yt-dlp command line. Problems (missing values,
+// options given twice, contradicting switches) are collected and reported
+// by Build instead of reaching yt-dlp.
+type ArgsBuilder struct {
+	args []string
+	seen map[string]bool
+	errs []error
+}
+
+func NewArgsBuilder() *ArgsBuilder {
+	return &ArgsBuilder{seen: map[string]bool{}}
+}
+
+func (b *ArgsBuilder) add(flag string, value ...string) *ArgsBuilder {
+	if b.seen[flag] && !repeatable[flag] {
+		b.errs = append(b.errs, fmt.Errorf("%s given more than once", flag))
+		return b
+	}
+	for _, v := range value {
+		if strings.TrimSpace(v) == "" {
+			b.errs = append(b.errs, fmt.Errorf("%s needs a value", flag))
+			return b
+		}
+	}
+	b.seen[flag] = true
+	b.args = append(b.args, flag)
+	b.args = append(b.args, value...)
+	return b
+}
+
+// Switch adds a flag without a value.
+func (b *ArgsBuilder) Switch(flag string) *ArgsBuilder {
+	return b.add(flag)
+}
+
+// Option adds a flag with its value.
+func (b *ArgsBuilder) Option(flag, value string) *ArgsBuilder {
+	return b.add(flag, value)
+}
+
+// Options adds an already assembled slice of flags, reading values for the
+// options listed in optionArity. A flag in neither optionArity nor
+// knownSwitches is an error, and so is everything after it, since where its
+// value ends can't be told.
+func (b *ArgsBuilder) Options(args []string) *ArgsBuilder {
+	for i := 0; i < len(args); i++ {
+		flag := args[i]
+		switch {
+		case !strings.HasPrefix(flag, "-"):
+			b.errs = append(b.errs, fmt.Errorf("unexpected argument %q", flag))
+		case optionArity[flag]:
+			if i+1 >= len(args) {
+				b.errs = append(b.errs, fmt.Errorf("%s needs a value", flag))
+				continue
+			}
+			b.add(flag, args[i+1])
+			i++
+		case knownSwitches[flag]:
+			b.add(flag)
+		default:
+			b.errs = append(b.errs, fmt.Errorf("unknown option %s", flag))
+			return b
+		}
+	}
+	return b
+}
+
+// FFmpegLocation points yt-dlp at the directory holding ffmpeg.
+func (b *ArgsBuilder) FFmpegLocation(dir string) *ArgsBuilder {
+	return b.Option("--ffmpeg-location", dir)
+}
+
+// Output sets the output template. With a temp path set, yt-dlp downloads
+// and merges there and moves the result into home.
+func (b *ArgsBuilder) Output(home, temp, template string) *ArgsBuilder {
+	if temp != "" {
+		b.Option("-P", "home:"+home).Option("-P", "temp:"+temp)
+	}
+	return b.Option("-o", template)
+}
+
+// Format sets the -f selector.
+func (b *ArgsBuilder) Format(selector string) *ArgsBuilder {
+	return b.Option("-f", selector)
+}
+
+// ExtractAudio converts the download to an audio-only file.
+func (b *ArgsBuilder) ExtractAudio(codec string) *ArgsBuilder {
+	return b.Switch("-x").Option("--audio-format", codec)
+}
+
+// MergeOutputFormat sets the container used when video and audio are merged.
+func (b *ArgsBuilder) MergeOutputFormat(container string) *ArgsBuilder {
+	return b.Option("--merge-output-format", container)
+}
+
+// Playlist chooses between the whole playlist and only the linked video.
+func (b *ArgsBuilder) Playlist(whole bool) *ArgsBuilder {
+	if whole {
+		return b.Switch("--yes-playlist")
+	}
+	return b.Switch("--no-playlist")
+}
+
+// Subtitles embeds the subtitle track(s) matching langs. auto and manual
+// select automatic captions and uploaded subtitles; convert, if set, is the
+// subtitle format to convert to.
+func (b *ArgsBuilder) Subtitles(langs string, manual, auto bool, convert string) *ArgsBuilder {
+	if !manual && !auto {
+		b.errs = append(b.errs, errors.New("subtitles requested without manual or automatic tracks"))
+		return b
+	}
+	b.Switch("--embed-subs").Option("--sub-lang", langs)
+	if manual {
+		b.Switch("--write-subs")
+	}
+	if auto {
+		b.Switch("--write-auto-subs")
+	}
+	if convert != "" {
+		if !subtitleConvertFormats[convert] {
+			b.errs = append(b.errs, fmt.Errorf("unsupported subtitle format %q", convert))
+			return b
+		}
+		b.Option("--convert-subs", convert)
+	}
+	return b
+}
+
+// PostprocessorArgs passes args to one postprocessor, e.g.
+// "EmbedSubtitle+ffmpeg".
+func (b *ArgsBuilder) PostprocessorArgs(target, args string) *ArgsBuilder {
+	return b.Option("--postprocessor-args", target+":"+args)
+}
+
+// ExtractorArgs passes args, "key=value" pairs separated by ";", to the
+// extractor named site, e.g. ExtractorArgs("youtube", "player_client=android").
+func (b *ArgsBuilder) ExtractorArgs(site, args string) *ArgsBuilder {
+	if err := ValidateExtractorArgs(site, args); err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	return b.Option("--extractor-args", strings.ToLower(site)+":"+args)
+}
+
+// ValidateExtractorArgs reports what is wrong with an --extractor-args
+// entry before it reaches yt-dlp.
+func ValidateExtractorArgs(site, args string) error {
+	if site == "" {
+		return errors.New("extractor arguments need a site")
+	}
+	for _, r := range site {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_') {
+			return fmt.Errorf("invalid extractor name %q", site)
+		}
+	}
+	if strings.TrimSpace(args) == "" {
+		return fmt.Errorf("no extractor arguments for %s", site)
+	}
+	for _, pair := range strings.Split(args, ";") {
+		key, _, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" || strings.ContainsAny(key, " \t") {
+			return fmt.Errorf("extractor argument %q for %s is not key=value", pair, site)
+		}
+	}
+	return nil
+}
+
+// SourceAddress makes yt-dlp connect from the local address ip. Call
+// IPVersion first so an address of the other family is caught.
+func (b *ArgsBuilder) SourceAddress(ip string) *ArgsBuilder {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("invalid source address %q", ip))
+		return b
+	}
+	if addr.Is4() && b.seen["--force-ipv6"] || addr.Is6() && !addr.Is4In6() && b.seen["--force-ipv4"] {
+		b.errs = append(b.errs, fmt.Errorf("source address %s doesn't match the forced IP version", addr))
+		return b
+	}
+	return b.Option("--source-address", addr.String())
+}
+
+// IPVersion restricts connections to IPv4 (4) or IPv6 (6); 0 leaves the
+// choice to the system.
+func (b *ArgsBuilder) IPVersion(v int) *ArgsBuilder {
+	switch v {
+	case 0:
+		return b
+	case 4:
+		return b.Switch("--force-ipv4")
+	case 6:
+		return b.Switch("--force-ipv6")
+	}
+	b.errs = append(b.errs, fmt.Errorf("unknown IP version %d", v))
+	return b
+}
+
+// Build checks the collected options and returns the command line for url.
+func (b *ArgsBuilder) Build(url string) ([]string, error) {
+	errs := append([]error(nil), b.errs...)
+	for _, pair := range conflictingSwitches {
+		if b.seen[pair[0]] && b.seen[pair[1]] {
+			errs = append(errs, fmt.Errorf("%s conflicts with %s", pair[0], pair[1]))
+		}
+	}
+	if !b.seen["-o"] {
+		errs = append(errs, errors.New("no output template"))
+	}
+	if strings.TrimSpace(url) == "" {
+		errs = append(errs, errors.New("no URL"))
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid yt-dlp arguments: %w", errors.Join(errs...))
+	}
+	return append(append([]string(nil), b.args...), url), nil
+}
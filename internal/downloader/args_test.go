@@ -0,0 +1,125 @@
+package downloader
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestArgsBuilderBuild(t *testing.T) {
+	const url = "https://example.com/watch?v=1"
+	tests := []struct {
+		name    string
+		build   func(b *ArgsBuilder)
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "output and format",
+			build: func(b *ArgsBuilder) { b.Output("", "", "%(title)s.%(ext)s").Format("best") },
+			want:  []string{"-o", "%(title)s.%(ext)s", "-f", "best", url},
+		},
+		{
+			name:  "staged output repeats -P",
+			build: func(b *ArgsBuilder) { b.Output("/home", "/tmp", "x.%(ext)s") },
+			want:  []string{"-P", "home:/home", "-P", "temp:/tmp", "-o", "x.%(ext)s", url},
+		},
+		{
+			name: "repeatable options",
+			build: func(b *ArgsBuilder) {
+				b.Output("", "", "x").
+					ExtractorArgs("youtube", "player_client=android").
+					ExtractorArgs("twitch", "client_id=abc").
+					PostprocessorArgs("Merger+ffmpeg", "-progress p").
+					PostprocessorArgs("EmbedSubtitle+ffmpeg", "-disposition:s:0 default")
+			},
+			want: []string{"-o", "x",
+				"--extractor-args", "youtube:player_client=android",
+				"--extractor-args", "twitch:client_id=abc",
+				"--postprocessor-args", "Merger+ffmpeg:-progress p",
+				"--postprocessor-args", "EmbedSubtitle+ffmpeg:-disposition:s:0 default",
+				url},
+		},
+		{
+			name: "options with values and switches",
+			build: func(b *ArgsBuilder) {
+				b.Output("", "", "x").Options([]string{"-x", "--audio-format", "mp3", "--playlist-reverse"})
+			},
+			want: []string{"-o", "x", "-x", "--audio-format", "mp3", "--playlist-reverse", url},
+		},
+		{
+			name:    "option given twice",
+			build:   func(b *ArgsBuilder) { b.Output("", "", "x").Format("best").Format("worst") },
+			wantErr: "-f given more than once",
+		},
+		{
+			name:    "conflicting playlist switches",
+			build:   func(b *ArgsBuilder) { b.Output("", "", "x").Playlist(true).Options([]string{"--no-playlist"}) },
+			wantErr: "--yes-playlist conflicts with --no-playlist",
+		},
+		{
+			name:    "conflicting overwrite switches",
+			build:   func(b *ArgsBuilder) { b.Output("", "", "x").Options([]string{"--force-overwrites", "--no-overwrites"}) },
+			wantErr: "--force-overwrites conflicts with --no-overwrites",
+		},
+		{
+			name:    "unknown option fails closed",
+			build:   func(b *ArgsBuilder) { b.Output("", "", "x").Options([]string{"--exec", "rm -rf /"}) },
+			wantErr: "unknown option --exec",
+		},
+		{
+			name:    "option missing its value",
+			build:   func(b *ArgsBuilder) { b.Output("", "", "x").Options([]string{"--retries"}) },
+			wantErr: "--retries needs a value",
+		},
+		{
+			name:    "stray argument",
+			build:   func(b *ArgsBuilder) { b.Output("", "", "x").Options([]string{"value"}) },
+			wantErr: `unexpected argument "value"`,
+		},
+		{
+			name:    "empty value",
+			build:   func(b *ArgsBuilder) { b.Output("", "", "x").Format(" ") },
+			wantErr: "-f needs a value",
+		},
+		{
+			name:    "no output template",
+			build:   func(b *ArgsBuilder) { b.Format("best") },
+			wantErr: "no output template",
+		},
+		{
+			name:    "invalid extractor args",
+			build:   func(b *ArgsBuilder) { b.Output("", "", "x").ExtractorArgs("you tube", "a=b") },
+			wantErr: `invalid extractor name "you tube"`,
+		},
+		{
+			name:    "subtitles need a track kind",
+			build:   func(b *ArgsBuilder) { b.Output("", "", "x").Subtitles("en", false, false, "") },
+			wantErr: "subtitles requested without manual or automatic tracks",
+		},
+		{
+			name:    "source address of the other family",
+			build:   func(b *ArgsBuilder) { b.Output("", "", "x").IPVersion(6).SourceAddress("192.0.2.1") },
+			wantErr: "doesn't match the forced IP version",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewArgsBuilder()
+			tt.build(b)
+			got, err := b.Build(url)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Build() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Build() = %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Silence below silenceThreshold lasting at least silenceMinimum seconds at
+// either end of a file counts as dead air.
+const (
+	silenceThreshold = "-50dB"
+	silenceMinimum   = 0.5
+)
+
+// AudioTrim is what TrimAudio cuts from an audio file.
+type AudioTrim struct {
+	// Silence removes dead air at the start and the end.
+	Silence bool
+	// Intro and Outro are fixed lengths in seconds cut from the start and
+	// the end, after any silence.
+	Intro float64
+	Outro float64
+}
+
+func (t AudioTrim) Enabled() bool { return t.Silence || t.Intro > 0 || t.Outro > 0 }
+
+var silenceLineRE = regexp.MustCompile(`silence_(start|end): (-?[0-9.]+)`)
+
+// silenceBounds returns where the audio starts after leading silence and
+// ends before trailing silence, reading ffmpeg's silencedetect log.
+func silenceBounds(log string, duration float64) (start, end float64) {
+	end = duration
+	lastStart := -1.0
+	first := true
+	for _, m := range silenceLineRE.FindAllStringSubmatch(log, -1) {
+		v, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			continue
+		}
+		switch m[1] {
+		case "start":
+			lastStart = v
+		case "end":
+			if first && lastStart <= 0.05 {
+				start = v
+			}
+			first = false
+			if v >= duration-0.05 {
+				end = lastStart
+			}
+			lastStart = -1
+		}
+	}
+	if lastStart >= 0 {
+		// Silence running to the end of the file has no silence_end line.
+		end = lastStart
+	}
+	return start, end
+}
+
+// TrimAudio cuts trim from src in place, copying the audio stream so the
+// quality is untouched. duration is the length of src; progress receives
+// 0..1 while silence is detected. It returns the seconds removed.
+func TrimAudio(ctx context.Context, ffmpeg, src string, trim AudioTrim, duration float64, progress func(float64)) (float64, error) {
+	if duration <= 0 {
+		return 0, errors.New("the duration is unknown")
+	}
+	start, end := 0.0, duration
+	if trim.Silence {
+		log, err := runFFmpegProgress(ctx, ffmpeg, duration, progress,
+			"-i", src, "-map", "0:a:0", "-af", fmt.Sprintf("silencedetect=noise=%s:d=%g", silenceThreshold, silenceMinimum), "-f", "null", "-")
+		if err != nil {
+			return 0, err
+		}
+		start, end = silenceBounds(string(log), duration)
+	}
+	start += trim.Intro
+	end -= trim.Outro
+	if end-start < 1 {
+		return 0, fmt.Errorf("trimming would leave %.0f seconds of %.0f", max(end-start, 0), duration)
+	}
+	if start < 0.05 && end > duration-0.05 {
+		return 0, nil
+	}
+	tmp := strings.TrimSuffix(src, filepath.Ext(src)) + ".trim" + filepath.Ext(src)
+	defer os.Remove(tmp)
+	if _, err := runFFmpegProgress(ctx, ffmpeg, 0, nil,
+		"-ss", strconv.FormatFloat(start, 'f', 3, 64), "-i", src,
+		"-t", strconv.FormatFloat(end-start, 'f', 3, 64),
+		"-map", "0", "-map_metadata", "0", "-c", "copy", tmp); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, src); err != nil {
+		return 0, err
+	}
+	return duration - (end - start), nil
+}
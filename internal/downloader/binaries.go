@@ -51,7 +51,7 @@ func (w *countingWriter) Write(p []byte) (int, error) {
 	return n, nil
 }
 
-func appDir() (string, error) {
+func defaultAppDir() (string, error) {
 	dir, err := os.UserCacheDir()
 	if err != nil {
 		return "", fmt.Errorf("could not resolve cache dir: %w", err)
@@ -63,6 +63,22 @@ func appDir() (string, error) {
 	return dir, nil
 }
 
+// appDir is the default app folder, or the folder it was moved to with
+// RelocateAppDir. A moved folder that can't be created (e.g. an unplugged
+// drive) falls back to the default.
+func appDir() (string, error) {
+	base, err := defaultAppDir()
+	if err != nil {
+		return "", err
+	}
+	if data, err := os.ReadFile(filepath.Join(base, appDirRedirectFile)); err == nil {
+		if dir := strings.TrimSpace(string(data)); dir != "" && os.MkdirAll(dir, 0755) == nil {
+			return dir, nil
+		}
+	}
+	return base, nil
+}
+
 const (
 	defaultFFmpegArchiveURL = "https://www.gyan.dev/ffmpeg/builds/ffmpeg-release-essentials.zip"
 	envFFmpegURL            = "YTGUI_FFMPEG_URL"
@@ -76,6 +92,17 @@ const (
 
 const latestBinaryChecksumsURL = "https://github.com/yt-dlp/yt-dlp/releases/latest/download/SHA2-256SUMS"
 
+const (
+	ytdlpSigningKeyURL  = "https://github.com/yt-dlp/yt-dlp/raw/master/public.key"
+	ytdlpSigningKeyFile = "yt-dlp-signing-key.asc"
+	envYTDLPSigningKey  = "YTGUI_YTDLP_SIGNING_KEY"
+	// ytdlpSigningKeyFingerprint pins the yt-dlp release key
+	// ("yt-dlp <maintainers@yt-dlp.org>"). Whatever key file is used must
+	// carry this primary key, so a swapped key can't vouch for a swapped
+	// checksum list.
+	ytdlpSigningKeyFingerprint = "AC0CBBE6848D6A873464AF4E57CF65933B5A7581"
+)
+
 var sha256LineRE = regexp.MustCompile(`(?i)\b([a-f0-9]{64})\b`)
 
 func ffmpegSourceURL() string {
@@ -193,6 +220,11 @@ func computeFileSHA256(path string) (string, error) {
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
+// FileSHA256 returns the hex SHA-256 of the file at path.
+func FileSHA256(path string) (string, error) {
+	return computeFileSHA256(path)
+}
+
 func verifyFileSHA256(path, expected, label string) error {
 	actual, err := computeFileSHA256(path)
 	if err != nil {
@@ -204,15 +236,20 @@ func verifyFileSHA256(path, expected, label string) error {
 	return nil
 }
 
-func resolveYTDLPSHA256(ctx context.Context) (string, error) {
-	if v := strings.TrimSpace(os.Getenv(envYTDLPSHA256)); v != "" {
+func resolveYTDLPSHA256(ctx context.Context, checksumsURL string) (string, error) {
+	// The override pins a release build; nightly builds are always looked
+	// up.
+	if v := strings.TrimSpace(os.Getenv(envYTDLPSHA256)); v != "" && checksumsURL == latestBinaryChecksumsURL {
 		return normalizeSHA256(v)
 	}
 	client := &http.Client{Timeout: checksumLookupTimeout}
-	text, err := fetchChecksumText(ctx, client, latestBinaryChecksumsURL)
+	text, err := fetchChecksumText(ctx, client, checksumsURL)
 	if err != nil {
 		return "", fmt.Errorf("could not fetch yt-dlp checksum list: %w", err)
 	}
+	if err := verifyYTDLPChecksums(ctx, client, text, checksumsURL+".sig"); err != nil {
+		return "", fmt.Errorf("yt-dlp checksum list failed the signature check: %w", err)
+	}
 	sum, err := parseSHA256FromList(text, "yt-dlp.exe")
 	if err != nil {
 		return "", fmt.Errorf("could not parse yt-dlp checksum: %w", err)
@@ -220,6 +257,53 @@ func resolveYTDLPSHA256(ctx context.Context) (string, error) {
 	return normalizeSHA256(sum)
 }
 
+// verifyYTDLPChecksums checks SHA2-256SUMS against SHA2-256SUMS.sig.
+func verifyYTDLPChecksums(ctx context.Context, client *http.Client, sums, signatureURL string) error {
+	keys, err := ytdlpSigningKeys(ctx, client)
+	if err != nil {
+		return err
+	}
+	sig, err := fetchChecksumText(ctx, client, signatureURL)
+	if err != nil {
+		return fmt.Errorf("could not fetch signature: %w", err)
+	}
+	return verifyDetachedSignature(keys, []byte(sums), []byte(sig))
+}
+
+// ytdlpSigningKeys loads the yt-dlp release key from YTGUI_YTDLP_SIGNING_KEY,
+// the copy cached in the app folder, or yt-dlp's repository, in that order.
+// Only a key block with the pinned fingerprint is accepted and cached.
+func ytdlpSigningKeys(ctx context.Context, client *http.Client) ([]pgpKey, error) {
+	if p := strings.TrimSpace(os.Getenv(envYTDLPSigningKey)); p != "" {
+		block, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("could not read signing key: %w", err)
+		}
+		return readPGPKeyring(block, ytdlpSigningKeyFingerprint)
+	}
+	dir, err := appDir()
+	if err != nil {
+		return nil, err
+	}
+	cached := filepath.Join(dir, ytdlpSigningKeyFile)
+	if block, err := os.ReadFile(cached); err == nil {
+		if keys, err := readPGPKeyring(block, ytdlpSigningKeyFingerprint); err == nil {
+			return keys, nil
+		}
+	}
+	block, err := fetchChecksumText(ctx, client, ytdlpSigningKeyURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch signing key: %w", err)
+	}
+	keys, err := readPGPKeyring([]byte(block), ytdlpSigningKeyFingerprint)
+	if err != nil {
+		return nil, err
+	}
+	// A failed write only means the key is fetched again next time.
+	_ = os.WriteFile(cached, []byte(block), 0o644)
+	return keys, nil
+}
+
 func resolveFFmpegSHA256(ctx context.Context, srcURL string) (string, error) {
 	if v := strings.TrimSpace(os.Getenv(envFFmpegSHA256)); v != "" {
 		return normalizeSHA256(v)
@@ -421,14 +505,25 @@ func replaceFileAtomic(dst string, src string) error {
 		os.Remove(tmp)
 		return err
 	}
-	if err := os.Rename(tmp, dst); err != nil {
+	// Replacing an installed tool waits for it to be idle; a first install
+	// has nothing to wait for.
+	rename := func() error { return os.Rename(tmp, dst) }
+	var err error
+	if _, statErr := os.Stat(dst); statErr == nil {
+		err = whileToolsIdle(rename)
+	} else {
+		err = rename()
+	}
+	if err != nil {
 		os.Remove(tmp)
 		return err
 	}
-	return nil
+	return recordToolDigest(dst)
 }
 
-func extractFFmpegFromZip(zipPath, dst string) error {
+// extractToolFromZip copies one executable (ffmpeg.exe or ffprobe.exe) out of
+// an ffmpeg build archive, preferring the copy under bin/.
+func extractToolFromZip(zipPath, exe, dst string) error {
 	zr, err := zip.OpenReader(zipPath)
 	if err != nil {
 		return err
@@ -439,15 +534,15 @@ func extractFFmpegFromZip(zipPath, dst string) error {
 	for i := range zr.File {
 		f := zr.File[i]
 		name := strings.ToLower(filepath.ToSlash(f.Name))
-		if !strings.HasSuffix(name, "/ffmpeg.exe") {
+		if !strings.HasSuffix(name, "/"+exe) {
 			continue
 		}
-		if selected == nil || strings.Contains(name, "/bin/ffmpeg.exe") {
+		if selected == nil || strings.Contains(name, "/bin/"+exe) {
 			selected = f
 		}
 	}
 	if selected == nil {
-		return fmt.Errorf("ffmpeg.exe not found in archive")
+		return fmt.Errorf("%s not found in archive", exe)
 	}
 
 	r, err := selected.Open()
@@ -456,7 +551,7 @@ func extractFFmpegFromZip(zipPath, dst string) error {
 	}
 	defer r.Close()
 
-	tmp, err := os.CreateTemp("", "ytgui-ffmpeg-*.exe")
+	tmp, err := os.CreateTemp("", "ytgui-"+strings.TrimSuffix(exe, ".exe")+"-*.exe")
 	if err != nil {
 		return err
 	}
@@ -477,7 +572,7 @@ func extractFFmpegFromZip(zipPath, dst string) error {
 	}
 	if !ok {
 		os.Remove(tmp.Name())
-		return fmt.Errorf("archive ffmpeg payload is not a Windows executable")
+		return fmt.Errorf("archive %s payload is not a Windows executable", exe)
 	}
 
 	return replaceFileAtomic(dst, tmp.Name())
@@ -486,7 +581,7 @@ func extractFFmpegFromZip(zipPath, dst string) error {
 func downloadBinaryByName(ctx context.Context, name, path string, progress DownloadProgressFunc) error {
 	switch strings.ToLower(name) {
 	case "yt-dlp.exe":
-		expectedSHA, err := resolveYTDLPSHA256(ctx)
+		expectedSHA, err := resolveYTDLPSHA256(ctx, latestBinaryChecksumsURL)
 		if err != nil {
 			return err
 		}
@@ -506,7 +601,7 @@ func downloadBinaryByName(ctx context.Context, name, path string, progress Downl
 			return fmt.Errorf("downloaded yt-dlp is not a Windows executable")
 		}
 		return replaceFileAtomic(path, tmp)
-	case "ffmpeg.exe":
+	case "ffmpeg.exe", "ffprobe.exe":
 		srcURL := ffmpegSourceURL()
 		expectedSHA, err := resolveFFmpegSHA256(ctx, srcURL)
 		if err != nil {
@@ -524,6 +619,9 @@ func downloadBinaryByName(ctx context.Context, name, path string, progress Downl
 		if isExe, err := looksLikeWindowsExe(tmp); err != nil {
 			return err
 		} else if isExe {
+			if name == "ffprobe.exe" {
+				return fmt.Errorf("ffmpeg source %s is a single executable without ffprobe", srcURL)
+			}
 			return replaceFileAtomic(path, tmp)
 		}
 		if isZip, err := looksLikeZip(tmp); err != nil {
@@ -539,7 +637,12 @@ func downloadBinaryByName(ctx context.Context, name, path string, progress Downl
 				URL:   srcURL,
 				Phase: "extract_done",
 			})
-			return extractFFmpegFromZip(tmp, path)
+			if name == "ffmpeg.exe" {
+				// ffprobe ships in the same archive; it is optional, so a
+				// failure here doesn't fail the ffmpeg install.
+				extractToolFromZip(tmp, "ffprobe.exe", filepath.Join(filepath.Dir(path), "ffprobe.exe"))
+			}
+			return extractToolFromZip(tmp, strings.ToLower(name), path)
 		}
 		return fmt.Errorf("unsupported ffmpeg download format from %s (expected .exe or .zip)", srcURL)
 	default:
@@ -547,24 +650,33 @@ func downloadBinaryByName(ctx context.Context, name, path string, progress Downl
 	}
 }
 
-func EnsureBinary(name string, data []byte) (string, error) {
-	return EnsureBinaryWithProgress(name, data, nil)
+func EnsureBinary(name string, data []byte, dataSHA256 string) (string, error) {
+	return EnsureBinaryWithProgress(name, data, dataSHA256, nil)
 }
 
-func EnsureBinaryWithProgress(name string, data []byte, progress DownloadProgressFunc) (string, error) {
-	return EnsureBinaryWithProgressCtx(context.Background(), name, data, progress)
+func EnsureBinaryWithProgress(name string, data []byte, dataSHA256 string, progress DownloadProgressFunc) (string, error) {
+	return EnsureBinaryWithProgressCtx(context.Background(), name, data, dataSHA256, progress)
 }
 
-func EnsureBinaryWithProgressCtx(ctx context.Context, name string, data []byte, progress DownloadProgressFunc) (string, error) {
+// EnsureBinaryWithProgressCtx installs name unless it is there already:
+// from data when the build embeds it, checked against dataSHA256, and
+// downloaded otherwise. Another ytgui instance installing it at the same
+// time is waited for.
+func EnsureBinaryWithProgressCtx(ctx context.Context, name string, data []byte, dataSHA256 string, progress DownloadProgressFunc) (string, error) {
 	dir, err := appDir()
 	if err != nil {
 		return "", err
 	}
+	lock, err := lockTools(ctx, toolsInstallLock, true)
+	if err != nil {
+		return "", err
+	}
+	defer lock.unlock()
 
 	path := filepath.Join(dir, name)
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		if len(data) > 0 {
-			if err := os.WriteFile(path, data, 0o755); err != nil {
+			if err := writeEmbeddedBinary(path, data, dataSHA256); err != nil {
 				return "", fmt.Errorf("could not write %s: %w", name, err)
 			}
 		} else {
@@ -579,6 +691,35 @@ func EnsureBinaryWithProgressCtx(ctx context.Context, name string, data []byte,
 	return path, nil
 }
 
+// writeEmbeddedBinary installs an embedded tool at path once the written
+// copy matches the digest recorded at build time.
+func writeEmbeddedBinary(path string, data []byte, want string) error {
+	want, err := normalizeSHA256(want)
+	if err != nil {
+		return fmt.Errorf("the build embeds %s without a valid SHA-256 digest: %w", filepath.Base(path), err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := verifyFileSHA256(tmp.Name(), want, "embedded "+filepath.Base(path)); err != nil {
+		return err
+	}
+	return replaceFileAtomic(path, tmp.Name())
+}
+
+func AppDir() (string, error) {
+	return appDir()
+}
+
 func BinaryPath(name string) (string, error) {
 	dir, err := appDir()
 	if err != nil {
@@ -587,6 +728,18 @@ func BinaryPath(name string) (string, error) {
 	return filepath.Join(dir, name), nil
 }
 
+func StagingDir() (string, error) {
+	dir, err := appDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "staging")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("could not create staging dir: %w", err)
+	}
+	return dir, nil
+}
+
 func BinaryExists(name string) (bool, string, error) {
 	path, err := BinaryPath(name)
 	if err != nil {
@@ -601,11 +754,15 @@ func BinaryExists(name string) (bool, string, error) {
 	}
 }
 
-func CleanupDownloadTemps() int {
-	patterns := []string{
+func downloadTempPatterns() []string {
+	return []string{
 		filepath.Join(os.TempDir(), "ytgui-ffmpeg-*"),
 		filepath.Join(os.TempDir(), "ytgui-ytdlp-*"),
 	}
+}
+
+func CleanupDownloadTemps() int {
+	patterns := downloadTempPatterns()
 	deleted := 0
 	seen := make(map[string]struct{})
 	for _, pattern := range patterns {
@@ -0,0 +1,116 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// toolsInstallLock serializes installing and replacing tools, so two
+	// ytgui instances don't download the same one into place at once.
+	toolsInstallLock = "tools-install.lock"
+	// toolsRunLock is held shared by every tool started through
+	// DefaultRunner and exclusively while a tool is swapped out.
+	toolsRunLock = "tools-run.lock"
+
+	lockPollInterval = 200 * time.Millisecond
+)
+
+// ErrToolsInUse is returned when an installed tool would be replaced while
+// yt-dlp, ffmpeg or ffprobe is running in this or another ytgui instance.
+var ErrToolsInUse = errors.New("the tools are in use by a running download")
+
+// fileLock is an advisory lock on a file in the app folder. Locks are
+// shared between processes only; the other platforms get a no-op lock.
+type fileLock struct {
+	f *os.File
+}
+
+func openLockFile(name string) (*os.File, error) {
+	dir, err := appDir()
+	if err != nil {
+		return nil, err
+	}
+	return os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_RDWR, 0o644)
+}
+
+// lockTools takes the named lock, polling until it is free or ctx is done.
+func lockTools(ctx context.Context, name string, exclusive bool) (*fileLock, error) {
+	f, err := openLockFile(name)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		ok, err := tryLockFile(f, exclusive)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if ok {
+			return &fileLock{f: f}, nil
+		}
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+func (l *fileLock) unlock() {
+	unlockFile(l.f)
+	l.f.Close()
+}
+
+// ToolsInUse reports whether a tool started through DefaultRunner is
+// running in this or another ytgui instance.
+func ToolsInUse() bool {
+	f, err := openLockFile(toolsRunLock)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	ok, err := tryLockFile(f, true)
+	if err != nil {
+		return false
+	}
+	if ok {
+		unlockFile(f)
+	}
+	return !ok
+}
+
+// WaitToolsIdle blocks until no tool runs in any ytgui instance or ctx is
+// done. Callers hold their own queue first, or new downloads keep it busy.
+func WaitToolsIdle(ctx context.Context) error {
+	lock, err := lockTools(ctx, toolsRunLock, true)
+	if err != nil {
+		return err
+	}
+	lock.unlock()
+	return nil
+}
+
+// whileToolsIdle runs swap, which replaces an installed tool, while no tool
+// runs, and keeps new ones from starting until it is done. It returns
+// ErrToolsInUse instead of waiting for running ones.
+func whileToolsIdle(swap func() error) error {
+	f, err := openLockFile(toolsRunLock)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	ok, err := tryLockFile(f, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrToolsInUse
+	}
+	defer unlockFile(f)
+	return swap()
+}
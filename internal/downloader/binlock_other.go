@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !windows
+
+package downloader
+
+import "os"
+
+func tryLockFile(f *os.File, exclusive bool) (bool, error) {
+	return true, nil
+}
+
+func unlockFile(f *os.File) {}
@@ -0,0 +1,45 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConvertTarget is an output ConvertMedia can produce.
+type ConvertTarget struct {
+	Name string
+	Ext  string
+	args []string
+}
+
+// ConvertTargets are the conversions offered for local files.
+var ConvertTargets = []ConvertTarget{
+	{Name: "MP4 (H.264)", Ext: ".mp4", args: []string{"-map", "0:V:0", "-map", "0:a:0?", "-c:v", "libx264", "-crf", "20", "-preset", "medium", "-pix_fmt", "yuv420p", "-c:a", "aac", "-b:a", "192k", "-movflags", "+faststart"}},
+	{Name: "MP3", Ext: ".mp3", args: []string{"-map", "0:a:0", "-vn", "-c:a", "libmp3lame", "-q:a", "2"}},
+	{Name: "Opus", Ext: ".opus", args: []string{"-map", "0:a:0", "-vn", "-c:a", "libopus", "-b:a", "128k"}},
+	{Name: "GIF", Ext: ".gif", args: gifArgs([]string{"fps=15", "scale=480:-2:flags=lanczos"})},
+}
+
+// ConvertOutputName is where ConvertMedia writes src converted to target:
+// next to it, with target's extension and a unique name.
+func ConvertOutputName(src string, target ConvertTarget) string {
+	return UniqueName(strings.TrimSuffix(src, filepath.Ext(src)) + target.Ext)
+}
+
+// ConvertMedia converts src into dst with ffmpeg. duration is the length of
+// src in seconds; progress receives 0..1 when it is known.
+func ConvertMedia(ctx context.Context, ffmpeg, src, dst string, target ConvertTarget, duration float64, progress func(float64)) error {
+	if len(target.args) == 0 {
+		return fmt.Errorf("unknown conversion %q", target.Name)
+	}
+	tmp := strings.TrimSuffix(dst, filepath.Ext(dst)) + ".converting" + filepath.Ext(dst)
+	defer os.Remove(tmp)
+	args := append([]string{"-i", src}, target.args...)
+	if _, err := runFFmpegProgress(ctx, ffmpeg, duration, progress, append(args, tmp)...); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
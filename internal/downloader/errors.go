@@ -0,0 +1,125 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrorKind says why a yt-dlp run failed, as far as its output tells.
+type ErrorKind int
+
+const (
+	// UnknownError: nothing in the output matched a known cause.
+	UnknownError ErrorKind = iota
+	// NetworkError: the connection failed or the server refused for now;
+	// trying again later may work.
+	NetworkError
+	// GeoBlocked: the video isn't available from this country.
+	GeoBlocked
+	// AgeRestricted: the site wants a signed-in, age-verified account.
+	AgeRestricted
+	// FormatUnavailable: the requested format or quality doesn't exist.
+	FormatUnavailable
+	// DiskFull: the destination ran out of space.
+	DiskFull
+	// ExtractorBroken: yt-dlp couldn't make sense of the site, which an
+	// update usually fixes.
+	ExtractorBroken
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case NetworkError:
+		return "network error"
+	case GeoBlocked:
+		return "geo-blocked"
+	case AgeRestricted:
+		return "age-restricted"
+	case FormatUnavailable:
+		return "format unavailable"
+	case DiskFull:
+		return "disk full"
+	case ExtractorBroken:
+		return "extractor broken"
+	default:
+		return "unknown error"
+	}
+}
+
+// errorHints are checked in order against the output's error lines, so the
+// specific causes win over the network hints that often accompany them.
+var errorHints = []struct {
+	kind  ErrorKind
+	hints []string
+}{
+	{DiskFull, []string{"no space left on device", "errno 28", "not enough space on the disk", "winerror 112", "disk quota exceeded"}},
+	{GeoBlocked, []string{"available in your country", "available from your location", "geo restrict", "geo-restrict", "geo_bypass", "blocked it in your country"}},
+	{AgeRestricted, []string{"confirm your age", "age-restricted", "age restricted", "inappropriate for some users", "age verification"}},
+	{FormatUnavailable, []string{"requested format is not available", "requested format not available", "no video formats found", "format is not available"}},
+	{ExtractorBroken, []string{"unable to extract", "nsig extraction failed", "signature extraction failed", "please report this issue", "unsupported url", "traceback (most recent call last)"}},
+	{NetworkError, []string{
+		"timed out",
+		"connection reset",
+		"connection aborted",
+		"connection refused",
+		"remotedisconnected",
+		"incompleteread",
+		"getaddrinfo failed",
+		"name resolution",
+		"network is unreachable",
+		"unable to download webpage",
+		"http error 5",
+		"http error 429",
+		"ssl: ",
+	}},
+}
+
+// ClassifyError decides what kind of failure err, a yt-dlp run's exit
+// error, was from the last lines it wrote. Only lines mentioning an error
+// count, and the latest matching line wins, since yt-dlp reports the fatal
+// one last. A nil or canceled err is never classified.
+func ClassifyError(err error, stderrTail []string) ErrorKind {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return UnknownError
+	}
+	for i := len(stderrTail) - 1; i >= 0; i-- {
+		line := strings.ToLower(stderrTail[i])
+		if !strings.Contains(line, "error") {
+			continue
+		}
+		for _, h := range errorHints {
+			for _, hint := range h.hints {
+				if strings.Contains(line, hint) {
+					return h.kind
+				}
+			}
+		}
+	}
+	return UnknownError
+}
+
+// DownloadError is a failed run together with its ErrorKind.
+type DownloadError struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *DownloadError) Error() string {
+	if e.Kind == UnknownError {
+		return e.Err.Error()
+	}
+	return e.Kind.String() + ": " + e.Err.Error()
+}
+
+func (e *DownloadError) Unwrap() error { return e.Err }
+
+// ErrorKindOf returns the kind of the DownloadError in err's chain, or
+// UnknownError when there is none.
+func ErrorKindOf(err error) ErrorKind {
+	var de *DownloadError
+	if errors.As(err, &de) {
+		return de.Kind
+	}
+	return UnknownError
+}
@@ -0,0 +1,127 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PlaylistEntries lists the item URLs of a playlist without resolving the
+// items. extra is passed through so range options like --playlist-items
+// narrow the list the same way they narrow the download.
+func PlaylistEntries(ctx context.Context, ytdlp, raw string, extra []string) ([]string, error) {
+	args := []string{"-J", "--flat-playlist", "--yes-playlist", "--encoding", "utf-8", "--no-warnings"}
+	args = append(args, extra...)
+	out, err := Output(ctx, ytdlp, append(args, raw)...)
+	if err != nil {
+		return nil, err
+	}
+	var info struct {
+		Entries []struct {
+			ID  string `json:"id"`
+			URL string `json:"url"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse playlist info: %w", err)
+	}
+	urls := make([]string, 0, len(info.Entries))
+	for _, e := range info.Entries {
+		switch {
+		case e.URL != "":
+			urls = append(urls, e.URL)
+		case e.ID != "":
+			urls = append(urls, e.ID)
+		}
+	}
+	return urls, nil
+}
+
+// SizeEstimate is the summed size of the formats a selector picks across
+// playlist items. Unknown counts probed items whose size couldn't be
+// determined. When only Sampled of the Items were probed, Bytes is
+// extrapolated from the average of the known sizes.
+type SizeEstimate struct {
+	Bytes   int64
+	Items   int
+	Sampled int
+	Unknown int
+}
+
+// Extrapolated reports whether the estimate is based on a sample.
+func (e SizeEstimate) Extrapolated() bool {
+	return e.Sampled < e.Items
+}
+
+// sampleURLs picks n URLs spread evenly over urls, so early and late parts
+// of a playlist, which often differ in length and quality, are both seen.
+func sampleURLs(urls []string, n int) []string {
+	if n <= 0 || len(urls) <= n {
+		return urls
+	}
+	out := make([]string, n)
+	for i := range out {
+		out[i] = urls[i*len(urls)/n]
+	}
+	return out
+}
+
+// EstimateSize probes the URLs (at most workers at once) and adds up the
+// sizes of the formats selector picks. Each probe runs yt-dlp, so with more
+// than sample URLs only sample of them, spread over the list, are probed
+// and the total is extrapolated; a sample of 0 probes all. Each probe may
+// take up to timeout; once ctx ends the remaining items count as unknown.
+// progress, if set, is called after each probe.
+func EstimateSize(ctx context.Context, ytdlp string, urls []string, selector string, workers, sample int, timeout time.Duration, progress func(done, total int)) SizeEstimate {
+	if workers < 1 {
+		workers = 1
+	}
+	probe := sampleURLs(urls, sample)
+	est := SizeEstimate{Items: len(urls), Sampled: len(probe)}
+	var mu sync.Mutex
+	done := 0
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for _, u := range probe {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(u string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			var size int64
+			pctx, cancel := context.WithTimeout(ctx, timeout)
+			formats, err := ListFormats(pctx, ytdlp, u)
+			cancel()
+			if err == nil {
+				for _, f := range SelectFormats(selector, formats) {
+					if f.Size() <= 0 {
+						size = 0
+						break
+					}
+					size += f.Size()
+				}
+			}
+			mu.Lock()
+			if size > 0 {
+				est.Bytes += size
+			} else {
+				est.Unknown++
+			}
+			done++
+			n := done
+			mu.Unlock()
+			if progress != nil {
+				progress(n, len(probe))
+			}
+		}(u)
+	}
+	wg.Wait()
+	if est.Extrapolated() {
+		if known := est.Sampled - est.Unknown; known > 0 {
+			est.Bytes = est.Bytes / int64(known) * int64(est.Items)
+		}
+	}
+	return est
+}
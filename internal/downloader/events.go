@@ -0,0 +1,92 @@
+package downloader
+
+import (
+	"sync"
+	"time"
+)
+
+// EventKind says what an Event reports.
+type EventKind int
+
+const (
+	// EventJobStarted: a queued job began running; Text is its URL.
+	EventJobStarted EventKind = iota
+	// EventStage: the job moved to a new stage; Text describes it.
+	EventStage
+	// EventProgress: Progress is the completed fraction, 0 to 1.
+	EventProgress
+	// EventSpeed: Speed is the current download rate in bytes per second.
+	EventSpeed
+	// EventLog: Text is a line meant for the user log.
+	EventLog
+	// EventRawLog: Text is raw tool output or an executed command line.
+	EventRawLog
+	// EventToolStatus: Text describes tool setup or an update check.
+	EventToolStatus
+	// EventPhase: the job entered a new phase, such as waiting for an
+	// answer or post-processing; Text names it.
+	EventPhase
+)
+
+// Event is one notification from the download engine. JobID is 0 for
+// events that don't belong to a queued job, such as tool setup.
+type Event struct {
+	Kind     EventKind
+	JobID    int64
+	Text     string
+	Progress float64
+	Speed    float64
+	Time     time.Time
+}
+
+type subscriber struct {
+	id int
+	fn func(Event)
+}
+
+// Bus delivers engine events to every subscriber, e.g. the window, a tray
+// icon or the control API.
+type Bus struct {
+	mu   sync.RWMutex
+	seq  int
+	subs []subscriber
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers fn for all future events and returns a function that
+// removes it again. Handlers run on the publishing goroutine, in the order
+// events are published, so they must not block.
+func (b *Bus) Subscribe(fn func(Event)) (unsubscribe func()) {
+	b.mu.Lock()
+	b.seq++
+	id := b.seq
+	b.subs = append(b.subs, subscriber{id: id, fn: fn})
+	b.mu.Unlock()
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, s := range b.subs {
+			if s.id == id {
+				b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish hands e to every subscriber, stamping it with the current time if
+// it has none.
+func (b *Bus) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	b.mu.RLock()
+	subs := b.subs
+	b.mu.RUnlock()
+	for _, s := range subs {
+		s.fn(e)
+	}
+}
@@ -0,0 +1,176 @@
+package downloader
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	feedFetchTimeout = 30 * time.Second
+	maxFeedSize      = 10 << 20
+)
+
+// FeedEntry is one item of an RSS or Atom feed. ID is stable across polls:
+// "youtube:<id>" for YouTube channel feeds, so it matches the download
+// history, else the item's GUID or link.
+type FeedEntry struct {
+	ID        string
+	Title     string
+	URL       string
+	Published time.Time
+}
+
+type Feed struct {
+	Title   string
+	Entries []FeedEntry
+}
+
+type rssFeed struct {
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			Title      string   `xml:"title"`
+			Links      []string `xml:"link"`
+			GUID       string   `xml:"guid"`
+			PubDate    string   `xml:"pubDate"`
+			Enclosures []struct {
+				URL  string `xml:"url,attr"`
+				Type string `xml:"type,attr"`
+			} `xml:"enclosure"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atomFeed struct {
+	Title   string `xml:"title"`
+	Entries []struct {
+		ID      string `xml:"id"`
+		VideoID string `xml:"http://www.youtube.com/xml/schemas/2015 videoId"`
+		Title   string `xml:"title"`
+		Links   []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// FetchFeed downloads and parses an RSS or Atom feed.
+func FetchFeed(ctx context.Context, feedURL string) (Feed, error) {
+	ctx, cancel := context.WithTimeout(ctx, feedFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return Feed{}, err
+	}
+	req.Header.Set("User-Agent", "ytgui")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Feed{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Feed{}, fmt.Errorf("feed request failed: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return Feed{}, err
+	}
+	return ParseFeed(data)
+}
+
+// ParseFeed reads RSS 2.0 and Atom. Podcast items link to their media
+// enclosure when they have one; entries come back oldest first.
+func ParseFeed(data []byte) (Feed, error) {
+	var root struct{ XMLName xml.Name }
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return Feed{}, fmt.Errorf("not a feed: %w", err)
+	}
+	var feed Feed
+	switch root.XMLName.Local {
+	case "rss":
+		var doc rssFeed
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return Feed{}, err
+		}
+		feed.Title = strings.TrimSpace(doc.Channel.Title)
+		for _, it := range doc.Channel.Items {
+			e := FeedEntry{Title: strings.TrimSpace(it.Title), Published: parseFeedTime(it.PubDate)}
+			for _, enc := range it.Enclosures {
+				if strings.HasPrefix(enc.Type, "video/") || strings.HasPrefix(enc.Type, "audio/") {
+					e.URL = strings.TrimSpace(enc.URL)
+					break
+				}
+			}
+			for _, l := range it.Links {
+				if e.URL != "" {
+					break
+				}
+				e.URL = strings.TrimSpace(l)
+			}
+			e.ID = strings.TrimSpace(it.GUID)
+			feed.Entries = appendFeedEntry(feed.Entries, e)
+		}
+	case "feed":
+		var doc atomFeed
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return Feed{}, err
+		}
+		feed.Title = strings.TrimSpace(doc.Title)
+		for _, en := range doc.Entries {
+			e := FeedEntry{Title: strings.TrimSpace(en.Title), ID: strings.TrimSpace(en.ID)}
+			for _, l := range en.Links {
+				if l.Rel == "" || l.Rel == "alternate" || l.Rel == "enclosure" {
+					e.URL = strings.TrimSpace(l.Href)
+					if l.Rel != "enclosure" {
+						break
+					}
+				}
+			}
+			if en.VideoID != "" {
+				e.ID = VideoID("youtube", en.VideoID)
+			}
+			e.Published = parseFeedTime(en.Published)
+			if e.Published.IsZero() {
+				e.Published = parseFeedTime(en.Updated)
+			}
+			feed.Entries = appendFeedEntry(feed.Entries, e)
+		}
+	default:
+		return Feed{}, errors.New("not an RSS or Atom feed")
+	}
+	sort.SliceStable(feed.Entries, func(i, j int) bool {
+		return feed.Entries[i].Published.Before(feed.Entries[j].Published)
+	})
+	return feed, nil
+}
+
+// appendFeedEntry keeps entries that have something to download, using the
+// URL as the ID when the feed gives none.
+func appendFeedEntry(entries []FeedEntry, e FeedEntry) []FeedEntry {
+	if e.URL == "" {
+		return entries
+	}
+	if e.ID == "" {
+		e.ID = e.URL
+	}
+	return append(entries, e)
+}
+
+func parseFeedTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.RFC3339, time.RFC1123Z, time.RFC1123, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,332 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Format struct {
+	ID       string  `json:"format_id"`
+	Ext      string  `json:"ext"`
+	VCodec   string  `json:"vcodec"`
+	ACodec   string  `json:"acodec"`
+	Width    int     `json:"width"`
+	Height   int     `json:"height"`
+	FPS      float64 `json:"fps"`
+	TBR      float64 `json:"tbr"`
+	Filesize int64   `json:"filesize"`
+	Approx   int64   `json:"filesize_approx"`
+	Note     string  `json:"format_note"`
+	// DynamicRange is SDR, HDR10, HDR10+, HDR12, HLG or DV; empty when the
+	// site doesn't say.
+	DynamicRange string `json:"dynamic_range"`
+}
+
+func (f Format) hasVideo() bool {
+	return f.VCodec != "" && f.VCodec != "none"
+}
+
+// IsHDR reports whether the format is a high dynamic range video.
+func (f Format) IsHDR() bool {
+	return f.hasVideo() && f.DynamicRange != "" && f.DynamicRange != "SDR"
+}
+
+func (f Format) hasAudio() bool {
+	return f.ACodec != "" && f.ACodec != "none"
+}
+
+func (f Format) describe() string {
+	var parts []string
+	if f.Ext != "" {
+		parts = append(parts, f.Ext)
+	}
+	if f.hasVideo() {
+		v := f.VCodec
+		if f.Height > 0 {
+			v += fmt.Sprintf(" %dp", f.Height)
+			if f.FPS > 0 {
+				v += fmt.Sprintf("%.0f", f.FPS)
+			}
+		}
+		if f.IsHDR() {
+			v += " " + f.DynamicRange
+		}
+		parts = append(parts, v)
+	}
+	if f.hasAudio() {
+		parts = append(parts, f.ACodec)
+	}
+	return fmt.Sprintf("%s (%s)", f.ID, strings.Join(parts, ", "))
+}
+
+func ListFormats(ctx context.Context, ytdlp, url string) ([]Format, error) {
+	meta, err := GetVideoMetadata(ctx, ytdlp, url)
+	if err != nil {
+		return nil, err
+	}
+	return meta.Formats, nil
+}
+
+var formatFilterRE = regexp.MustCompile(`\[([a-z_]+)\s*(\^=|\$=|\*=|!=|<=|>=|=|<|>)(\??)\s*([^\]]+)\]`)
+
+type formatFilter struct {
+	field string
+	op    string
+	value string
+	// optional filters ("<=?") also let formats without the field through.
+	optional bool
+}
+
+func (ff formatFilter) String() string {
+	if ff.optional {
+		return ff.field + ff.op + "?" + ff.value
+	}
+	return ff.field + ff.op + ff.value
+}
+
+func (ff formatFilter) match(f Format) bool {
+	var text string
+	var num float64
+	numeric := true
+	switch ff.field {
+	case "vcodec":
+		text, numeric = f.VCodec, false
+	case "acodec":
+		text, numeric = f.ACodec, false
+	case "ext":
+		text, numeric = f.Ext, false
+	case "dynamic_range":
+		text, numeric = f.DynamicRange, false
+	case "height":
+		num = float64(f.Height)
+	case "width":
+		num = float64(f.Width)
+	case "fps":
+		num = f.FPS
+	case "tbr":
+		num = f.TBR
+	case "filesize":
+		num = float64(f.Filesize)
+	default:
+		// Unknown fields are not interpreted; treat them as satisfied so the
+		// explanation still follows the rest of the selector.
+		return true
+	}
+	if !numeric {
+		if text == "" && ff.optional {
+			return true
+		}
+		switch ff.op {
+		case "^=":
+			return strings.HasPrefix(text, ff.value)
+		case "$=":
+			return strings.HasSuffix(text, ff.value)
+		case "*=":
+			return strings.Contains(text, ff.value)
+		case "!=":
+			return text != ff.value
+		default:
+			return text == ff.value
+		}
+	}
+	want, err := strconv.ParseFloat(ff.value, 64)
+	if num == 0 && ff.optional {
+		return true
+	}
+	if err != nil || num == 0 {
+		// yt-dlp drops formats with unknown values from comparisons.
+		return false
+	}
+	switch ff.op {
+	case "<=":
+		return num <= want
+	case ">=":
+		return num >= want
+	case "<":
+		return num < want
+	case ">":
+		return num > want
+	case "!=":
+		return num != want
+	default:
+		return num == want
+	}
+}
+
+type formatSpec struct {
+	raw     string
+	kind    string
+	filters []formatFilter
+}
+
+func parseFormatSpec(raw string) formatSpec {
+	spec := formatSpec{raw: raw, kind: raw}
+	if i := strings.Index(raw, "["); i >= 0 {
+		spec.kind = raw[:i]
+		for _, m := range formatFilterRE.FindAllStringSubmatch(raw[i:], -1) {
+			spec.filters = append(spec.filters, formatFilter{field: m[1], op: m[2], optional: m[3] == "?", value: strings.TrimSpace(m[4])})
+		}
+	}
+	return spec
+}
+
+func (s formatSpec) candidates(formats []Format) []Format {
+	var out []Format
+	for _, f := range formats {
+		switch s.kind {
+		case "bestvideo", "bv":
+			if !f.hasVideo() || f.hasAudio() {
+				continue
+			}
+		case "bestaudio", "ba":
+			if f.hasVideo() || !f.hasAudio() {
+				continue
+			}
+		default:
+			if !f.hasVideo() || !f.hasAudio() {
+				continue
+			}
+		}
+		ok := true
+		for _, ff := range s.filters {
+			if !ff.match(f) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			out = append(out, f)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Height != out[j].Height {
+			return out[i].Height > out[j].Height
+		}
+		if out[i].FPS != out[j].FPS {
+			return out[i].FPS > out[j].FPS
+		}
+		return out[i].TBR > out[j].TBR
+	})
+	return out
+}
+
+func (s formatSpec) streamName() string {
+	switch s.kind {
+	case "bestvideo", "bv":
+		return "video-only"
+	case "bestaudio", "ba":
+		return "audio-only"
+	default:
+		return "combined"
+	}
+}
+
+func describeFilters(filters []formatFilter) string {
+	if len(filters) == 0 {
+		return "no constraints"
+	}
+	parts := make([]string, 0, len(filters))
+	for _, ff := range filters {
+		parts = append(parts, ff.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// pickAlternative picks the best format for every "+"-joined part of one
+// selector alternative, or describes the first part nothing matched.
+func pickAlternative(parts []string, formats []Format) ([]Format, string) {
+	var picked []Format
+	for _, p := range parts {
+		spec := parseFormatSpec(strings.TrimSpace(p))
+		c := spec.candidates(formats)
+		if len(c) == 0 {
+			return nil, fmt.Sprintf("no %s format with %s", spec.streamName(), describeFilters(spec.filters))
+		}
+		picked = append(picked, c[0])
+	}
+	return picked, ""
+}
+
+// HasStreams reports whether any of formats carries video and audio.
+// Formats that don't name a codec count as neither.
+func HasStreams(formats []Format) (video, audio bool) {
+	for _, f := range formats {
+		video = video || f.hasVideo()
+		audio = audio || f.hasAudio()
+	}
+	return video, audio
+}
+
+// SelectFormats returns the formats the first matching alternative of a -f
+// selector picks, using the same interpretation as ExplainFormatSelector.
+func SelectFormats(selector string, formats []Format) []Format {
+	for _, alt := range strings.Split(selector, "/") {
+		if picked, missing := pickAlternative(strings.Split(alt, "+"), formats); missing == "" {
+			return picked
+		}
+	}
+	return nil
+}
+
+// Size returns the exact or approximate file size yt-dlp reported, 0 if
+// neither is known.
+func (f Format) Size() int64 {
+	if f.Filesize > 0 {
+		return f.Filesize
+	}
+	return f.Approx
+}
+
+// ExplainFormatSelector walks a -f selector the way yt-dlp does (first
+// alternative whose every part has a matching format wins) and returns
+// human-readable lines describing which rule matched and why earlier ones
+// were skipped. Only the filter fields the app itself generates are
+// interpreted, so the result is an explanation, not a re-implementation.
+func ExplainFormatSelector(selector string, formats []Format) []string {
+	var lines []string
+	if len(formats) == 0 {
+		return []string{"No format list available to explain the selection."}
+	}
+	alternatives := strings.Split(selector, "/")
+	for i, alt := range alternatives {
+		parts := strings.Split(alt, "+")
+		picked, missing := pickAlternative(parts, formats)
+		if missing != "" {
+			lines = append(lines, fmt.Sprintf("Rule %d skipped: %s.", i+1, missing))
+			continue
+		}
+
+		label := "preferred rule"
+		if i > 0 {
+			label = "fallback rule"
+		}
+		lines = append(lines, fmt.Sprintf("Rule %d matched (%s): %s", i+1, label, alt))
+		for _, f := range picked {
+			lines = append(lines, "  -> "+f.describe())
+		}
+		for _, p := range parts {
+			spec := parseFormatSpec(strings.TrimSpace(p))
+			for _, ff := range spec.filters {
+				switch {
+				case ff.field == "height":
+					lines = append(lines, fmt.Sprintf("  Resolution cap applied: %sp or lower.", ff.value))
+				case ff.field == "vcodec" || ff.field == "acodec":
+					lines = append(lines, fmt.Sprintf("  Codec preference satisfied: %s starts with %s.", ff.field, ff.value))
+				case ff.field == "fps":
+					lines = append(lines, fmt.Sprintf("  Frame rate cap applied: %s fps or lower.", ff.value))
+				case ff.field == "dynamic_range":
+					lines = append(lines, fmt.Sprintf("  Dynamic range preference satisfied: %s.", ff))
+				}
+			}
+		}
+		if i > 0 {
+			lines = append(lines, "  Earlier rules had no matching formats, so a less specific rule was used.")
+		}
+		return lines
+	}
+	return append(lines, "No rule matched; yt-dlp will report that the requested format is unavailable.")
+}
@@ -0,0 +1,57 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	// minToolSize is far below any real yt-dlp or ffmpeg build; a smaller
+	// file is a truncated download or a saved error page.
+	minToolSize = 1 << 20
+
+	healthCheckTimeout = 20 * time.Second
+)
+
+// CheckTool reports why the tool at path can't be used, or nil when it
+// looks healthy: it must be a plausibly sized executable that answers
+// args (e.g. --version). The installed tools are Windows builds, so
+// elsewhere only the size is checked.
+func CheckTool(ctx context.Context, path string, args ...string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() < minToolSize {
+		return fmt.Errorf("the file is only %d bytes", info.Size())
+	}
+	if runtime.GOOS != "windows" {
+		return nil
+	}
+	if ok, err := looksLikeWindowsExe(path); err != nil {
+		return err
+	} else if !ok {
+		return errors.New("the file is not a Windows executable")
+	}
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	out, err := Output(ctx, path, args...)
+	if err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) && len(ee.Stderr) > 0 {
+			lines := strings.Split(strings.TrimSpace(string(ee.Stderr)), "\n")
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(lines[len(lines)-1]))
+		}
+		return err
+	}
+	if strings.TrimSpace(string(out)) == "" {
+		return errors.New("the tool printed no version")
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package downloader
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Loudness targets in LUFS.
+const (
+	LoudnessEBUR128 = -23.0
+	LoudnessPodcast = -16.0
+	LoudnessMusic   = -14.0
+)
+
+// loudnormMeasure is the JSON the first loudnorm pass prints.
+type loudnormMeasure struct {
+	InputI      string `json:"input_i"`
+	InputTP     string `json:"input_tp"`
+	InputLRA    string `json:"input_lra"`
+	InputThresh string `json:"input_thresh"`
+	Offset      string `json:"target_offset"`
+}
+
+// loudnessEncoders re-encode the audio formats Audio Only downloads produce.
+var loudnessEncoders = map[string][]string{
+	".mp3":  {"-c:a", "libmp3lame"},
+	".m4a":  {"-c:a", "aac"},
+	".opus": {"-c:a", "libopus"},
+	".ogg":  {"-c:a", "libvorbis"},
+	".flac": {"-c:a", "flac"},
+	".wav":  {"-c:a", "pcm_s16le"},
+}
+
+// NormalizeLoudness runs ffmpeg's loudnorm filter in two passes, measuring
+// first so the second can apply a linear gain to reach target LUFS with a
+// -1.5 dBTP ceiling. The result replaces src. bitrate ("192k") is used for
+// the re-encode; empty picks a high VBR quality. probe describes src;
+// progress receives 0..1 over both passes when its duration is known.
+func NormalizeLoudness(ctx context.Context, ffmpeg, src string, target float64, bitrate string, probe MediaProbe, progress func(float64)) error {
+	ext := strings.ToLower(filepath.Ext(src))
+	codec, ok := loudnessEncoders[ext]
+	if !ok {
+		return fmt.Errorf("can't normalize %s files", ext)
+	}
+	filter := fmt.Sprintf("loudnorm=I=%g:TP=-1.5:LRA=11", target)
+	half := func(offset float64) func(float64) {
+		return func(p float64) {
+			if progress != nil {
+				progress(offset + p/2)
+			}
+		}
+	}
+	stderr, err := runFFmpegProgress(ctx, ffmpeg, probe.Duration, half(0),
+		"-i", src, "-map", "0:a:0", "-af", filter+":print_format=json", "-f", "null", "-")
+	if err != nil {
+		return err
+	}
+	start := bytes.LastIndexByte(stderr, '{')
+	end := bytes.LastIndexByte(stderr, '}')
+	if start < 0 || end < start {
+		return errors.New("loudnorm printed no measurement")
+	}
+	var m loudnormMeasure
+	if err := json.Unmarshal(stderr[start:end+1], &m); err != nil {
+		return fmt.Errorf("reading the loudnorm measurement: %w", err)
+	}
+	if m.InputI == "-inf" {
+		return errors.New("the audio is silent")
+	}
+
+	args := []string{"-i", src, "-map", "0:a:0", "-map_metadata", "0"}
+	if ext == ".mp3" || ext == ".m4a" || ext == ".flac" {
+		// Keep embedded cover art.
+		args = append(args, "-map", "0:v?", "-c:v", "copy")
+	}
+	args = append(args, "-af", fmt.Sprintf("%s:measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s:linear=true",
+		filter, m.InputI, m.InputTP, m.InputLRA, m.InputThresh, m.Offset))
+	args = append(args, codec...)
+	if probe.SampleRate > 0 {
+		// loudnorm resamples to 192 kHz internally; go back to the source rate.
+		args = append(args, "-ar", strconv.Itoa(probe.SampleRate))
+	}
+	switch {
+	case bitrate != "" && ext != ".flac" && ext != ".wav":
+		args = append(args, "-b:a", strings.ToLower(bitrate))
+	case ext == ".mp3":
+		args = append(args, "-q:a", "0")
+	}
+	if ext == ".mp3" {
+		args = append(args, "-id3v2_version", "3")
+	}
+	tmp := strings.TrimSuffix(src, filepath.Ext(src)) + ".loudnorm" + filepath.Ext(src)
+	defer os.Remove(tmp)
+	if _, err := runFFmpegProgress(ctx, ffmpeg, probe.Duration, half(0.5), append(args, tmp)...); err != nil {
+		return err
+	}
+	return os.Rename(tmp, src)
+}
+
+// runFFmpegProgress runs ffmpeg, reporting progress from -progress output,
+// and returns what it wrote to stderr.
+func runFFmpegProgress(ctx context.Context, ffmpeg string, duration float64, progress func(float64), args ...string) ([]byte, error) {
+	full := append([]string{"-hide_banner", "-nostats", "-y", "-progress", "pipe:1"}, args...)
+	p, err := DefaultRunner.Start(ctx, ffmpeg, full)
+	if err != nil {
+		return nil, err
+	}
+	var stderr bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&stderr, p.Stderr())
+		close(done)
+	}()
+	sc := bufio.NewScanner(p.Stdout())
+	for sc.Scan() {
+		v, ok := strings.CutPrefix(sc.Text(), "out_time_us=")
+		if !ok || duration <= 0 || progress == nil {
+			continue
+		}
+		if us, err := strconv.ParseInt(v, 10, 64); err == nil && us >= 0 {
+			progress(min(float64(us)/1e6/duration, 1))
+		}
+	}
+	<-done
+	if err := p.Wait(); err != nil {
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		if msg := strings.TrimSpace(lines[len(lines)-1]); msg != "" {
+			return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("ffmpeg failed: %w", err)
+	}
+	return stderr.Bytes(), nil
+}
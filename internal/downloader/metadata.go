@@ -0,0 +1,211 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metadataTTL bounds how long fetched metadata is reused. It is well below
+// the lifetime of the stream URLs in it and short enough that a re-download
+// later in the day sees current formats.
+const metadataTTL = 10 * time.Minute
+
+// VideoMetadata is the part of yt-dlp's -J output ytgui uses. One fetch
+// serves the title, subtitle and format lookups made before a download.
+type VideoMetadata struct {
+	ID         string  `json:"id"`
+	Extractor  string  `json:"extractor_key"`
+	Title      string  `json:"title"`
+	Uploader   string  `json:"uploader"`
+	UploaderID string  `json:"uploader_id"`
+	ChannelID  string  `json:"channel_id"`
+	Duration   float64 `json:"duration"`
+	UploadDate string  `json:"upload_date"`
+	IsLive     bool    `json:"is_live"`
+	// VCodec is set for sites that report a single stream instead of a
+	// format list.
+	VCodec      string      `json:"vcodec"`
+	Language    string      `json:"language"`
+	Description string      `json:"description"`
+	Chapters    []Chapter   `json:"chapters"`
+	Formats     []Format    `json:"formats"`
+	Thumbnails  []Thumbnail `json:"thumbnails"`
+	// Subtitles and AutomaticCaptions map a language code to the files
+	// offered for it.
+	Subtitles         map[string][]SubtitleFile `json:"subtitles"`
+	AutomaticCaptions map[string][]SubtitleFile `json:"automatic_captions"`
+}
+
+type Chapter struct {
+	Title     string  `json:"title"`
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+}
+
+type Thumbnail struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type SubtitleFile struct {
+	Ext  string `json:"ext"`
+	URL  string `json:"url"`
+	Name string `json:"name"`
+}
+
+// VideoID is the video's "extractor:id" key (see VideoID).
+func (m *VideoMetadata) VideoID() string {
+	return VideoID(m.Extractor, m.ID)
+}
+
+// ChannelKey identifies the video's channel as "extractor:id", preferring
+// the channel ID, which survives renames, over the uploader handle. It is
+// empty when yt-dlp reports neither.
+func (m *VideoMetadata) ChannelKey() string {
+	if m.ChannelID != "" {
+		return VideoID(m.Extractor, m.ChannelID)
+	}
+	return VideoID(m.Extractor, m.UploaderID)
+}
+
+// Uploaded is the upload day, or the zero time when the site doesn't say.
+func (m *VideoMetadata) Uploaded() time.Time {
+	t, err := time.Parse("20060102", m.UploadDate)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// BestThumbnail is the URL of the largest thumbnail, or "" when there is
+// none. yt-dlp lists thumbnails worst first, so ties go to the later one.
+func (m *VideoMetadata) BestThumbnail() string {
+	best, bestArea := "", -1
+	for _, t := range m.Thumbnails {
+		if area := t.Width * t.Height; t.URL != "" && area >= bestArea {
+			best, bestArea = t.URL, area
+		}
+	}
+	return best
+}
+
+type metadataEntry struct {
+	done    chan struct{}
+	meta    *VideoMetadata
+	err     error
+	fetched time.Time
+}
+
+// metadataCache holds fetches keyed by video ID (see VideoID); byURL maps
+// each URL asked for to the ID it resolved to, or to the fetch still in
+// flight, so concurrent lookups of one URL share a single yt-dlp process.
+var metadataCache = struct {
+	sync.Mutex
+	byID     map[string]*metadataEntry
+	byURL    map[string]string
+	inFlight map[string]*metadataEntry
+}{
+	byID:     make(map[string]*metadataEntry),
+	byURL:    make(map[string]string),
+	inFlight: make(map[string]*metadataEntry),
+}
+
+// MetadataArgs are the yt-dlp arguments GetVideoMetadata runs with, for logs.
+func MetadataArgs(url string) []string {
+	return []string{"-J", "--encoding", "utf-8", "--no-warnings", "--no-playlist", url}
+}
+
+// MetadataCached reports whether GetVideoMetadata would answer url without
+// starting yt-dlp.
+func MetadataCached(url string) bool {
+	metadataCache.Lock()
+	defer metadataCache.Unlock()
+	return cachedMetadataLocked(url) != nil
+}
+
+func cachedMetadataLocked(url string) *metadataEntry {
+	id, ok := metadataCache.byURL[url]
+	if !ok {
+		return nil
+	}
+	e, ok := metadataCache.byID[id]
+	if !ok || time.Since(e.fetched) > metadataTTL {
+		delete(metadataCache.byURL, url)
+		delete(metadataCache.byID, id)
+		return nil
+	}
+	return e
+}
+
+// GetVideoMetadata returns url's metadata from one yt-dlp -J run, reusing a
+// recent fetch of the same video. Failures aren't cached. A caller joining a
+// fetch already in flight stops waiting when its own ctx ends.
+func GetVideoMetadata(ctx context.Context, ytdlp, url string) (*VideoMetadata, error) {
+	metadataCache.Lock()
+	if e := cachedMetadataLocked(url); e != nil {
+		metadataCache.Unlock()
+		return e.meta, nil
+	}
+	if e, ok := metadataCache.inFlight[url]; ok {
+		metadataCache.Unlock()
+		select {
+		case <-e.done:
+			return e.meta, e.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	e := &metadataEntry{done: make(chan struct{})}
+	metadataCache.inFlight[url] = e
+	metadataCache.Unlock()
+
+	e.meta, e.err = fetchMetadata(ctx, ytdlp, url)
+	e.fetched = time.Now()
+
+	metadataCache.Lock()
+	delete(metadataCache.inFlight, url)
+	if e.err == nil {
+		id := e.meta.VideoID()
+		if id == "" {
+			id = url
+		}
+		metadataCache.byID[id] = e
+		metadataCache.byURL[url] = id
+	}
+	metadataCache.Unlock()
+	close(e.done)
+	return e.meta, e.err
+}
+
+// PrefetchMetadata starts fetching url's metadata in the background so the
+// download that follows finds it cached. The fetch is given up after
+// timeout.
+func PrefetchMetadata(ytdlp, url string, timeout time.Duration) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		GetVideoMetadata(ctx, ytdlp, url)
+	}()
+}
+
+func fetchMetadata(ctx context.Context, ytdlp, url string) (*VideoMetadata, error) {
+	out, err := Output(ctx, ytdlp, MetadataArgs(url)...)
+	if err != nil {
+		return nil, err
+	}
+	var meta VideoMetadata
+	if err := json.Unmarshal(out, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse video metadata: %w", err)
+	}
+	meta.Title = strings.TrimSpace(meta.Title)
+	meta.Uploader = strings.TrimSpace(meta.Uploader)
+	if meta.Title == "" {
+		return nil, fmt.Errorf("failed to parse title")
+	}
+	return &meta, nil
+}
@@ -0,0 +1,185 @@
+package downloader
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NameRules controls how video titles are cleaned up before they become
+// file names.
+type NameRules struct {
+	// Strip lists phrases removed from titles, matched case-insensitively,
+	// e.g. "[Official Video]" or "(Lyrics)".
+	Strip []string
+	// StripEmoji drops pictographs, emoji modifiers and joiners.
+	StripEmoji bool
+	// CollapseSpace turns runs of whitespace into a single space.
+	CollapseSpace bool
+	// MaxLength caps the file name, without extension, in characters. Zero
+	// means no limit.
+	MaxLength int
+	// DatePrefix starts names with the upload date (YYYYMMDD), so a folder
+	// sorts by publication.
+	DatePrefix bool
+	// IDSuffix ends names with the video ID in brackets, e.g.
+	// "[dQw4w9WgXcQ]", which tells apart videos with the same title.
+	IDSuffix bool
+	// Restrict keeps names to ASCII letters, digits and ._-[] with
+	// underscores for spaces, like yt-dlp's --restrict-filenames, for NAS
+	// shares and scripts that choke on anything else.
+	Restrict bool
+}
+
+func DefaultNameRules() NameRules {
+	return NameRules{
+		Strip: []string{
+			"[Official Video]",
+			"(Official Video)",
+			"[Official Music Video]",
+			"(Official Music Video)",
+			"(Lyrics)",
+			"[Lyrics]",
+			"(Official Audio)",
+		},
+		CollapseSpace: true,
+		MaxLength:     150,
+	}
+}
+
+var spaceRunRE = regexp.MustCompile(`\s+`)
+
+// Template is the yt-dlp output template naming files the way the date and
+// ID rules ask, for downloads named by yt-dlp rather than BuildFileName.
+// Fields the site doesn't report are left out.
+func (r NameRules) Template() string {
+	sep := r.separator()
+	t := "%(title)s"
+	if r.DatePrefix {
+		t = "%(upload_date&{}" + sep + "|)s" + t
+	}
+	if r.IDSuffix {
+		t += "%(id&" + sep + "[{}]|)s"
+	}
+	return t + ".%(ext)s"
+}
+
+// separator goes between the parts of a name.
+func (r NameRules) separator() string {
+	if r.Restrict {
+		return "_"
+	}
+	return " "
+}
+
+// part makes s usable as one part of a file name under these rules.
+func (r NameRules) part(s string) string {
+	s = sanitizeFileNamePart(s)
+	if r.Restrict {
+		s = restrictName(s)
+	}
+	return s
+}
+
+// accentFolds spells accented Latin letters the way yt-dlp does with
+// --restrict-filenames.
+var accentFolds = func() map[rune]string {
+	from := []rune("ÂÃÄÀÁÅÆÇÈÉÊËÌÍÎÏÐÑÒÓÔÕÖŐØŒÙÚÛÜŰÝÞßàáâãäåæçèéêëìíîïðñòóôõöőøœùúûüűýþÿ")
+	to := strings.Fields("A A A A A A AE C E E E E I I I I D N O O O O O O O OE U U U U U Y TH ss " +
+		"a a a a a a ae c e e e e i i i i o n o o o o o o o oe u u u u u y th y")
+	m := make(map[rune]string, len(from))
+	for i, c := range from {
+		m[c] = to[i]
+	}
+	return m
+}()
+
+var underscoreRunRE = regexp.MustCompile(`_{2,}`)
+
+// restrictName folds accents and turns every other character outside
+// A-Z, a-z, 0-9 and ._-[] into an underscore.
+func restrictName(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch {
+		case c < utf8.RuneSelf && (unicode.IsLetter(c) || unicode.IsDigit(c) || strings.ContainsRune("._-[]", c)):
+			b.WriteRune(c)
+		case accentFolds[c] != "":
+			b.WriteString(accentFolds[c])
+		default:
+			b.WriteByte('_')
+		}
+	}
+	out := strings.Trim(underscoreRunRE.ReplaceAllString(b.String(), "_"), "_.")
+	if out == "" {
+		return "untitled"
+	}
+	return out
+}
+
+// CleanTitle applies the strip, emoji and whitespace rules. MaxLength is
+// applied by BuildFileName, which knows the whole name.
+func (r NameRules) CleanTitle(title string) string {
+	for _, phrase := range r.Strip {
+		phrase = strings.TrimSpace(phrase)
+		if phrase == "" {
+			continue
+		}
+		re := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(phrase))
+		title = re.ReplaceAllString(title, " ")
+	}
+	if r.StripEmoji {
+		title = strings.Map(func(c rune) rune {
+			if isEmojiRune(c) {
+				return -1
+			}
+			return c
+		}, title)
+	}
+	if r.CollapseSpace {
+		title = spaceRunRE.ReplaceAllString(title, " ")
+	}
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "untitled"
+	}
+	return title
+}
+
+func isEmojiRune(c rune) bool {
+	switch {
+	case unicode.Is(unicode.So, c):
+		return true
+	case c == 0x200D, c == 0xFE0F, c == 0x20E3:
+		return true
+	case c >= 0x1F3FB && c <= 0x1F3FF:
+		return true
+	}
+	return false
+}
+
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// avoidReservedName keeps Windows from treating a file like "CON.mp4" or
+// "nul.mp3" as a device.
+func avoidReservedName(base string) string {
+	if windowsReservedNames[strings.ToUpper(strings.TrimSpace(base))] {
+		return base + "_"
+	}
+	return base
+}
+
+func truncateRunes(s string, max int) string {
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimRight(string(runes[:max]), ". ")
+}
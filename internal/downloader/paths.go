@@ -0,0 +1,75 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxPath is the classic Windows MAX_PATH, including the terminating NUL.
+const maxPath = 260
+
+// pathLen counts UTF-16 units, which is what Windows path limits measure.
+func pathLen(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+// FileNameBudget returns how many characters a file name in dir may use
+// before the full path hits MAX_PATH, or 0 when long paths are available and
+// no limit applies.
+func FileNameBudget(dir string) int {
+	if LongPathsEnabled() {
+		return 0
+	}
+	budget := maxPath - 1 - pathLen(dir) - 1
+	if budget < 16 {
+		budget = 16
+	}
+	return budget
+}
+
+// FitFileName shortens name, keeping its extension, so dir\name stays within
+// MAX_PATH when the system has no long path support.
+func FitFileName(dir, name string) string {
+	budget := FileNameBudget(dir)
+	if budget == 0 || pathLen(name) <= budget {
+		return name
+	}
+	ext := filepath.Ext(name)
+	base := []rune(strings.TrimSuffix(name, ext))
+	for len(base) > 1 && pathLen(string(base))+pathLen(ext) > budget {
+		base = base[:len(base)-1]
+	}
+	return strings.TrimRight(string(base), ". ") + ext
+}
+
+// ValidateFolder checks that dir exists, is a directory and accepts new
+// files. Network shares often list fine but refuse writes, which would
+// otherwise only surface once yt-dlp fails mid-download.
+func ValidateFolder(dir string) error {
+	info, err := os.Stat(ExtendedPath(dir))
+	if err != nil {
+		if isUNCPath(dir) {
+			return fmt.Errorf("network folder is not reachable: %w", err)
+		}
+		return err
+	}
+	if !info.IsDir() {
+		return errors.New("not a folder")
+	}
+	probe, err := os.CreateTemp(ExtendedPath(dir), ".ytgui-write-test-*")
+	if err != nil {
+		return fmt.Errorf("folder is not writable: %w", err)
+	}
+	name := probe.Name()
+	probe.Close()
+	os.Remove(name)
+	return nil
+}
+
+func isUNCPath(p string) bool {
+	return strings.HasPrefix(p, `\\`) || strings.HasPrefix(p, "//")
+}
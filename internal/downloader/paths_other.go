@@ -0,0 +1,13 @@
+//go:build !windows
+
+package downloader
+
+// LongPathsEnabled is always true outside Windows; MAX_PATH doesn't apply.
+func LongPathsEnabled() bool {
+	return true
+}
+
+// ExtendedPath returns p unchanged outside Windows.
+func ExtendedPath(p string) string {
+	return p
+}
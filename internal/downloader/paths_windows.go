@@ -0,0 +1,45 @@
+//go:build windows
+
+package downloader
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	longPathsOnce    sync.Once
+	longPathsEnabled bool
+)
+
+// LongPathsEnabled reports whether the LongPathsEnabled policy is set, which
+// lets tools without \\?\ handling use paths past MAX_PATH.
+func LongPathsEnabled() bool {
+	longPathsOnce.Do(func() {
+		out, err := Output(context.Background(), "reg", "query", `HKLM\SYSTEM\CurrentControlSet\Control\FileSystem`, "/v", "LongPathsEnabled")
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(string(out))
+		longPathsEnabled = len(fields) > 0 && fields[len(fields)-1] == "0x1"
+	})
+	return longPathsEnabled
+}
+
+// ExtendedPath adds the \\?\ (or \\?\UNC\) prefix to absolute paths that
+// would exceed MAX_PATH so Windows APIs accept them.
+func ExtendedPath(p string) string {
+	if pathLen(p) < maxPath-12 || strings.HasPrefix(p, `\\?\`) {
+		return p
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
@@ -0,0 +1,383 @@
+package downloader
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Just enough OpenPGP (RFC 4880) to check the detached RSA signatures yt-dlp
+// publishes next to its checksum files: armored or binary public keys with
+// subkeys, and v4 binary-document signatures.
+
+const (
+	pgpTagSignature = 2
+	pgpTagPublicKey = 6
+	pgpTagPublicSub = 14
+
+	pgpSigBinary        = 0x00
+	pgpSigSubkeyBinding = 0x18
+)
+
+var pgpHashes = map[byte]crypto.Hash{
+	8:  crypto.SHA256,
+	9:  crypto.SHA384,
+	10: crypto.SHA512,
+	11: crypto.SHA224,
+}
+
+type pgpPacket struct {
+	tag  byte
+	body []byte
+}
+
+// pgpKey is one RSA key of a key block.
+type pgpKey struct {
+	fingerprint []byte
+	pub         *rsa.PublicKey
+	body        []byte
+}
+
+func (k pgpKey) keyID() []byte { return k.fingerprint[len(k.fingerprint)-8:] }
+
+type pgpSignature struct {
+	sigType byte
+	hash    crypto.Hash
+	hashed  []byte // version through hashed subpackets, as signed
+	issuer  []byte // key ID or fingerprint, if the signature names one
+	value   []byte
+}
+
+// dearmor returns the binary data of an ASCII-armored block, or data itself
+// if it isn't armored.
+func dearmor(data []byte) ([]byte, error) {
+	if !bytes.Contains(data, []byte("-----BEGIN PGP")) {
+		return data, nil
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	var b64 strings.Builder
+	inBlock, inBody := false, false
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case strings.HasPrefix(line, "-----BEGIN PGP"):
+			inBlock = true
+		case !inBlock:
+		case strings.HasPrefix(line, "-----END PGP"):
+			return base64.StdEncoding.DecodeString(b64.String())
+		case !inBody:
+			// Armor headers end at the first blank line.
+			inBody = line == ""
+		case strings.HasPrefix(line, "="):
+			// CRC24 checksum; the signatures themselves protect the data.
+		default:
+			b64.WriteString(line)
+		}
+	}
+	return nil, errors.New("unterminated PGP armor")
+}
+
+func readPGPPackets(data []byte) ([]pgpPacket, error) {
+	var packets []pgpPacket
+	for len(data) > 0 {
+		hdr := data[0]
+		if hdr&0x80 == 0 {
+			return nil, errors.New("invalid OpenPGP packet header")
+		}
+		var tag byte
+		var n, off int
+		if hdr&0x40 != 0 {
+			tag = hdr & 0x3f
+			if len(data) < 2 {
+				return nil, errors.New("truncated OpenPGP packet")
+			}
+			switch l := data[1]; {
+			case l < 192:
+				n, off = int(l), 2
+			case l < 224:
+				if len(data) < 3 {
+					return nil, errors.New("truncated OpenPGP packet")
+				}
+				n, off = (int(l)-192)<<8+int(data[2])+192, 3
+			case l == 255:
+				if len(data) < 6 {
+					return nil, errors.New("truncated OpenPGP packet")
+				}
+				n, off = int(binary.BigEndian.Uint32(data[2:6])), 6
+			default:
+				return nil, errors.New("partial-length OpenPGP packets are not supported")
+			}
+		} else {
+			tag = (hdr >> 2) & 0x0f
+			switch hdr & 3 {
+			case 0:
+				if len(data) < 2 {
+					return nil, errors.New("truncated OpenPGP packet")
+				}
+				n, off = int(data[1]), 2
+			case 1:
+				if len(data) < 3 {
+					return nil, errors.New("truncated OpenPGP packet")
+				}
+				n, off = int(binary.BigEndian.Uint16(data[1:3])), 3
+			case 2:
+				if len(data) < 5 {
+					return nil, errors.New("truncated OpenPGP packet")
+				}
+				n, off = int(binary.BigEndian.Uint32(data[1:5])), 5
+			default:
+				n, off = len(data)-1, 1
+			}
+		}
+		if n < 0 || off+n > len(data) {
+			return nil, errors.New("truncated OpenPGP packet")
+		}
+		packets = append(packets, pgpPacket{tag: tag, body: data[off : off+n]})
+		data = data[off+n:]
+	}
+	return packets, nil
+}
+
+func readMPI(b []byte) (mpi, rest []byte, err error) {
+	if len(b) < 2 {
+		return nil, nil, errors.New("truncated MPI")
+	}
+	n := (int(binary.BigEndian.Uint16(b)) + 7) / 8
+	if len(b) < 2+n {
+		return nil, nil, errors.New("truncated MPI")
+	}
+	return b[2 : 2+n], b[2+n:], nil
+}
+
+// parsePGPKey reads a v4 public key packet. Keys that aren't RSA get a
+// fingerprint but no pub.
+func parsePGPKey(body []byte) (pgpKey, error) {
+	if len(body) < 6 || body[0] != 4 {
+		return pgpKey{}, errors.New("only v4 OpenPGP keys are supported")
+	}
+	h := sha1.New()
+	h.Write([]byte{0x99, byte(len(body) >> 8), byte(len(body))})
+	h.Write(body)
+	k := pgpKey{fingerprint: h.Sum(nil), body: body}
+	switch body[5] {
+	case 1, 2, 3:
+		n, rest, err := readMPI(body[6:])
+		if err != nil {
+			return pgpKey{}, err
+		}
+		e, _, err := readMPI(rest)
+		if err != nil {
+			return pgpKey{}, err
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() > 1<<31-1 {
+			return pgpKey{}, errors.New("unsupported RSA exponent")
+		}
+		k.pub = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}
+	}
+	return k, nil
+}
+
+func parsePGPSignature(body []byte) (pgpSignature, error) {
+	if len(body) < 6 || body[0] != 4 {
+		return pgpSignature{}, errors.New("only v4 OpenPGP signatures are supported")
+	}
+	if body[2] < 1 || body[2] > 3 {
+		return pgpSignature{}, fmt.Errorf("unsupported signature algorithm %d", body[2])
+	}
+	hash, ok := pgpHashes[body[3]]
+	if !ok {
+		return pgpSignature{}, fmt.Errorf("unsupported signature hash %d", body[3])
+	}
+	hashedLen := int(binary.BigEndian.Uint16(body[4:6]))
+	if len(body) < 6+hashedLen+2 {
+		return pgpSignature{}, errors.New("truncated signature")
+	}
+	sig := pgpSignature{sigType: body[1], hash: hash, hashed: body[:6+hashedLen]}
+	unhashedLen := int(binary.BigEndian.Uint16(body[6+hashedLen:]))
+	rest := body[6+hashedLen+2:]
+	if len(rest) < unhashedLen+2 {
+		return pgpSignature{}, errors.New("truncated signature")
+	}
+	for _, sub := range [][]byte{body[6 : 6+hashedLen], rest[:unhashedLen]} {
+		if id := pgpIssuer(sub); id != nil && sig.issuer == nil {
+			sig.issuer = id
+		}
+	}
+	value, _, err := readMPI(rest[unhashedLen+2:])
+	if err != nil {
+		return pgpSignature{}, err
+	}
+	sig.value = value
+	return sig, nil
+}
+
+// pgpIssuer returns the issuer fingerprint or key ID from subpackets.
+func pgpIssuer(b []byte) []byte {
+	var keyID []byte
+	for len(b) > 0 {
+		var n, off int
+		switch l := b[0]; {
+		case l < 192:
+			n, off = int(l), 1
+		case l < 255:
+			if len(b) < 2 {
+				return keyID
+			}
+			n, off = (int(l)-192)<<8+int(b[1])+192, 2
+		default:
+			if len(b) < 5 {
+				return keyID
+			}
+			n, off = int(binary.BigEndian.Uint32(b[1:5])), 5
+		}
+		if n < 1 || off+n > len(b) {
+			return keyID
+		}
+		data := b[off+1 : off+n]
+		switch b[off] & 0x7f {
+		case 33:
+			if len(data) == 21 {
+				return data[1:]
+			}
+		case 16:
+			if len(data) == 8 {
+				keyID = data
+			}
+		}
+		b = b[off+n:]
+	}
+	return keyID
+}
+
+func (s pgpSignature) verify(k pgpKey, signed ...[]byte) error {
+	if k.pub == nil {
+		return errors.New("signing key is not RSA")
+	}
+	h := s.hash.New()
+	for _, part := range signed {
+		h.Write(part)
+	}
+	h.Write(s.hashed)
+	var trailer [6]byte
+	trailer[0], trailer[1] = 4, 0xff
+	binary.BigEndian.PutUint32(trailer[2:], uint32(len(s.hashed)))
+	h.Write(trailer[:])
+	value := s.value
+	if size := k.pub.Size(); len(value) < size {
+		value = append(make([]byte, size-len(value)), value...)
+	}
+	return rsa.VerifyPKCS1v15(k.pub, s.hash, h.Sum(nil), value)
+}
+
+func keyPacketPrefix(body []byte) []byte {
+	return append([]byte{0x99, byte(len(body) >> 8), byte(len(body))}, body...)
+}
+
+// readPGPKeyring parses a public key block whose primary key has the given
+// fingerprint (hex, spaces ignored). It returns the primary key and every
+// subkey bound to it by a valid binding signature.
+func readPGPKeyring(block []byte, fingerprint string) ([]pgpKey, error) {
+	data, err := dearmor(block)
+	if err != nil {
+		return nil, err
+	}
+	packets, err := readPGPPackets(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(packets) == 0 || packets[0].tag != pgpTagPublicKey {
+		return nil, errors.New("not an OpenPGP public key")
+	}
+	primary, err := parsePGPKey(packets[0].body)
+	if err != nil {
+		return nil, err
+	}
+	want := strings.ToUpper(strings.ReplaceAll(fingerprint, " ", ""))
+	if got := strings.ToUpper(hex.EncodeToString(primary.fingerprint)); got != want {
+		return nil, fmt.Errorf("key fingerprint %s does not match the pinned %s", got, want)
+	}
+	keys := []pgpKey{primary}
+	var sub *pgpKey
+	for _, p := range packets[1:] {
+		switch p.tag {
+		case pgpTagPublicKey:
+			return keys, nil
+		case pgpTagPublicSub:
+			k, err := parsePGPKey(p.body)
+			if err != nil {
+				sub = nil
+				continue
+			}
+			sub = &k
+		case pgpTagSignature:
+			if sub == nil {
+				continue
+			}
+			sig, err := parsePGPSignature(p.body)
+			if err != nil || sig.sigType != pgpSigSubkeyBinding {
+				continue
+			}
+			if sig.verify(primary, keyPacketPrefix(primary.body), keyPacketPrefix(sub.body)) == nil {
+				keys = append(keys, *sub)
+				sub = nil
+			}
+		}
+	}
+	return keys, nil
+}
+
+// verifyDetachedSignature checks a detached signature over data made by one
+// of keys.
+func verifyDetachedSignature(keys []pgpKey, data, signature []byte) error {
+	raw, err := dearmor(signature)
+	if err != nil {
+		return err
+	}
+	packets, err := readPGPPackets(raw)
+	if err != nil {
+		return err
+	}
+	lastErr := errors.New("no signature found")
+	for _, p := range packets {
+		if p.tag != pgpTagSignature {
+			continue
+		}
+		sig, err := parsePGPSignature(p.body)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if sig.sigType != pgpSigBinary {
+			lastErr = fmt.Errorf("unexpected signature type 0x%02x", sig.sigType)
+			continue
+		}
+		matched := false
+		for _, k := range keys {
+			if sig.issuer != nil && !bytes.Equal(sig.issuer, k.fingerprint) && !bytes.Equal(sig.issuer, k.keyID()) {
+				continue
+			}
+			matched = true
+			if err := sig.verify(k, data); err != nil {
+				lastErr = fmt.Errorf("bad signature: %w", err)
+				continue
+			}
+			return nil
+		}
+		if !matched {
+			lastErr = fmt.Errorf("signed by unknown key %X", sig.issuer)
+		}
+	}
+	return lastErr
+}
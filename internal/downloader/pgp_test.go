@@ -0,0 +1,78 @@
+package downloader
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// The fixtures in testdata were made with gpg: release.key has a
+// certify-only primary key and an RSA signing subkey, the way the yt-dlp
+// release key is laid out, and SHA2-256SUMS.sig is a binary detached
+// signature made with that subkey. other.key and SHA2-256SUMS.other.sig
+// are an unrelated key and its signature over the same file.
+const (
+	testReleaseKeyFingerprint = "6CD2353AFE00842A00E5E2B8104D4EBD2B9C452E"
+	testOtherKeyFingerprint   = "094F737DF59D08F147CDA8F684CF019904C01FB7"
+)
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestReadPGPKeyring(t *testing.T) {
+	keys, err := readPGPKeyring(readTestdata(t, "release.key"), testReleaseKeyFingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("got %d keys, want the primary key and its signing subkey", len(keys))
+	}
+	if _, err := readPGPKeyring(readTestdata(t, "release.key"), ytdlpSigningKeyFingerprint); err == nil {
+		t.Error("a key with another fingerprint was accepted")
+	}
+}
+
+func TestVerifyDetachedSignature(t *testing.T) {
+	sums := readTestdata(t, "SHA2-256SUMS")
+	release, err := readPGPKeyring(readTestdata(t, "release.key"), testReleaseKeyFingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := readPGPKeyring(readTestdata(t, "other.key"), testOtherKeyFingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := []byte(strings.Replace(string(sums), "yt-dlp.exe", "yt-dlp.exf", 1))
+
+	tests := []struct {
+		name    string
+		keys    []pgpKey
+		data    []byte
+		sig     string
+		wantErr string
+	}{
+		{name: "good", keys: release, data: sums, sig: "SHA2-256SUMS.sig"},
+		{name: "tampered data", keys: release, data: tampered, sig: "SHA2-256SUMS.sig", wantErr: "bad signature"},
+		{name: "signed by another key", keys: release, data: sums, sig: "SHA2-256SUMS.other.sig", wantErr: "unknown key"},
+		{name: "checked against another key", keys: other, data: sums, sig: "SHA2-256SUMS.sig", wantErr: "unknown key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyDetachedSignature(tt.keys, tt.data, readTestdata(t, tt.sig))
+			switch {
+			case tt.wantErr == "":
+				if err != nil {
+					t.Fatalf("verifyDetachedSignature() = %v", err)
+				}
+			case err == nil || !strings.Contains(err.Error(), tt.wantErr):
+				t.Fatalf("verifyDetachedSignature() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// RemuxToMKV copies the streams of video plus the given subtitle files into a
+// Matroska container at dst, marking the first subtitle track as default.
+// Matroska accepts the codec/subtitle combinations MP4 rejects, so it is used
+// as the fallback when embedding into MP4 fails.
+func RemuxToMKV(ffmpeg, video string, subs []string, dst string) error {
+	args := []string{"-hide_banner", "-loglevel", "error", "-y", "-i", video}
+	for _, s := range subs {
+		args = append(args, "-i", s)
+	}
+	args = append(args, "-map", "0")
+	for i := range subs {
+		args = append(args, "-map", fmt.Sprintf("%d", i+1))
+	}
+	args = append(args, "-c", "copy")
+	for _, s := range subs {
+		if strings.EqualFold(filepath.Ext(s), ".vtt") {
+			args = append(args, "-c:s", "srt")
+			break
+		}
+	}
+	if len(subs) > 0 {
+		args = append(args, "-disposition:s:0", "default")
+	}
+	tmp := strings.TrimSuffix(dst, filepath.Ext(dst)) + ".remux.mkv"
+	args = append(args, tmp)
+
+	if _, err := Output(context.Background(), ffmpeg, args...); err != nil {
+		os.Remove(tmp)
+		var msg string
+		var ee *exec.ExitError
+		if errors.As(err, &ee) {
+			msg = strings.TrimSpace(string(ee.Stderr))
+		}
+		if msg != "" {
+			return fmt.Errorf("ffmpeg remux failed: %w: %s", err, msg)
+		}
+		return fmt.Errorf("ffmpeg remux failed: %w", err)
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// minFitVideoKbps is the lowest video bitrate FitToSize will encode at;
+// below it the result isn't worth watching.
+const minFitVideoKbps = 150
+
+// FitToSize re-encodes src into dst so the result stays under maxBytes:
+// H.264/AAC in two passes for video, MP3 at the fitting bitrate for audio.
+// duration is the length of src in seconds.
+func FitToSize(ctx context.Context, ffmpeg, src, dst string, duration float64, maxBytes int64, hasVideo bool) error {
+	if duration <= 0 {
+		return errors.New("the duration is unknown")
+	}
+	// Leave room for container overhead.
+	totalKbps := int(float64(maxBytes) * 8 * 0.96 / duration / 1000)
+	tmp := strings.TrimSuffix(dst, filepath.Ext(dst)) + ".fit" + filepath.Ext(dst)
+	defer os.Remove(tmp)
+	run := func(args ...string) error {
+		if _, err := Output(ctx, ffmpeg, append([]string{"-hide_banner", "-loglevel", "error", "-y"}, args...)...); err != nil {
+			var ee *exec.ExitError
+			if errors.As(err, &ee) && len(bytes.TrimSpace(ee.Stderr)) > 0 {
+				return fmt.Errorf("ffmpeg failed: %w: %s", err, bytes.TrimSpace(ee.Stderr))
+			}
+			return fmt.Errorf("ffmpeg failed: %w", err)
+		}
+		return nil
+	}
+	if !hasVideo {
+		kbps := min(totalKbps, 320)
+		if kbps < 32 {
+			return fmt.Errorf("%.0f minutes of audio can't fit in %d MB", duration/60, maxBytes/1000000)
+		}
+		if err := run("-i", src, "-vn", "-c:a", "libmp3lame", "-b:a", fmt.Sprintf("%dk", kbps), tmp); err != nil {
+			return err
+		}
+		return os.Rename(tmp, dst)
+	}
+	audioKbps := 128
+	if totalKbps < 1000 {
+		audioKbps = 64
+	}
+	videoKbps := totalKbps - audioKbps
+	if videoKbps < minFitVideoKbps {
+		return fmt.Errorf("%.0f minutes of video can't fit in %d MB", duration/60, maxBytes/1000000)
+	}
+	passLog, err := os.MkdirTemp("", "ytgui-fit-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(passLog)
+	video := []string{"-c:v", "libx264", "-preset", "medium", "-b:v", fmt.Sprintf("%dk", videoKbps), "-passlogfile", filepath.Join(passLog, "pass")}
+	first := append([]string{"-i", src}, video...)
+	if err := run(append(first, "-pass", "1", "-an", "-f", "null", os.DevNull)...); err != nil {
+		return err
+	}
+	second := append([]string{"-i", src}, video...)
+	second = append(second, "-pass", "2", "-c:a", "aac", "-b:a", fmt.Sprintf("%dk", audioKbps), "-movflags", "+faststart", tmp)
+	if err := run(second...); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+// sdrTonemapFilter maps PQ/HLG video to BT.709 SDR with the Hable curve.
+const sdrTonemapFilter = "zscale=t=linear:npl=100,format=gbrpf32le,zscale=p=bt709,tonemap=hable:desat=0,zscale=t=bt709:m=bt709:r=tv,format=yuv420p"
+
+// TonemapToSDR re-encodes an HDR video as 8-bit SDR H.264 at dst, copying
+// the audio and subtitle streams.
+func TonemapToSDR(ctx context.Context, ffmpeg, src, dst string) error {
+	tmp := strings.TrimSuffix(dst, filepath.Ext(dst)) + ".sdr" + filepath.Ext(dst)
+	defer os.Remove(tmp)
+	_, err := Output(ctx, ffmpeg, "-hide_banner", "-loglevel", "error", "-y", "-i", src,
+		"-map", "0:V", "-map", "0:a?", "-map", "0:s?", "-vf", sdrTonemapFilter,
+		"-c:v", "libx264", "-crf", "18", "-preset", "medium",
+		"-color_primaries", "bt709", "-color_trc", "bt709", "-colorspace", "bt709",
+		"-c:a", "copy", "-c:s", "copy", tmp)
+	if err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) && len(bytes.TrimSpace(ee.Stderr)) > 0 {
+			return fmt.Errorf("ffmpeg tonemap failed: %w: %s", err, bytes.TrimSpace(ee.Stderr))
+		}
+		return fmt.Errorf("ffmpeg tonemap failed: %w", err)
+	}
+	return os.Rename(tmp, dst)
+}
+
+// Clip formats ExportClip can produce.
+const (
+	ClipGIF  = "gif"
+	ClipWebM = "webm"
+)
+
+// gifArgs encode the first video stream as a looping GIF after filters. A
+// palette made from the clip itself keeps GIF's 256 colours close to the
+// source.
+func gifArgs(filters []string) []string {
+	chain := strings.Join(append(filters, "split[a][b];[a]palettegen[p];[b][p]paletteuse"), ",")
+	return []string{"-filter_complex", "[0:v]" + chain, "-an", "-loop", "0"}
+}
+
+// ExportClip converts a downloaded clip into an animated GIF or a VP9/Opus
+// WebM at dst. fps and width reduce the frame rate and scale the picture
+// keeping its aspect; 0 keeps the source value.
+func ExportClip(ctx context.Context, ffmpeg, src, dst, format string, fps, width int) error {
+	var filters []string
+	if fps > 0 {
+		filters = append(filters, fmt.Sprintf("fps=%d", fps))
+	}
+	if width > 0 {
+		// Encoders want even dimensions.
+		filters = append(filters, fmt.Sprintf("scale=%d:-2:flags=lanczos", width))
+	}
+	args := []string{"-hide_banner", "-loglevel", "error", "-y", "-i", src}
+	switch format {
+	case ClipGIF:
+		args = append(args, gifArgs(filters)...)
+	case ClipWebM:
+		if len(filters) > 0 {
+			args = append(args, "-vf", strings.Join(filters, ","))
+		}
+		args = append(args, "-map", "0:V", "-map", "0:a?", "-c:v", "libvpx-vp9", "-crf", "32", "-b:v", "0", "-row-mt", "1", "-c:a", "libopus", "-b:a", "96k")
+	default:
+		return fmt.Errorf("unsupported clip format %q", format)
+	}
+	tmp := strings.TrimSuffix(dst, filepath.Ext(dst)) + ".export" + filepath.Ext(dst)
+	defer os.Remove(tmp)
+	if _, err := Output(ctx, ffmpeg, append(args, tmp)...); err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) && len(bytes.TrimSpace(ee.Stderr)) > 0 {
+			return fmt.Errorf("ffmpeg clip export failed: %w: %s", err, bytes.TrimSpace(ee.Stderr))
+		}
+		return fmt.Errorf("ffmpeg clip export failed: %w", err)
+	}
+	return os.Rename(tmp, dst)
+}
@@ -0,0 +1,109 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+type MediaProbe struct {
+	FormatName   string
+	Duration     float64
+	VideoStreams int
+	AudioStreams int
+	// HDR is set when a video stream uses a PQ or HLG transfer.
+	HDR bool
+	// SampleRate is that of the first audio stream, in Hz.
+	SampleRate int
+	// Width and Height are those of the first video stream that isn't
+	// embedded cover art.
+	Width, Height int
+}
+
+// ProbeMedia reads container and stream information with ffprobe. A file
+// ffprobe can't parse at all (e.g. a missing moov atom) is reported as an
+// error carrying ffprobe's message.
+func ProbeMedia(ffprobe, path string) (MediaProbe, error) {
+	out, err := Output(context.Background(), ffprobe,
+		"-v", "error",
+		"-show_entries", "format=format_name,duration:stream=codec_type,color_transfer,sample_rate,width,height:stream_disposition=attached_pic",
+		"-of", "json",
+		path,
+	)
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return MediaProbe{}, fmt.Errorf("ffprobe: %s", strings.TrimSpace(string(ee.Stderr)))
+		}
+		return MediaProbe{}, err
+	}
+
+	var info struct {
+		Format struct {
+			FormatName string `json:"format_name"`
+			Duration   string `json:"duration"`
+		} `json:"format"`
+		Streams []struct {
+			CodecType     string `json:"codec_type"`
+			ColorTransfer string `json:"color_transfer"`
+			SampleRate    string `json:"sample_rate"`
+			Width         int    `json:"width"`
+			Height        int    `json:"height"`
+			Disposition   struct {
+				AttachedPic int `json:"attached_pic"`
+			} `json:"disposition"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return MediaProbe{}, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+	p := MediaProbe{FormatName: info.Format.FormatName}
+	p.Duration, _ = strconv.ParseFloat(info.Format.Duration, 64)
+	for _, s := range info.Streams {
+		switch s.CodecType {
+		case "video":
+			p.VideoStreams++
+			if s.ColorTransfer == "smpte2084" || s.ColorTransfer == "arib-std-b67" {
+				p.HDR = true
+			}
+			if p.Width == 0 && s.Disposition.AttachedPic == 0 {
+				p.Width, p.Height = s.Width, s.Height
+			}
+		case "audio":
+			p.AudioStreams++
+			if p.SampleRate == 0 {
+				p.SampleRate, _ = strconv.Atoi(s.SampleRate)
+			}
+		}
+	}
+	return p, nil
+}
+
+// VerifyMedia compares a probe with what the download should contain and
+// returns the problems found; an empty result means the file looks complete.
+// expected is the duration from the site's metadata, or 0 if unknown.
+// wantVideo and wantAudio say which streams the chosen formats had, so a
+// silent source isn't reported as broken.
+func VerifyMedia(p MediaProbe, expected float64, wantVideo, wantAudio bool) []string {
+	var problems []string
+	if wantVideo && p.VideoStreams == 0 {
+		problems = append(problems, "no video stream")
+	}
+	if wantAudio && p.AudioStreams == 0 {
+		problems = append(problems, "no audio stream")
+	}
+	switch {
+	case p.Duration <= 0:
+		problems = append(problems, "duration unreadable (file may be truncated)")
+	case expected > 0:
+		// Allow container rounding plus a small share of the length.
+		tolerance := math.Max(2, expected*0.02)
+		if math.Abs(p.Duration-expected) > tolerance {
+			problems = append(problems, fmt.Sprintf("duration %.0fs, expected %.0fs", p.Duration, expected))
+		}
+	}
+	return problems
+}
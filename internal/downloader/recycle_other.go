@@ -0,0 +1,11 @@
+//go:build !windows
+
+package downloader
+
+import "os"
+
+// RecycleFile deletes path. Only the Windows build has a Recycle Bin to move
+// files to.
+func RecycleFile(path string) error {
+	return os.Remove(path)
+}
@@ -0,0 +1,76 @@
+//go:build windows
+
+package downloader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	foDelete          = 0x0003
+	fofSilent         = 0x0004
+	fofNoConfirmation = 0x0010
+	fofAllowUndo      = 0x0040
+	fofNoErrorUI      = 0x0400
+)
+
+var procSHFileOperationW = syscall.NewLazyDLL("shell32.dll").NewProc("SHFileOperationW")
+
+// shFileOpStruct is SHFILEOPSTRUCTW with its 64-bit layout.
+type shFileOpStruct struct {
+	hwnd                  uintptr
+	wFunc                 uint32
+	pFrom                 *uint16
+	pTo                   *uint16
+	fFlags                uint16
+	fAnyOperationsAborted int32
+	hNameMappings         uintptr
+	lpszProgressTitle     *uint16
+}
+
+// RecycleFile moves path to the Recycle Bin, so a replaced or deleted
+// download can still be restored. Drives without a Recycle Bin, such as
+// most network shares, delete the file outright.
+func RecycleFile(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return err
+	}
+	// The shell doesn't take \\?\ paths, and pFrom is a list ending in an
+	// empty string, hence the extra NUL.
+	abs = shellPath(abs)
+	from, err := syscall.UTF16FromString(abs)
+	if err != nil {
+		return err
+	}
+	op := shFileOpStruct{
+		wFunc:  foDelete,
+		pFrom:  &append(from, 0)[0],
+		fFlags: fofAllowUndo | fofNoConfirmation | fofSilent | fofNoErrorUI,
+	}
+	if r, _, _ := procSHFileOperationW.Call(uintptr(unsafe.Pointer(&op))); r != 0 {
+		return fmt.Errorf("could not move %s to the Recycle Bin (error 0x%x)", filepath.Base(abs), r)
+	}
+	if op.fAnyOperationsAborted != 0 {
+		return fmt.Errorf("moving %s to the Recycle Bin was aborted", filepath.Base(abs))
+	}
+	return nil
+}
+
+// shellPath turns an extended-length path back into the form the shell
+// takes: \\?\UNC\server\share becomes \\server\share and \\?\C:\ becomes C:\.
+func shellPath(p string) string {
+	const unc = `\\?\UNC\`
+	if len(p) >= len(unc) && strings.EqualFold(p[:len(unc)], unc) {
+		return `\\` + p[len(unc):]
+	}
+	return strings.TrimPrefix(p, `\\?\`)
+}
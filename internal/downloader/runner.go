@@ -0,0 +1,117 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// Process is a started tool invocation.
+type Process interface {
+	Stdout() io.Reader
+	Stderr() io.Reader
+	// Wait blocks until the process exits, then releases its output
+	// streams; readers still blocked on them see EOF.
+	Wait() error
+	Kill() error
+}
+
+// Runner starts the external tools. The yt-dlp and ffprobe calls in this
+// package and the UI's download engine go through DefaultRunner, so tests
+// can replay canned yt-dlp transcripts and other backends (e.g. remote
+// execution) can be plugged in.
+type Runner interface {
+	Start(ctx context.Context, name string, args []string) (Process, error)
+}
+
+// DefaultRunner is the runner used by the package functions.
+var DefaultRunner Runner = ExecRunner{}
+
+// ExecRunner runs tools as local child processes.
+type ExecRunner struct{}
+
+// Start holds the shared tools lock until the process exits, so tools
+// aren't replaced under it. Without an app folder to lock in, the tool runs
+// unguarded.
+func (ExecRunner) Start(ctx context.Context, name string, args []string) (Process, error) {
+	lock, err := lockTools(ctx, toolsRunLock, false)
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	p, err := startExec(ctx, name, args)
+	if err != nil {
+		if lock != nil {
+			lock.unlock()
+		}
+		return nil, err
+	}
+	p.lock = lock
+	return p, nil
+}
+
+func startExec(ctx context.Context, name string, args []string) (*execProcess, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), "PYTHONIOENCODING=utf-8")
+	setCmdHideWindow(cmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &execProcess{cmd: cmd, stdout: stdout, stderr: stderr}, nil
+}
+
+type execProcess struct {
+	cmd    *exec.Cmd
+	stdout io.Reader
+	stderr io.Reader
+	lock   *fileLock
+}
+
+func (p *execProcess) Stdout() io.Reader { return p.stdout }
+func (p *execProcess) Stderr() io.Reader { return p.stderr }
+
+func (p *execProcess) Wait() error {
+	err := p.cmd.Wait()
+	if p.lock != nil {
+		p.lock.unlock()
+		p.lock = nil
+	}
+	return err
+}
+
+func (p *execProcess) Kill() error { return p.cmd.Process.Kill() }
+
+// Output runs a tool through DefaultRunner and returns its stdout. As with
+// exec.Cmd.Output, a failing command's stderr is attached to the returned
+// *exec.ExitError.
+func Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	p, err := DefaultRunner.Start(ctx, name, args)
+	if err != nil {
+		return nil, err
+	}
+	var stderr bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&stderr, p.Stderr())
+		close(done)
+	}()
+	out, readErr := io.ReadAll(p.Stdout())
+	<-done
+	err = p.Wait()
+	if ee, ok := err.(*exec.ExitError); ok {
+		ee.Stderr = stderr.Bytes()
+	}
+	if err == nil {
+		err = readErr
+	}
+	return out, err
+}
@@ -0,0 +1,147 @@
+package downloader
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// transcriptStep is one recorded tool run: the expected command line, what
+// it wrote and how it exited.
+type transcriptStep struct {
+	command        string
+	stdout, stderr strings.Builder
+	exit           int
+}
+
+// loadTranscript reads a transcript file: "$ " starts a run with its
+// command line, "1> " and "2> " lines are its stdout and stderr, and
+// "exit N" ends it. Lines starting with "#" are comments.
+func loadTranscript(t *testing.T, path string) []*transcriptStep {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var steps []*transcriptStep
+	var cur *transcriptStep
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "$ "):
+			cur = &transcriptStep{command: strings.TrimPrefix(line, "$ ")}
+			steps = append(steps, cur)
+		case cur == nil:
+			t.Fatalf("%s: output before the first command: %q", path, line)
+		case strings.HasPrefix(line, "1> "):
+			cur.stdout.WriteString(strings.TrimPrefix(line, "1> ") + "\n")
+		case strings.HasPrefix(line, "2> "):
+			cur.stderr.WriteString(strings.TrimPrefix(line, "2> ") + "\n")
+		case strings.HasPrefix(line, "exit "):
+			if cur.exit, err = strconv.Atoi(strings.TrimPrefix(line, "exit ")); err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+		default:
+			t.Fatalf("%s: unexpected line %q", path, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return steps
+}
+
+// fakeRunner replays transcript steps in order and fails the test when the
+// code under test runs something else.
+type fakeRunner struct {
+	t     *testing.T
+	steps []*transcriptStep
+}
+
+func (r *fakeRunner) Start(ctx context.Context, name string, args []string) (Process, error) {
+	r.t.Helper()
+	got := strings.Join(append([]string{name}, args...), " ")
+	if len(r.steps) == 0 {
+		r.t.Fatalf("unexpected run: %s", got)
+	}
+	step := r.steps[0]
+	r.steps = r.steps[1:]
+	if got != step.command {
+		r.t.Fatalf("ran %q\nwant %q", got, step.command)
+	}
+	return &fakeProcess{
+		stdout: strings.NewReader(step.stdout.String()),
+		stderr: strings.NewReader(step.stderr.String()),
+		exit:   step.exit,
+	}, nil
+}
+
+type fakeProcess struct {
+	stdout, stderr io.Reader
+	exit           int
+}
+
+func (p *fakeProcess) Stdout() io.Reader { return p.stdout }
+func (p *fakeProcess) Stderr() io.Reader { return p.stderr }
+func (p *fakeProcess) Kill() error       { return nil }
+
+func (p *fakeProcess) Wait() error {
+	if p.exit != 0 {
+		return fmt.Errorf("exit status %d", p.exit)
+	}
+	return nil
+}
+
+func TestMetadataTranscript(t *testing.T) {
+	runner := &fakeRunner{t: t, steps: loadTranscript(t, "testdata/metadata.transcript")}
+	old := DefaultRunner
+	DefaultRunner = runner
+	defer func() { DefaultRunner = old }()
+	ctx := context.Background()
+
+	meta, err := GetVideoMetadata(ctx, "yt-dlp", "https://www.youtube.com/watch?v=dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Title != "Never Gonna Give You Up" || meta.Uploader != "Rick Astley" || meta.Duration != 212 || len(meta.Formats) != 2 {
+		t.Errorf("unexpected metadata: %+v", meta)
+	}
+	if id := meta.VideoID(); id != VideoID("Youtube", "dQw4w9WgXcQ") {
+		t.Errorf("VideoID() = %q", id)
+	}
+	// The second lookup is answered from the cache; a run would exhaust
+	// the transcript out of order.
+	if _, err := GetVideoMetadata(ctx, "yt-dlp", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := DefaultRunner.Start(ctx, "yt-dlp", MetadataArgs("https://www.youtube.com/watch?v=blocked0000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, p.Stdout())
+	var tail []string
+	sc := bufio.NewScanner(p.Stderr())
+	for sc.Scan() {
+		tail = append(tail, sc.Text())
+	}
+	err = p.Wait()
+	if err == nil {
+		t.Fatal("blocked video: no error")
+	}
+	if kind := ClassifyError(err, tail); kind != GeoBlocked {
+		t.Errorf("ClassifyError() = %v, want %v", kind, GeoBlocked)
+	}
+	if len(runner.steps) != 0 {
+		t.Errorf("%d transcript step(s) not run", len(runner.steps))
+	}
+}
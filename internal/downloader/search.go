@@ -0,0 +1,94 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type SearchResult struct {
+	ID        string
+	Title     string
+	Channel   string
+	Duration  float64
+	URL       string
+	Thumbnail string
+}
+
+type searchEntry struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	Channel    string  `json:"channel"`
+	Uploader   string  `json:"uploader"`
+	Duration   float64 `json:"duration"`
+	URL        string  `json:"url"`
+	Thumbnails []struct {
+		URL    string `json:"url"`
+		Width  int    `json:"width"`
+		Height int    `json:"height"`
+	} `json:"thumbnails"`
+}
+
+// SearchYouTube runs a flat "ytsearchN:" query, which returns titles,
+// channels and thumbnails without resolving every video's formats.
+func SearchYouTube(ytdlp, query string, limit int) ([]SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("empty search query")
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	out, err := Output(context.Background(), ytdlp,
+		fmt.Sprintf("ytsearch%d:%s", limit, query),
+		"-J",
+		"--flat-playlist",
+		"--encoding", "utf-8",
+		"--no-warnings",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var info struct {
+		Entries []searchEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse search results: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(info.Entries))
+	for _, e := range info.Entries {
+		r := SearchResult{
+			ID:       e.ID,
+			Title:    strings.TrimSpace(e.Title),
+			Channel:  strings.TrimSpace(e.Channel),
+			Duration: e.Duration,
+			URL:      e.URL,
+		}
+		if r.Channel == "" {
+			r.Channel = strings.TrimSpace(e.Uploader)
+		}
+		if r.URL == "" || !strings.HasPrefix(r.URL, "http") {
+			if e.ID == "" {
+				continue
+			}
+			r.URL = "https://www.youtube.com/watch?v=" + e.ID
+		}
+		// Pick the smallest thumbnail that is still wide enough for a list row.
+		for _, t := range e.Thumbnails {
+			if t.URL == "" {
+				continue
+			}
+			if r.Thumbnail == "" || (t.Width >= 160 && t.Width < 480) {
+				r.Thumbnail = t.URL
+			}
+		}
+		if r.Thumbnail == "" && e.ID != "" {
+			r.Thumbnail = "https://i.ytimg.com/vi/" + e.ID + "/mqdefault.jpg"
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
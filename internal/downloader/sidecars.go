@@ -0,0 +1,79 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// SidecarOptions chooses the extra material saved next to a download.
+type SidecarOptions struct {
+	// Comments writes the info JSON with the video's comments.
+	Comments bool
+	// MaxComments caps how many comments YouTube returns; 0 fetches all.
+	MaxComments int
+	// LiveChat saves the chat of a stream or premiere as JSON.
+	LiveChat bool
+	// ExtractorArgs are passed on as in the download itself, keyed by site.
+	ExtractorArgs map[string]string
+}
+
+// DownloadSidecars fetches the material opts asks for without downloading
+// the media again and returns the files written next to base (the output
+// path without extension).
+func DownloadSidecars(ctx context.Context, ytdlp, url, base string, opts SidecarOptions) ([]string, error) {
+	b := NewArgsBuilder().
+		Switch("--skip-download").
+		Playlist(false).
+		Output("", "", ExtendedPath(base)+".%(ext)s").
+		Options([]string{"--encoding", "utf-8", "--no-warnings"})
+	args := map[string]string{}
+	for site, a := range opts.ExtractorArgs {
+		args[site] = a
+	}
+	var want []string
+	if opts.Comments {
+		b.Switch("--write-info-json").Switch("--write-comments")
+		if opts.MaxComments > 0 {
+			limit := "max_comments=" + strconv.Itoa(opts.MaxComments)
+			if a := args["youtube"]; a != "" {
+				limit = a + ";" + limit
+			}
+			args["youtube"] = limit
+		}
+		want = append(want, base+".info.json")
+	}
+	if opts.LiveChat {
+		b.Switch("--write-subs").Option("--sub-langs", "live_chat")
+		want = append(want, base+".live_chat.json")
+	}
+	if len(want) == 0 {
+		return nil, nil
+	}
+	for site, a := range args {
+		b.ExtractorArgs(site, a)
+	}
+	cmd, err := b.Build(url)
+	if err != nil {
+		return nil, err
+	}
+	_, err = Output(ctx, ytdlp, cmd...)
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(ee.Stderr)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	var written []string
+	for _, path := range want {
+		if _, err := os.Stat(path); err == nil {
+			written = append(written, path)
+		}
+	}
+	return written, nil
+}
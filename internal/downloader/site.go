@@ -0,0 +1,158 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SiteInfo describes what the extractor behind a URL can deliver.
+type SiteInfo struct {
+	Extractor    string
+	HasVideo     bool
+	HasSubtitles bool
+	IsLive       bool
+}
+
+// IsYouTubeURL reports whether raw points at YouTube, whose extractor the app
+// is tuned for; other sites go through DetectSite first.
+func IsYouTubeURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(strings.TrimPrefix(u.Hostname(), "www."))
+	return host == "youtu.be" || host == "youtube.com" || strings.HasSuffix(host, ".youtube.com")
+}
+
+// DetectSite asks yt-dlp (-J) which extractor handles raw and whether the
+// result has video streams and subtitles. A single video is looked up
+// through GetVideoMetadata, so the download that follows finds it cached.
+// Playlists are read flat, in which case streams are unknown and assumed to
+// include video.
+func DetectSite(ctx context.Context, ytdlp, raw string, playlist bool) (SiteInfo, error) {
+	if !playlist {
+		meta, err := GetVideoMetadata(ctx, ytdlp, raw)
+		if err != nil {
+			return SiteInfo{}, err
+		}
+		return siteFromMetadata(meta), nil
+	}
+	out, err := Output(ctx, ytdlp,
+		"-J",
+		"--flat-playlist",
+		"--encoding", "utf-8",
+		"--no-warnings",
+		raw,
+	)
+	if err != nil {
+		return SiteInfo{}, err
+	}
+	var info struct {
+		ExtractorKey      string                     `json:"extractor_key"`
+		Extractor         string                     `json:"extractor"`
+		Type              string                     `json:"_type"`
+		IsLive            bool                       `json:"is_live"`
+		Formats           []Format                   `json:"formats"`
+		VCodec            string                     `json:"vcodec"`
+		Subtitles         map[string]json.RawMessage `json:"subtitles"`
+		AutomaticCaptions map[string]json.RawMessage `json:"automatic_captions"`
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return SiteInfo{}, fmt.Errorf("failed to parse site info: %w", err)
+	}
+
+	site := SiteInfo{
+		Extractor:    info.ExtractorKey,
+		IsLive:       info.IsLive,
+		HasSubtitles: len(info.Subtitles) > 0 || len(info.AutomaticCaptions) > 0,
+	}
+	if site.Extractor == "" {
+		site.Extractor = info.Extractor
+	}
+	switch {
+	case info.Type == "playlist":
+		site.HasVideo = true
+	case len(info.Formats) > 0:
+		for _, f := range info.Formats {
+			if f.hasVideo() {
+				site.HasVideo = true
+				break
+			}
+		}
+	default:
+		// An empty vcodec means unknown, not audio-only.
+		site.HasVideo = info.VCodec != "none"
+	}
+	return site, nil
+}
+
+func siteFromMetadata(meta *VideoMetadata) SiteInfo {
+	site := SiteInfo{
+		Extractor:    meta.Extractor,
+		IsLive:       meta.IsLive,
+		HasSubtitles: len(meta.Subtitles) > 0 || len(meta.AutomaticCaptions) > 0,
+	}
+	if len(meta.Formats) == 0 {
+		site.HasVideo = meta.VCodec != "none"
+		return site
+	}
+	for _, f := range meta.Formats {
+		if f.hasVideo() {
+			site.HasVideo = true
+			break
+		}
+	}
+	return site
+}
+
+// PlaylistTitle returns the title of the playlist behind raw, reading only
+// its first item.
+func PlaylistTitle(ctx context.Context, ytdlp, raw string) (string, error) {
+	out, err := Output(ctx, ytdlp,
+		"--flat-playlist",
+		"--yes-playlist",
+		"--playlist-items", "1",
+		"--print", "%(playlist_title)s",
+		"--encoding", "utf-8",
+		"--no-warnings",
+		raw,
+	)
+	if err != nil {
+		return "", err
+	}
+	title, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	if title = strings.TrimSpace(title); title == "" || title == "NA" {
+		return "", fmt.Errorf("no playlist title for %s", raw)
+	}
+	return title, nil
+}
+
+// PlaylistCount returns how many items the playlist behind raw has. Watch
+// links with a list= parameter are read as the whole playlist.
+func PlaylistCount(ctx context.Context, ytdlp, raw string) (int, error) {
+	out, err := Output(ctx, ytdlp,
+		"-J",
+		"--flat-playlist",
+		"--yes-playlist",
+		"--encoding", "utf-8",
+		"--no-warnings",
+		raw,
+	)
+	if err != nil {
+		return 0, err
+	}
+	var info struct {
+		PlaylistCount int               `json:"playlist_count"`
+		Entries       []json.RawMessage `json:"entries"`
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return 0, fmt.Errorf("failed to parse playlist info: %w", err)
+	}
+	if info.PlaylistCount > 0 {
+		return info.PlaylistCount, nil
+	}
+	return len(info.Entries), nil
+}
@@ -0,0 +1,218 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// appDirRedirectFile, in the default app folder, holds the path the app
+// folder was moved to.
+const appDirRedirectFile = "location.txt"
+
+var toolFiles = map[string]bool{"yt-dlp.exe": true, "ffmpeg.exe": true, "ffprobe.exe": true}
+
+// StorageUsage is what ytgui keeps on disk, in bytes.
+type StorageUsage struct {
+	Dir     string
+	Tools   int64 // yt-dlp, ffmpeg and ffprobe
+	Staging int64 // downloads being merged on local disk
+	Data    int64 // settings, history, the saved queue and other files
+	Temp    int64 // unfinished tool downloads in the system temp folder
+}
+
+func (u StorageUsage) Total() int64 {
+	return u.Tools + u.Staging + u.Data + u.Temp
+}
+
+func dirSize(dir string) int64 {
+	var n int64
+	filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				n += info.Size()
+			}
+		}
+		return nil
+	})
+	return n
+}
+
+// MeasureStorage adds up the app folder and leftover temp downloads.
+func MeasureStorage() (StorageUsage, error) {
+	dir, err := appDir()
+	if err != nil {
+		return StorageUsage{}, err
+	}
+	u := StorageUsage{Dir: dir}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return u, err
+	}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		switch {
+		case e.IsDir() && e.Name() == "staging":
+			u.Staging += dirSize(path)
+		case toolFiles[strings.ToLower(e.Name())]:
+			u.Tools += dirSize(path)
+		default:
+			u.Data += dirSize(path)
+		}
+	}
+	for _, pattern := range downloadTempPatterns() {
+		matches, _ := filepath.Glob(pattern)
+		for _, m := range matches {
+			u.Temp += dirSize(m)
+		}
+	}
+	return u, nil
+}
+
+// RedownloadBinary replaces an installed tool with a fresh, verified copy.
+// The old file stays in place until the new one is complete.
+func RedownloadBinary(ctx context.Context, name string, progress DownloadProgressFunc) error {
+	path, err := BinaryPath(name)
+	if err != nil {
+		return err
+	}
+	lock, err := lockTools(ctx, toolsInstallLock, true)
+	if err != nil {
+		return err
+	}
+	defer lock.unlock()
+	if err := downloadBinaryByName(ctx, name, path, progress); err != nil {
+		return fmt.Errorf("could not download %s: %w", name, err)
+	}
+	return nil
+}
+
+// RelocateAppDir moves the app folder's contents to dst, which must be empty
+// or not exist yet, and uses dst as the app folder from then on. An empty
+// dst moves it back to the default location. Tools must not be running.
+//
+// Everything is copied before the redirect is switched, so a failed copy
+// leaves the current folder in use and untouched. The old copies are
+// removed last; one that can't be removed only costs space.
+func RelocateAppDir(dst string) (string, error) {
+	base, err := defaultAppDir()
+	if err != nil {
+		return "", err
+	}
+	cur, err := appDir()
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(dst) == "" {
+		dst = base
+	}
+	dst, err = filepath.Abs(dst)
+	if err != nil {
+		return "", err
+	}
+	if strings.EqualFold(dst, cur) {
+		return cur, nil
+	}
+	if rel, err := filepath.Rel(cur, dst); err == nil && !strings.HasPrefix(rel, "..") {
+		return "", errors.New("the new folder can't be inside the current one")
+	}
+	if dst != base {
+		if entries, err := os.ReadDir(dst); err == nil && len(entries) > 0 {
+			return "", fmt.Errorf("%s is not empty", dst)
+		}
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return "", err
+	}
+	entries, err := os.ReadDir(cur)
+	if err != nil {
+		return "", err
+	}
+	var moved []string
+	for _, e := range entries {
+		name := e.Name()
+		if cur == base && name == appDirRedirectFile {
+			continue
+		}
+		if name == toolsInstallLock || name == toolsRunLock {
+			// Lock files belong to the folder in use; they are created
+			// again where needed.
+			continue
+		}
+		if err := copyEntry(filepath.Join(cur, name), filepath.Join(dst, name)); err != nil {
+			for _, m := range moved {
+				os.RemoveAll(filepath.Join(dst, m))
+			}
+			os.RemoveAll(filepath.Join(dst, name))
+			return "", fmt.Errorf("could not copy %s: %w", name, err)
+		}
+		moved = append(moved, name)
+	}
+	redirect := filepath.Join(base, appDirRedirectFile)
+	if dst == base {
+		err = os.Remove(redirect)
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	} else {
+		err = os.WriteFile(redirect, []byte(dst+"\n"), 0o644)
+	}
+	if err != nil {
+		for _, m := range moved {
+			os.RemoveAll(filepath.Join(dst, m))
+		}
+		return "", err
+	}
+	for _, m := range moved {
+		os.RemoveAll(filepath.Join(cur, m))
+	}
+	if cur != base {
+		// Only succeeds once the folder is empty.
+		os.Remove(cur)
+	}
+	return dst, nil
+}
+
+// copyEntry copies the file or directory tree src to dst.
+func copyEntry(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return copyFile(p, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -1,10 +1,8 @@
 package downloader
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
 	"sort"
 	"strings"
 )
@@ -35,44 +33,28 @@ func isOriginalLang(code, videoLang string) bool {
 	return normalizeLangCode(code) == normalizeLangCode(videoLang)
 }
 
-func GetAvailableSubtitles(ytdlp, url string) ([]SubOption, error) {
-	cmd := exec.Command(ytdlp,
-		"--print", "%(subtitles)j",
-		"--print", "%(automatic_captions)j",
-		"--print", "%(language)s",
-		"--encoding", "utf-8",
-		"--no-warnings",
-		"--skip-download",
-		"--no-playlist",
-		url,
-	)
-	cmd.Env = append(os.Environ(), "PYTHONIOENCODING=utf-8")
-
-	setCmdHideWindow(cmd)
+// isTranslatedCaption reports whether an automatic_captions entry is a
+// machine translation; YouTube serves those with a tlang URL parameter.
+func isTranslatedCaption(files []SubtitleFile) bool {
+	for _, f := range files {
+		if strings.Contains(f.URL, "tlang=") {
+			return true
+		}
+	}
+	return false
+}
 
-	out, err := cmd.Output()
+func GetAvailableSubtitles(ctx context.Context, ytdlp, url string) ([]SubOption, error) {
+	meta, err := GetVideoMetadata(ctx, ytdlp, url)
 	if err != nil {
 		return nil, err
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-	if len(lines) < 3 {
-		if len(lines) < 2 {
-			return nil, fmt.Errorf("failed to parse subtitle data")
-		}
-	}
-
-	var manualMap map[string]interface{}
-	var autoMap map[string]interface{}
-	videoLang := "en"
-
-	json.Unmarshal([]byte(lines[0]), &manualMap)
-	json.Unmarshal([]byte(lines[1]), &autoMap)
-	if len(lines) >= 3 {
-		videoLang = strings.TrimSpace(lines[2])
-		if videoLang == "NA" || videoLang == "" {
-			videoLang = "en"
-		}
+	manualMap := meta.Subtitles
+	autoMap := meta.AutomaticCaptions
+	videoLang := strings.TrimSpace(meta.Language)
+	if videoLang == "" {
+		videoLang = "en"
 	}
 
 	var options []SubOption
@@ -97,14 +79,19 @@ func GetAvailableSubtitles(ytdlp, url string) ([]SubOption, error) {
 	sort.Strings(autoCodes)
 	for _, code := range autoCodes {
 		label := fmt.Sprintf("Autogenerated (%s)", code)
-		if isOriginalLang(code, videoLang) {
+		translated := isTranslatedCaption(autoMap[code])
+		switch {
+		case isOriginalLang(code, videoLang):
 			label = fmt.Sprintf("Autogenerated (Original - %s)", code)
+		case translated:
+			label = fmt.Sprintf("Auto-translated (%s)", code)
 		}
 		options = append(options, SubOption{
-			Label:      label,
-			Code:       code,
-			IsAuto:     true,
-			IsOriginal: isOriginalLang(code, videoLang),
+			Label:        label,
+			Code:         code,
+			IsAuto:       true,
+			IsOriginal:   isOriginalLang(code, videoLang),
+			IsTranslated: translated,
 		})
 	}
 
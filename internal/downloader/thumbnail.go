@@ -0,0 +1,45 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ThumbnailFormats are the image formats thumbnails can be converted to.
+var ThumbnailFormats = []string{"jpg", "png"}
+
+// DownloadThumbnail saves the largest thumbnail the site offers, converted
+// to format (jpg or png), at base plus the format's extension. yt-dlp's
+// thumbnail list holds cover images only, never storyboard sheets.
+func DownloadThumbnail(ytdlp, ffmpeg, url, base, format string) (string, error) {
+	b := NewArgsBuilder().
+		FFmpegLocation(filepath.Dir(ffmpeg)).
+		Switch("--skip-download").
+		Switch("--write-thumbnail").
+		Option("--convert-thumbnails", format).
+		Playlist(false).
+		Output("", "", ExtendedPath(base)+".%(ext)s").
+		Options([]string{"--encoding", "utf-8", "--no-warnings"})
+	args, err := b.Build(url)
+	if err != nil {
+		return "", err
+	}
+	_, err = Output(context.Background(), ytdlp, args...)
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(ee.Stderr)))
+	}
+	if err != nil {
+		return "", err
+	}
+	path := base + "." + format
+	if _, err := os.Stat(path); err != nil {
+		return "", errors.New("the site offers no thumbnail for this video")
+	}
+	return path, nil
+}
@@ -0,0 +1,125 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// toolDigestsName is the manifest of the installed tools' SHA-256 digests,
+// in sha256sum format. It is updated whenever the app installs or replaces
+// a tool, so a tool changed by anything else shows up at the next start.
+const toolDigestsName = "tools.sha256"
+
+// managedTools are the tools the app installs into its folder. ffprobe
+// comes from the ffmpeg archive, so it is listed after ffmpeg.
+var managedTools = []string{"yt-dlp.exe", "ffmpeg.exe", "ffprobe.exe"}
+
+func readToolDigests(dir string) map[string]string {
+	digests := map[string]string{}
+	data, err := os.ReadFile(filepath.Join(dir, toolDigestsName))
+	if err != nil {
+		return digests
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			digests[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+		}
+	}
+	return digests
+}
+
+func writeToolDigests(dir string, digests map[string]string) error {
+	names := make([]string, 0, len(digests))
+	for name := range digests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s  %s\n", digests[name], name)
+	}
+	return os.WriteFile(filepath.Join(dir, toolDigestsName), []byte(b.String()), 0o644)
+}
+
+// recordToolDigest notes the digest of the tool just installed at path.
+// Callers hold the install lock.
+func recordToolDigest(path string) error {
+	sum, err := computeFileSHA256(path)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	digests := readToolDigests(dir)
+	digests[filepath.Base(path)] = sum
+	return writeToolDigests(dir, digests)
+}
+
+// VerifyInstalledBinaries checks the installed tools against the digests
+// recorded when they were installed and removes those that no longer
+// match, so the next tool check installs them again. A broken ffprobe
+// takes ffmpeg with it, since both come from the same archive. Tools
+// installed before digests were kept are recorded as they are. It returns
+// the names of the removed tools.
+func VerifyInstalledBinaries(ctx context.Context) ([]string, error) {
+	dir, err := appDir()
+	if err != nil {
+		return nil, err
+	}
+	lock, err := lockTools(ctx, toolsInstallLock, true)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.unlock()
+
+	digests := readToolDigests(dir)
+	changed := false
+	bad := map[string]bool{}
+	for _, name := range managedTools {
+		sum, err := computeFileSHA256(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			if _, ok := digests[name]; ok {
+				delete(digests, name)
+				changed = true
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		want, ok := digests[name]
+		switch {
+		case !ok:
+			digests[name] = sum
+			changed = true
+		case want != sum:
+			bad[name] = true
+		}
+	}
+	if bad["ffprobe.exe"] {
+		if _, err := os.Stat(filepath.Join(dir, "ffmpeg.exe")); err == nil {
+			bad["ffmpeg.exe"] = true
+		}
+	}
+	var removed []string
+	for _, name := range managedTools {
+		if !bad[name] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return removed, fmt.Errorf("could not remove damaged %s: %w", name, err)
+		}
+		delete(digests, name)
+		changed = true
+		removed = append(removed, name)
+	}
+	if changed {
+		if err := writeToolDigests(dir, digests); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
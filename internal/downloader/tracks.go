@@ -0,0 +1,131 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Track is one part of a long recording, in seconds from its start. An End
+// of 0 means the end of the recording.
+type Track struct {
+	Title string
+	Start float64
+	End   float64
+}
+
+// GetTracks returns the chapters of a video, or when it has none the
+// tracklist written into its description. An empty result means neither
+// was found.
+func GetTracks(ctx context.Context, ytdlp, url string) ([]Track, error) {
+	meta, err := GetVideoMetadata(ctx, ytdlp, url)
+	if err != nil {
+		return nil, err
+	}
+	if len(meta.Chapters) > 1 {
+		tracks := make([]Track, len(meta.Chapters))
+		for i, c := range meta.Chapters {
+			tracks[i] = Track{Title: strings.TrimSpace(c.Title), Start: c.StartTime, End: c.EndTime}
+		}
+		return tracks, nil
+	}
+	return ParseTracklist(meta.Description, meta.Duration), nil
+}
+
+var (
+	tracklistTimeRE = regexp.MustCompile(`[\[(]?\b((?:\d{1,2}:)?\d{1,2}:\d{2})\b[\])]?`)
+	// tracklistJunk is what surrounds a title on a tracklist line: numbering,
+	// dashes and separators.
+	tracklistJunk = " \t-–—|:.)]"
+)
+
+// ParseTracklist reads a "0:00 Title" style tracklist from free text, such
+// as a video description. Lines without a timestamp are ignored, and at
+// least two tracks in increasing order are needed; duration, when known,
+// drops timestamps past the end.
+func ParseTracklist(text string, duration float64) []Track {
+	var tracks []Track
+	for _, line := range strings.Split(text, "\n") {
+		loc := tracklistTimeRE.FindStringSubmatchIndex(line)
+		if loc == nil {
+			continue
+		}
+		start := clockSeconds(line[loc[2]:loc[3]])
+		if len(tracks) > 0 && start <= tracks[len(tracks)-1].Start {
+			continue
+		}
+		if duration > 0 && start >= duration {
+			continue
+		}
+		title := strings.Trim(line[:loc[0]], tracklistJunk+"0123456789")
+		if rest := strings.Trim(line[loc[1]:], tracklistJunk); rest != "" {
+			// The title usually follows the time; a leading part is kept
+			// only when nothing follows.
+			title = rest
+		}
+		if title == "" {
+			title = fmt.Sprintf("Track %d", len(tracks)+1)
+		}
+		tracks = append(tracks, Track{Title: title, Start: start})
+	}
+	if len(tracks) < 2 {
+		return nil
+	}
+	for i := range tracks[:len(tracks)-1] {
+		tracks[i].End = tracks[i+1].Start
+	}
+	return tracks
+}
+
+// clockSeconds reads "m:ss" or "h:mm:ss".
+func clockSeconds(s string) float64 {
+	total := 0
+	for _, p := range strings.Split(s, ":") {
+		n, _ := strconv.Atoi(p)
+		total = total*60 + n
+	}
+	return float64(total)
+}
+
+// SplitAudio cuts src into one file per track in dir, named "NN - Title"
+// with src's extension, copying the audio so the quality is untouched.
+// Each file is tagged with its title, track number and album. progress is
+// called after each track.
+func SplitAudio(ctx context.Context, ffmpeg, src, dir string, tracks []Track, album, artist string, progress func(done, total int)) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	ext := filepath.Ext(src)
+	var files []string
+	for i, t := range tracks {
+		name := fmt.Sprintf("%02d - %s%s", i+1, sanitizeFileNamePart(t.Title), ext)
+		dst := filepath.Join(dir, name)
+		args := []string{"-ss", strconv.FormatFloat(t.Start, 'f', 3, 64)}
+		if t.End > t.Start {
+			args = append(args, "-to", strconv.FormatFloat(t.End, 'f', 3, 64))
+		}
+		args = append(args, "-i", src, "-map", "0:a:0", "-map", "0:v?", "-c", "copy",
+			"-map_metadata", "-1",
+			"-metadata", "title="+t.Title,
+			"-metadata", fmt.Sprintf("track=%d/%d", i+1, len(tracks)),
+			"-metadata", "album="+album)
+		if artist != "" {
+			args = append(args, "-metadata", "artist="+artist, "-metadata", "album_artist="+artist)
+		}
+		if strings.EqualFold(ext, ".mp3") {
+			args = append(args, "-id3v2_version", "3")
+		}
+		if _, err := runFFmpegProgress(ctx, ffmpeg, 0, nil, append(args, dst)...); err != nil {
+			return files, fmt.Errorf("track %d (%s): %w", i+1, t.Title, err)
+		}
+		files = append(files, dst)
+		if progress != nil {
+			progress(i+1, len(tracks))
+		}
+	}
+	return files, nil
+}
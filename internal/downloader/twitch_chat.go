@@ -0,0 +1,271 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// chatLineSeconds is how long each chat message stays on screen in the
+	// SSA sidecar.
+	chatLineSeconds = 6
+
+	// twitchGQLURL serves the comments of a VOD. The v5 "rechat" API that
+	// yt-dlp's subtitle track used was shut down with the rest of Kraken.
+	twitchGQLURL = "https://gql.twitch.tv/gql"
+	// twitchClientID is the public client ID of the Twitch web player,
+	// which the GQL endpoint requires from anonymous callers.
+	twitchClientID = "kimne78kx3ncx6brgo4mv6wki5h1ko"
+
+	chatPageTimeout = 20 * time.Second
+	// chatMaxPages bounds the paging; a page holds a few dozen messages.
+	chatMaxPages = 100000
+)
+
+const chatCommentsQuery = `query($id: ID!, $cursor: Cursor) {
+  video(id: $id) {
+    comments(after: $cursor) {
+      edges { cursor node { contentOffsetSeconds commenter { displayName } message { fragments { text } } } }
+      pageInfo { hasNextPage }
+    }
+  }
+}`
+
+// ErrNoChatReplay is returned when Twitch has no chat replay for a VOD
+// (clips and expired or subscriber-only VODs have none).
+var ErrNoChatReplay = errors.New("no chat replay available")
+
+// chatReplayFile is the JSON written next to the video, in the layout the
+// old rechat API used so earlier exports stay readable.
+type chatReplayFile struct {
+	Comments []chatReplayComment `json:"comments"`
+}
+
+type chatReplayComment struct {
+	ContentOffsetSeconds float64 `json:"content_offset_seconds"`
+	Commenter            struct {
+		DisplayName string `json:"display_name"`
+	} `json:"commenter"`
+	Message struct {
+		Body string `json:"body"`
+	} `json:"message"`
+}
+
+// TwitchVideoID returns the ID of a twitch.tv/videos/<id> link.
+func TwitchVideoID(rawURL string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", false
+	}
+	rest, ok := strings.CutPrefix(u.Path, "/videos/")
+	id, _, _ := strings.Cut(rest, "/")
+	if !ok || id == "" || strings.Trim(id, "0123456789") != "" {
+		return "", false
+	}
+	return id, true
+}
+
+// DownloadChatReplay pages through the chat replay of a Twitch VOD with the
+// GQL API and saves it next to base (the output path without extension). It
+// returns the path of the JSON file.
+func DownloadChatReplay(ctx context.Context, videoURL, base string) (string, error) {
+	id, ok := TwitchVideoID(videoURL)
+	if !ok {
+		return "", ErrNoChatReplay
+	}
+	var replay chatReplayFile
+	cursor := ""
+	for page := 0; page < chatMaxPages; page++ {
+		comments, next, more, err := fetchChatPage(ctx, id, cursor)
+		if err != nil {
+			return "", err
+		}
+		replay.Comments = append(replay.Comments, comments...)
+		if !more || next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(replay.Comments) == 0 {
+		return "", ErrNoChatReplay
+	}
+	data, err := json.Marshal(replay)
+	if err != nil {
+		return "", err
+	}
+	path := base + ".chat.json"
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// fetchChatPage returns one page of comments after cursor ("" for the
+// start), the cursor of its last comment and whether more follow.
+func fetchChatPage(ctx context.Context, id, cursor string) ([]chatReplayComment, string, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, chatPageTimeout)
+	defer cancel()
+	vars := map[string]any{"id": id, "cursor": nil}
+	if cursor != "" {
+		vars["cursor"] = cursor
+	}
+	body, err := json.Marshal(map[string]any{"query": chatCommentsQuery, "variables": vars})
+	if err != nil {
+		return nil, "", false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, twitchGQLURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, "", false, err
+	}
+	req.Header.Set("Client-Id", twitchClientID)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ytgui")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", false, fmt.Errorf("chat request failed: %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", false, err
+	}
+	return parseChatPage(data)
+}
+
+func parseChatPage(data []byte) ([]chatReplayComment, string, bool, error) {
+	var page struct {
+		Data struct {
+			Video *struct {
+				Comments *struct {
+					Edges []struct {
+						Cursor string `json:"cursor"`
+						Node   struct {
+							ContentOffsetSeconds float64 `json:"contentOffsetSeconds"`
+							Commenter            *struct {
+								DisplayName string `json:"displayName"`
+							} `json:"commenter"`
+							Message struct {
+								Fragments []struct {
+									Text string `json:"text"`
+								} `json:"fragments"`
+							} `json:"message"`
+						} `json:"node"`
+					} `json:"edges"`
+					PageInfo struct {
+						HasNextPage bool `json:"hasNextPage"`
+					} `json:"pageInfo"`
+				} `json:"comments"`
+			} `json:"video"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, "", false, fmt.Errorf("failed to parse chat replay: %w", err)
+	}
+	if len(page.Errors) > 0 {
+		return nil, "", false, fmt.Errorf("chat request failed: %s", page.Errors[0].Message)
+	}
+	video := page.Data.Video
+	if video == nil || video.Comments == nil {
+		return nil, "", false, ErrNoChatReplay
+	}
+	var comments []chatReplayComment
+	cursor := ""
+	for _, e := range video.Comments.Edges {
+		var c chatReplayComment
+		c.ContentOffsetSeconds = e.Node.ContentOffsetSeconds
+		// Deleted accounts come back without a commenter.
+		if e.Node.Commenter != nil {
+			c.Commenter.DisplayName = e.Node.Commenter.DisplayName
+		}
+		var text strings.Builder
+		for _, f := range e.Node.Message.Fragments {
+			text.WriteString(f.Text)
+		}
+		c.Message.Body = text.String()
+		comments = append(comments, c)
+		cursor = e.Cursor
+	}
+	return comments, cursor, video.Comments.PageInfo.HasNextPage, nil
+}
+
+type chatMessage struct {
+	Offset float64
+	Author string
+	Text   string
+}
+
+func readChatReplay(path string) ([]chatMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var replay chatReplayFile
+	if err := json.Unmarshal(data, &replay); err != nil {
+		return nil, fmt.Errorf("failed to parse chat replay: %w", err)
+	}
+	msgs := make([]chatMessage, 0, len(replay.Comments))
+	for _, c := range replay.Comments {
+		if strings.TrimSpace(c.Message.Body) == "" {
+			continue
+		}
+		msgs = append(msgs, chatMessage{
+			Offset: c.ContentOffsetSeconds,
+			Author: c.Commenter.DisplayName,
+			Text:   c.Message.Body,
+		})
+	}
+	return msgs, nil
+}
+
+// ChatReplayToSSA converts a chat replay JSON file into an SSA (v4+)
+// subtitle file that scrolls the messages in the top left corner, so players
+// can show the chat alongside an archived stream.
+func ChatReplayToSSA(jsonPath, ssaPath string) error {
+	msgs, err := readChatReplay(jsonPath)
+	if err != nil {
+		return err
+	}
+	if len(msgs) == 0 {
+		return ErrNoChatReplay
+	}
+
+	var b strings.Builder
+	b.WriteString("[Script Info]\nScriptType: v4.00+\nPlayResX: 1280\nPlayResY: 720\nWrapStyle: 0\n\n")
+	b.WriteString("[V4+ Styles]\n")
+	b.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	b.WriteString("Style: Chat,Arial,20,&H00FFFFFF,&H00FFFFFF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,1,0,7,10,10,10,1\n\n")
+	b.WriteString("[Events]\n")
+	b.WriteString("Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	for _, m := range msgs {
+		fmt.Fprintf(&b, "Dialogue: 0,%s,%s,Chat,,0,0,0,,{\\b1}%s{\\b0}: %s\n",
+			ssaTime(m.Offset), ssaTime(m.Offset+chatLineSeconds),
+			ssaEscape(m.Author), ssaEscape(m.Text))
+	}
+	return os.WriteFile(ssaPath, []byte(b.String()), 0o644)
+}
+
+func ssaTime(sec float64) string {
+	cs := int(sec*100 + 0.5)
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, cs/6000%60, cs/100%60, cs%100)
+}
+
+// ssaEscape keeps chat text from being read as override tags or line breaks.
+func ssaEscape(s string) string {
+	s = strings.NewReplacer("{", "(", "}", ")", "\\", "/", "\r", " ", "\n", " ").Replace(s)
+	return strings.TrimSpace(s)
+}
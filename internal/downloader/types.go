@@ -5,4 +5,7 @@ type SubOption struct {
 	Code       string
 	IsAuto     bool
 	IsOriginal bool
+	// IsTranslated marks auto captions machine-translated from another
+	// language (YouTube's tlang variants).
+	IsTranslated bool
 }
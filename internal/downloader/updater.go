@@ -11,7 +11,6 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 )
@@ -23,22 +22,37 @@ type release struct {
 const (
 	latestReleaseAPIURL = "https://api.github.com/repos/yt-dlp/yt-dlp/releases/latest"
 	latestBinaryURL     = "https://github.com/yt-dlp/yt-dlp/releases/latest/download/yt-dlp.exe"
+
+	nightlyReleaseAPIURL = "https://api.github.com/repos/yt-dlp/yt-dlp-nightly-builds/releases/latest"
+	nightlyDownloadURL   = "https://github.com/yt-dlp/yt-dlp-nightly-builds/releases/latest/download/"
 )
 
-func getLocalVersion(path string) (string, error) {
-	cmd := exec.Command(path, "--version")
-	out, err := cmd.Output()
+// ytdlpChannel is where a yt-dlp build is published. Nightly builds have
+// their own repository, with checksums signed by the same release key.
+type ytdlpChannel struct {
+	api       string
+	binary    string
+	checksums string
+}
+
+var (
+	stableChannel  = ytdlpChannel{latestReleaseAPIURL, latestBinaryURL, latestBinaryChecksumsURL}
+	nightlyChannel = ytdlpChannel{nightlyReleaseAPIURL, nightlyDownloadURL + "yt-dlp.exe", nightlyDownloadURL + "SHA2-256SUMS"}
+)
+
+func getLocalVersion(ctx context.Context, path string) (string, error) {
+	out, err := Output(ctx, path, "--version")
 	if err != nil {
 		return "", err
 	}
 	return strings.TrimSpace(string(out)), nil
 }
 
-func getLatestVersion(ctx context.Context, client *http.Client) (string, error) {
+func getLatestVersion(ctx context.Context, client *http.Client, ch ytdlpChannel) (string, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, latestReleaseAPIURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ch.api, nil)
 	if err != nil {
 		return "", err
 	}
@@ -65,21 +79,30 @@ func getLatestVersion(ctx context.Context, client *http.Client) (string, error)
 	return strings.TrimSpace(r.TagName), nil
 }
 
+// needsUpdate reports whether latest is newer than local. yt-dlp versions
+// are zero-padded dates, with nightly builds adding the time
+// ("2024.08.06.232812"), so they compare as strings; a nightly build isn't
+// replaced by the older release of the same day.
 func needsUpdate(local, latest string) bool {
 	local = strings.TrimPrefix(strings.TrimSpace(local), "v")
 	latest = strings.TrimPrefix(strings.TrimSpace(latest), "v")
-	return local != "" && latest != "" && local != latest
+	return local != "" && latest != "" && latest > local
 }
 
-func downloadLatest(ctx context.Context, client *http.Client, path string, progress DownloadProgressFunc) error {
+func downloadLatest(ctx context.Context, client *http.Client, path string, ch ytdlpChannel, progress DownloadProgressFunc) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	expectedSHA, err := resolveYTDLPSHA256(ctx)
+	lock, err := lockTools(ctx, toolsInstallLock, true)
+	if err != nil {
+		return err
+	}
+	defer lock.unlock()
+	expectedSHA, err := resolveYTDLPSHA256(ctx, ch.checksums)
 	if err != nil {
 		return err
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, latestBinaryURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ch.binary, nil)
 	if err != nil {
 		return err
 	}
@@ -96,7 +119,7 @@ func downloadLatest(ctx context.Context, client *http.Client, path string, progr
 
 	emitDownloadProgress(progress, DownloadStats{
 		Tool:            "yt-dlp.exe",
-		URL:             latestBinaryURL,
+		URL:             ch.binary,
 		Phase:           "start",
 		DownloadedBytes: 0,
 		TotalBytes:      resp.ContentLength,
@@ -125,7 +148,7 @@ func downloadLatest(ctx context.Context, client *http.Client, path string, progr
 		onAdd: func(downloaded int64) {
 			emitDownloadProgress(progress, DownloadStats{
 				Tool:            "yt-dlp.exe",
-				URL:             latestBinaryURL,
+				URL:             ch.binary,
 				Phase:           "downloading",
 				DownloadedBytes: downloaded,
 				TotalBytes:      resp.ContentLength,
@@ -150,13 +173,16 @@ func downloadLatest(ctx context.Context, client *http.Client, path string, progr
 		return err
 	}
 
-	if err := os.Rename(tmp, path); err != nil {
+	if err := whileToolsIdle(func() error { return os.Rename(tmp, path) }); err != nil {
 		os.Remove(tmp)
 		return err
 	}
+	if err := recordToolDigest(path); err != nil {
+		return err
+	}
 	emitDownloadProgress(progress, DownloadStats{
 		Tool:            "yt-dlp.exe",
-		URL:             latestBinaryURL,
+		URL:             ch.binary,
 		Phase:           "done",
 		DownloadedBytes: counter.total,
 		TotalBytes:      resp.ContentLength,
@@ -180,13 +206,18 @@ func TryUpdateYTDLPWithProgressCtx(ctx context.Context, path string, logf func(s
 	apiClient := &http.Client{Timeout: 15 * time.Second}
 	downloadClient := &http.Client{Timeout: downloadTimeout}
 
-	local, err := getLocalVersion(path)
+	if ToolsInUse() {
+		logf("yt-dlp update deferred while a download is running; it is checked again at the next start.")
+		return ErrToolsInUse
+	}
+
+	local, err := getLocalVersion(ctx, path)
 	if err != nil {
 		logf(fmt.Sprintf("Could not read local yt-dlp version: %v", err))
 		return err
 	}
 
-	latest, err := getLatestVersion(ctx, apiClient)
+	latest, err := getLatestVersion(ctx, apiClient, stableChannel)
 	if err != nil {
 		logf(fmt.Sprintf("Could not check latest yt-dlp version: %v", err))
 		return err
@@ -198,10 +229,57 @@ func TryUpdateYTDLPWithProgressCtx(ctx context.Context, path string, logf func(s
 	}
 
 	logf(fmt.Sprintf("Updating yt-dlp from %s to %s...", local, latest))
-	if err := downloadLatest(ctx, downloadClient, path, progress); err != nil {
+	if err := downloadLatest(ctx, downloadClient, path, stableChannel, progress); err != nil {
 		logf(fmt.Sprintf("yt-dlp update failed: %v", err))
 		return err
 	}
 	logf("yt-dlp update complete.")
 	return nil
 }
+
+// YTDLPUpdate is a yt-dlp build newer than the installed one.
+type YTDLPUpdate struct {
+	Current string
+	Version string
+	// Nightly is set for a nightly build, offered when no release is newer.
+	Nightly bool
+}
+
+// FindYTDLPUpdate looks for a newer yt-dlp than the one at path: the latest
+// release first and, with nightly set, the latest nightly build after that.
+// ok is false when path is already the newest.
+func FindYTDLPUpdate(ctx context.Context, path string, nightly bool) (u YTDLPUpdate, ok bool, err error) {
+	local, err := getLocalVersion(ctx, path)
+	if err != nil {
+		return YTDLPUpdate{}, false, fmt.Errorf("could not read local yt-dlp version: %w", err)
+	}
+	client := &http.Client{Timeout: 15 * time.Second}
+	latest, err := getLatestVersion(ctx, client, stableChannel)
+	if err != nil {
+		return YTDLPUpdate{}, false, err
+	}
+	if needsUpdate(local, latest) {
+		return YTDLPUpdate{Current: local, Version: latest}, true, nil
+	}
+	if !nightly {
+		return YTDLPUpdate{}, false, nil
+	}
+	latest, err = getLatestVersion(ctx, client, nightlyChannel)
+	if err != nil {
+		return YTDLPUpdate{}, false, err
+	}
+	if needsUpdate(local, latest) {
+		return YTDLPUpdate{Current: local, Version: latest, Nightly: true}, true, nil
+	}
+	return YTDLPUpdate{}, false, nil
+}
+
+// InstallYTDLPUpdate replaces the yt-dlp at path with the build u found,
+// verified like a regular update.
+func InstallYTDLPUpdate(ctx context.Context, path string, u YTDLPUpdate, progress DownloadProgressFunc) error {
+	ch := stableChannel
+	if u.Nightly {
+		ch = nightlyChannel
+	}
+	return downloadLatest(ctx, &http.Client{Timeout: downloadTimeout}, path, ch, progress)
+}
@@ -1,71 +1,101 @@
 package downloader
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 )
 
-func GetVideoInfo(ytdlp, url string) (title, channel string, err error) {
-	cmd := exec.Command(ytdlp,
-		"--print", "%(title)s",
-		"--print", "%(uploader)s",
-		"--encoding", "utf-8",
-		"--no-warnings",
-		"--skip-download",
-		"--no-playlist",
-		url,
-	)
-	cmd.Env = append(os.Environ(), "PYTHONIOENCODING=utf-8")
-
-	setCmdHideWindow(cmd)
-
-	out, err := cmd.Output()
-	if err != nil {
-		return "", "", err
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-	if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
-		return "", "", fmt.Errorf("failed to parse title")
+// VideoID identifies a video across downloads as "extractor:id", e.g.
+// "youtube:dQw4w9WgXcQ". It is empty when yt-dlp doesn't know either part.
+func VideoID(extractor, id string) string {
+	extractor = strings.ToLower(strings.TrimSpace(extractor))
+	id = strings.TrimSpace(id)
+	if extractor == "" || extractor == "na" || id == "" || id == "NA" {
+		return ""
 	}
+	return extractor + ":" + id
+}
 
-	title = strings.TrimSpace(lines[0])
-	if len(lines) > 1 {
-		channel = strings.TrimSpace(lines[1])
-	}
-	return title, channel, nil
+// SafeName makes s usable as a single file or folder name.
+func SafeName(s string) string {
+	return sanitizeFileNamePart(s)
 }
 
+// fileNameReserved are the characters sanitizeFileNamePart replaces; none of
+// them is allowed in a Windows file name.
+const fileNameReserved = `<>:"/\|?*`
+
 func sanitizeFileNamePart(s string) string {
-	replacer := strings.NewReplacer(
-		`<`, "_",
-		`>`, "_",
-		`:`, "_",
-		`"`, "_",
-		`/`, "_",
-		`\\`, "_",
-		`|`, "_",
-		`?`, "_",
-		`*`, "_",
-	)
-	clean := strings.TrimSpace(replacer.Replace(s))
-	clean = strings.Trim(clean, ". ")
+	clean := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(fileNameReserved, r) {
+			return '_'
+		}
+		return r
+	}, s)
+	clean = strings.Trim(strings.TrimSpace(clean), ". ")
 	if clean == "" {
 		return "untitled"
 	}
 	return clean
 }
 
-func BuildFileName(title, channel, ext string, includeChannel bool) string {
-	safeTitle := sanitizeFileNamePart(title)
-	if includeChannel && strings.TrimSpace(channel) != "" {
-		safeChannel := sanitizeFileNamePart(channel)
-		return fmt.Sprintf("%s [%s].%s", safeTitle, safeChannel, ext)
+// CheckFileName reports why base, a file name without extension, would be
+// changed by the rules BuildFileName cleans titles with, so a name typed in
+// by hand can be fixed before it is used.
+func CheckFileName(base string, rules NameRules) error {
+	if strings.TrimSpace(base) == "" {
+		return errors.New("the name is empty")
+	}
+	if i := strings.IndexAny(base, fileNameReserved); i >= 0 {
+		return fmt.Errorf("%q is not allowed in file names", base[i:i+1])
+	}
+	if sanitizeFileNamePart(base) != base {
+		return errors.New("the name can't start or end with a space or dot")
+	}
+	if avoidReservedName(base) != base {
+		return fmt.Errorf("%s is a reserved name on Windows", base)
+	}
+	if rules.Restrict && restrictName(base) != base {
+		return errors.New("safe file names use only ASCII letters, digits, ._-[] and no spaces")
+	}
+	return nil
+}
+
+// NameSource is what a file name is built from. UploadDate (YYYYMMDD) and
+// ID are only used when the rules ask for them.
+type NameSource struct {
+	Title      string
+	Channel    string
+	UploadDate string
+	ID         string
+}
+
+func BuildFileName(src NameSource, ext string, includeChannel bool, rules NameRules) string {
+	safeTitle := rules.part(rules.CleanTitle(src.Title))
+	sep := rules.separator()
+	prefix, suffix := "", ""
+	if rules.DatePrefix && strings.TrimSpace(src.UploadDate) != "" {
+		prefix = rules.part(src.UploadDate) + sep
+	}
+	if includeChannel && strings.TrimSpace(src.Channel) != "" {
+		suffix = fmt.Sprintf("%s[%s]", sep, rules.part(src.Channel))
+	}
+	if rules.IDSuffix && strings.TrimSpace(src.ID) != "" {
+		suffix += fmt.Sprintf("%s[%s]", sep, rules.part(src.ID))
+	}
+	if rules.MaxLength > 0 {
+		// The date, channel and ID are kept whole; the title gives way first.
+		keep := rules.MaxLength - utf8.RuneCountInString(prefix+suffix)
+		if keep < 1 {
+			keep = 1
+		}
+		safeTitle = truncateRunes(safeTitle, keep)
 	}
-	return fmt.Sprintf("%s.%s", safeTitle, ext)
+	return fmt.Sprintf("%s.%s", avoidReservedName(prefix+safeTitle+suffix), ext)
 }
 
 func UniqueName(path string) string {
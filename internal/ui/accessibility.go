@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	prefIconLabels  = "icon_button_labels"
+	prefSpeakStatus = "speak_status"
+)
+
+// iconLabels gives icon-only buttons their text as well. Fyne doesn't expose
+// widgets to screen readers, so a visible label is the only way magnifier
+// and OCR-based readers can tell the buttons apart. Buttons are built once,
+// so a change applies after a restart.
+var iconLabels atomic.Bool
+
+// newIconButton is a button shown as just an icon, or with label next to it
+// when icon labels are on.
+func newIconButton(label string, icon fyne.Resource, tapped func()) *widget.Button {
+	text := ""
+	if iconLabels.Load() {
+		text = label
+	}
+	return widget.NewButtonWithIcon(text, icon, tapped)
+}
+
+// statusLine is the main window's status label. With "Read status changes
+// aloud" on, every new text is also spoken by the system's speech engine.
+type statusLine struct {
+	*widget.Label
+	narrator *narrator
+}
+
+func newStatusLine(text string, n *narrator) *statusLine {
+	return &statusLine{Label: widget.NewLabel(text), narrator: n}
+}
+
+func (s *statusLine) SetText(text string) {
+	if text == s.Text {
+		return
+	}
+	s.Label.SetText(text)
+	s.narrator.say(text)
+}
+
+// narrator reads status changes aloud. Only the latest text matters, so a
+// new one cuts off whatever is still being read.
+type narrator struct {
+	enabled func() bool
+
+	mu  sync.Mutex
+	cmd *exec.Cmd
+}
+
+func (n *narrator) say(text string) {
+	text = strings.TrimSpace(text)
+	if text == "" || !n.enabled() {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.cmd != nil {
+		n.cmd.Process.Kill()
+		n.cmd = nil
+	}
+	cmd := speechCommand(text)
+	if cmd == nil {
+		return
+	}
+	setCmdHideWindow(cmd)
+	if err := cmd.Start(); err != nil {
+		return
+	}
+	n.cmd = cmd
+	go cmd.Wait()
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -18,20 +19,27 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
 	"ytgui/internal/downloader"
 )
 
+// Assets are tools embedded in the build, with the SHA-256 digests of the
+// embedded bytes they are checked against when written out.
 type Assets struct {
-	YTDLP  []byte
-	FFmpeg []byte
+	YTDLP        []byte
+	FFmpeg       []byte
+	YTDLPSHA256  string
+	FFmpegSHA256 string
 }
 
 var percentRegex = regexp.MustCompile(`\[(download|ffmpeg)\]\s+(\d+(\.\d+)?)%`)
@@ -39,7 +47,74 @@ var progressLineRegex = regexp.MustCompile(`\[download\]\s+(.+)`)
 var etaRegex = regexp.MustCompile(`ETA\s+([0-9:]+)`)
 
 const maxLogLineLen = 220
+
+const metadataPrefetchDelay = 800 * time.Millisecond
 const prefDownloadDir = "download_dir"
+const prefStagedDirs = "staged_download_dirs"
+const prefSubtitleLang = "subtitle_language"
+
+var qualityOptions = []string{"Best", "2160p", "1440p", "1080p", "720p", "480p", "Audio Only"}
+
+const (
+	profileCompatible = "Widely Compatible (H.264/AAC)"
+	profileSmaller    = "Smaller File Size (AV1/VP9)"
+)
+
+var profileOptions = []string{profileCompatible, profileSmaller}
+
+// subFormats are the --convert-subs targets offered in the UI. Auto keeps the
+// old behaviour of converting to SRT only when merging into MP4.
+const subFormatAuto = "Auto"
+
+var subFormats = []string{subFormatAuto, "srt", "ass", "vtt"}
+
+// fpsOptions cap the frame rate independently of the resolution choice.
+const fpsAny = "Any"
+
+var fpsOptions = []string{fpsAny, "60", "30"}
+
+func fpsCap(option string) int {
+	n, _ := strconv.Atoi(option)
+	return n
+}
+
+func fpsOption(limit int) string {
+	if limit <= 0 {
+		return fpsAny
+	}
+	return strconv.Itoa(limit)
+}
+
+// Subtitle track preference. In "never ask" mode the first category of the
+// chosen order that exists is used, and subtitles are skipped when none does.
+const (
+	prefSubtitleMode  = "subtitle_mode"
+	prefSubtitleOrder = "subtitle_order"
+
+	subtitleModeAsk   = "Ask when several tracks fit"
+	subtitleModeNever = "Never ask, skip if unavailable"
+
+	subCreatorOriginal = "creator_original"
+	subCreatorEnglish  = "creator_english"
+	subAutoOriginal    = "auto_original"
+	subAutoEnglish     = "auto_english"
+)
+
+var subtitleModes = []string{subtitleModeAsk, subtitleModeNever}
+
+var subtitleOrders = []string{
+	"Creator Original → Creator English → Auto Original → Auto English",
+	"Creator English → Creator Original → Auto English → Auto Original",
+	"Creator Original → Auto Original → Creator English → Auto English",
+	"Creator English → Auto English → Creator Original → Auto Original",
+}
+
+var subtitleOrderKeys = map[string][]string{
+	subtitleOrders[0]: {subCreatorOriginal, subCreatorEnglish, subAutoOriginal, subAutoEnglish},
+	subtitleOrders[1]: {subCreatorEnglish, subCreatorOriginal, subAutoEnglish, subAutoOriginal},
+	subtitleOrders[2]: {subCreatorOriginal, subAutoOriginal, subCreatorEnglish, subAutoEnglish},
+	subtitleOrders[3]: {subCreatorEnglish, subAutoEnglish, subCreatorOriginal, subAutoOriginal},
+}
 
 func defaultDownloadDir() string {
 	home, err := os.UserHomeDir()
@@ -52,11 +127,52 @@ func defaultDownloadDir() string {
 func folderButtonText(path string) string {
 	trimmed := strings.TrimSpace(path)
 	if trimmed == "" {
-		return "Choose Folder"
+		return tr("Choose Folder")
 	}
 	return trimmed
 }
 
+func sameFolder(a, b string) bool {
+	a = strings.TrimSpace(a)
+	b = strings.TrimSpace(b)
+	if a == "" || b == "" {
+		return false
+	}
+	return strings.EqualFold(filepath.Clean(a), filepath.Clean(b))
+}
+
+func folderInList(list []string, dir string) bool {
+	for _, d := range list {
+		if sameFolder(d, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func setFolderInList(list []string, dir string, on bool) []string {
+	out := make([]string, 0, len(list)+1)
+	for _, d := range list {
+		if !sameFolder(d, dir) {
+			out = append(out, d)
+		}
+	}
+	if on && strings.TrimSpace(dir) != "" {
+		out = append(out, filepath.Clean(dir))
+	}
+	return out
+}
+
+// statusSetter and progressSetter are satisfied by the shared status widgets
+// as well as by per-job views in the download queue.
+type statusSetter interface {
+	SetText(string)
+}
+
+type progressSetter interface {
+	SetValue(float64)
+}
+
 func runOnMain(f func()) {
 	f()
 }
@@ -65,7 +181,7 @@ func appendLog(logBox *widget.Entry, msg string, mu *sync.Mutex) {
 	mu.Lock()
 	defer mu.Unlock()
 	runOnMain(func() {
-		logBox.SetText(logBox.Text + msg + "\n")
+		logBox.SetText(trimLogText(logBox.Text + msg + "\n"))
 	})
 }
 
@@ -76,7 +192,7 @@ func appendNerdLog(nerdLogBox *widget.Entry, msg string, mu *sync.Mutex) {
 	mu.Lock()
 	defer mu.Unlock()
 	runOnMain(func() {
-		nerdLogBox.SetText(nerdLogBox.Text + msg + "\n")
+		nerdLogBox.SetText(trimLogText(nerdLogBox.Text + msg + "\n"))
 	})
 }
 
@@ -99,6 +215,16 @@ func formatCommandLine(exe string, args []string) string {
 	return strings.Join(parts, " ")
 }
 
+// logMetadataFetch shows the yt-dlp run behind a metadata lookup, or that a
+// recent fetch of the video is reused.
+func logMetadataFetch(ev publisher, ytdlp, url string) {
+	if downloader.MetadataCached(url) {
+		ev.raw("[metadata] using cached info for " + url)
+		return
+	}
+	ev.raw("> " + formatCommandLine(ytdlp, downloader.MetadataArgs(url)))
+}
+
 func parseProgress(line string) float64 {
 	m := percentRegex.FindStringSubmatch(line)
 	if len(m) >= 3 {
@@ -117,9 +243,9 @@ func compactStatus(line string) string {
 	}
 	pct := m[2]
 	if em := etaRegex.FindStringSubmatch(line); len(em) > 1 {
-		return fmt.Sprintf("Downloading %s%% (ETA %s)", pct, em[1])
+		return trf("Downloading %s%% (ETA %s)", pct, em[1])
 	}
-	return fmt.Sprintf("Downloading %s%%", pct)
+	return trf("Downloading %s%%", pct)
 }
 
 func formatBytes(v int64) string {
@@ -150,6 +276,18 @@ func downloadDisplayName(stats downloader.DownloadStats) string {
 	return "download"
 }
 
+// downloadShare is the part of the bar used by the download stages; the rest
+// is reserved for merging and subtitle embedding.
+const downloadShare = 0.9
+
+type postprocessWatch struct {
+	label    string
+	output   string
+	expected int64
+	start    float64
+	end      float64
+}
+
 type downloadProgressTracker struct {
 	mu            sync.Mutex
 	totalStages   int
@@ -157,6 +295,111 @@ type downloadProgressTracker struct {
 	hasStage      bool
 	stageProgress float64
 	seenDest      map[string]struct{}
+	destOrder     []string
+	post          *postprocessWatch
+	hasSubs       bool
+	// stageStart and stageWeight split the download share of the bar by
+	// stream size; nil means equal stages.
+	stageStart  []float64
+	stageWeight []float64
+	// progressFile is where ffmpeg writes -progress reports during merge
+	// and embed; duration is the length of the media in seconds.
+	progressFile string
+	duration     float64
+}
+
+// useFFmpegProgress makes postprocessing progress come from the -progress
+// reports ffmpeg writes to file instead of the temp file size.
+func (t *downloadProgressTracker) useFFmpegProgress(file string, duration float64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.progressFile, t.duration = file, duration
+	t.mu.Unlock()
+}
+
+// ffmpegProgress reads how far the running postprocessor is, 0..1. The
+// report file of the previous stage is removed when a stage starts, so
+// the file only ever describes the current one.
+func (t *downloadProgressTracker) ffmpegProgress() (float64, bool) {
+	if t.progressFile == "" || t.duration <= 0 {
+		return 0, false
+	}
+	data, err := os.ReadFile(t.progressFile)
+	if err != nil {
+		return 0, false
+	}
+	i := bytes.LastIndex(data, []byte("out_time_us="))
+	if i < 0 {
+		return 0, false
+	}
+	line, _, _ := bytes.Cut(data[i+len("out_time_us="):], []byte("\n"))
+	us, err := strconv.ParseInt(strings.TrimSpace(string(line)), 10, 64)
+	if err != nil || us < 0 {
+		return 0, false
+	}
+	return min(float64(us)/1e6/t.duration, 1), true
+}
+
+// startPostprocess begins tracking a merge or embed stage.
+func (t *downloadProgressTracker) startPostprocess(pp *postprocessWatch) {
+	if t.progressFile != "" {
+		os.Remove(t.progressFile)
+	}
+	t.post = pp
+}
+
+// subtitleStageShare is the part of the download share given to the
+// subtitle stage when stages are weighted by size.
+const subtitleStageShare = 0.02
+
+// setStreamSizes weights the stages by the sizes of the formats about to be
+// downloaded, in download order. Unknown sizes, or a count that doesn't
+// match the stages, keep the stages equal.
+func (t *downloadProgressTracker) setStreamSizes(sizes []int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	media := t.totalStages
+	if t.hasSubs {
+		media--
+	}
+	if len(sizes) != media || media < 2 {
+		return
+	}
+	var total int64
+	for _, s := range sizes {
+		if s <= 0 {
+			return
+		}
+		total += s
+	}
+	var weights []float64
+	mediaShare := 1.0
+	if t.hasSubs {
+		// yt-dlp writes subtitles before the media.
+		weights = append(weights, subtitleStageShare)
+		mediaShare -= subtitleStageShare
+	}
+	for _, s := range sizes {
+		weights = append(weights, mediaShare*float64(s)/float64(total))
+	}
+	t.stageWeight = weights
+	t.stageStart = make([]float64, len(weights))
+	for i := 1; i < len(weights); i++ {
+		t.stageStart[i] = t.stageStart[i-1] + weights[i-1]
+	}
+}
+
+// stageValue is the bar position at progress p (0..1) of the current stage.
+func (t *downloadProgressTracker) stageValue(p float64) float64 {
+	if t.stageWeight != nil {
+		return downloadShare * (t.stageStart[t.stageIndex] + t.stageWeight[t.stageIndex]*p)
+	}
+	return downloadShare * (float64(t.stageIndex) + p) / float64(t.totalStages)
 }
 
 func newDownloadProgressTracker(quality string, subOpt *downloader.SubOption, playlist bool) *downloadProgressTracker {
@@ -176,7 +419,42 @@ func newDownloadProgressTracker(quality string, subOpt *downloader.SubOption, pl
 	return &downloadProgressTracker{
 		totalStages: stages,
 		seenDest:    make(map[string]struct{}),
+		hasSubs:     subOpt != nil,
+	}
+}
+
+func quotedPathAfter(line, marker string) string {
+	i := strings.Index(line, marker)
+	if i < 0 {
+		return ""
+	}
+	rest := strings.TrimSpace(line[i+len(marker):])
+	return strings.Trim(rest, `"`)
+}
+
+func fileSize(p string) int64 {
+	info, err := os.Stat(p)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// ffmpegProgressArgs has a postprocessor's ffmpeg write -progress reports to
+// file, or nothing for an empty file. yt-dlp splits postprocessor args like
+// a POSIX shell, so the path is quoted and uses forward slashes.
+func ffmpegProgressArgs(file string) string {
+	if file == "" {
+		return ""
 	}
+	return ` -progress "` + filepath.ToSlash(file) + `" -nostats`
+}
+
+// tempOutputName mirrors yt-dlp's prepend_extension(name, "temp"), which is
+// where ffmpeg writes while a postprocessor runs.
+func tempOutputName(output string) string {
+	ext := filepath.Ext(output)
+	return strings.TrimSuffix(output, ext) + ".temp" + ext
 }
 
 func (t *downloadProgressTracker) update(rawLine string) (float64, string, bool) {
@@ -193,6 +471,7 @@ func (t *downloadProgressTracker) update(rawLine string) (float64, string, bool)
 		dest := strings.TrimSpace(strings.TrimPrefix(line, destPrefix))
 		if _, ok := t.seenDest[dest]; !ok {
 			t.seenDest[dest] = struct{}{}
+			t.destOrder = append(t.destOrder, dest)
 			if !t.hasStage {
 				t.hasStage = true
 				t.stageIndex = 0
@@ -202,8 +481,8 @@ func (t *downloadProgressTracker) update(rawLine string) (float64, string, bool)
 				t.stageProgress = 0
 			}
 		}
-		v := float64(t.stageIndex) / float64(t.totalStages)
-		return v, fmt.Sprintf("Downloading (%d/%d)...", t.stageIndex+1, t.totalStages), true
+		v := t.stageValue(0)
+		return v, trf("Downloading (%d/%d)...", t.stageIndex+1, t.totalStages), true
 	}
 
 	if p := parseProgress(rawLine); p >= 0 && t.hasStage {
@@ -211,71 +490,92 @@ func (t *downloadProgressTracker) update(rawLine string) (float64, string, bool)
 			p = t.stageProgress
 		}
 		t.stageProgress = p
-		v := (float64(t.stageIndex) + p) / float64(t.totalStages)
+		v := t.stageValue(p)
 		return v, compactStatus(rawLine), true
 	}
 
 	if strings.Contains(line, "[Merger]") {
-		v := (float64(t.totalStages) - 0.1) / float64(t.totalStages)
-		return v, "Merging formats...", true
+		var expected int64
+		for _, d := range t.destOrder {
+			expected += fileSize(d)
+		}
+		t.startPostprocess(&postprocessWatch{
+			label:    tr("Merging formats"),
+			output:   quotedPathAfter(line, "Merging formats into"),
+			expected: expected,
+			start:    downloadShare,
+			end:      0.97,
+		})
+		return downloadShare, tr("Merging formats..."), true
 	}
 	if strings.Contains(line, "[EmbedSubtitle]") {
-		v := (float64(t.totalStages) - 0.05) / float64(t.totalStages)
-		return v, "Embedding subtitles...", true
+		out := quotedPathAfter(line, "Embedding subtitles in")
+		t.startPostprocess(&postprocessWatch{
+			label:    tr("Embedding subtitles"),
+			output:   out,
+			expected: fileSize(out),
+			start:    0.97,
+			end:      0.995,
+		})
+		return 0.97, tr("Embedding subtitles..."), true
 	}
 
 	return 0, "", false
 }
 
-func userLogSummary(rawLine string) (string, bool) {
-	line := strings.TrimSpace(strings.ReplaceAll(rawLine, "\r", ""))
-	if line == "" {
-		return "", false
-	}
-
-	if strings.HasPrefix(line, "WARNING:") || strings.HasPrefix(line, "ERROR:") {
-		return line, true
+// pollPostprocess reports merge/embed progress. yt-dlp does not forward
+// ffmpeg's own progress for postprocessors, so ffmpeg is told to write its
+// -progress reports to a file; without one, progress is estimated from the
+// size of the temp file ffmpeg is writing, relative to the combined size of
+// its inputs.
+func (t *downloadProgressTracker) pollPostprocess() (float64, string, bool) {
+	if t == nil {
+		return 0, "", false
 	}
-	if strings.HasPrefix(line, "[youtube]") {
-		if strings.Contains(line, "Extracting URL") {
-			return "Fetching video information...", true
-		}
-		return "", false
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pp := t.post
+	if pp == nil {
+		return 0, "", false
 	}
-	if strings.HasPrefix(line, "[info]") {
-		if strings.Contains(line, "Downloading subtitles:") {
-			return "Downloading subtitles...", true
+	part, ok := t.ffmpegProgress()
+	if !ok {
+		if pp.output == "" || pp.expected <= 0 {
+			return 0, "", false
 		}
-		if strings.Contains(line, "Downloading 1 format(s):") || strings.Contains(line, "Downloading 2 format(s):") {
-			return "Downloading media streams...", true
+		written := fileSize(tempOutputName(pp.output))
+		if written <= 0 {
+			return 0, "", false
 		}
-		return "", false
-	}
-	if strings.Contains(line, "[SubtitlesConvertor]") {
-		return "Preparing subtitles...", true
-	}
-	if strings.Contains(line, "[Merger]") {
-		return "Merging audio/video...", true
+		part = min(float64(written)/float64(pp.expected), 1)
 	}
-	if strings.Contains(line, "[EmbedSubtitle]") {
-		return "Embedding subtitles...", true
-	}
-	return "", false
+	v := pp.start + (pp.end-pp.start)*part
+	return v, fmt.Sprintf("%s... %.0f%%", pp.label, part*100), true
+}
+
+var logTagRE = regexp.MustCompile(`^\[([A-Za-z0-9:_+.-]+)\]`)
+
+// ytdlpCoreTags are yt-dlp's own log prefixes, as opposed to extractor names.
+var ytdlpCoreTags = map[string]bool{
+	"info": true, "download": true, "debug": true,
+	"Merger": true, "EmbedSubtitle": true, "SubtitlesConvertor": true,
+	"ExtractAudio": true, "MoveFiles": true, "Metadata": true,
+	"VideoConvertor": true, "VideoRemuxer": true, "FixupM3u8": true,
+	"FixupM4a": true, "FixupDuplicateMoov": true, "FixupStretched": true,
+	"ffmpeg": true, "hlsnative": true, "dashsegments": true,
 }
 
-func scanAndLog(r io.Reader, logBox *widget.Entry, nerdLogBox *widget.Entry, status *widget.Label, progress *widget.ProgressBar, mu *sync.Mutex, onProgress func(string) (float64, string, bool)) {
+func scanAndLog(r io.Reader, ev publisher, onProgress func(string) (float64, string, bool)) {
 	sc := bufio.NewScanner(r)
 	for sc.Scan() {
 		rawLine := sc.Text()
-		appendNerdLog(nerdLogBox, rawLine, mu)
+		ev.raw(rawLine)
 		if onProgress != nil {
 			if p, s, ok := onProgress(rawLine); ok {
-				runOnMain(func() {
-					progress.SetValue(p)
-					if strings.TrimSpace(s) != "" {
-						status.SetText(s)
-					}
-				})
+				ev.SetValue(p)
+				if strings.TrimSpace(s) != "" {
+					ev.SetText(s)
+				}
 			}
 		}
 		line, ok := userLogSummary(rawLine)
@@ -285,41 +585,56 @@ func scanAndLog(r io.Reader, logBox *widget.Entry, nerdLogBox *widget.Entry, sta
 		if len(line) > maxLogLineLen {
 			line = line[:maxLogLineLen] + " ..."
 		}
-		appendLog(logBox, line, mu)
+		ev.log(line)
 	}
 	if err := sc.Err(); err != nil {
-		appendLog(logBox, fmt.Sprintf("log stream error: %v", err), mu)
+		ev.log(trf("log stream error: %v", err))
+	}
+}
+
+// maxHeight reads the height cap of a quality choice such as "1080p"; 0
+// means no cap.
+func maxHeight(choice string) int {
+	h, err := strconv.Atoi(strings.TrimSuffix(choice, "p"))
+	if err != nil {
+		return 0
 	}
+	return h
 }
 
-func formatFromChoice(choice, outputProfile string) []string {
+// formatFromChoice builds the format arguments from the resolution choice,
+// the codec profile and the frame-rate cap (0 for any). Formats that don't
+// report a frame rate pass the cap.
+func formatFromChoice(choice, outputProfile string, maxFPS int) []string {
 	if choice == "Audio Only" {
 		return []string{"-x", "--audio-format", "mp3"}
 	}
+	if args, ok := twitchFormatArgs(choice); ok {
+		return args
+	}
 
-	if outputProfile == "Widely Compatible (H.264/AAC)" {
-		switch choice {
-		case "1080p":
-			return []string{"-f", "bestvideo[vcodec^=avc1][height<=1080]+bestaudio[acodec^=mp4a]/best[vcodec^=avc1][acodec^=mp4a][height<=1080]/bestvideo[height<=1080]+bestaudio/best[height<=1080]"}
-		case "720p":
-			return []string{"-f", "bestvideo[vcodec^=avc1][height<=720]+bestaudio[acodec^=mp4a]/best[vcodec^=avc1][acodec^=mp4a][height<=720]/bestvideo[height<=720]+bestaudio/best[height<=720]"}
-		case "480p":
-			return []string{"-f", "bestvideo[vcodec^=avc1][height<=480]+bestaudio[acodec^=mp4a]/best[vcodec^=avc1][acodec^=mp4a][height<=480]/bestvideo[height<=480]+bestaudio/best[height<=480]"}
-		default:
-			return []string{"-f", "bestvideo[vcodec^=avc1]+bestaudio[acodec^=mp4a]/best[vcodec^=avc1][acodec^=mp4a]/bestvideo+bestaudio/best"}
-		}
+	caps := ""
+	if h := maxHeight(choice); h > 0 {
+		caps += fmt.Sprintf("[height<=%d]", h)
+	}
+	if maxFPS > 0 {
+		caps += fmt.Sprintf("[fps<=?%d]", maxFPS)
 	}
+	if outputProfile == profileCompatible {
+		return []string{"-f", fmt.Sprintf("bestvideo[vcodec^=avc1]%[1]s+bestaudio[acodec^=mp4a]/best[vcodec^=avc1][acodec^=mp4a]%[1]s/bestvideo%[1]s+bestaudio/best%[1]s", caps)}
+	}
+	return []string{"-f", fmt.Sprintf("bestvideo[vcodec^=av01]%[1]s+bestaudio[acodec^=opus]/bestvideo[vcodec^=vp9]%[1]s+bestaudio[acodec^=opus]/bestvideo%[1]s+bestaudio/best%[1]s", caps)}
+}
 
-	switch choice {
-	case "1080p":
-		return []string{"-f", "bestvideo[vcodec^=av01][height<=1080]+bestaudio[acodec^=opus]/bestvideo[vcodec^=vp9][height<=1080]+bestaudio[acodec^=opus]/bestvideo[height<=1080]+bestaudio/best[height<=1080]"}
-	case "720p":
-		return []string{"-f", "bestvideo[vcodec^=av01][height<=720]+bestaudio[acodec^=opus]/bestvideo[vcodec^=vp9][height<=720]+bestaudio[acodec^=opus]/bestvideo[height<=720]+bestaudio/best[height<=720]"}
-	case "480p":
-		return []string{"-f", "bestvideo[vcodec^=av01][height<=480]+bestaudio[acodec^=opus]/bestvideo[vcodec^=vp9][height<=480]+bestaudio[acodec^=opus]/bestvideo[height<=480]+bestaudio/best[height<=480]"}
-	default:
-		return []string{"-f", "bestvideo[vcodec^=av01]+bestaudio[acodec^=opus]/bestvideo[vcodec^=vp9]+bestaudio[acodec^=opus]/bestvideo+bestaudio/best"}
+// formatSelectorOf returns the -f expression of assembled format arguments;
+// audio extraction relies on yt-dlp's default bestaudio/best selector.
+func formatSelectorOf(args []string) string {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == "-f" {
+			return args[i+1]
+		}
 	}
+	return "bestaudio/best"
 }
 
 func subtitleLangBase(code string) string {
@@ -376,63 +691,98 @@ func subtitleAvailabilitySummary(opts []downloader.SubOption) []string {
 	}
 	sort.Strings(langList)
 	if len(langList) == 0 {
-		langList = []string{"none"}
+		langList = []string{tr("none")}
 	}
 
 	yesNo := func(v bool) string {
 		if v {
-			return "YES"
+			return tr("YES")
 		}
-		return "NO"
+		return tr("NO")
 	}
 
 	return []string{
-		"Subtitle availability:",
-		"  Creator Uploaded (Original): " + yesNo(creatorOriginal),
-		"  Creator Uploaded (English): " + yesNo(creatorEnglish),
-		"  Auto Generated (Original): " + yesNo(autoOriginal),
-		"  Auto Generated (English): " + yesNo(autoEnglish),
-		"  Languages: " + strings.Join(langList, ", "),
+		tr("Subtitle availability:"),
+		"  " + tr("Creator Uploaded (Original)") + ": " + yesNo(creatorOriginal),
+		"  " + tr("Creator Uploaded (English)") + ": " + yesNo(creatorEnglish),
+		"  " + tr("Auto Generated (Original)") + ": " + yesNo(autoOriginal),
+		"  " + tr("Auto Generated (English)") + ": " + yesNo(autoEnglish),
+		"  " + tr("Languages") + ": " + strings.Join(langList, ", "),
 	}
 }
 
 type subtitleCategoryChoice struct {
+	key   string
 	label string
 	opt   downloader.SubOption
 }
 
-func subtitleCategoryChoices(opts []downloader.SubOption) []subtitleCategoryChoice {
+// subtitleCategoryChoices groups the available tracks into the categories
+// offered to the user. A preferred language adds its own creator, auto and
+// auto-translated categories in front of the defaults.
+func subtitleCategoryChoices(opts []downloader.SubOption, preferred string) []subtitleCategoryChoice {
 	type category struct {
+		key   string
 		label string
 		match func(downloader.SubOption) bool
 	}
 
-	categories := []category{
+	var categories []category
+	if pref := subtitleLangBase(preferred); pref != "" && !isOriginalSubtitleLang(opts, pref) {
+		categories = append(categories,
+			category{
+				key:   "preferred",
+				label: trf("Creator Uploaded (%s)", preferred),
+				match: func(o downloader.SubOption) bool {
+					return !o.IsAuto && subtitleLangBase(o.Code) == pref
+				},
+			},
+			category{
+				key:   "preferred",
+				label: trf("Auto Generated (%s)", preferred),
+				match: func(o downloader.SubOption) bool {
+					return o.IsAuto && !o.IsTranslated && subtitleLangBase(o.Code) == pref
+				},
+			},
+			category{
+				key:   "preferred",
+				label: trf("Auto-translated (%s)", preferred),
+				match: func(o downloader.SubOption) bool {
+					return o.IsAuto && o.IsTranslated && subtitleLangBase(o.Code) == pref
+				},
+			},
+		)
+	}
+	categories = append(categories, []category{
 		{
-			label: "Creator Uploaded (Original)",
+			key:   subCreatorOriginal,
+			label: tr("Creator Uploaded (Original)"),
 			match: func(o downloader.SubOption) bool {
 				return !o.IsAuto && o.IsOriginal
 			},
 		},
 		{
-			label: "Creator Uploaded (English)",
+			key:   subCreatorEnglish,
+			label: tr("Creator Uploaded (English)"),
 			match: func(o downloader.SubOption) bool {
 				return !o.IsAuto && subtitleLangBase(o.Code) == "en"
 			},
 		},
 		{
-			label: "Auto Generated (Original)",
+			key:   subAutoOriginal,
+			label: tr("Auto Generated (Original)"),
 			match: func(o downloader.SubOption) bool {
 				return o.IsAuto && o.IsOriginal
 			},
 		},
 		{
-			label: "Auto Generated (English)",
+			key:   subAutoEnglish,
+			label: tr("Auto Generated (English)"),
 			match: func(o downloader.SubOption) bool {
 				return o.IsAuto && subtitleLangBase(o.Code) == "en"
 			},
 		},
-	}
+	}...)
 
 	var out []subtitleCategoryChoice
 	for _, c := range categories {
@@ -447,6 +797,7 @@ func subtitleCategoryChoices(opts []downloader.SubOption) []subtitleCategoryChoi
 			continue
 		}
 		out = append(out, subtitleCategoryChoice{
+			key:   c.key,
 			label: c.label,
 			opt:   *best,
 		})
@@ -454,8 +805,35 @@ func subtitleCategoryChoices(opts []downloader.SubOption) []subtitleCategoryChoi
 	return out
 }
 
-func subtitleCategoryOptions(opts []downloader.SubOption) []downloader.SubOption {
-	choices := subtitleCategoryChoices(opts)
+// preferredSubtitleChoice applies a saved order without asking. Categories for
+// the preferred subtitle language always come first; nil means nothing fits.
+func preferredSubtitleChoice(opts []downloader.SubOption, preferred, order string) *subtitleCategoryChoice {
+	keys, ok := subtitleOrderKeys[order]
+	if !ok {
+		keys = subtitleOrderKeys[subtitleOrders[0]]
+	}
+	choices := subtitleCategoryChoices(opts, preferred)
+	for _, key := range append([]string{"preferred"}, keys...) {
+		for _, c := range choices {
+			if c.key == key {
+				return &c
+			}
+		}
+	}
+	return nil
+}
+
+func isOriginalSubtitleLang(opts []downloader.SubOption, base string) bool {
+	for _, o := range opts {
+		if o.IsOriginal && subtitleLangBase(o.Code) == base {
+			return true
+		}
+	}
+	return false
+}
+
+func subtitleCategoryOptions(opts []downloader.SubOption, preferred string) []downloader.SubOption {
+	choices := subtitleCategoryChoices(opts, preferred)
 	out := make([]downloader.SubOption, 0, len(choices))
 	for _, c := range choices {
 		out = append(out, c.opt)
@@ -498,6 +876,8 @@ func subtitlePriorityScore(o downloader.SubOption) int {
 		return 1
 	case o.IsOriginal:
 		return 2
+	case o.IsTranslated:
+		return 5
 	case subtitleLangBase(o.Code) == "en":
 		return 3
 	default:
@@ -527,7 +907,19 @@ func pickBestSubtitleOption(opts []downloader.SubOption) *downloader.SubOption {
 	return &chosen
 }
 
-func planSubtitleSelection(opts []downloader.SubOption) (*downloader.SubOption, []downloader.SubOption) {
+func planSubtitleSelection(opts []downloader.SubOption, preferred string) (*downloader.SubOption, []downloader.SubOption) {
+	if pref := subtitleLangBase(preferred); pref != "" {
+		var matches []downloader.SubOption
+		for _, o := range opts {
+			if subtitleLangBase(o.Code) == pref {
+				matches = append(matches, o)
+			}
+		}
+		if len(matches) > 0 {
+			return pickBestSubtitleOption(matches), nil
+		}
+	}
+
 	var manualOriginal []downloader.SubOption
 	var manualAny []downloader.SubOption
 	var autoOriginal []downloader.SubOption
@@ -567,11 +959,11 @@ func planSubtitleSelection(opts []downloader.SubOption) (*downloader.SubOption,
 	return nil, pool
 }
 
-func askSubtitleChoice(w fyne.Window, opts []downloader.SubOption) *downloader.SubOption {
+func askSubtitleChoice(w fyne.Window, opts []downloader.SubOption, preferred string) *downloader.SubOption {
 	if len(opts) == 0 {
 		return nil
 	}
-	choices := subtitleCategoryChoices(opts)
+	choices := subtitleCategoryChoices(opts, preferred)
 	if len(choices) == 0 {
 		return nil
 	}
@@ -589,11 +981,11 @@ func askSubtitleChoice(w fyne.Window, opts []downloader.SubOption) *downloader.S
 		combo.SetSelected(choiceStrings[0])
 
 		d := dialog.NewCustomConfirm(
-			"Select Subtitles",
-			"Download",
-			"Cancel",
+			tr("Select Subtitles"),
+			tr("Download"),
+			tr("Cancel"),
 			container.NewVBox(
-				widget.NewLabel("Choose a subtitle track:"),
+				widget.NewLabel(tr("Choose a subtitle track:")),
 				combo,
 			),
 			func(confirmed bool) {
@@ -622,12 +1014,12 @@ func askDownloadWithoutSubs(w fyne.Window) bool {
 	choiceCh := make(chan bool, 1)
 	runOnMain(func() {
 		d := dialog.NewCustomConfirm(
-			"No Subtitles Available",
-			"Download without subtitles",
-			"Quit Application",
+			tr("No Subtitles Available"),
+			tr("Download without subtitles"),
+			tr("Quit Application"),
 			container.NewVBox(
-				widget.NewLabel("No preferred subtitle type is available."),
-				widget.NewLabel("Continue download without subtitles?"),
+				widget.NewLabel(tr("No preferred subtitle type is available.")),
+				widget.NewLabel(tr("Continue download without subtitles?")),
 			),
 			func(confirmed bool) {
 				choiceCh <- confirmed
@@ -658,14 +1050,14 @@ func checkMissingTools() ([]string, error) {
 func askDownloadRequiredTools(w fyne.Window, missing []string) bool {
 	choiceCh := make(chan bool, 1)
 	runOnMain(func() {
-		msg := "The app needs to download required tools:\n" + strings.Join(missing, "\n")
+		msg := tr("The app needs to download required tools:") + "\n" + strings.Join(missing, "\n")
 		d := dialog.NewCustomConfirm(
-			"Setup Required",
-			"Download",
-			"Quit Application",
+			tr("Setup Required"),
+			tr("Download"),
+			tr("Quit Application"),
 			container.NewVBox(
 				widget.NewLabel(msg),
-				widget.NewLabel("Download now? This should happen only once."),
+				widget.NewLabel(tr("Download now? This should happen only once.")),
 			),
 			func(confirmed bool) {
 				choiceCh <- confirmed
@@ -678,60 +1070,73 @@ func askDownloadRequiredTools(w fyne.Window, missing []string) bool {
 	return <-choiceCh
 }
 
-func askDuplicateAction(w fyne.Window, file string) string {
-	choiceCh := make(chan string, 1)
+// askDuplicateAction returns the chosen policy and whether it should be
+// applied to every remaining duplicate in this run.
+func askDuplicateAction(w fyne.Window, file string) (string, bool) {
+	type answer struct {
+		choice string
+		all    bool
+	}
+	choiceCh := make(chan answer, 1)
 	runOnMain(func() {
 		var d dialog.Dialog
 		choiceSet := false
+		applyAll := widget.NewCheck(tr("Apply to all remaining downloads"), nil)
 		sendChoice := func(choice string) {
 			if choiceSet {
 				return
 			}
 			choiceSet = true
-			choiceCh <- choice
+			choiceCh <- answer{choice, applyAll.Checked}
 			d.Hide()
 		}
 
-		buttons := container.NewGridWithColumns(3,
-			widget.NewButton("Rename", func() {
-				sendChoice("rename")
+		buttons := container.NewGridWithColumns(4,
+			widget.NewButton(tr("Rename"), func() {
+				sendChoice(duplicateRename)
+			}),
+			widget.NewButton(tr("Replace"), func() {
+				sendChoice(duplicateReplace)
 			}),
-			widget.NewButton("Replace", func() {
-				sendChoice("replace")
+			widget.NewButton(tr("Skip this download"), func() {
+				sendChoice(duplicateSkip)
 			}),
-			widget.NewButton("Cancel", func() {
-				sendChoice("rename")
+			widget.NewButton(tr(duplicateCancel), func() {
+				sendChoice(duplicateCancel)
 			}),
 		)
 
 		d = dialog.NewCustom(
-			"File Exists",
+			tr("File Exists"),
 			"",
 			container.NewVBox(
-				widget.NewLabel("File already exists:"),
+				widget.NewLabel(tr("File already exists:")),
 				widget.NewLabel(file),
-				widget.NewLabel("Choose what to do:"),
+				widget.NewLabel(tr("Choose what to do:")),
 				buttons,
+				applyAll,
 			),
 			w,
 		)
+		// Closing the dialog without an answer must not download anything.
 		d.SetOnClosed(func() {
 			if choiceSet {
 				return
 			}
 			choiceSet = true
-			choiceCh <- "rename"
+			choiceCh <- answer{duplicateCancel, false}
 		})
-		d.Resize(fyne.NewSize(420, 220))
+		d.Resize(fyne.NewSize(560, 250))
 		d.Show()
 	})
 
-	return <-choiceCh
+	a := <-choiceCh
+	return a.choice, a.all
 }
 
-func cleanupSubtitleSidecars(videoPath string) int {
+func findSubtitleSidecars(videoPath string) []string {
 	if strings.TrimSpace(videoPath) == "" || strings.Contains(videoPath, "%(") {
-		return 0
+		return nil
 	}
 
 	dir := filepath.Dir(videoPath)
@@ -739,12 +1144,12 @@ func cleanupSubtitleSidecars(videoPath string) int {
 	ext := filepath.Ext(videoName)
 	base := strings.TrimSuffix(videoName, ext)
 	if strings.TrimSpace(base) == "" || base == videoName {
-		return 0
+		return nil
 	}
 
 	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return 0
+		return nil
 	}
 
 	subtitleExts := map[string]struct{}{
@@ -756,7 +1161,7 @@ func cleanupSubtitleSidecars(videoPath string) int {
 		".lrc":  {},
 	}
 
-	deleted := 0
+	var found []string
 	baseLower := strings.ToLower(base)
 	for _, entry := range entries {
 		if entry.IsDir() {
@@ -774,15 +1179,84 @@ func cleanupSubtitleSidecars(videoPath string) int {
 			continue
 		}
 
-		fullPath := filepath.Join(dir, name)
+		found = append(found, filepath.Join(dir, name))
+	}
+
+	return found
+}
+
+func cleanupSubtitleSidecars(videoPath string) int {
+	deleted := 0
+	for _, fullPath := range findSubtitleSidecars(videoPath) {
 		if rmErr := os.Remove(fullPath); rmErr == nil || os.IsNotExist(rmErr) {
 			deleted++
 		}
 	}
-
 	return deleted
 }
 
+// linePhase reports when a yt-dlp line starts downloading a file or moves
+// on to merging, converting or embedding; playlists go back and forth.
+func linePhase(line string) (appState, bool) {
+	if strings.HasPrefix(line, "[download] Destination:") {
+		return stateDownloading, true
+	}
+	for _, tag := range []string{"[Merger]", "[ExtractAudio]", "[EmbedSubtitle]", "[VideoConvertor]", "[FixupM3u8]"} {
+		if strings.HasPrefix(line, tag) {
+			return statePostprocessing, true
+		}
+	}
+	return 0, false
+}
+
+// isPostprocessFailure reports whether a yt-dlp output line signals that the
+// media was downloaded but a postprocessor (merge/embed) failed afterwards.
+func isPostprocessFailure(line string) bool {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "ERROR:") {
+		return false
+	}
+	return strings.Contains(line, "Postprocessing") ||
+		strings.Contains(line, "EmbedSubtitle") ||
+		strings.Contains(line, "Conversion failed")
+}
+
+// fallbackToMKV rescues a download whose subtitle embedding into MP4 failed by
+// remuxing the already-downloaded MP4 and its subtitle sidecars into MKV.
+func fallbackToMKV(ffmpeg, output, staged string, keepSubs bool, ev publisher) (string, bool) {
+	if strings.TrimSpace(output) == "" || strings.Contains(output, "%(") {
+		return "", false
+	}
+	src := output
+	if _, err := os.Stat(src); err != nil {
+		// With local staging the unfinished MP4 is left in the staging dir.
+		src = staged
+		if _, err := os.Stat(src); err != nil {
+			return "", false
+		}
+	}
+	subs := findSubtitleSidecars(src)
+	dst := strings.TrimSuffix(output, filepath.Ext(output)) + ".mkv"
+	if _, err := os.Stat(dst); err == nil {
+		dst = downloader.UniqueName(dst)
+	}
+	ev.log(tr("Embedding into MP4 failed. Retrying post-processing into MKV..."))
+	if err := downloader.RemuxToMKV(ffmpeg, src, subs, dst); err != nil {
+		ev.log(trf("MKV fallback failed: %v", err))
+		return "", false
+	}
+	if err := os.Remove(src); err != nil && !os.IsNotExist(err) {
+		ev.log(trf("Could not remove intermediate MP4: %v", err))
+	}
+	if !keepSubs {
+		for _, sub := range subs {
+			os.Remove(sub)
+		}
+	}
+	ev.log(trf("Saved as MKV instead: %s", dst))
+	return dst, true
+}
+
 func cleanupPartialMediaArtifacts(outputPath string) int {
 	if strings.TrimSpace(outputPath) == "" || strings.Contains(outputPath, "%(") {
 		return 0
@@ -826,50 +1300,154 @@ func cleanupPartialMediaArtifacts(outputPath string) int {
 	return deleted
 }
 
-func runYTDLP(url, downloadDir, quality, outputProfile, ytdlp, ffmpeg string, includeChannel, playlist bool, subOpt *downloader.SubOption, w fyne.Window, logBox *widget.Entry, nerdLogBox *widget.Entry, status *widget.Label, progress *widget.ProgressBar, mu *sync.Mutex, setCancelable func(string, context.CancelFunc) int64, clearCancelable func(int64)) {
+// ytdlpRun is one yt-dlp download: the job's settings and what the queue
+// resolved for this attempt.
+type ytdlpRun struct {
+	URL                string
+	Settings           jobSettings
+	YTDLP, FFmpeg      string
+	Subtitle           *downloader.SubOption
+	ArchiveFile        string
+	TemplateCollisions string
+
+	OnDuplicate func(path string) string
+	OnVideoID   func(id string) bool
+	OnOutput    func(path string, duration float64)
+	OnFileName  func(s downloadSummary, base, ext string) (string, bool)
+	// OnStreams reports whether the formats chosen for a single video have
+	// video and audio; it isn't called when that can't be told up front.
+	OnStreams func(video, audio bool)
+	Probe     probeFunc
+}
+
+func runYTDLP(ctx context.Context, run ytdlpRun, ev publisher) error {
+	settings := run.Settings
+	url, ytdlp, ffmpeg := run.URL, run.YTDLP, run.FFmpeg
+	outputProfile, maxFPS := settings.Profile, settings.MaxFPS
+	loudness := loudnessTarget(settings.Loudness)
 	if runtime.GOOS != "windows" {
-		appendLog(logBox, "This build is intended for Windows only.", mu)
-		runOnMain(func() { status.SetText("Windows build required") })
-		return
+		ev.log(tr("This build is intended for Windows only."))
+		ev.SetText(tr("Windows build required"))
+		return errors.New("windows build required")
+	}
+
+	if settings.Device != "" && settings.Device != deviceAny {
+		outputProfile, maxFPS = deviceAdjust(settings.Device, outputProfile, maxFPS)
+		ev.log(trf("Playback device: %s", tr(settings.Device)))
 	}
 
-	output := "%(title)s.%(ext)s"
-	if strings.TrimSpace(downloadDir) != "" {
-		output = filepath.Join(downloadDir, "%(title)s.%(ext)s")
+	output := settings.NameRules.Template()
+	if strings.TrimSpace(settings.Folder) != "" {
+		output = filepath.Join(settings.Folder, settings.NameRules.Template())
+	}
+	if settings.SortTemplate != "" {
+		output = filepath.Join(settings.Folder, ytdlpSortTemplate(settings.SortTemplate, settings.Quality == "Audio Only", settings.Playlist), settings.NameRules.Template())
 	}
 	mergeFormat := "mp4"
-	if outputProfile == "Smaller File Size (AV1/VP9)" {
+	if outputProfile == profileSmaller || settings.Preserve {
 		mergeFormat = "mkv"
 	}
-	if !playlist {
-		appendNerdLog(nerdLogBox, "> "+formatCommandLine(ytdlp, []string{"--print", "%(title)s", "--print", "%(uploader)s", "--encoding", "utf-8", "--no-warnings", "--skip-download", "--no-playlist", url}), mu)
-		title, channel, infoErr := downloader.GetVideoInfo(ytdlp, url)
+	var expectedDuration float64
+	var videoTitle, uploader string
+	var formats []downloader.Format
+	var collisionArgs []string
+	templateMarker := ""
+	if !settings.Playlist {
+		logMetadataFetch(ev, ytdlp, url)
+		pctx, done := run.Probe(ctx)
+		meta, infoErr := downloader.GetVideoMetadata(pctx, ytdlp, url)
+		infoErr = done(infoErr)
+		var title, channel, videoID string
+		var duration float64
+		var name downloader.NameSource
+		if infoErr == nil {
+			title, channel, videoID, duration = meta.Title, meta.Uploader, meta.VideoID(), meta.Duration
+			formats = meta.Formats
+			name = downloader.NameSource{Channel: channel, UploadDate: meta.UploadDate, ID: meta.ID}
+			if meta.IsLive {
+				ev.log(tr("This is a live stream; it is recorded until the stream ends or you cancel."))
+			}
+		}
+		expectedDuration = duration
+		videoTitle, uploader = title, channel
+		if settings.Quality == "Audio Only" && looksVisual(title) {
+			ev.log(trf("%q looks like a video worth watching, but Audio Only is selected.", title))
+		}
+		if settings.ClipStart > 0 || settings.ClipEnd > 0 {
+			// A clip gets its own name so it never collides with the full video.
+			title += " (" + clipLabel(settings.ClipStart, settings.ClipEnd) + ")"
+			expectedDuration = max(duration-float64(settings.ClipStart), 0)
+			if settings.ClipEnd > 0 {
+				expectedDuration = min(expectedDuration, float64(settings.ClipEnd-settings.ClipStart))
+			}
+		}
 		if infoErr != nil {
-			appendLog(logBox, fmt.Sprintf("Could not fetch metadata, using template output: %v", infoErr), mu)
+			ev.log(trf("Could not fetch metadata, using template output: %v", infoErr))
+			collisionArgs, templateMarker = templateCollisionPlan(settings.Duplicates, run.TemplateCollisions)
+			if templateMarker != "" {
+				output = strings.TrimSuffix(output, ".%(ext)s") + templateMarker + ".%(ext)s"
+			}
 		} else {
-			targetDir := strings.TrimSpace(downloadDir)
+			download := true
+			if videoID != "" {
+				ev.asking(stateProbing, func() { download = run.OnVideoID(videoID) })
+			}
+			if !download {
+				ev.SetText(tr("Skipped (downloaded before)"))
+				ev.SetValue(1.0)
+				return nil
+			}
+			targetDir := strings.TrimSpace(settings.Folder)
 			if targetDir == "" {
 				targetDir, _ = os.Getwd()
 			}
 
 			targetExt := mergeFormat
-			if quality == "Audio Only" {
+			if settings.Quality == "Audio Only" {
 				targetExt = "mp3"
 			}
+			if settings.SortTemplate != "" {
+				targetDir = filepath.Join(targetDir, expandSortTemplate(settings.SortTemplate, settings.Quality == "Audio Only", channel))
+				if err := os.MkdirAll(targetDir, 0o755); err != nil {
+					ev.log(trf("Cannot create subfolder: %v", err))
+					ev.SetText(tr("Cannot create subfolder"))
+					return err
+				}
+				ev.log(trf("Sorted into: %s", targetDir))
+			}
 
-			fileName := downloader.BuildFileName(title, channel, targetExt, includeChannel)
+			name.Title = title
+			base := strings.TrimSuffix(downloader.BuildFileName(name, targetExt, settings.IncludeChannel, settings.NameRules), "."+targetExt)
+			summary := downloadSummary{Title: videoTitle, Channel: channel, Duration: expectedDuration, Quality: tr(settings.Quality), Folder: targetDir, Rules: settings.NameRules}
+			ok := false
+			ev.asking(stateProbing, func() { base, ok = run.OnFileName(summary, base, targetExt) })
+			if !ok {
+				ev.log(tr("Download canceled by user."))
+				ev.SetText(tr("Download canceled"))
+				return context.Canceled
+			}
+			fileName := downloader.FitFileName(targetDir, base+"."+targetExt)
 			fullPath := filepath.Join(targetDir, fileName)
 			if _, err := os.Stat(fullPath); err == nil {
-				choice := askDuplicateAction(w, fullPath)
+				var choice string
+				ev.asking(stateProbing, func() { choice = run.OnDuplicate(fullPath) })
 				switch choice {
-				case "replace":
-					if rmErr := os.Remove(fullPath); rmErr != nil && !os.IsNotExist(rmErr) {
-						appendLog(logBox, fmt.Sprintf("Cannot replace existing file: %v", rmErr), mu)
-						runOnMain(func() { status.SetText("Cannot replace existing file") })
-						return
+				case duplicateCancel:
+					ev.log(tr("Download canceled by user."))
+					ev.SetText(tr("Download canceled"))
+					return context.Canceled
+				case duplicateSkip:
+					ev.log(trf("Skipped, file already exists: %s", fullPath))
+					run.OnOutput(fullPath, expectedDuration)
+					ev.SetText(tr("Skipped (already downloaded)"))
+					ev.SetValue(1.0)
+					return nil
+				case duplicateReplace:
+					if rmErr := downloader.RecycleFile(fullPath); rmErr != nil && !os.IsNotExist(rmErr) {
+						ev.log(trf("Cannot replace existing file: %v", rmErr))
+						ev.SetText(tr("Cannot replace existing file"))
+						return rmErr
 					}
-				case "rename":
-					fullPath = downloader.UniqueName(fullPath)
 				default:
 					fullPath = downloader.UniqueName(fullPath)
 				}
@@ -878,125 +1456,412 @@ func runYTDLP(url, downloadDir, quality, outputProfile, ytdlp, ffmpeg string, in
 		}
 	}
 
-	args := []string{
-		"--ffmpeg-location", filepath.Dir(ffmpeg),
-		"-o", output,
+	b := downloader.NewArgsBuilder().FFmpegLocation(filepath.Dir(ffmpeg))
+	partialOutput := output
+	stagingDir := ""
+	if settings.StageLocal {
+		dir, err := downloader.StagingDir()
+		if err != nil {
+			ev.log(trf("Local staging unavailable, writing directly: %v", err))
+		} else {
+			stagingDir = dir
+		}
 	}
-	args = append(args, formatFromChoice(quality, outputProfile)...)
-	if playlist {
-		args = append(args, "--yes-playlist")
+	if stagingDir != "" {
+		// yt-dlp only honours temp: paths for relative output templates.
+		homeDir := filepath.Dir(output)
+		if !filepath.IsAbs(output) {
+			homeDir, _ = os.Getwd()
+		}
+		name := filepath.Base(output)
+		b.Output(downloader.ExtendedPath(homeDir), stagingDir, name)
+		partialOutput = filepath.Join(stagingDir, name)
+		ev.log(trf("Merging on local disk before copying to: %s", homeDir))
 	} else {
-		args = append(args, "--no-playlist")
+		b.Output("", "", downloader.ExtendedPath(output))
 	}
-
-	if subOpt != nil {
-		appendLog(logBox, fmt.Sprintf("Selected Subtitles: %s", subOpt.Label), mu)
-		args = append(args, "--embed-subs", "--sub-lang", subOpt.Code)
-		if subOpt.IsAuto {
-			args = append(args, "--write-auto-subs")
-		} else {
-			args = append(args, "--write-subs")
+	formatArgs := fitDevice(preferDynamicRange(formatFromChoice(settings.Quality, outputProfile, maxFPS), settings.DynamicRange), settings.Device)
+	if settings.Preserve {
+		formatArgs = []string{"-f", preserveFormat}
+		preserveArgs(b)
+		ev.log(tr("Archive mode: best quality with subtitles, info JSON, description, thumbnail and chapters."))
+	}
+	if settings.SizeLimit.MaxMB > 0 && !settings.SizeLimit.Reencode {
+		formatArgs = limitFormatArgs(formatArgs, settings.SizeLimit.bytes())
+		ev.log(trf("Only formats under %d MB will be picked.", settings.SizeLimit.MaxMB))
+	}
+	b.Options(formatArgs)
+	if picked := downloader.SelectFormats(formatSelectorOf(formatArgs), formats); len(picked) > 0 && run.OnStreams != nil {
+		run.OnStreams(downloader.HasStreams(picked))
+	}
+	if settings.Quality == "Audio Only" && settings.AudioQuality != "" && settings.AudioQuality != audioQualityBest {
+		b.Option("--audio-quality", settings.AudioQuality)
+	}
+	b.Playlist(settings.Playlist)
+	if settings.Playlist {
+		b.Options(playlistOverwriteArgs(settings.Duplicates))
+		if run.ArchiveFile != "" {
+			b.Option("--download-archive", run.ArchiveFile)
 		}
-		if mergeFormat == "mp4" {
-			// MP4 is more reliable with converted text subtitle tracks.
-			args = append(args, "--convert-subs", "srt")
+		b.Options(settings.PlaylistFilter.ytdlpArgs())
+		ext := mergeFormat
+		if settings.Quality == "Audio Only" {
+			ext = "mp3"
+		}
+		if n := playlistTrimLength(ctx, ytdlp, url, filepath.Dir(output), ext, run.Probe); n > 0 {
+			// Item names aren't known yet; let yt-dlp keep them under MAX_PATH.
+			b.Option("--trim-filenames", strconv.Itoa(n))
+		}
+	} else {
+		switch {
+		case settings.ClipEnd > 0:
+			b.Option("--download-sections", fmt.Sprintf("*%d-%d", settings.ClipStart, settings.ClipEnd))
+			ev.log(trf("Clipping from %s to %s.", formatTimestamp(settings.ClipStart), formatTimestamp(settings.ClipEnd)))
+		case settings.ClipStart > 0:
+			b.Option("--download-sections", fmt.Sprintf("*%d-inf", settings.ClipStart))
+			ev.log(trf("Clipping from %s to the end.", formatTimestamp(settings.ClipStart)))
 		}
-		// Mark first embedded subtitle track as default so players like VLC auto-pick it.
-		args = append(args, "--postprocessor-args", "EmbedSubtitle+ffmpeg:-disposition:s:0 default")
 	}
-
-	args = append(args, "--merge-output-format", mergeFormat)
-	appendLog(logBox, fmt.Sprintf("Output profile: %s (%s)", outputProfile, strings.ToUpper(mergeFormat)), mu)
-	args = append(args, url)
-	appendNerdLog(nerdLogBox, "> "+formatCommandLine(ytdlp, args), mu)
-	downloadCtx, cancelDownload := context.WithCancel(context.Background())
-	opID := setCancelable("media download", cancelDownload)
-	defer clearCancelable(opID)
-	cmd := exec.CommandContext(downloadCtx, ytdlp, args...)
-
-	setCmdHideWindow(cmd)
-
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		appendLog(logBox, fmt.Sprintf("Failed to capture stdout: %v", err), mu)
-		runOnMain(func() { status.SetText("Error: stdout capture failed") })
-		return
+	if settings.NameRules.Restrict {
+		b.Switch("--restrict-filenames")
 	}
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		appendLog(logBox, fmt.Sprintf("Failed to capture stderr: %v", err), mu)
-		runOnMain(func() { status.SetText("Error: stderr capture failed") })
-		return
+	b.Options(collisionArgs)
+	b.Options(settings.Retry.ytdlpArgs())
+	for _, e := range settings.ExtractorArgs {
+		b.ExtractorArgs(e.Site, e.Args)
 	}
-
-	if err := cmd.Start(); err != nil {
-		appendLog(logBox, fmt.Sprintf("Failed to start yt-dlp: %v", err), mu)
-		runOnMain(func() { status.SetText("Failed to start download") })
-		return
+	if settings.Thumbnail != "" {
+		b.Switch("--write-thumbnail").Option("--convert-thumbnails", settings.Thumbnail)
+	}
+	b.IPVersion(settings.Binding.IPVersion)
+	if settings.Binding.Address != "" {
+		b.SourceAddress(settings.Binding.Address)
 	}
 
-	tracker := newDownloadProgressTracker(quality, subOpt, playlist)
+	progressFile := ""
+	if !settings.Playlist && expectedDuration > 0 {
+		progressFile = filepath.Join(os.TempDir(), fmt.Sprintf("ytgui-progress-%d.txt", time.Now().UnixNano()))
+		defer os.Remove(progressFile)
+	}
+	if run.Subtitle != nil {
+		ev.log(trf("Selected Subtitles: %s", run.Subtitle.Label))
+		convert := ""
+		switch {
+		case settings.SubFormat != "" && settings.SubFormat != subFormatAuto:
+			convert = settings.SubFormat
+		case mergeFormat == "mp4":
+			// MP4 is more reliable with converted text subtitle tracks.
+			convert = "srt"
+		}
+		b.Subtitles(run.Subtitle.Code, settings.Playlist || !run.Subtitle.IsAuto, settings.Playlist || run.Subtitle.IsAuto, convert)
+		// Mark first embedded subtitle track as default so players like VLC auto-pick it.
+		b.PostprocessorArgs("EmbedSubtitle+ffmpeg", "-disposition:s:0 default"+ffmpegProgressArgs(progressFile))
+	}
+	if progressFile != "" && settings.Quality != "Audio Only" {
+		b.PostprocessorArgs("Merger+ffmpeg", strings.TrimSpace(ffmpegProgressArgs(progressFile)))
+	}
 
-	var wg sync.WaitGroup
+	b.MergeOutputFormat(mergeFormat)
+	ev.log(trf("Output profile: %s (%s)", outputProfile, strings.ToUpper(mergeFormat)))
+	args, err := b.Build(url)
+	if err != nil {
+		ev.log(trf("Cannot start download: %v", err))
+		ev.SetText(tr("Failed to start download"))
+		return err
+	}
+	ev.raw("> " + formatCommandLine(ytdlp, args))
+	ev.phase(stateDownloading)
+	tracker := newDownloadProgressTracker(settings.Quality, run.Subtitle, settings.Playlist)
+	tracker.useFFmpegProgress(progressFile, expectedDuration)
+	if !settings.Playlist {
+		selector := formatSelectorOf(formatArgs)
+		go func() {
+			logMetadataFetch(ev, ytdlp, url)
+			pctx, done := run.Probe(ctx)
+			formats, err := downloader.ListFormats(pctx, ytdlp, url)
+			err = done(err)
+			if err != nil {
+				ev.raw(fmt.Sprintf("[formats] could not explain selection: %v", err))
+				return
+			}
+			ev.log(trf("Why this format (%s, %s):", settings.Quality, outputProfile))
+			for _, line := range downloader.ExplainFormatSelector(selector, formats) {
+				ev.log("  " + line)
+			}
+			picked := downloader.SelectFormats(selector, formats)
+			sizes := make([]int64, len(picked))
+			for i, f := range picked {
+				sizes[i] = f.Size()
+			}
+			tracker.setStreamSizes(sizes)
+			for _, f := range picked {
+				if f.IsHDR() {
+					ev.log(trf("The chosen video is HDR (%s).", f.DynamicRange))
+				}
+			}
+		}()
+	}
+	// The stall watchdog stops yt-dlp through its own cause so the partial
+	// files survive for the restart.
+	ctx, stopStalled := context.WithCancelCause(ctx)
+	defer stopStalled(nil)
+	proc, err := downloader.DefaultRunner.Start(ctx, ytdlp, args)
+	if err != nil {
+		ev.log(trf("Failed to start yt-dlp: %v", err))
+		ev.SetText(tr("Failed to start download"))
+		return err
+	}
+
+	var subWatch *playlistSubtitleWatch
+	if settings.Playlist && run.Subtitle != nil {
+		subWatch = &playlistSubtitleWatch{}
+	}
+	var postprocessFailed atomic.Bool
+	var failures failureTail
+	var outWatch outputWatch
+	var stall stallWatch
+	stall.touch()
+	onLine := func(line string) (float64, string, bool) {
+		stall.observe(line)
+		if isPostprocessFailure(line) {
+			postprocessFailed.Store(true)
+		}
+		failures.observe(line)
+		if phase, ok := linePhase(line); ok {
+			ev.phase(phase)
+		}
+		outWatch.observe(line)
+		if v, ok := parseSpeed(line); ok {
+			ev.SetSpeed(v)
+		}
+		if subWatch != nil {
+			if msg, ok := subWatch.observe(line); ok {
+				ev.log(msg)
+			}
+		}
+		return tracker.update(line)
+	}
+
+	pollDone := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-pollDone:
+				return
+			case <-ticker.C:
+				if p, s, ok := tracker.pollPostprocess(); ok {
+					ev.SetValue(p)
+					ev.SetText(s)
+				}
+				if stall.stalled(settings.Retry.StallTimeout) {
+					ev.log(trf("No data received for %s; the download looks stalled.", settings.Retry.StallTimeout))
+					ev.SetText(trf("Stalled: no data for %s", settings.Retry.StallTimeout))
+					if settings.Retry.StallAction != stallMarkOnly {
+						stopStalled(errStalled)
+					}
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
 	wg.Add(2)
 
 	go func() {
 		defer wg.Done()
-		scanAndLog(stdout, logBox, nerdLogBox, status, progress, mu, tracker.update)
+		scanAndLog(proc.Stdout(), ev, onLine)
 	}()
 
 	go func() {
 		defer wg.Done()
-		scanAndLog(stderr, logBox, nerdLogBox, status, progress, mu, tracker.update)
+		scanAndLog(proc.Stderr(), ev, onLine)
 	}()
 
-	err = cmd.Wait()
+	err = proc.Wait()
 	wg.Wait()
+	close(pollDone)
+	var exitErr *exec.ExitError
+	if settings.Playlist && settings.PlaylistFilter.MaxDownloads > 0 && errors.As(err, &exitErr) && exitErr.ExitCode() == ytdlpMaxDownloadsExit {
+		ev.log(trf("Stopped after %d download(s) as set in the playlist options.", settings.PlaylistFilter.MaxDownloads))
+		err = nil
+	}
 	if err != nil {
-		if errors.Is(downloadCtx.Err(), context.Canceled) {
-			if removed := cleanupPartialMediaArtifacts(output); removed > 0 {
-				appendLog(logBox, fmt.Sprintf("Removed %d partial/intermediate file(s).", removed), mu)
+		if errors.Is(context.Cause(ctx), errResumeRestart) {
+			ev.log(tr("Download interrupted by system sleep; keeping partial files to continue."))
+			ev.SetText(tr("Waiting to resume..."))
+			return errResumeRestart
+		}
+		if errors.Is(context.Cause(ctx), errStalled) {
+			return errStalled
+		}
+		if errors.Is(ctx.Err(), context.Canceled) {
+			if removed := cleanupPartialMediaArtifacts(partialOutput); removed > 0 {
+				ev.log(trf("Removed %d partial/intermediate file(s).", removed))
 			}
-			appendLog(logBox, "Download canceled by user.", mu)
-			runOnMain(func() {
-				status.SetText("Download canceled")
-				progress.SetValue(0)
-			})
-			return
+			ev.log(tr("Download canceled by user."))
+			ev.SetText(tr("Download canceled"))
+			ev.SetValue(0)
+			return context.Canceled
 		}
-		appendLog(logBox, fmt.Sprintf("yt-dlp exited with error: %v", err), mu)
-		runOnMain(func() { status.SetText("Download failed") })
-		return
+		if postprocessFailed.Load() && mergeFormat == "mp4" && !settings.Playlist {
+			ev.SetText(tr("Retrying post-processing as MKV..."))
+			if dst, ok := fallbackToMKV(ffmpeg, output, partialOutput, settings.KeepSubs, ev); ok {
+				run.OnOutput(dst, expectedDuration)
+				ev.log(tr("Download complete (MKV fallback)."))
+				ev.SetText(tr("Download complete (saved as MKV)"))
+				ev.SetValue(1.0)
+				return nil
+			}
+		}
+		ev.log(trf("yt-dlp exited with error: %v", err))
+		ev.SetText(tr("Download failed"))
+		kind := downloader.ClassifyError(err, failures.snapshot())
+		if hint := failureHint(kind); hint != "" {
+			ev.log(hint)
+		}
+		return &downloader.DownloadError{Kind: kind, Err: err}
 	}
-	if subOpt != nil && !playlist {
-		if removed := cleanupSubtitleSidecars(output); removed > 0 {
-			appendLog(logBox, fmt.Sprintf("Cleaned up %d subtitle sidecar file(s).", removed), mu)
+	ev.phase(statePostprocessing)
+	if run.Subtitle != nil && !settings.Playlist {
+		if settings.KeepSubs {
+			if n := len(findSubtitleSidecars(output)); n > 0 {
+				ev.log(trf("Kept %d subtitle file(s) next to the video.", n))
+			}
+		} else if removed := cleanupSubtitleSidecars(output); removed > 0 {
+			ev.log(trf("Cleaned up %d subtitle sidecar file(s).", removed))
 		}
 	}
-	appendLog(logBox, "Download complete.", mu)
-	runOnMain(func() {
-		status.SetText("Download complete")
-		progress.SetValue(1.0)
-	})
+	if !settings.Playlist {
+		if final := outWatch.resolved(output); final != "" {
+			if templateMarker != "" {
+				var dropped string
+				final, dropped = settleTemplateOutput(final, templateMarker, func(path string) (choice string) {
+					ev.asking(statePostprocessing, func() { choice = run.OnDuplicate(path) })
+					return choice
+				}, ev)
+				switch dropped {
+				case duplicateCancel:
+					ev.SetText(tr("Download canceled"))
+					return context.Canceled
+				case duplicateSkip:
+					run.OnOutput(final, expectedDuration)
+					ev.SetText(tr("Skipped (already downloaded)"))
+					ev.SetValue(1.0)
+					return nil
+				}
+			}
+			if settings.Tonemap && settings.Quality != "Audio Only" {
+				final = tonemapDownload(ctx, ffmpeg, final, ev)
+			}
+			if settings.SizeLimit.MaxMB > 0 && settings.SizeLimit.Reencode {
+				final = fitDownload(ctx, ffmpeg, final, expectedDuration, settings.SizeLimit, settings.Quality != "Audio Only", ev)
+			}
+			if settings.AudioTrim.Enabled() && settings.Quality == "Audio Only" {
+				trimDownload(ctx, ffmpeg, final, settings.AudioTrim, ev)
+			}
+			if loudness != 0 && settings.Quality == "Audio Only" {
+				normalizeDownload(ctx, ffmpeg, final, loudness, settings.AudioQuality, ev)
+			}
+			if settings.SplitTracks && settings.Quality == "Audio Only" {
+				splitDownload(ctx, ytdlp, ffmpeg, url, final, videoTitle, uploader, run.Probe, ev)
+			}
+			if settings.ClipExport.Format != "" && settings.Quality != "Audio Only" {
+				final = exportDownloadedClip(ctx, ffmpeg, final, settings.ClipExport, ev)
+			}
+			ev.log(trf("Saved to: %s", final))
+			run.OnOutput(final, expectedDuration)
+		}
+	} else if files := outWatch.files(); len(files) > 0 {
+		logSavedFiles(files, ev)
+		ev.log(tr("Playlist items aren't checked with ffprobe or added to the history."))
+	}
+	ev.log(tr("Download complete."))
+	ev.SetText(tr("Download complete"))
+	ev.SetValue(1.0)
+	return nil
+}
+
+func openInFileManager(target string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("explorer", target)
+	case "darwin":
+		cmd = exec.Command("open", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+	return cmd.Start()
+}
+
+func queueRowText(job downloadJob) string {
+	text := fmt.Sprintf("#%d [%s] %s", job.ID, tr(job.State.String()), job.URL)
+	if job.Priority && job.State == jobQueued {
+		text = fmt.Sprintf("#%d [%s] %s", job.ID, tr("Next"), job.URL)
+	}
+	if s := strings.TrimSpace(job.Status); s != "" {
+		text += " - " + s
+	}
+	if len(job.Settings.Tags) > 0 {
+		text += " {" + formatTags(job.Settings.Tags) + "}"
+	}
+	return text
 }
 
-func RunApp(assets Assets) {
-	a := app.NewWithID("com.wishall.ytgui")
+func RunApp(assets Assets, args []string) {
+	startURLs := launchURLs(args)
+	startBatches := batchArgs(args)
+	// A running ytgui takes the links, or just comes to the front; batch
+	// files still start their own window.
+	if len(startBatches) == 0 && forwardToRunningInstance(startURLs) {
+		return
+	}
+
+	a := withConfig(app.NewWithID("com.wishall.ytgui"))
 	a.SetIcon(appIcon)
-	w := a.NewWindow("yt-dlp Portable GUI")
+	setLanguage(a.Preferences().String(prefLanguage))
+	w := a.NewWindow(tr("yt-dlp Portable GUI"))
 	w.SetIcon(appIcon)
-	w.Resize(fyne.NewSize(600, 400))
+	applyAppearance(a)
+	scale := float32(a.Preferences().FloatWithFallback(prefUIScale, 1))
+	w.Resize(restoredWindowSize(a.Preferences(), fyne.NewSize(600*scale, 400*scale)))
+	var states *appStateMachine
+	// activeJobs counts the running and waiting jobs of the queue.
+	var activeJobs func() (running, queued int)
+	// finishInBackground is set while the window is hidden in the tray
+	// waiting for the queue to drain; ytgui quits when it does.
+	var finishInBackground atomic.Bool
+	quit := func() {
+		saveWindowSize(a.Preferences(), w.Canvas().Size())
+		a.Quit()
+	}
+	var background func()
+	if desk, ok := a.(desktop.App); ok {
+		background = func() {
+			desk.SetSystemTrayIcon(appIcon)
+			desk.SetSystemTrayMenu(fyne.NewMenu("ytgui",
+				fyne.NewMenuItem(tr("Show ytgui"), func() {
+					finishInBackground.Store(false)
+					w.Show()
+				}),
+			))
+			finishInBackground.Store(true)
+			w.Hide()
+			// The last job may have ended while the dialog was open.
+			if running, queued := activeJobs(); running+queued == 0 {
+				quit()
+			}
+		}
+	}
 	confirmClose := func() {
-		dialog.ShowConfirm(
-			"Exit",
-			"Close ytgui?",
-			func(ok bool) {
-				if ok {
-					a.Quit()
-				}
-			},
-			w,
-		)
+		if states == nil || !states.state().busy() {
+			quit()
+			return
+		}
+		running, queued := activeJobs()
+		askClose(w, running, queued, quit, background)
 	}
 	w.SetCloseIntercept(confirmClose)
 	w.Canvas().AddShortcut(&desktop.CustomShortcut{
@@ -1006,10 +1871,15 @@ func RunApp(assets Assets) {
 		confirmClose()
 	})
 
-	url := widget.NewEntry()
-	url.SetPlaceHolder("Paste video URL")
+	url := newShortcutEntry()
+	url.SetPlaceHolder(tr("Paste video URL"))
 
 	prefs := a.Preferences()
+	if prefs.Bool(prefLowMemory) {
+		setLowMemoryMode(true)
+	}
+	iconLabels.Store(prefs.Bool(prefIconLabels))
+	setLogVerbosity(selectedOption(prefs, prefLogVerbosity, logLevels, logNormal))
 	defaultDir := defaultDownloadDir()
 	savedDir := strings.TrimSpace(prefs.StringWithFallback(prefDownloadDir, ""))
 	downloadDir := savedDir
@@ -1017,22 +1887,225 @@ func RunApp(assets Assets) {
 		downloadDir = defaultDir
 	}
 	prefs.SetString(prefDownloadDir, downloadDir)
+	// Twitch mode swaps in Twitch's quality labels while the URL points at a
+	// VOD or clip; its choice is remembered separately.
+	twitchMode := false
+	qualityChoices := func() ([]string, string, string) {
+		if twitchMode {
+			return twitchQualities, prefTwitchQuality, "Source"
+		}
+		return qualityOptions, prefQuality, "720p"
+	}
+	var audioRow *fyne.Container
 	qualitySelect := widget.NewSelect(
-		[]string{"Best", "1080p", "720p", "480p", "Audio Only"},
-		func(string) {},
+		trList(qualityOptions),
+		func(shown string) {
+			options, key, _ := qualityChoices()
+			prefs.SetString(key, untr(options, shown))
+			if audioRow != nil {
+				if untr(options, shown) == "Audio Only" {
+					audioRow.Show()
+				} else {
+					audioRow.Hide()
+				}
+			}
+		},
 	)
-	qualitySelect.SetSelected("720p")
+	qualitySelect.SetSelected(tr(selectedOption(prefs, prefQuality, qualityOptions, "720p")))
+	audioQualitySelect := widget.NewSelect(trList(audioQualities), func(shown string) {
+		prefs.SetString(prefAudioQuality, untr(audioQualities, shown))
+	})
+	audioQualitySelect.SetSelected(tr(selectedOption(prefs, prefAudioQuality, audioQualities, audioQualityBest)))
+	loudnessSelect := widget.NewSelect(trList(loudnessOptions), func(shown string) {
+		prefs.SetString(prefLoudness, untr(loudnessOptions, shown))
+	})
+	loudnessSelect.SetSelected(tr(selectedOption(prefs, prefLoudness, loudnessOptions, loudnessOff)))
+	trimRow, setAudioTrim := audioTrimRow(prefs)
+	splitCheck := widget.NewCheck(tr("Split into tracks"), func(on bool) {
+		prefs.SetBool(prefSplitTracks, on)
+	})
+	splitCheck.SetChecked(prefs.Bool(prefSplitTracks))
+	audioRow = container.NewVBox(
+		container.NewHBox(widget.NewLabel(tr("Audio quality:")), audioQualitySelect, widget.NewLabel(tr("Normalize loudness:")), loudnessSelect, splitCheck),
+		trimRow,
+	)
+	fpsSelect := widget.NewSelect(trList(fpsOptions), func(shown string) {
+		prefs.SetString(prefMaxFPS, untr(fpsOptions, shown))
+	})
+	fpsSelect.SetSelected(tr(selectedOption(prefs, prefMaxFPS, fpsOptions, fpsAny)))
+	rangeSelect := widget.NewSelect(trList(dynamicRanges), func(shown string) {
+		prefs.SetString(prefDynamicRange, untr(dynamicRanges, shown))
+	})
+	rangeSelect.SetSelected(tr(selectedOption(prefs, prefDynamicRange, dynamicRanges, rangePreferSDR)))
+	tonemapCheck := widget.NewCheck(tr("Convert HDR to SDR"), func(on bool) {
+		prefs.SetBool(prefTonemap, on)
+	})
+	tonemapCheck.SetChecked(prefs.Bool(prefTonemap))
+	if selectedOption(prefs, prefQuality, qualityOptions, "720p") != "Audio Only" {
+		audioRow.Hide()
+	}
+	twitchChatSelect := widget.NewSelect(trList(twitchChatModes), func(shown string) {
+		prefs.SetString(prefTwitchChat, untr(twitchChatModes, shown))
+	})
+	twitchChatSelect.SetSelected(tr(selectedOption(prefs, prefTwitchChat, twitchChatModes, twitchChatOff)))
+	twitchRow := container.NewHBox(widget.NewLabel(tr("Twitch chat replay:")), twitchChatSelect)
+	twitchRow.Hide()
+	setTwitchMode := func(on bool) {
+		if on == twitchMode {
+			return
+		}
+		twitchMode = on
+		options, key, fallback := qualityChoices()
+		qualitySelect.Options = trList(options)
+		qualitySelect.SetSelected(tr(selectedOption(prefs, key, options, fallback)))
+		if on {
+			twitchRow.Show()
+		} else {
+			twitchRow.Hide()
+		}
+	}
+	selectedQuality := func() string {
+		options, _, _ := qualityChoices()
+		return untr(options, qualitySelect.Selected)
+	}
 	profileSelect := widget.NewSelect(
-		[]string{"Widely Compatible (H.264/AAC)", "Smaller File Size (AV1/VP9)"},
-		func(string) {},
+		trList(profileOptions),
+		func(shown string) {
+			prefs.SetString(prefProfile, untr(profileOptions, shown))
+		},
 	)
-	profileSelect.SetSelected("Widely Compatible (H.264/AAC)")
-	nameWithChannel := widget.NewCheck("Include channel name in filename", func(bool) {})
-	playlistCheck := widget.NewCheck("Download Playlist", func(bool) {})
-	subsCheck := widget.NewCheck("Download Subtitles", func(bool) {})
-	subsCheck.SetChecked(false)
-	nameWithChannel.SetChecked(true)
-	status := widget.NewLabel("Idle")
+	profileSelect.SetSelected(tr(selectedOption(prefs, prefProfile, profileOptions, profileCompatible)))
+	nameWithChannel := widget.NewCheck(tr("Include channel name in filename"), func(on bool) {
+		prefs.SetBool(prefWithChannel, on)
+	})
+	var listPrompt *playlistPrompt
+	listOptions := playlistOptionsPanel(prefs)
+	listOptions.Hide()
+	playlistCheck := widget.NewCheck(tr("Download Playlist"), func(on bool) {
+		prefs.SetBool(prefPlaylist, on)
+		if on {
+			listOptions.Show()
+		} else {
+			listOptions.Hide()
+		}
+		if listPrompt != nil {
+			listPrompt.update(url.Text, on)
+		}
+	})
+	subFormatSelect := widget.NewSelect(trList(subFormats), func(shown string) {
+		prefs.SetString(prefSubFormat, untr(subFormats, shown))
+	})
+	subFormatSelect.SetSelected(tr(selectedOption(prefs, prefSubFormat, subFormats, subFormatAuto)))
+	keepSubsCheck := widget.NewCheck(tr("Keep subtitle files"), func(on bool) {
+		prefs.SetBool(prefKeepSubs, on)
+	})
+	keepSubsCheck.SetChecked(prefs.Bool(prefKeepSubs))
+	subsCheck := widget.NewCheck(tr("Download Subtitles"), func(on bool) {
+		prefs.SetBool(prefSubtitles, on)
+		if on {
+			subFormatSelect.Enable()
+			keepSubsCheck.Enable()
+		} else {
+			subFormatSelect.Disable()
+			keepSubsCheck.Disable()
+		}
+	})
+	subsCheck.SetChecked(prefs.BoolWithFallback(prefSubtitles, false))
+	if !subsCheck.Checked {
+		subFormatSelect.Disable()
+		keepSubsCheck.Disable()
+	}
+	playlistCheck.SetChecked(prefs.BoolWithFallback(prefPlaylist, false))
+	nameWithChannel.SetChecked(prefs.BoolWithFallback(prefWithChannel, true))
+	deviceSelect := widget.NewSelect(trList(deviceProfiles), func(shown string) {
+		prefs.SetString(prefDeviceProfile, untr(deviceProfiles, shown))
+	})
+	deviceSelect.SetSelected(tr(selectedOption(prefs, prefDeviceProfile, deviceProfiles, deviceAny)))
+	compat := newCompatNotice(func() { profileSelect.SetSelected(tr(profileCompatible)) })
+	refreshCompat := func() {
+		compat.update(compatibilityWarnings(jobSettings{
+			Quality:   selectedQuality(),
+			Profile:   untr(profileOptions, profileSelect.Selected),
+			Device:    untr(deviceProfiles, deviceSelect.Selected),
+			Subtitles: subsCheck.Checked,
+			SubFormat: untr(subFormats, subFormatSelect.Selected),
+			KeepSubs:  keepSubsCheck.Checked,
+		}))
+	}
+	for _, s := range []*widget.Select{qualitySelect, profileSelect, deviceSelect, subFormatSelect} {
+		changed := s.OnChanged
+		s.OnChanged = func(v string) {
+			changed(v)
+			refreshCompat()
+		}
+	}
+	for _, c := range []*widget.Check{subsCheck, keepSubsCheck} {
+		changed := c.OnChanged
+		c.OnChanged = func(on bool) {
+			changed(on)
+			refreshCompat()
+		}
+	}
+	refreshCompat()
+	presets := newPresetBar(w, prefs, func() qualityPreset {
+		return qualityPreset{
+			Quality:        selectedOption(prefs, prefQuality, qualityOptions, "720p"),
+			Profile:        untr(profileOptions, profileSelect.Selected),
+			AudioQuality:   selectedOption(prefs, prefAudioQuality, audioQualities, ""),
+			Loudness:       selectedOption(prefs, prefLoudness, loudnessOptions, ""),
+			TrimSilence:    prefs.Bool(prefTrimSilence),
+			CutIntro:       prefs.Int(prefCutIntro),
+			CutOutro:       prefs.Int(prefCutOutro),
+			SplitTracks:    splitCheck.Checked,
+			MaxFPS:         fpsCap(selectedOption(prefs, prefMaxFPS, fpsOptions, fpsAny)),
+			DynamicRange:   selectedOption(prefs, prefDynamicRange, dynamicRanges, ""),
+			Tonemap:        tonemapCheck.Checked,
+			IncludeChannel: nameWithChannel.Checked,
+			Subtitles:      subsCheck.Checked,
+			SubtitleLang:   strings.TrimSpace(prefs.String(prefSubtitleLang)),
+			SubFormat:      untr(subFormats, subFormatSelect.Selected),
+			KeepSubs:       keepSubsCheck.Checked,
+			SortRule:       selectedOption(prefs, prefSortRule, sortRules, ""),
+			SortTemplate:   prefs.String(prefSortTemplate),
+		}
+	}, func(p qualityPreset) {
+		if containsString(qualityOptions, p.Quality) {
+			prefs.SetString(prefQuality, p.Quality)
+			if !twitchMode {
+				qualitySelect.SetSelected(tr(p.Quality))
+			}
+		}
+		if containsString(profileOptions, p.Profile) {
+			profileSelect.SetSelected(tr(p.Profile))
+		}
+		audioQualitySelect.SetSelected(tr(valueOr(p.AudioQuality, audioQualityBest)))
+		loudnessSelect.SetSelected(tr(valueOr(p.Loudness, loudnessOff)))
+		splitCheck.SetChecked(p.SplitTracks)
+		setAudioTrim(downloader.AudioTrim{Silence: p.TrimSilence, Intro: float64(p.CutIntro), Outro: float64(p.CutOutro)})
+		fpsSelect.SetSelected(tr(fpsOption(p.MaxFPS)))
+		rangeSelect.SetSelected(tr(valueOr(p.DynamicRange, rangePreferSDR)))
+		tonemapCheck.SetChecked(p.Tonemap)
+		nameWithChannel.SetChecked(p.IncludeChannel)
+		subsCheck.SetChecked(p.Subtitles)
+		prefs.SetString(prefSubtitleLang, p.SubtitleLang)
+		subFormatSelect.SetSelected(tr(valueOr(p.SubFormat, subFormatAuto)))
+		keepSubsCheck.SetChecked(p.KeepSubs)
+		prefs.SetString(prefSortRule, valueOr(p.SortRule, sortOff))
+		prefs.SetString(prefSortTemplate, p.SortTemplate)
+	})
+	status := newStatusLine(tr("Idle"), &narrator{enabled: func() bool { return prefs.Bool(prefSpeakStatus) }})
+	probes := &probeTracker{timeout: func() time.Duration { return probeTimeout(prefs) }}
+	stopWaiting := widget.NewButton(tr("Stop Waiting"), probes.stopAll)
+	stopWaiting.Hide()
+	probes.onChange = func(n int) {
+		runOnMain(func() {
+			if n > 0 {
+				stopWaiting.Show()
+			} else {
+				stopWaiting.Hide()
+			}
+		})
+	}
 	progress := widget.NewProgressBar()
 	progress.SetValue(0)
 
@@ -1041,11 +2114,15 @@ func RunApp(assets Assets) {
 	nerdLogBox := widget.NewMultiLineEntry()
 	nerdLogBox.Wrapping = fyne.TextWrapOff
 	var logMu sync.Mutex
+	bus := downloader.NewBus()
+	appEvents := publisher{bus: bus}
+	var dupResolver duplicateResolver
 	var cancelMu sync.Mutex
 	var cancelSeq int64
 	var activeCancel context.CancelFunc
 	var activeCancelLabel string
 	var cancelDownloadBtn *widget.Button
+	var queue *downloadQueue
 
 	setCancelable := func(label string, cancel context.CancelFunc) int64 {
 		cancelMu.Lock()
@@ -1054,9 +2131,7 @@ func RunApp(assets Assets) {
 		activeCancel = cancel
 		activeCancelLabel = label
 		cancelMu.Unlock()
-		runOnMain(func() {
-			cancelDownloadBtn.Enable()
-		})
+		states.setCancelable(true)
 		return opID
 	}
 
@@ -1069,246 +2144,1546 @@ func RunApp(assets Assets) {
 		activeCancel = nil
 		activeCancelLabel = ""
 		cancelMu.Unlock()
+		states.setCancelable(false)
+	}
+
+	cancelDownloadBtn = widget.NewButton(tr("Cancel Download"), func() {
+		cancelMu.Lock()
+		cancel := activeCancel
+		label := activeCancelLabel
+		cancelMu.Unlock()
+		if cancel == nil {
+			running := queue.runningCount()
+			if running == 0 {
+				return
+			}
+			cancel = func() { queue.cancelRunning() }
+			label = "media download"
+			if running > 1 {
+				label = fmt.Sprintf("%d media downloads", running)
+			}
+		}
+
+		// Labels stay English internally; only the text shown is translated.
+		shown := tr(label)
+		if tool, ok := strings.CutPrefix(label, "downloading "); ok {
+			shown = trf("downloading %s", tool)
+		} else if n, ok := strings.CutSuffix(label, " media downloads"); ok {
+			shown = trf("%s media downloads", n)
+		}
+		msg := tr("Cancel current internet download?\n\nPartial/intermediate files will be deleted where possible.")
+		if strings.TrimSpace(label) != "" {
+			msg = trf("Cancel current internet download (%s)?\n\nPartial/intermediate files will be deleted where possible.", shown)
+		}
+		confirmText := tr("Cancel Download")
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(label)), "downloading ") {
+			confirmText = tr("Quit Application")
+		}
+		d := dialog.NewCustomConfirm(
+			tr("Cancel Download"),
+			confirmText,
+			tr("Back"),
+			widget.NewLabel(msg),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				appendLog(logBox, tr("Cancel requested by user."), &logMu)
+				cancel()
+			},
+			w,
+		)
+		d.Resize(fyne.NewSize(520, 220))
+		d.Show()
+	})
+	cancelDownloadBtn.Disable()
+
+	stageCheck := widget.NewCheck(tr("Merge on local disk first (slow USB/network folder)"), nil)
+	stageCheck.SetChecked(folderInList(prefs.StringList(prefStagedDirs), downloadDir))
+	stageCheck.OnChanged = func(on bool) {
+		prefs.SetStringList(prefStagedDirs, setFolderInList(prefs.StringList(prefStagedDirs), downloadDir, on))
+	}
+
+	var chooseFolder *widget.Button
+	useFolder := func(dir string) {
+		if err := downloader.ValidateFolder(dir); err != nil {
+			appendLog(logBox, trf("Cannot use %s: %v", dir, err), &logMu)
+			dialog.ShowError(fmt.Errorf("%s: %w", dir, err), w)
+			return
+		}
+		downloadDir = dir
+		prefs.SetString(prefDownloadDir, downloadDir)
+		rememberFolder(prefs, downloadDir)
+		runOnMain(func() {
+			chooseFolder.SetText(folderButtonText(downloadDir))
+			stageCheck.SetChecked(folderInList(prefs.StringList(prefStagedDirs), downloadDir))
+		})
+		appendLog(logBox, trf("Download folder: %s", downloadDir), &logMu)
+	}
+	browseFolder := func() {
+		dialog.ShowFolderOpen(func(lu fyne.ListableURI, err error) {
+			if err != nil || lu == nil {
+				return
+			}
+			useFolder(lu.Path())
+		}, w)
+	}
+	chooseFolder = widget.NewButton(folderButtonText(downloadDir), browseFolder)
+	var folderMenuBtn *widget.Button
+	folderMenuBtn = newIconButton(tr("Recent Folders"), theme.MenuDropDownIcon(), func() {
+		showFolderMenu(w, folderMenuBtn, folderMenu(prefs, downloadDir, useFolder, browseFolder))
+	})
+	openFolder := widget.NewButton(tr("Open Folder"), func() {
+		target := strings.TrimSpace(downloadDir)
+		if target == "" {
+			appendLog(logBox, tr("No download folder selected."), &logMu)
+			states.show(tr("No download folder selected"))
+			return
+		}
+		info, err := os.Stat(target)
+		if err != nil || !info.IsDir() {
+			appendLog(logBox, trf("Download folder does not exist: %s", target), &logMu)
+			states.show(tr("Download folder missing"))
+			return
+		}
+
+		if err := openInFileManager(target); err != nil {
+			appendLog(logBox, trf("Failed to open folder: %v", err), &logMu)
+			states.show(tr("Failed to open folder"))
+		}
+	})
+
+	var toolsReady atomic.Bool
+	var preparedYTDLPPath string
+	var preparedFFmpegPath string
+	var preparedFFprobePath string
+	var history historyStore
+	if err := history.load(); err != nil {
+		appendNerdLog(nerdLogBox, fmt.Sprintf("[history] load failed: %v", err), &logMu)
+	}
+	redownload := func(url string, settings jobSettings) {
+		settings.Duplicates = duplicateReplace
+		settings.Redownload = true
+		job := queue.add(url, settings)
+		appendLog(logBox, trf("Queued job #%d to download again: %s", job.ID, url), &logMu)
+	}
+	// recordDownload adds a finished file to the history, checking it with
+	// ffprobe first when available. wantVideo and wantAudio are the streams
+	// the file should have.
+	recordDownload := func(job *downloadJob, result downloadResult, videoID, title, channel string, expected float64, wantVideo, wantAudio bool) {
+		path := result.Path
+		entry := historyEntry{
+			URL:      job.URL,
+			VideoID:  videoID,
+			Title:    title,
+			Channel:  channel,
+			Path:     path,
+			Finished: time.Now(),
+			Settings: job.Settings,
+			Tags:     job.Settings.Tags,
+			Note:     job.Settings.Note,
+		}
+		if result.Probed {
+			if result.ProbeErr != nil {
+				entry.Problems = []string{result.ProbeErr.Error()}
+			} else {
+				entry.Problems = downloader.VerifyMedia(result.Probe, expected, wantVideo, wantAudio)
+			}
+			entry.Verify = verifyOK
+			if len(entry.Problems) > 0 {
+				entry.Verify = verifySuspect
+			}
+		}
+		entry.Size = result.Size
+		if sum, err := downloader.FileSHA256(path); err == nil {
+			entry.SHA256 = sum
+			appendNerdLog(job.NerdLog, "[history] sha256 "+sum+"  "+path, &logMu)
+		} else {
+			appendNerdLog(job.NerdLog, fmt.Sprintf("[history] sha256 failed: %v", err), &logMu)
+		}
+		if job.Settings.Preserve {
+			if files, manifest, err := writeChecksums(path); err == nil {
+				entry.Files = files
+				appendLog(job.Log, trf("Checksums of %d file(s) written to %s", len(files), manifest), &logMu)
+			} else {
+				appendLog(job.Log, trf("Could not write checksums: %v", err), &logMu)
+			}
+		}
+		if err := history.add(entry); err != nil {
+			appendNerdLog(job.NerdLog, fmt.Sprintf("[history] save failed: %v", err), &logMu)
+		}
+		if entry.Verify != verifySuspect {
+			if entry.Verify == verifyOK {
+				appendLog(job.Log, tr("Verified: streams and duration look complete."), &logMu)
+			}
+			return
+		}
+		appendLog(job.Log, trf("File looks incomplete: %s", strings.Join(entry.Problems, "; ")), &logMu)
+		if job.Settings.Redownload {
+			return
+		}
+		if prefs.Bool(prefAutoRedownload) {
+			redownload(job.URL, job.Settings)
+			return
+		}
+		runOnMain(func() {
+			dialog.ShowConfirm(tr("Download Looks Incomplete"),
+				trf("%s\n%s\nDownload it again?", path, strings.Join(entry.Problems, "; ")),
+				func(ok bool) {
+					if ok {
+						redownload(job.URL, job.Settings)
+					}
+				}, w)
+		})
+	}
+	var btn *widget.Button
+	executeJob := func(ctx context.Context, job *downloadJob) error {
+		ev := publisher{bus: bus, job: job.ID}
+		settings := job.Settings
+		ytdlpPath := preparedYTDLPPath
+		ffmpegPath := preparedFFmpegPath
+		if strings.TrimSpace(ytdlpPath) == "" || strings.TrimSpace(ffmpegPath) == "" {
+			ev.log(tr("Tools are not ready yet. Please wait."))
+			ev.SetText(tr("Preparing required tools..."))
+			return errors.New("tools are not ready")
+		}
+		ev.log(trf("Job #%d: %s", job.ID, job.URL))
+		ev.raw("Tool path: " + ytdlpPath)
+		ev.raw("Tool path: " + ffmpegPath)
+
+		if !downloader.IsYouTubeURL(job.URL) {
+			ev.SetText(tr("Detecting site..."))
+			if settings.Playlist {
+				ev.raw("> " + formatCommandLine(ytdlpPath, []string{"-J", "--flat-playlist", "--encoding", "utf-8", "--no-warnings", job.URL}))
+			} else {
+				logMetadataFetch(ev, ytdlpPath, job.URL)
+			}
+			pctx, done := probes.start(ctx)
+			site, err := downloader.DetectSite(pctx, ytdlpPath, job.URL, settings.Playlist)
+			err = done(err)
+			if err != nil {
+				ev.log(trf("Could not detect the site: %v", err))
+			} else {
+				ev.log(trf("Site: %s", site.Extractor))
+				if !site.HasVideo && settings.Quality != "Audio Only" {
+					ev.log(tr("This site only offers audio; saving as audio."))
+					settings.Quality = "Audio Only"
+				}
+				if settings.Subtitles && !settings.Playlist && !site.HasSubtitles {
+					ev.log(tr("No subtitles offered for this video; skipping subtitles."))
+					settings.Subtitles = false
+				}
+			}
+		}
+
+		if settings.Playlist && isMixPlaylist(job.URL) {
+			if limit := prefs.IntWithFallback(prefMixItemCap, defaultMixItemCap); settings.PlaylistFilter.capMix(limit) {
+				ev.log(trf("This is a YouTube Mix, which never ends. Downloading only the first %d items.", limit))
+			} else {
+				ev.log(trf("This is a YouTube Mix, which never ends. Using the playlist range %s.", settings.PlaylistFilter.Items))
+			}
+		}
+		if settings.Playlist && job.Restarts == 0 && prefs.Bool(prefConfirmPlaylistSize) {
+			ev.SetText(tr("Estimating playlist size..."))
+			var rangeArgs []string
+			if settings.PlaylistFilter.Items != "" {
+				rangeArgs = []string{"--playlist-items", settings.PlaylistFilter.Items}
+			}
+			pctx, done := probes.start(ctx)
+			entries, err := downloader.PlaylistEntries(pctx, ytdlpPath, job.URL, rangeArgs)
+			err = done(err)
+			if err != nil {
+				ev.log(trf("Could not estimate the playlist size: %v", err))
+			} else {
+				// Stop Waiting ends the whole estimate; items not probed yet
+				// count as unknown.
+				pctx, done := probes.start(ctx)
+				est := downloader.EstimateSize(pctx, ytdlpPath, entries, deviceFormatSelector(settings), estimateWorkers, estimateSample, probeTimeout(prefs), func(done, total int) {
+					ev.SetText(trf("Estimating playlist size (%d/%d)...", done, total))
+				})
+				done(nil)
+				estimate := sizeEstimateText(est)
+				ev.log(estimate)
+				confirmed := false
+				ev.asking(stateProbing, func() { confirmed = askConfirmPlaylistSize(w, estimate) })
+				if !confirmed {
+					ev.log(tr("Playlist download canceled."))
+					return context.Canceled
+				}
+			}
+		}
+
+		var selectedSub *downloader.SubOption
+		if settings.Preserve {
+			selectedSub = preserveSubtitles()
+		} else if settings.Subtitles && settings.Playlist {
+			selectedSub = playlistSubtitleOption(settings.SubtitleLang, settings.SubtitleOrder)
+		} else if settings.Subtitles {
+			ev.SetText(tr("Checking subtitles..."))
+			ev.log(tr("Fetching subtitle list..."))
+
+			logMetadataFetch(ev, ytdlpPath, job.URL)
+			pctx, done := probes.start(ctx)
+			opts, err := downloader.GetAvailableSubtitles(pctx, ytdlpPath, job.URL)
+			err = done(err)
+			if err != nil {
+				ev.log(trf("Could not list subtitles: %v. Proceeding without.", err))
+			} else {
+				for _, line := range subtitleAvailabilitySummary(opts) {
+					ev.log(line)
+				}
+
+				categoryOpts := subtitleCategoryOptions(opts, settings.SubtitleLang)
+				if settings.SubtitleMode == subtitleModeNever {
+					if c := preferredSubtitleChoice(opts, settings.SubtitleLang, settings.SubtitleOrder); c != nil {
+						selectedSub = &c.opt
+						ev.log(trf("Subtitles picked by preference (%s): %s", c.label, c.opt.Label))
+					} else {
+						ev.log(tr("No subtitles match the saved preference. Downloading without subtitles."))
+					}
+				} else if len(categoryOpts) == 0 {
+					ev.log(tr("No preferred subtitle category available."))
+					proceed := false
+					ev.asking(stateProbing, func() { proceed = askDownloadWithoutSubs(w) })
+					if !proceed {
+						ev.log(tr("Download canceled by user (no subtitles available). Quitting application."))
+						runOnMain(func() {
+							states.show(tr("Quitting application..."))
+							a.Quit()
+						})
+						return context.Canceled
+					}
+					ev.log(tr("Proceeding without subtitles."))
+					selectedSub = nil
+				} else {
+					autoSelected, promptOptions := planSubtitleSelection(categoryOpts, settings.SubtitleLang)
+					switch {
+					case autoSelected != nil:
+						selectedSub = autoSelected
+						ev.log(trf("Auto-selected subtitles: %s", selectedSub.Label))
+					case len(promptOptions) > 0:
+						ev.log(tr("Multiple subtitle languages found. Please choose one."))
+						ev.asking(stateProbing, func() { selectedSub = askSubtitleChoice(w, categoryOpts, settings.SubtitleLang) })
+					default:
+						selectedSub = nil
+					}
+				}
+			}
+		}
+
+		ev.SetText(tr("Starting download..."))
+		ev.SetValue(0)
+		ev.log(tr("Starting download..."))
+
+		var outPath, videoID, outTitle, outChannel string
+		// Without the chosen formats to go by, only audio downloads are
+		// sure to have audio: some sites serve silent clips.
+		audioOnly := settings.Quality == "Audio Only"
+		wantVideo, wantAudio := !audioOnly, audioOnly
+		var outDuration float64
+		// Playlist items the history has, including imported ones, are left
+		// to yt-dlp's archive check unless the job replaces them.
+		var archiveFile string
+		if settings.Playlist && settings.Duplicates != duplicateReplace && !settings.Redownload {
+			path, err := syncDownloadArchive(history.list())
+			if err != nil {
+				ev.log(trf("Could not write the download archive: %v", err))
+			} else {
+				archiveFile = path
+			}
+		}
+		started := time.Now()
+		stallRestarts := 0
+		for attempt := 1; ; attempt++ {
+			err := runYTDLP(ctx, ytdlpRun{
+				URL:                job.URL,
+				Settings:           settings,
+				YTDLP:              ytdlpPath,
+				FFmpeg:             ffmpegPath,
+				Subtitle:           selectedSub,
+				ArchiveFile:        archiveFile,
+				TemplateCollisions: selectedOption(prefs, prefTemplateCollisions, templateCollisionModes, templateCollisionsPolicy),
+				OnDuplicate: func(path string) string {
+					return dupResolver.resolve(w, path, settings.Duplicates)
+				},
+				OnVideoID: func(id string) bool {
+					videoID = id
+					if settings.Redownload {
+						return true
+					}
+					return dupResolver.resolveSeen(w, &history, id, settings.Duplicates, ev)
+				},
+				OnOutput: func(path string, duration float64) {
+					outPath, outDuration = path, duration
+					queue.update(job.ID, func(j *downloadJob) { j.Output = path })
+				},
+				OnFileName: func(s downloadSummary, base, ext string) (string, bool) {
+					outTitle, outChannel = s.Title, s.Channel
+					// A name picked once is kept for retries and restarts.
+					if settings.FileName != "" {
+						return settings.FileName, true
+					}
+					if !prefs.Bool(prefAskFileName) {
+						return base, true
+					}
+					name, ok := askFileName(w, s, base, ext)
+					if ok {
+						settings.FileName = name
+						queue.update(job.ID, func(j *downloadJob) { j.Settings.FileName = name })
+						if name != base {
+							ev.log(trf("Saving as: %s.%s", name, ext))
+						}
+					}
+					return name, ok
+				},
+				OnStreams: func(video, audio bool) {
+					wantVideo, wantAudio = video && !audioOnly, audio || audioOnly
+				},
+				Probe: probes.start,
+			}, ev)
+			if err == nil && outPath != "" {
+				result := newDownloadResult(preparedFFprobePath, outPath, time.Since(started))
+				summary := result.summary()
+				ev.log(trf("Finished %s: %s", filepath.Base(outPath), summary))
+				queue.update(job.ID, func(j *downloadJob) { j.Summary = summary })
+				recordDownload(job, result, videoID, outTitle, outChannel, outDuration, wantVideo, wantAudio)
+				if settings.TwitchChat != "" && settings.TwitchChat != twitchChatOff && !settings.Playlist && isTwitchVOD(job.URL) {
+					ev.SetText(tr("Downloading chat replay..."))
+					downloadTwitchChat(job.URL, outPath, settings.TwitchChat, ev)
+				}
+				if settings.Archive.enabled() && !settings.Playlist {
+					ev.SetText(tr("Saving comments and chat..."))
+					saveSidecars(ctx, ytdlpPath, job.URL, outPath, settings.Archive, settings.ExtractorArgs, ev)
+				}
+			}
+			// Restarting a stalled download doesn't use up an attempt.
+			if errors.Is(err, errStalled) && ctx.Err() == nil && stallRestarts < maxStallRestarts {
+				stallRestarts++
+				attempt--
+				if settings.Retry.StallAction == stallSwitchClient && downloader.IsYouTubeURL(job.URL) {
+					var client string
+					settings.ExtractorArgs, client = nextYouTubeClient(settings.ExtractorArgs)
+					ev.log(trf("Restarting the stalled download with the %s player client.", client))
+				} else {
+					ev.log(tr("Restarting the stalled download."))
+				}
+				ev.SetText(tr("Restarting stalled download..."))
+				continue
+			}
+			if ctx.Err() != nil || !settings.Retry.shouldRetry(attempt, err) {
+				return err
+			}
+			wait := settings.Retry.delay(attempt)
+			ev.log(trf("Attempt %d of %d failed; retrying in %s.", attempt, settings.Retry.Attempts, wait))
+			ev.SetText(trf("Retrying in %s...", wait))
+			select {
+			case <-ctx.Done():
+				if errors.Is(context.Cause(ctx), errResumeRestart) {
+					return errResumeRestart
+				}
+				return context.Canceled
+			case <-time.After(wait):
+			}
+		}
+	}
+	runJob := func(ctx context.Context, job *downloadJob) error {
+		bus.Publish(downloader.Event{Kind: downloader.EventJobStarted, JobID: job.ID, Text: job.URL})
+		err := executeJob(ctx, job)
+		states.jobDone(job.ID, err)
+		appEvents.log(jobSummaryLine(job.ID, err))
+		return err
+	}
+	queue = newDownloadQueue(runJob)
+	activeJobs = func() (running, queued int) {
+		for _, job := range queue.snapshot() {
+			switch job.State {
+			case jobRunning:
+				running++
+			case jobQueued:
+				queued++
+			}
+		}
+		return running, queued
+	}
+	states = newAppStateMachine(func(s appStatus) {
+		if !states.ready() {
+			btn.Disable()
+		} else {
+			btn.Enable()
+		}
+		if s.State.busy() || s.Cancelable {
+			cancelDownloadBtn.Enable()
+		} else {
+			cancelDownloadBtn.Disable()
+		}
+		switch s.State {
+		case stateIdle:
+			status.SetText(valueOr(s.Note, tr("Idle")))
+		case stateError:
+			status.SetText(s.Note)
+		default:
+			if s.Activity != "" {
+				status.SetText(s.Activity)
+			}
+		}
+	})
+	unsubscribe := showEvents(bus, queue, states, logBox, nerdLogBox, progress, &logMu)
+	defer unsubscribe()
+	speed := newSpeedGraph()
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			total := queue.totalSpeed()
+			runOnMain(func() { speed.add(total) })
+		}
+	}()
+
+	jobLogHolder := container.NewStack(widget.NewLabel(tr("Select a job to see its log.")))
+	jobNerdHolder := container.NewStack(widget.NewLabel(tr("Select a job to see its raw output.")))
+	jobFile := newFileActions(func(msg string) { appendLog(logBox, msg, &logMu) })
+	lastFile := newFileActions(func(msg string) { appendLog(logBox, msg, &logMu) })
+	jobDetail := container.NewAppTabs(
+		container.NewTabItem(tr("Job Log"), jobLogHolder),
+		container.NewTabItem(tr("Job Nerd Log"), jobNerdHolder),
+	)
+	queueList := widget.NewList(
+		func() int { return len(queue.snapshot()) },
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, newStateBadge(), nil,
+				container.NewVBox(widget.NewLabel(""), widget.NewProgressBar()))
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			jobs := queue.snapshot()
+			if id < 0 || id >= len(jobs) {
+				return
+			}
+			job := jobs[id]
+			row := item.(*fyne.Container)
+			box := row.Objects[0].(*fyne.Container)
+			box.Objects[0].(*widget.Label).SetText(queueRowText(job))
+			box.Objects[1].(*widget.ProgressBar).SetValue(job.Progress)
+			updateStateBadge(row.Objects[1].(*fyne.Container), job.State, prefs.Bool(prefHighContrast))
+		},
+	)
+	var selectedJob int64
+	moveUp := newIconButton(tr("Move Up"), theme.MoveUpIcon(), nil)
+	moveDown := newIconButton(tr("Move Down"), theme.MoveDownIcon(), nil)
+	nextCheck := widget.NewCheck(tr("Download next"), nil)
+	editJob := widget.NewButtonWithIcon(tr("Edit..."), theme.DocumentCreateIcon(), nil)
+	updateQueueActions := func() {
+		var job *downloadJob
+		for _, j := range queue.snapshot() {
+			if j.ID == selectedJob {
+				job = &j
+				break
+			}
+		}
+		if job == nil || job.State != jobQueued {
+			moveUp.Disable()
+			moveDown.Disable()
+			editJob.Disable()
+			nextCheck.SetChecked(false)
+			nextCheck.Disable()
+			return
+		}
+		moveUp.Enable()
+		moveDown.Enable()
+		editJob.Enable()
+		nextCheck.Enable()
+		nextCheck.SetChecked(job.Priority)
+	}
+	moveSelected := func(direction int) {
+		if to := queue.move(selectedJob, direction); to >= 0 {
+			queueList.Select(to)
+		}
+	}
+	moveUp.OnTapped = func() { moveSelected(-1) }
+	moveDown.OnTapped = func() { moveSelected(1) }
+	nextCheck.OnChanged = func(on bool) {
+		queue.setPriority(selectedJob, on)
+	}
+	editJob.OnTapped = func() {
+		for _, job := range queue.snapshot() {
+			if job.ID != selectedJob || job.State != jobQueued {
+				continue
+			}
+			qualities := qualityOptions
+			if isTwitchURL(job.URL) {
+				qualities = twitchQualities
+			}
+			id := job.ID
+			showJobEditor(w, job, qualities, profileOptions, func(settings jobSettings) {
+				if queue.setSettings(id, settings) {
+					appendLog(logBox, trf("Updated settings of job #%d.", id), &logMu)
+				} else {
+					appendLog(logBox, trf("Job #%d already started; settings unchanged.", id), &logMu)
+				}
+			})
+			return
+		}
+	}
+	updateQueueActions()
+	queueList.OnSelected = func(id widget.ListItemID) {
+		jobs := queue.snapshot()
+		if id < 0 || id >= len(jobs) {
+			return
+		}
+		selectedJob = jobs[id].ID
+		updateQueueActions()
+		jobFile.setWithDetails(jobs[id].Output, jobs[id].Summary)
+		jobLogHolder.Objects = []fyne.CanvasObject{jobs[id].Log}
+		jobNerdHolder.Objects = []fyne.CanvasObject{jobs[id].NerdLog}
+		jobLogHolder.Refresh()
+		jobNerdHolder.Refresh()
+	}
+	queue.onChange = func() {
+		jobs := queue.snapshot()
+		var running []downloadJob
+		queued := 0
+		for _, j := range jobs {
+			switch j.State {
+			case jobRunning:
+				running = append(running, j)
+			case jobQueued:
+				queued++
+			}
+		}
+		runOnMain(func() {
+			queueList.Refresh()
+			updateQueueActions()
+			switch {
+			case len(running) == 1 && queued == 0:
+				states.setActivity(running[0].Status)
+				progress.SetValue(running[0].Progress)
+			case len(running) > 0:
+				sum := 0.0
+				for _, j := range running {
+					sum += j.Progress
+				}
+				states.setActivity(trf("%s (%d running, %d queued)", running[len(running)-1].Status, len(running), queued))
+				progress.SetValue(sum / float64(len(running)))
+			}
+		})
+	}
+
+	postQueueSelect := widget.NewSelect(trList(postQueueActions), nil)
+	postQueueSelect.SetSelected(tr(postQueueNothing))
+	queue.onDrained = func(completed int, last *downloadJob) {
+		dupResolver.reset()
+		if finishInBackground.Load() {
+			defer quit()
+		}
+		action := untr(postQueueActions, postQueueSelect.Selected)
+		if completed == 0 || action == postQueueNothing || action == "" {
+			return
+		}
+		folder := downloadDir
+		if last != nil && strings.TrimSpace(last.Settings.Folder) != "" {
+			folder = last.Settings.Folder
+		}
+		appendLog(logBox, trf("All downloads finished (%d completed). Running: %s", completed, tr(action)), &logMu)
+		runPostQueueAction(w, action, folder, func(msg string) {
+			appendLog(logBox, msg, &logMu)
+		})
+	}
+
+	go watchSystemResume(resumeCheckInterval, func(asleep time.Duration) {
+		n := queue.restartRunning()
+		appendNerdLog(nerdLogBox, fmt.Sprintf("[power] system resumed after sleeping %s", asleep.Round(time.Second)), &logMu)
+		if n > 0 {
+			appendLog(logBox, trf("System resumed from sleep. Restarting %d interrupted download(s).", n), &logMu)
+		}
+	})
+
+	currentSettings := func() jobSettings {
+		return jobSettings{
+			Quality:        selectedQuality(),
+			Profile:        untr(profileOptions, profileSelect.Selected),
+			AudioQuality:   selectedOption(prefs, prefAudioQuality, audioQualities, audioQualityBest),
+			Loudness:       selectedOption(prefs, prefLoudness, loudnessOptions, loudnessOff),
+			AudioTrim:      loadAudioTrim(prefs),
+			SplitTracks:    prefs.Bool(prefSplitTracks),
+			MaxFPS:         fpsCap(selectedOption(prefs, prefMaxFPS, fpsOptions, fpsAny)),
+			DynamicRange:   selectedOption(prefs, prefDynamicRange, dynamicRanges, rangePreferSDR),
+			Device:         selectedOption(prefs, prefDeviceProfile, deviceProfiles, deviceAny),
+			Tonemap:        prefs.Bool(prefTonemap),
+			Thumbnail:      thumbnailFormat(prefs),
+			Archive:        loadArchiveOptions(prefs),
+			Folder:         strings.TrimSpace(downloadDir),
+			IncludeChannel: nameWithChannel.Checked,
+			Playlist:       playlistCheck.Checked,
+			StageLocal:     stageCheck.Checked,
+			Subtitles:      subsCheck.Checked,
+			SubtitleLang:   strings.TrimSpace(prefs.String(prefSubtitleLang)),
+			SubtitleMode:   selectedOption(prefs, prefSubtitleMode, subtitleModes, subtitleModeAsk),
+			SubtitleOrder:  selectedOption(prefs, prefSubtitleOrder, subtitleOrders, subtitleOrders[0]),
+			Duplicates:     selectedOption(prefs, prefDuplicatePolicy, duplicatePolicies, duplicateAsk),
+			NameRules:      loadNameRules(prefs),
+			Retry:          loadRetryPolicy(prefs),
+			ExtractorArgs:  loadExtractorArgs(prefs),
+			Binding:        loadNetworkBinding(prefs),
+			SizeLimit:      loadFileSizeLimit(prefs),
+			SortTemplate:   sortTemplate(selectedOption(prefs, prefSortRule, sortRules, sortOff), prefs.String(prefSortTemplate)),
+			SubFormat:      untr(subFormats, subFormatSelect.Selected),
+			KeepSubs:       keepSubsCheck.Checked,
+			PlaylistFilter: loadPlaylistFilter(prefs),
+			TwitchChat:     selectedOption(prefs, prefTwitchChat, twitchChatModes, twitchChatOff),
+		}
+	}
+
+	panes := &downloadPanes{
+		w:        w,
+		prefs:    prefs,
+		bus:      bus,
+		ready:    toolsReady.Load,
+		settings: currentSettings,
+		enqueue: func(urls []string, settings []jobSettings, queued func([]*downloadJob)) {
+			queue.addAll(urls, settings, queued)
+		},
+	}
+	ytdlpFix := &extractorUpdate{
+		w:     w,
+		ytdlp: func() string { return preparedYTDLPPath },
+		retry: func(job downloadJob) {
+			retried := queue.add(job.URL, job.Settings)
+			appendLog(logBox, trf("Retrying job #%d as #%d.", job.ID, retried.ID), &logMu)
+		},
+		logf: func(msg string) { appendLog(logBox, msg, &logMu) },
+	}
+	var batches batchTracker
+	queue.onFinished = func(job downloadJob) {
+		panes.finished(job)
+		if job.State == jobFailed && downloader.ErrorKindOf(job.Err) == downloader.ExtractorBroken {
+			ytdlpFix.failed(job)
+		}
+		if job.State == jobDone && job.Output != "" {
+			runOnMain(func() { lastFile.setWithDetails(job.Output, job.Summary) })
+		}
+		post, done := batches.finished(job.ID)
+		if job.State == jobDone {
+			for _, step := range post {
+				if err := runBatchPostStep(step, job.Settings.Folder); err != nil {
+					appendLog(logBox, trf("#%d post step %q failed: %v", job.ID, step, err), &logMu)
+				}
+			}
+		}
+		if done == nil {
+			return
+		}
+		summary, lines := batchReport(done, queue.snapshot())
+		appendLog(logBox, summary, &logMu)
+		for _, l := range lines {
+			appendLog(logBox, l, &logMu)
+		}
+		runOnMain(func() {
+			dialog.ShowInformation(tr("Batch finished"), summary, w)
+		})
+	}
+	runBatch := func(path string) {
+		bf, err := loadBatchFile(path)
+		if err != nil {
+			appendLog(logBox, trf("Batch not started: %v", err), &logMu)
+			runOnMain(func() { dialog.ShowError(err, w) })
+			return
+		}
+		base := currentSettings()
+		urls := make([]string, len(bf.Jobs))
+		settings := make([]jobSettings, len(bf.Jobs))
+		for i := range bf.Jobs {
+			urls[i] = bf.Jobs[i].URL
+			settings[i] = bf.settingsFor(i, base)
+		}
+		run := &batchRun{name: filepath.Base(path), post: make(map[int64][]string)}
+		queue.addAll(urls, settings, func(jobs []*downloadJob) {
+			for i, j := range jobs {
+				run.ids = append(run.ids, j.ID)
+				run.post[j.ID] = bf.postFor(i)
+			}
+			batches.add(run)
+		})
+		appendLog(logBox, trf("Batch %s: queued %d job(s).", run.name, len(run.ids)), &logMu)
+	}
+	runBatchFromDialog := func() {
+		if !states.ready() {
+			return
+		}
+		open := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+			if err != nil || rc == nil {
+				return
+			}
+			path := rc.URI().Path()
+			rc.Close()
+			runBatch(path)
+		}, w)
+		open.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+		open.Show()
+	}
+	exportQueue := func() {
+		jobs := exportableJobs(queue.snapshot())
+		if len(jobs) == 0 {
+			dialog.ShowInformation(tr("Export Queue"), tr("The queue has nothing to export."), w)
+			return
+		}
+		save := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+			if err != nil || wc == nil {
+				return
+			}
+			defer wc.Close()
+			if err := writeQueueFile(wc, wc.URI().Name(), jobs); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			dialog.ShowInformation(tr("Queue exported"), trf("%d download(s) saved to %s.", len(jobs), wc.URI().Path()), w)
+		}, w)
+		save.SetFileName("ytgui-queue.json")
+		save.Show()
+	}
+	exportHistory := func() {
+		entries := history.list()
+		if len(entries) == 0 {
+			dialog.ShowInformation(tr("Export History"), tr("The history is empty."), w)
+			return
+		}
+		save := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+			if err != nil || wc == nil {
+				return
+			}
+			defer wc.Close()
+			if err := writeHistoryFile(wc, wc.URI().Name(), entries); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			dialog.ShowInformation(tr("History exported"), trf("%d download(s) saved to %s.", len(entries), wc.URI().Path()), w)
+		}, w)
+		save.SetFileName("ytgui-history.csv")
+		save.Show()
+	}
+	importQueue := func() {
+		if !states.ready() {
+			return
+		}
+		open := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+			if err != nil || rc == nil {
+				return
+			}
+			defer rc.Close()
+			imported, err := readQueueFile(rc, rc.URI().Name(), currentSettings())
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			urls := make([]string, len(imported))
+			settings := make([]jobSettings, len(imported))
+			for i, sj := range imported {
+				urls[i] = sj.URL
+				settings[i] = sj.Settings
+			}
+			queue.addAll(urls, settings, func(jobs []*downloadJob) {
+				for i, job := range jobs {
+					if imported[i].Priority {
+						queue.setPriority(job.ID, true)
+					}
+				}
+			})
+			appendLog(logBox, trf("Imported %d download(s) from %s.", len(imported), rc.URI().Name()), &logMu)
+		}, w)
+		open.SetFilter(storage.NewExtensionFileFilter(queueFileFormats))
+		open.Show()
+	}
+	// importDownloaded adds videos downloaded with another tool to the
+	// history, so they count as downloaded before.
+	importDownloaded := func(entries []historyEntry, source string) {
+		added, err := history.importVideos(entries)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		summary := trf("Imported %d video(s) from %s; %d were already in the history.", added, source, len(entries)-added)
+		appendLog(logBox, summary, &logMu)
+		dialog.ShowInformation(tr("Import finished"), summary, w)
+	}
+	importArchive := func() {
+		open := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+			if err != nil || rc == nil {
+				return
+			}
+			defer rc.Close()
+			entries, err := readYTDLPArchive(rc)
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			importDownloaded(entries, rc.URI().Name())
+		}, w)
+		open.SetFilter(storage.NewExtensionFileFilter([]string{".txt"}))
+		open.Show()
+	}
+	importFolder := func() {
+		dialog.ShowFolderOpen(func(lu fyne.ListableURI, err error) {
+			if err != nil || lu == nil {
+				return
+			}
+			dir := lu.Path()
+			go func() {
+				entries, err := scanDownloadedFolder(dir)
+				runOnMain(func() {
+					if err != nil {
+						dialog.ShowError(err, w)
+						return
+					}
+					if len(entries) == 0 {
+						dialog.ShowInformation(tr("Import finished"), trf("No files named with a YouTube video ID, like \"Title [dQw4w9WgXcQ].mp4\", were found in %s.", dir), w)
+						return
+					}
+					importDownloaded(entries, dir)
+				})
+			}()
+		}, w)
+	}
+	// rememberChannel saves the selected preset and the download folder as
+	// the defaults for the channel of the link in the URL field.
+	rememberChannel := func() {
+		if !states.ready() {
+			return
+		}
+		link, ok := parseLaunchArg(url.Text)
+		if !ok {
+			states.show(tr("Enter a video URL first."))
+			return
+		}
+		preset, folder := presets.choice.Selected, downloadDir
+		states.show(tr("Looking up the channel..."))
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), probeTimeout(prefs))
+			defer cancel()
+			meta, err := downloader.GetVideoMetadata(ctx, preparedYTDLPPath, link)
+			runOnMain(func() {
+				if err != nil {
+					states.show(tr("Could not look up the channel"))
+					dialog.ShowError(err, w)
+					return
+				}
+				key := meta.ChannelKey()
+				if key == "" {
+					states.show(tr("No channel found"))
+					dialog.ShowInformation(tr("Channel Defaults"), tr("The site doesn't say which channel this video is from."), w)
+					return
+				}
+				d := channelDefault{Key: key, Name: valueOr(meta.Uploader, key), Preset: preset, Folder: folder}
+				putChannelDefault(prefs, d)
+				states.show(tr("Channel defaults saved"))
+				appendLog(logBox, trf("Remembered for channel %s", channelDefaultText(d)), &logMu)
+			})
+		}()
+	}
+	downloadThumbnail := func() {
+		if !states.ready() {
+			return
+		}
+		link := strings.TrimSpace(url.Text)
+		if link == "" {
+			states.show(tr("Enter a video URL first."))
+			return
+		}
+		settings := currentSettings()
+		format := selectedOption(prefs, prefThumbnailFormat, downloader.ThumbnailFormats, downloader.ThumbnailFormats[0])
+		go func() {
+			path, err := downloadThumbnailOnly(probes.start, preparedYTDLPPath, preparedFFmpegPath, link, settings.Folder, format, settings.IncludeChannel, settings.NameRules, appEvents)
+			if err != nil {
+				appEvents.log(trf("Thumbnail download failed: %v", err))
+				appEvents.SetText(tr("Thumbnail download failed"))
+				return
+			}
+			appEvents.log(trf("Thumbnail saved: %s", path))
+			appEvents.SetText(tr("Thumbnail saved"))
+			runOnMain(func() { lastFile.set(path) })
+		}()
+	}
+
+	preserveDownload := func() {
+		if !states.ready() {
+			return
+		}
+		downloadURL, _ := normalizeVideoURL(url.Text)
+		if downloadURL == "" {
+			states.show(tr("Missing URL"))
+			return
+		}
+		job := queue.add(downloadURL, preserveSettings(currentSettings()))
+		appendLog(logBox, trf("Queued job #%d to preserve completely: %s", job.ID, downloadURL), &logMu)
+		url.SetText("")
+	}
+
+	downloadClip := func() {
+		if !states.ready() {
+			return
+		}
+		downloadURL, start := normalizeVideoURL(url.Text)
+		if downloadURL == "" {
+			states.show(tr("Missing URL"))
+			return
+		}
+		askClipRange(w, prefs, start, func(start, end int, export clipExport) {
+			settings := currentSettings()
+			settings.Playlist = false
+			settings.ClipStart, settings.ClipEnd, settings.ClipExport = start, end, export
+			if export.Format != "" && settings.Quality == "Audio Only" {
+				settings.Quality = "Best"
+			}
+			job := queue.add(downloadURL, settings)
+			appendLog(logBox, trf("Queued job #%d to download the clip %s: %s", job.ID, clipLabel(start, end), downloadURL), &logMu)
+			url.SetText("")
+		})
+	}
+
+	var launchMu sync.Mutex
+	pendingLaunch := append([]string(nil), startURLs...)
+	enqueueLaunchURL := func(u string) {
+		launchMu.Lock()
+		if !toolsReady.Load() {
+			pendingLaunch = append(pendingLaunch, u)
+			launchMu.Unlock()
+			appendLog(logBox, trf("Received link, will queue once tools are ready: %s", u), &logMu)
+			return
+		}
+		launchMu.Unlock()
+		u, _ = normalizeVideoURL(u)
+		job := queue.add(u, currentSettings())
+		appendLog(logBox, trf("Queued job #%d from external link: %s", job.ID, u), &logMu)
+		runOnMain(func() { w.RequestFocus() })
+	}
+	flushLaunchURLs := func() {
+		launchMu.Lock()
+		urls := pendingLaunch
+		pendingLaunch = nil
+		launchMu.Unlock()
+		for _, u := range urls {
+			enqueueLaunchURL(u)
+		}
+	}
+	showWindow := func() {
 		runOnMain(func() {
-			cancelDownloadBtn.Disable()
+			finishInBackground.Store(false)
+			w.Show()
+			w.RequestFocus()
 		})
 	}
+	if stop, err := listenForLaunches(enqueueLaunchURL, showWindow); err != nil {
+		appendNerdLog(nerdLogBox, fmt.Sprintf("[launch] could not listen for forwarded links: %v", err), &logMu)
+	} else {
+		defer stop()
+	}
+	if err := registerURLScheme(); err != nil {
+		appendNerdLog(nerdLogBox, fmt.Sprintf("[launch] could not register %s:// links: %v", urlScheme, err), &logMu)
+	}
 
-	cancelDownloadBtn = widget.NewButton("Cancel Download", func() {
-		cancelMu.Lock()
-		cancel := activeCancel
-		label := activeCancelLabel
-		cancelMu.Unlock()
-		if cancel == nil {
+	var snapshots snapshotServer
+	startSnapshots := func() {
+		snapshots.stop()
+		if !prefs.Bool(prefSnapshotEnabled) {
+			return
+		}
+		addr := strings.TrimSpace(prefs.StringWithFallback(prefSnapshotAddr, defaultSnapshotAddr))
+		if err := snapshots.start(addr, queue.snapshot); err != nil {
+			appendLog(logBox, trf("Could not start status snapshot endpoint on %s: %v", addr, err), &logMu)
 			return
 		}
+		appendLog(logBox, trf("Status snapshot available at http://%s/snapshot.json", addr), &logMu)
+	}
+	startSnapshots()
+	defer snapshots.stop()
 
-		msg := "Cancel current internet download?\n\nPartial/intermediate files will be deleted where possible."
-		if strings.TrimSpace(label) != "" {
-			msg = fmt.Sprintf("Cancel current internet download (%s)?\n\nPartial/intermediate files will be deleted where possible.", label)
+	api := newControlAPI(queue, func(req enqueueRequest) (int64, error) {
+		if !toolsReady.Load() {
+			return 0, errors.New("required tools are still being prepared")
 		}
-		confirmText := "Cancel Download"
-		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(label)), "downloading ") {
-			confirmText = "Quit Application"
+		settings := currentSettings()
+		if req.Preset != nil {
+			p, ok := findPreset(prefs, *req.Preset)
+			if !ok {
+				return 0, fmt.Errorf("unknown preset %q", *req.Preset)
+			}
+			p.applyTo(&settings)
 		}
-		d := dialog.NewCustomConfirm(
-			"Cancel Download",
-			confirmText,
-			"Back",
-			widget.NewLabel(msg),
-			func(ok bool) {
-				if !ok {
-					return
-				}
-				appendLog(logBox, "Cancel requested by user.", &logMu)
-				cancel()
-			},
-			w,
-		)
-		d.Resize(fyne.NewSize(520, 220))
-		d.Show()
+		req.apply(&settings)
+		job := queue.add(req.URL, settings)
+		appendLog(logBox, trf("Queued job #%d via control API: %s", job.ID, req.URL), &logMu)
+		return job.ID, nil
+	}, func() []string {
+		var names []string
+		for _, p := range loadPresets(prefs) {
+			names = append(names, p.Name)
+		}
+		return names
 	})
-	cancelDownloadBtn.Disable()
+	startControlAPI := func() {
+		api.stop()
+		if !prefs.Bool(prefControlAPIEnabled) {
+			return
+		}
+		token := prefs.String(prefControlAPIToken)
+		if token == "" {
+			var err error
+			if token, err = newAPIToken(); err != nil {
+				appendLog(logBox, trf("Could not create a control API token: %v", err), &logMu)
+				return
+			}
+			prefs.SetString(prefControlAPIToken, token)
+		}
+		addr := strings.TrimSpace(prefs.StringWithFallback(prefControlAPIAddr, defaultControlAPIAddr))
+		if err := api.start(addr, token); err != nil {
+			appendLog(logBox, trf("Could not start control API on %s: %v", addr, err), &logMu)
+			return
+		}
+		appendLog(logBox, trf("Control API listening on http://%s/api/jobs", addr), &logMu)
+	}
+	startControlAPI()
+	defer api.stop()
 
-	var chooseFolder *widget.Button
-	chooseFolder = widget.NewButton(folderButtonText(downloadDir), func() {
-		dialog.ShowFolderOpen(func(lu fyne.ListableURI, err error) {
-			if err != nil || lu == nil {
+	var watcher folderWatcher
+	startWatcher := func() {
+		watcher.stop()
+		dir := strings.TrimSpace(prefs.String(prefWatchPath))
+		if !prefs.Bool(prefWatchEnabled) || dir == "" {
+			return
+		}
+		watcher.start(dir, toolsReady.Load, func(file string, urls []string) {
+			if len(urls) == 0 {
+				appendLog(logBox, trf("Watched folder: no links in %s.", file), &logMu)
 				return
 			}
-			downloadDir = lu.Path()
-			prefs.SetString(prefDownloadDir, downloadDir)
-			runOnMain(func() {
-				chooseFolder.SetText(folderButtonText(downloadDir))
-			})
-			appendLog(logBox, "Download folder: "+downloadDir, &logMu)
-		}, w)
-	})
-	openFolder := widget.NewButton("Open Folder", func() {
-		target := strings.TrimSpace(downloadDir)
-		if target == "" {
-			appendLog(logBox, "No download folder selected.", &logMu)
-			runOnMain(func() { status.SetText("No download folder selected") })
+			settings := currentSettings()
+			if p, ok := findPreset(prefs, prefs.String(prefWatchPreset)); ok {
+				p.applyTo(&settings)
+			}
+			all := make([]jobSettings, len(urls))
+			for i := range all {
+				all[i] = settings
+			}
+			queue.addAll(urls, all, nil)
+			appendLog(logBox, trf("Watched folder: queued %d link(s) from %s.", len(urls), file), &logMu)
+		}, func(err error) {
+			appendLog(logBox, trf("Watched folder: %v", err), &logMu)
+		})
+		appendLog(logBox, trf("Watching %s for link files.", dir), &logMu)
+	}
+	startWatcher()
+	defer watcher.stop()
+
+	feeds := &feedPoller{
+		prefs: prefs,
+		ready: toolsReady.Load,
+		enqueue: func(feed feedSubscription, entries []downloader.FeedEntry) {
+			settings := currentSettings()
+			if p, ok := findPreset(prefs, prefs.String(prefFeedPreset)); ok {
+				p.applyTo(&settings)
+			}
+			urls := make([]string, len(entries))
+			all := make([]jobSettings, len(entries))
+			for i, e := range entries {
+				urls[i] = e.URL
+				all[i] = settings
+			}
+			queue.addAll(urls, all, nil)
+			appendLog(logBox, trf("Feed %s: queued %d new entry(s).", feed.Title, len(entries)), &logMu)
+		},
+		logf: func(s string) { appendLog(logBox, s, &logMu) },
+	}
+	feeds.start()
+	defer feeds.stop()
+	var store queueStore
+	queueChanged := queue.onChange
+	queue.onChange = func() {
+		queueChanged()
+		api.markDirty()
+		store.markDirty()
+	}
+	restoreQueue := func() {
+		defer func() {
+			store.markDirty()
+			go store.run(queue)
+		}()
+		stored, err := loadStoredQueue()
+		if err != nil {
+			appendLog(logBox, trf("Could not read the saved queue: %v", err), &logMu)
 			return
 		}
-		info, err := os.Stat(target)
-		if err != nil || !info.IsDir() {
-			appendLog(logBox, "Download folder does not exist: "+target, &logMu)
-			runOnMain(func() { status.SetText("Download folder missing") })
+		if len(stored) == 0 {
+			return
+		}
+		if !askResumeQueue(w, len(stored)) {
+			discardStoredQueue()
+			appendLog(logBox, trf("Discarded %d unfinished download(s) from the last session.", len(stored)), &logMu)
 			return
 		}
+		urls := make([]string, len(stored))
+		settings := make([]jobSettings, len(stored))
+		for i, sj := range stored {
+			urls[i] = sj.URL
+			settings[i] = sj.Settings
+		}
+		queue.addAll(urls, settings, func(jobs []*downloadJob) {
+			for i, job := range jobs {
+				restarts := stored[i].Restarts
+				if stored[i].Running && restarts == 0 {
+					restarts = 1
+				}
+				if restarts > 0 {
+					queue.update(job.ID, func(j *downloadJob) { j.Restarts = restarts })
+				}
+				if stored[i].Priority {
+					queue.setPriority(job.ID, true)
+				}
+			}
+		})
+		appendLog(logBox, trf("Resumed %d unfinished download(s) from the last session.", len(stored)), &logMu)
+	}
 
-		var cmd *exec.Cmd
-		switch runtime.GOOS {
-		case "windows":
-			cmd = exec.Command("explorer", target)
-		case "darwin":
-			cmd = exec.Command("open", target)
-		default:
-			cmd = exec.Command("xdg-open", target)
+	integrationsPage := func() fyne.CanvasObject {
+		addrEntry := widget.NewEntry()
+		addrEntry.SetText(prefs.StringWithFallback(prefSnapshotAddr, defaultSnapshotAddr))
+		addrEntry.OnSubmitted = func(v string) {
+			prefs.SetString(prefSnapshotAddr, strings.TrimSpace(v))
+			startSnapshots()
+		}
+		enabled := widget.NewCheck(tr("Serve read-only queue snapshot (JSON)"), nil)
+		enabled.SetChecked(prefs.Bool(prefSnapshotEnabled))
+		enabled.OnChanged = func(on bool) {
+			prefs.SetBool(prefSnapshotEnabled, on)
+			prefs.SetString(prefSnapshotAddr, strings.TrimSpace(addrEntry.Text))
+			startSnapshots()
+		}
+		pushLink := widget.NewLabel("")
+		pushLink.Wrapping = fyne.TextWrapBreak
+		copyLink := widget.NewButton(tr("Copy Link"), func() {
+			addr := strings.TrimSpace(prefs.StringWithFallback(prefControlAPIAddr, defaultControlAPIAddr))
+			w.Clipboard().SetContent(pushPageURL(addr, prefs.String(prefControlAPIToken)))
+		})
+		updatePush := func() {
+			addr := strings.TrimSpace(prefs.StringWithFallback(prefControlAPIAddr, defaultControlAPIAddr))
+			switch {
+			case !prefs.Bool(prefControlAPIEnabled):
+				pushLink.SetText(tr("Enable the control API to send links from your phone."))
+				copyLink.Disable()
+			case !pushReachable(addr):
+				pushLink.SetText(tr("Listen on 0.0.0.0:8766 so your phone can reach the send page."))
+				copyLink.Disable()
+			default:
+				pushLink.SetText(trf("Open this on your phone to send links: %s", pushPageURL(addr, prefs.String(prefControlAPIToken))))
+				copyLink.Enable()
+			}
 		}
-		if err := cmd.Start(); err != nil {
-			appendLog(logBox, fmt.Sprintf("Failed to open folder: %v", err), &logMu)
-			runOnMain(func() { status.SetText("Failed to open folder") })
+		apiAddr := widget.NewEntry()
+		apiAddr.SetText(prefs.StringWithFallback(prefControlAPIAddr, defaultControlAPIAddr))
+		apiAddr.OnSubmitted = func(v string) {
+			prefs.SetString(prefControlAPIAddr, strings.TrimSpace(v))
+			startControlAPI()
+			updatePush()
 		}
+		tokenEntry := widget.NewEntry()
+		tokenEntry.SetText(prefs.String(prefControlAPIToken))
+		tokenEntry.Disable()
+		regenerate := widget.NewButton(tr("New Token"), func() {
+			token, err := newAPIToken()
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			prefs.SetString(prefControlAPIToken, token)
+			tokenEntry.SetText(token)
+			startControlAPI()
+			updatePush()
+		})
+		apiEnabled := widget.NewCheck(tr("Enable control API (enqueue, status, cancel, events)"), nil)
+		apiEnabled.SetChecked(prefs.Bool(prefControlAPIEnabled))
+		apiEnabled.OnChanged = func(on bool) {
+			prefs.SetBool(prefControlAPIEnabled, on)
+			prefs.SetString(prefControlAPIAddr, strings.TrimSpace(apiAddr.Text))
+			startControlAPI()
+			tokenEntry.SetText(prefs.String(prefControlAPIToken))
+			updatePush()
+		}
+		updatePush()
+		return container.NewVBox(
+			enabled,
+			widget.NewForm(widget.NewFormItem(tr("Listen address"), addrEntry)),
+			widget.NewLabel(tr("Dashboards can poll /snapshot.json. Use 0.0.0.0:8765 to allow other devices on your network.")),
+			widget.NewSeparator(),
+			apiEnabled,
+			widget.NewForm(
+				widget.NewFormItem(tr("Listen address"), apiAddr),
+				widget.NewFormItem(tr("Token"), container.NewBorder(nil, nil, nil, regenerate, tokenEntry)),
+			),
+			widget.NewLabel(tr("Send the token as \"Authorization: Bearer <token>\". Events stream over WebSocket at /api/events; browsers offer the subprotocols \"ytgui\" and \"ytgui-token.<token>\" instead.")),
+			container.NewBorder(nil, nil, nil, copyLink, pushLink),
+			widget.NewSeparator(),
+			watchFolderSection(w, prefs, startWatcher),
+		)
+	}
+	generalPage := func() fyne.CanvasObject {
+		names := make([]string, len(languages))
+		current := 0
+		for i, l := range languages {
+			names[i] = tr(l.name)
+			if l.code == prefs.String(prefLanguage) {
+				current = i
+			}
+		}
+		note := widget.NewLabel("")
+		langSelect := widget.NewSelect(names, nil)
+		langSelect.SetSelected(names[current])
+		langSelect.OnChanged = func(name string) {
+			for i, n := range names {
+				if n == name {
+					prefs.SetString(prefLanguage, languages[i].code)
+				}
+			}
+			note.SetText(tr("Restart ytgui to apply the new language."))
+		}
+		subLang := widget.NewEntry()
+		subLang.SetPlaceHolder(tr("e.g. de or pt-BR (empty: original/English)"))
+		subLang.SetText(prefs.String(prefSubtitleLang))
+		subLang.OnChanged = func(v string) {
+			prefs.SetString(prefSubtitleLang, strings.TrimSpace(v))
+		}
+		subMode := widget.NewSelect(trList(subtitleModes), func(shown string) {
+			prefs.SetString(prefSubtitleMode, untr(subtitleModes, shown))
+		})
+		subMode.SetSelected(tr(selectedOption(prefs, prefSubtitleMode, subtitleModes, subtitleModeAsk)))
+		subOrder := widget.NewSelect(trList(subtitleOrders), func(shown string) {
+			prefs.SetString(prefSubtitleOrder, untr(subtitleOrders, shown))
+		})
+		subOrder.SetSelected(tr(selectedOption(prefs, prefSubtitleOrder, subtitleOrders, subtitleOrders[0])))
+		dupPolicy := widget.NewSelect(trList(duplicatePolicies), func(shown string) {
+			prefs.SetString(prefDuplicatePolicy, untr(duplicatePolicies, shown))
+		})
+		dupPolicy.SetSelected(tr(selectedOption(prefs, prefDuplicatePolicy, duplicatePolicies, duplicateAsk)))
+		templateCollisionSelect := widget.NewSelect(trList(templateCollisionModes), func(shown string) {
+			prefs.SetString(prefTemplateCollisions, untr(templateCollisionModes, shown))
+		})
+		templateCollisionSelect.SetSelected(tr(selectedOption(prefs, prefTemplateCollisions, templateCollisionModes, templateCollisionsPolicy)))
+		logLevel := widget.NewSelect(trList(logLevels), func(shown string) {
+			level := untr(logLevels, shown)
+			prefs.SetString(prefLogVerbosity, level)
+			setLogVerbosity(level)
+		})
+		logLevel.SetSelected(tr(logVerbosity()))
+		thumbFormat := widget.NewSelect(downloader.ThumbnailFormats, func(format string) {
+			prefs.SetString(prefThumbnailFormat, format)
+		})
+		thumbFormat.SetSelected(selectedOption(prefs, prefThumbnailFormat, downloader.ThumbnailFormats, downloader.ThumbnailFormats[0]))
+		saveThumb := widget.NewCheck(tr("Save the thumbnail next to every video"), func(on bool) {
+			prefs.SetBool(prefSaveThumbnail, on)
+		})
+		saveThumb.SetChecked(prefs.Bool(prefSaveThumbnail))
+		return container.NewVBox(
+			widget.NewForm(
+				widget.NewFormItem(tr("Language"), langSelect),
+				widget.NewFormItem(tr("Subtitle language"), subLang),
+				widget.NewFormItem(tr("Always prefer"), subOrder),
+				widget.NewFormItem(tr("Subtitle prompts"), subMode),
+				widget.NewFormItem(tr("If the file exists"), dupPolicy),
+				widget.NewFormItem(tr("If the name isn't known in advance"), templateCollisionSelect),
+				widget.NewFormItem(tr("Log detail"), logLevel),
+				widget.NewFormItem(tr("Thumbnail format"), thumbFormat),
+			),
+			saveThumb,
+			note,
+			widget.NewLabel(tr("Subtitles in the preferred language are picked automatically, using YouTube's auto-translation when no real track exists.")),
+			widget.NewSeparator(),
+			configTransfer(w, prefs, note),
+		)
+	}
+	diag := &diagnosticsEnv{
+		app:    a,
+		window: w,
+		tools: func() (string, string) {
+			if !toolsReady.Load() {
+				return "", ""
+			}
+			return preparedYTDLPPath, preparedFFmpegPath
+		},
+		folder: func() string { return downloadDir },
+		logs: func() map[string]string {
+			logs := map[string]string{
+				"normal.log": logBox.Text,
+				"nerd.log":   nerdLogBox.Text,
+			}
+			for _, job := range queue.snapshot() {
+				logs[fmt.Sprintf("job-%d.log", job.ID)] = job.Log.Text + "\n--- raw output ---\n" + job.NerdLog.Text
+			}
+			return logs
+		},
+	}
+	storage := &storageEnv{
+		window: w,
+		prefs:  prefs,
+		busy: func() string {
+			if !toolsReady.Load() {
+				return tr("Tools are being prepared. Try again when ytgui is idle.")
+			}
+			if queue.runningCount() > 0 {
+				return tr("Wait for running downloads to finish first.")
+			}
+			return ""
+		},
+		holdTools: func(held bool) {
+			launchMu.Lock()
+			toolsReady.Store(!held)
+			launchMu.Unlock()
+			states.setSetup(held)
+		},
+		needRestart: func(note string) {
+			launchMu.Lock()
+			toolsReady.Store(false)
+			launchMu.Unlock()
+			// Setup stays on, so the note shows as a setup error and
+			// downloads stay unavailable.
+			states.setupFailed(note)
+		},
+	}
+	// Updating yt-dlp after an extractor failure holds the queue the same
+	// way re-downloading the tools does.
+	ytdlpFix.hold = storage.holdTools
+	settingsBtn := widget.NewButton(tr("Settings"), func() {
+		showSettings(w, []settingsPage{
+			{title: "General", content: generalPage},
+			{title: "Appearance", content: appearancePage(a)},
+			{title: "Filenames", content: filenamesPage(prefs)},
+			{title: "Network", content: networkPage(prefs)},
+			{title: "Archiving", content: archivingPage(prefs)},
+			{title: "Feeds", content: feedsPage(w, prefs, feeds)},
+			{title: "Channels", content: channelsPage(prefs)},
+			{title: "Integrations", content: integrationsPage},
+			{title: "Storage", content: storagePage(storage)},
+			{title: "Diagnostics", content: diagnosticsPage(diag)},
+		})
 	})
 
-	var toolsReady atomic.Bool
-	var preparedYTDLPPath string
-	var preparedFFmpegPath string
-	var btn *widget.Button
-	btn = widget.NewButton("Download", func() {
-		if !toolsReady.Load() {
-			status.SetText("Preparing required tools...")
+	w.SetMainMenu(fyne.NewMainMenu(
+		fyne.NewMenu(tr("File"),
+			fyne.NewMenuItem(tr("New Download Tab"), panes.open),
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem(tr("Run Batch..."), runBatchFromDialog),
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem(tr("Import Queue..."), importQueue),
+			fyne.NewMenuItem(tr("Export Queue..."), exportQueue),
+			fyne.NewMenuItem(tr("Export History..."), exportHistory),
+			fyne.NewMenuItem(tr("Import yt-dlp Archive..."), importArchive),
+			fyne.NewMenuItem(tr("Import Downloaded Folder..."), importFolder),
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem(tr("Remember for This Channel"), rememberChannel),
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem(tr("Download Thumbnail Only"), downloadThumbnail),
+			fyne.NewMenuItem(tr("Download Clip..."), downloadClip),
+		),
+		fyne.NewMenu(tr("Help"),
+			fyne.NewMenuItem(tr("Diagnostics..."), func() { showDiagnostics(diag) }),
+		),
+	))
+
+	btn = widget.NewButton(tr("Download"), func() {
+		if !states.ready() {
+			return
+		}
+		downloadURL, start := normalizeVideoURL(url.Text)
+		if listPrompt.pending() {
+			states.show(tr("Choose whether to download the video or the playlist first."))
 			return
 		}
-		btn.Disable()
-		downloadURL := strings.TrimSpace(url.Text)
-		selectedQuality := qualitySelect.Selected
-		selectedProfile := profileSelect.Selected
-		selectedFolder := strings.TrimSpace(downloadDir)
-		selectedNameWithChannel := nameWithChannel.Checked
-		selectedPlaylist := playlistCheck.Checked
-		checkSubs := subsCheck.Checked
+		settings := currentSettings()
 
 		if downloadURL == "" {
-			status.SetText("Missing URL")
-			btn.Enable()
+			states.show(tr("Missing URL"))
 			return
 		}
-		if selectedFolder != "" &&
-			defaultDir != "" &&
-			strings.EqualFold(filepath.Clean(selectedFolder), filepath.Clean(defaultDir)) {
-			if err := os.MkdirAll(selectedFolder, 0o755); err != nil {
-				status.SetText("Cannot create default download folder")
-				appendLog(logBox, fmt.Sprintf("Failed to create default folder %s: %v", selectedFolder, err), &logMu)
-				btn.Enable()
+		if settings.Folder != "" && sameFolder(settings.Folder, defaultDir) {
+			if err := os.MkdirAll(settings.Folder, 0o755); err != nil {
+				states.show(tr("Cannot create default download folder"))
+				appendLog(logBox, trf("Failed to create default folder %s: %v", settings.Folder, err), &logMu)
 				return
 			}
 		}
 
-		go func() {
-			defer runOnMain(func() { btn.Enable() })
-			ytdlpPath := preparedYTDLPPath
-			ffmpegPath := preparedFFmpegPath
-			if strings.TrimSpace(ytdlpPath) == "" || strings.TrimSpace(ffmpegPath) == "" {
-				appendLog(logBox, "Tools are not ready yet. Please wait.", &logMu)
-				runOnMain(func() { status.SetText("Preparing required tools...") })
-				return
-			}
-			appendNerdLog(nerdLogBox, "Tool path: "+ytdlpPath, &logMu)
-			appendNerdLog(nerdLogBox, "Tool path: "+ffmpegPath, &logMu)
-
-			var selectedSub *downloader.SubOption
-			if checkSubs && !selectedPlaylist {
-				runOnMain(func() { status.SetText("Checking subtitles...") })
-				appendLog(logBox, "Fetching subtitle list...", &logMu)
-
-				appendNerdLog(nerdLogBox, "> "+formatCommandLine(ytdlpPath, []string{"--print", "%(subtitles)j", "--print", "%(automatic_captions)j", "--print", "%(language)s", "--encoding", "utf-8", "--no-warnings", "--skip-download", "--no-playlist", downloadURL}), &logMu)
-				opts, err := downloader.GetAvailableSubtitles(ytdlpPath, downloadURL)
-				if err != nil {
-					appendLog(logBox, fmt.Sprintf("Could not list subtitles: %v. Proceeding without.", err), &logMu)
-				} else {
-					for _, line := range subtitleAvailabilitySummary(opts) {
-						appendLog(logBox, line, &logMu)
-					}
-
-					categoryOpts := subtitleCategoryOptions(opts)
-					if len(categoryOpts) == 0 {
-						appendLog(logBox, "No preferred subtitle category available.", &logMu)
-						if !askDownloadWithoutSubs(w) {
-							appendLog(logBox, "Download canceled by user (no subtitles available). Quitting application.", &logMu)
-							runOnMain(func() {
-								status.SetText("Quitting application...")
-								a.Quit()
-							})
-							return
-						}
-						appendLog(logBox, "Proceeding without subtitles.", &logMu)
-						selectedSub = nil
-					}
-
-					autoSelected, promptOptions := planSubtitleSelection(categoryOpts)
-					switch {
-					case autoSelected != nil:
-						selectedSub = autoSelected
-						appendLog(logBox, "Auto-selected subtitles: "+selectedSub.Label, &logMu)
-					case len(promptOptions) > 0:
-						appendLog(logBox, "Multiple subtitle languages found. Please choose one.", &logMu)
-						selectedSub = askSubtitleChoice(w, categoryOpts)
-					default:
-						selectedSub = nil
-					}
+		enqueue := func(settings jobSettings) {
+			job := queue.add(downloadURL, settings)
+			appendLog(logBox, trf("Queued job #%d: %s", job.ID, downloadURL), &logMu)
+			url.SetText("")
+		}
+		if start > 0 && !settings.Playlist {
+			askClipFromTimestamp(w, start, func(clip bool) {
+				if clip {
+					settings.ClipStart = start
 				}
-			}
-
-			runOnMain(func() {
-				status.SetText("Starting download...")
-				progress.SetValue(0)
+				enqueue(settings)
 			})
-			appendLog(logBox, "Starting download...", &logMu)
-
-			runYTDLP(downloadURL, selectedFolder, selectedQuality, selectedProfile, ytdlpPath, ffmpegPath, selectedNameWithChannel, selectedPlaylist, selectedSub, w, logBox, nerdLogBox, status, progress, &logMu, setCancelable, clearCancelable)
-		}()
+			return
+		}
+		enqueue(settings)
 	})
-	btn.Disable()
+	states.setSetup(true)
 	go func() {
-		runOnMain(func() {
-			status.SetText("Checking required tools...")
-		})
-		appendLog(logBox, "Required tools check...", &logMu)
+		appEvents.tool(tr("Checking required tools..."))
+		appEvents.log(tr("Required tools check..."))
 		for _, tool := range []string{"yt-dlp.exe", "ffmpeg.exe"} {
 			if path, err := downloader.BinaryPath(tool); err == nil {
-				appendNerdLog(nerdLogBox, "[setup] check exists "+path, &logMu)
+				appEvents.raw("[setup] check exists " + path)
 			} else {
-				appendNerdLog(nerdLogBox, fmt.Sprintf("[setup] resolve path for %s failed: %v", tool, err), &logMu)
+				appEvents.raw(fmt.Sprintf("[setup] resolve path for %s failed: %v", tool, err))
 			}
 		}
+		removed, err := downloader.VerifyInstalledBinaries(context.Background())
+		for _, tool := range removed {
+			appEvents.log(trf("%s failed its checksum check and will be installed again.", tool))
+		}
+		if err != nil {
+			appEvents.log(trf("Could not verify the installed tools: %v", err))
+		}
 		missing, err := checkMissingTools()
 		if err != nil {
-			appendLog(logBox, fmt.Sprintf("Failed to check required tools: %v", err), &logMu)
-			runOnMain(func() { status.SetText("Tool check failed") })
+			appEvents.log(trf("Failed to check required tools: %v", err))
+			appEvents.tool(tr("Tool check failed"))
+			states.setupFailed(tr("Tool check failed"))
 			return
 		}
 		if len(missing) == 0 {
-			appendNerdLog(nerdLogBox, "[setup] all required tools present", &logMu)
+			appEvents.raw("[setup] all required tools present")
 		} else {
-			appendNerdLog(nerdLogBox, "[setup] missing tools: "+strings.Join(missing, ", "), &logMu)
+			appEvents.raw("[setup] missing tools: " + strings.Join(missing, ", "))
 		}
-		appendLog(logBox, "Required tools check done.", &logMu)
+		appEvents.log(tr("Required tools check done."))
 		freshYTDLPDownloaded := false
 		if len(missing) > 0 {
-			appendLog(logBox, "Missing required tools: "+strings.Join(missing, ", "), &logMu)
+			appEvents.log(trf("Missing required tools: %s", strings.Join(missing, ", ")))
 			if !askDownloadRequiredTools(w, missing) {
-				appendLog(logBox, "Setup canceled by user. Quitting application.", &logMu)
-				runOnMain(func() {
-					status.SetText("Quitting application...")
-					a.Quit()
-				})
+				appEvents.log(tr("Setup canceled by user. Quitting application."))
+				appEvents.tool(tr("Quitting application..."))
+				runOnMain(a.Quit)
 				return
 			}
-			runOnMain(func() { status.SetText("Downloading required tools...") })
-			toolData := func(tool string) []byte {
+			appEvents.tool(tr("Downloading required tools..."))
+			toolData := func(tool string) ([]byte, string) {
 				switch tool {
 				case "yt-dlp.exe":
-					return assets.YTDLP
+					return assets.YTDLP, assets.YTDLPSHA256
 				case "ffmpeg.exe":
-					return assets.FFmpeg
+					return assets.FFmpeg, assets.FFmpegSHA256
 				default:
-					return nil
+					return nil, ""
 				}
 			}
 			downloadSlots := make(map[string]int)
 			for _, tool := range missing {
-				if len(toolData(tool)) == 0 {
+				if data, _ := toolData(tool); len(data) == 0 {
 					downloadSlots[tool] = len(downloadSlots)
 				}
 			}
 			totalDownloads := len(downloadSlots)
 			for i, tool := range missing {
-				appendLog(logBox, "Downloading "+tool+"...", &logMu)
-				appendNerdLog(nerdLogBox, "[setup] ensure "+tool, &logMu)
-				data := toolData(tool)
+				appEvents.log(trf("Downloading %s...", tool))
+				appEvents.raw("[setup] ensure " + tool)
+				data, dataSHA256 := toolData(tool)
 				if tool == "yt-dlp.exe" {
 					freshYTDLPDownloaded = true
 				}
@@ -1322,10 +3697,8 @@ func RunApp(assets Assets) {
 							if stats.TotalBytes > 0 {
 								size = formatBytes(stats.TotalBytes)
 							}
-							appendNerdLog(nerdLogBox, fmt.Sprintf("[setup] download start %s <- %s (size: %s)", stats.Tool, stats.URL, size), &logMu)
-							runOnMain(func() {
-								status.SetText("Downloading " + stats.Tool + "...")
-							})
+							appEvents.raw(fmt.Sprintf("[setup] download start %s <- %s (size: %s)", stats.Tool, stats.URL, size))
+							appEvents.tool(trf("Downloading %s...", stats.Tool))
 						case "downloading":
 							if totalDownloads <= 0 || stats.TotalBytes <= 0 {
 								return
@@ -1339,26 +3712,24 @@ func RunApp(assets Assets) {
 							}
 							v := (float64(slot) + part) / float64(totalDownloads)
 							fileName := downloadDisplayName(stats)
-							runOnMain(func() {
-								progress.SetValue(v)
-								status.SetText(fmt.Sprintf("Downloading %s... %s / %s", fileName, formatBytes(stats.DownloadedBytes), formatBytes(stats.TotalBytes)))
-							})
+							appEvents.SetValue(v)
+							appEvents.tool(trf("Downloading %s... %s / %s", fileName, formatBytes(stats.DownloadedBytes), formatBytes(stats.TotalBytes)))
 						case "done":
-							appendNerdLog(nerdLogBox, fmt.Sprintf("[setup] download done %s (%s)", stats.Tool, formatBytes(stats.DownloadedBytes)), &logMu)
+							appEvents.raw(fmt.Sprintf("[setup] download done %s (%s)", stats.Tool, formatBytes(stats.DownloadedBytes)))
 							if totalDownloads > 0 {
 								v := float64(slot+1) / float64(totalDownloads)
-								runOnMain(func() { progress.SetValue(v) })
+								appEvents.SetValue(v)
 							}
 						case "extract_start":
-							appendNerdLog(nerdLogBox, fmt.Sprintf("[setup] extract start %s from archive", stats.Tool), &logMu)
-							runOnMain(func() { status.SetText("Extracting " + stats.Tool + "...") })
+							appEvents.raw(fmt.Sprintf("[setup] extract start %s from archive", stats.Tool))
+							appEvents.tool(trf("Extracting %s...", stats.Tool))
 						case "extract_done":
-							appendNerdLog(nerdLogBox, fmt.Sprintf("[setup] extract done %s", stats.Tool), &logMu)
+							appEvents.raw(fmt.Sprintf("[setup] extract done %s", stats.Tool))
 						case "retry":
-							appendNerdLog(nerdLogBox, fmt.Sprintf("[setup] retrying download %s from %s", stats.Tool, stats.URL), &logMu)
-							runOnMain(func() { status.SetText("Retrying " + stats.Tool + " download...") })
+							appEvents.raw(fmt.Sprintf("[setup] retrying download %s from %s", stats.Tool, stats.URL))
+							appEvents.tool(trf("Retrying %s download...", stats.Tool))
 						case "canceled":
-							appendNerdLog(nerdLogBox, fmt.Sprintf("[setup] canceled %s download; partial file removed", stats.Tool), &logMu)
+							appEvents.raw(fmt.Sprintf("[setup] canceled %s download; partial file removed", stats.Tool))
 						}
 					}
 				}
@@ -1369,7 +3740,7 @@ func RunApp(assets Assets) {
 					toolCtx, toolCancel = context.WithCancel(context.Background())
 					toolOpID = setCancelable("downloading "+tool, toolCancel)
 				}
-				if _, err := downloader.EnsureBinaryWithProgressCtx(toolCtx, tool, data, progressCb); err != nil {
+				if _, err := downloader.EnsureBinaryWithProgressCtx(toolCtx, tool, data, dataSHA256, progressCb); err != nil {
 					if tracked {
 						clearCancelable(toolOpID)
 					}
@@ -1378,19 +3749,18 @@ func RunApp(assets Assets) {
 					}
 					if errors.Is(err, context.Canceled) {
 						if removed := downloader.CleanupDownloadTemps(); removed > 0 {
-							appendNerdLog(nerdLogBox, fmt.Sprintf("[setup] cleaned up %d temp download file(s)", removed), &logMu)
-							appendLog(logBox, fmt.Sprintf("Removed %d temporary download file(s).", removed), &logMu)
+							appEvents.raw(fmt.Sprintf("[setup] cleaned up %d temp download file(s)", removed))
+							appEvents.log(trf("Removed %d temporary download file(s).", removed))
 						}
-						appendLog(logBox, fmt.Sprintf("Canceled %s download by user.", tool), &logMu)
-						runOnMain(func() {
-							status.SetText("Download canceled")
-							progress.SetValue(0)
-							a.Quit()
-						})
+						appEvents.log(trf("Canceled %s download by user.", tool))
+						appEvents.tool(tr("Download canceled"))
+						appEvents.SetValue(0)
+						runOnMain(a.Quit)
 						return
 					}
-					appendLog(logBox, fmt.Sprintf("Failed to prepare %s: %v", tool, err), &logMu)
-					runOnMain(func() { status.SetText("Setup failed") })
+					appEvents.log(trf("Failed to prepare %s: %v", tool, err))
+					appEvents.tool(tr("Setup failed"))
+					states.setupFailed(tr("Setup failed"))
 					return
 				}
 				if tracked {
@@ -1399,65 +3769,74 @@ func RunApp(assets Assets) {
 				if toolCancel != nil {
 					toolCancel()
 				}
-				appendLog(logBox, tool+" is ready.", &logMu)
-				appendNerdLog(nerdLogBox, "[setup] "+tool+" ready", &logMu)
+				appEvents.log(tool + " is ready.")
+				appEvents.raw("[setup] " + tool + " ready")
 				if !tracked {
-					appendNerdLog(nerdLogBox, "[setup] "+tool+" prepared from embedded data (no network download)", &logMu)
+					appEvents.raw("[setup] " + tool + " prepared from embedded data (no network download)")
 				}
 				if tracked && i == len(missing)-1 {
-					runOnMain(func() { status.SetText("All required downloads complete.") })
+					appEvents.tool(tr("All required downloads complete."))
 				}
 			}
 		}
 		ytdlpPath, err := downloader.BinaryPath("yt-dlp.exe")
 		if err != nil {
-			appendLog(logBox, fmt.Sprintf("Failed to resolve yt-dlp path: %v", err), &logMu)
-			runOnMain(func() { status.SetText("Setup failed") })
+			appEvents.log(trf("Failed to resolve yt-dlp path: %v", err))
+			appEvents.tool(tr("Setup failed"))
+			states.setupFailed(tr("Setup failed"))
 			return
 		}
 		ffmpegPath, err := downloader.BinaryPath("ffmpeg.exe")
 		if err != nil {
-			appendLog(logBox, fmt.Sprintf("Failed to resolve ffmpeg path: %v", err), &logMu)
-			runOnMain(func() { status.SetText("Setup failed") })
+			appEvents.log(trf("Failed to resolve ffmpeg path: %v", err))
+			appEvents.tool(tr("Setup failed"))
+			states.setupFailed(tr("Setup failed"))
 			return
 		}
+		appEvents.tool(tr("Checking tools..."))
+		if names, problems := brokenTools(); len(names) > 0 {
+			for _, p := range problems {
+				appEvents.log(trf("Tool check failed: %s", p))
+			}
+			if askRepairTools(w, problems) {
+				repairTools(names, appEvents)
+			} else {
+				appEvents.log(tr("Tools not repaired; downloads may fail. Use Settings > Storage > Re-download Tools later."))
+			}
+		}
 		preparedYTDLPPath = ytdlpPath
 		preparedFFmpegPath = ffmpegPath
-		appendNerdLog(nerdLogBox, "Prepared tool path: "+preparedYTDLPPath, &logMu)
-		appendNerdLog(nerdLogBox, "Prepared tool path: "+preparedFFmpegPath, &logMu)
+		if ok, ffprobePath, _ := downloader.BinaryExists("ffprobe.exe"); ok {
+			preparedFFprobePath = ffprobePath
+			appEvents.raw("Prepared tool path: " + preparedFFprobePath)
+		} else {
+			appEvents.log(tr("ffprobe not found; downloads won't be verified. It is installed with the next ffmpeg download."))
+		}
+		appEvents.raw("Prepared tool path: " + preparedYTDLPPath)
+		appEvents.raw("Prepared tool path: " + preparedFFmpegPath)
 		if freshYTDLPDownloaded {
-			appendLog(logBox, "yt-dlp update check skipped (fresh install).", &logMu)
-			appendLog(logBox, "yt-dlp update check done.", &logMu)
+			appEvents.log(tr("yt-dlp update check skipped (fresh install)."))
+			appEvents.log(tr("yt-dlp update check done."))
 		} else {
-			appendLog(logBox, "yt-dlp update check...", &logMu)
-			runOnMain(func() {
-				status.SetText("Checking yt-dlp updates...")
-			})
-			appendNerdLog(nerdLogBox, "> "+formatCommandLine(preparedYTDLPPath, []string{"--version"}), &logMu)
-			appendNerdLog(nerdLogBox, "> GET https://api.github.com/repos/yt-dlp/yt-dlp/releases/latest", &logMu)
+			appEvents.log(tr("yt-dlp update check..."))
+			appEvents.tool(tr("Checking yt-dlp updates..."))
+			appEvents.raw("> " + formatCommandLine(preparedYTDLPPath, []string{"--version"}))
+			appEvents.raw("> GET https://api.github.com/repos/yt-dlp/yt-dlp/releases/latest")
 			updateCtx, updateCancel := context.WithCancel(context.Background())
 			updateOpID := setCancelable("updating yt-dlp", updateCancel)
 			updateErr := downloader.TryUpdateYTDLPWithProgressCtx(updateCtx, preparedYTDLPPath, func(msg string) {
-				appendLog(logBox, msg, &logMu)
-				appendNerdLog(nerdLogBox, "[yt-dlp-update] "+msg, &logMu)
+				appEvents.log(msg)
+				appEvents.raw("[yt-dlp-update] " + msg)
 				lower := strings.ToLower(msg)
 				switch {
 				case strings.Contains(lower, "updating yt-dlp"):
-					runOnMain(func() {
-						status.SetText("Updating yt-dlp...")
-					})
+					appEvents.tool(tr("Updating yt-dlp..."))
 				case strings.Contains(lower, "update complete"):
-					runOnMain(func() {
-						status.SetText("yt-dlp update complete")
-					})
+					appEvents.tool(tr("yt-dlp update complete"))
 				case strings.Contains(lower, "up to date"):
-					runOnMain(func() {
-						status.SetText("yt-dlp is up to date")
-					})
+					appEvents.tool(tr("yt-dlp is up to date"))
 				case strings.Contains(lower, "could not check latest yt-dlp version"):
-					runOnMain(func() {
-						status.SetText("Could not check yt-dlp updates")
-					})
+					appEvents.tool(tr("Could not check yt-dlp updates"))
 				}
 			}, func(stats downloader.DownloadStats) {
 				switch stats.Phase {
@@ -1466,7 +3845,7 @@ func RunApp(assets Assets) {
 					if stats.TotalBytes > 0 {
 						size = formatBytes(stats.TotalBytes)
 					}
-					appendNerdLog(nerdLogBox, fmt.Sprintf("[yt-dlp-update] download start %s (size: %s)", stats.URL, size), &logMu)
+					appEvents.raw(fmt.Sprintf("[yt-dlp-update] download start %s (size: %s)", stats.URL, size))
 				case "downloading":
 					if stats.TotalBytes <= 0 {
 						return
@@ -1478,67 +3857,320 @@ func RunApp(assets Assets) {
 					if part > 1 {
 						part = 1
 					}
-					runOnMain(func() {
-						progress.SetValue(part)
-						status.SetText(fmt.Sprintf("Updating yt-dlp... %s / %s", formatBytes(stats.DownloadedBytes), formatBytes(stats.TotalBytes)))
-					})
+					appEvents.SetValue(part)
+					appEvents.tool(trf("Updating yt-dlp... %s / %s", formatBytes(stats.DownloadedBytes), formatBytes(stats.TotalBytes)))
 				case "done":
-					appendNerdLog(nerdLogBox, fmt.Sprintf("[yt-dlp-update] download done (%s)", formatBytes(stats.DownloadedBytes)), &logMu)
-					runOnMain(func() { progress.SetValue(1.0) })
+					appEvents.raw(fmt.Sprintf("[yt-dlp-update] download done (%s)", formatBytes(stats.DownloadedBytes)))
+					appEvents.SetValue(1.0)
 				}
 			})
 			clearCancelable(updateOpID)
 			updateCancel()
 			if errors.Is(updateErr, context.Canceled) {
-				appendLog(logBox, "yt-dlp update canceled by user.", &logMu)
-				runOnMain(func() {
-					status.SetText("yt-dlp update canceled")
-					progress.SetValue(0)
-				})
+				appEvents.log(tr("yt-dlp update canceled by user."))
+				appEvents.tool(tr("yt-dlp update canceled"))
+				appEvents.SetValue(0)
 			}
-			appendLog(logBox, "yt-dlp update check done.", &logMu)
+			appEvents.log(tr("yt-dlp update check done."))
 		}
+		launchMu.Lock()
 		toolsReady.Store(true)
-		runOnMain(func() {
-			status.SetText("Idle")
-			progress.SetValue(0)
-			btn.Enable()
-		})
+		launchMu.Unlock()
+		appEvents.SetValue(0)
+		states.setSetup(false)
+		restoreQueue()
+		flushLaunchURLs()
+		for _, path := range startBatches {
+			runBatch(path)
+		}
 	}()
 
-	clear := widget.NewButton("Clear", func() {
+	clear := widget.NewButton(tr("Clear"), func() {
 		logBox.SetText("")
 	})
-	clearNerd := widget.NewButton("Clear Nerd", func() {
+	clearNerd := widget.NewButton(tr("Clear Nerd"), func() {
 		nerdLogBox.SetText("")
 	})
+	clearQueue := widget.NewButton(tr("Clear Finished"), func() {
+		queue.clearFinished()
+	})
+	highContrastCheck := widget.NewCheck(tr("High contrast status"), func(on bool) {
+		prefs.SetBool(prefHighContrast, on)
+		queueList.Refresh()
+	})
+	highContrastCheck.SetChecked(prefs.Bool(prefHighContrast))
+
+	searchPanel := newSearchPanel(
+		func() string {
+			if !toolsReady.Load() {
+				return ""
+			}
+			return preparedYTDLPPath
+		},
+		func(picked string) {
+			url.SetText(picked)
+		},
+		func(picked string) {
+			if !states.ready() {
+				return
+			}
+			job := queue.add(picked, currentSettings())
+			appendLog(logBox, trf("Queued job #%d from search: %s", job.ID, picked), &logMu)
+		},
+	)
+
+	console := newNerdConsole(func() (string, string) {
+		if !toolsReady.Load() {
+			return "", ""
+		}
+		return preparedYTDLPPath, preparedFFmpegPath
+	}, appEvents)
+
+	converter := newConvertPanel(w, prefs, func() (string, string) {
+		if !toolsReady.Load() {
+			return "", ""
+		}
+		return preparedFFmpegPath, preparedFFprobePath
+	}, func(msg string) {
+		appendLog(logBox, msg, &logMu)
+	})
+	convertTab := container.NewTabItem(tr("Convert"), converter.box)
+	library := newLibraryPanel(w, prefs, func() []string {
+		return []string{downloadDir}
+	}, &history, func(msg string) {
+		appendLog(logBox, msg, &logMu)
+	}, func(e historyEntry) {
+		redownload(e.URL, e.jobSettings())
+	})
+	libraryTab := container.NewTabItem(tr("Library"), library.box)
 
 	logTabs := container.NewAppTabs(
-		container.NewTabItem("Normal Logs", logBox),
-		container.NewTabItem("Nerd Terminal", nerdLogBox),
+		container.NewTabItem(tr("Normal Logs"), logBox),
+		container.NewTabItem(tr("Nerd Terminal"), container.NewBorder(nil, console.box, nil, nil, newNerdTerminal(nerdLogBox, w.Clipboard()).box)),
+		container.NewTabItem(tr("Queue"), container.NewBorder(
+			nil,
+			container.NewBorder(nil, nil, widget.NewLabel(tr("When all downloads finish:")), container.NewHBox(moveUp, moveDown, editJob, nextCheck, highContrastCheck, clearQueue), postQueueSelect),
+			nil,
+			nil,
+			container.NewVSplit(queueList, container.NewBorder(jobFile.box, nil, nil, nil, jobDetail)),
+		)),
+		container.NewTabItem(tr("Search"), searchPanel),
+		container.NewTabItem(tr("History"), newHistoryPanel(w, &history, func(msg string) {
+			appendLog(logBox, msg, &logMu)
+		}, func(e historyEntry) {
+			redownload(e.URL, e.jobSettings())
+		})),
+		libraryTab,
+		convertTab,
+	)
+	w.SetOnDropped(func(_ fyne.Position, uris []fyne.URI) {
+		for _, u := range uris {
+			if u.Scheme() == "file" {
+				converter.setFile(u.Path())
+				logTabs.Select(convertTab)
+				return
+			}
+		}
+	})
+	if i := prefs.Int(prefLogTab); i > 0 && i < len(logTabs.Items) {
+		logTabs.SelectIndex(i)
+		if logTabs.Selected() == libraryTab {
+			library.refresh()
+		}
+	}
+	logTabs.OnSelected = func(tab *container.TabItem) {
+		prefs.SetInt(prefLogTab, logTabs.SelectedIndex())
+		if tab == libraryTab {
+			library.refresh()
+		}
+	}
+
+	pasteURL := func() {
+		if text := strings.TrimSpace(w.Clipboard().Content()); text != "" {
+			url.SetText(text)
+		}
+	}
+	pasteBtn := widget.NewButtonWithIcon(tr("Paste"), theme.ContentPasteIcon(), pasteURL)
+	startDownload := func() {
+		if !btn.Disabled() {
+			btn.OnTapped()
+		}
+	}
+	cancelCurrent := func() {
+		if !cancelDownloadBtn.Disabled() {
+			cancelDownloadBtn.OnTapped()
+		}
+	}
+
+	shortcuts := newShortcutSet(w.Canvas())
+	shortcuts.add(fyne.KeyL, func() { w.Canvas().Focus(url) })
+	shortcuts.add(fyne.KeyO, func() { openFolder.OnTapped() })
+	shortcuts.add(fyne.KeyT, panes.open)
+	// Ctrl+V outside a text field pastes into the URL entry; inside one the
+	// focused field handles it as usual.
+	w.Canvas().AddShortcut(&fyne.ShortcutPaste{}, func(fyne.Shortcut) { pasteURL() })
+	w.Canvas().SetOnTypedKey(func(ev *fyne.KeyEvent) {
+		switch ev.Name {
+		case fyne.KeyReturn, fyne.KeyEnter:
+			startDownload()
+		case fyne.KeyEscape:
+			cancelCurrent()
+		}
+	})
+	url.onShortcut = shortcuts.handle
+	url.onEscape = cancelCurrent
+	url.OnSubmitted = func(string) { startDownload() }
+	listPrompt = newPlaylistPrompt(
+		func(playlist bool) { playlistCheck.SetChecked(playlist) },
+		// Like the metadata prefetch below, the count waits until typing
+		// pauses, and a newer link cancels it.
+		func(link string, done func(n int)) func() {
+			ytdlp := preparedYTDLPPath
+			if ytdlp == "" {
+				return nil
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			timer := time.AfterFunc(metadataPrefetchDelay, func() {
+				defer cancel()
+				tctx, stop := context.WithTimeout(ctx, probeTimeout(prefs))
+				defer stop()
+				n, err := downloader.PlaylistCount(tctx, ytdlp, link)
+				if err != nil {
+					if ctx.Err() == nil {
+						appendNerdLog(nerdLogBox, fmt.Sprintf("[playlist] could not count items: %v", err), &logMu)
+					}
+					return
+				}
+				runOnMain(func() { done(n) })
+			})
+			return func() {
+				timer.Stop()
+				cancel()
+			}
+		},
 	)
+	// A pasted single-video link starts the metadata fetch right away, so
+	// it's usually done by the time Download is pressed. The delay keeps
+	// typing from starting a fetch per keystroke.
+	var prefetchTimer *time.Timer
+	// musicRow suggests Audio Only, or a music preset, for YouTube Music
+	// links.
+	musicLabel := widget.NewLabel("")
+	var musicRow *fyne.Container
+	useMusic := widget.NewButton(tr("Use Audio Only"), func() {
+		if name := musicPresetName(loadPresets(prefs)); name == "" || !presets.use(name) {
+			qualitySelect.SetSelected(tr("Audio Only"))
+		}
+		musicRow.Hide()
+	})
+	musicRow = container.NewHBox(musicLabel, useMusic)
+	musicRow.Hide()
+	updateMusicHint := func(link string) {
+		if !isYouTubeMusicURL(link) || selectedQuality() == "Audio Only" {
+			musicRow.Hide()
+			return
+		}
+		if name := musicPresetName(loadPresets(prefs)); name != "" {
+			musicLabel.SetText(trf("This is a YouTube Music link. Use the %q preset?", name))
+		} else {
+			musicLabel.SetText(tr("This is a YouTube Music link. Download the audio only?"))
+		}
+		musicRow.Show()
+	}
+	applyChannelDefault := func(d channelDefault) {
+		if d.Preset != "" && !presets.use(d.Preset) {
+			appendLog(logBox, trf("Channel %s: the preset %q no longer exists.", d.Name, d.Preset), &logMu)
+		}
+		if d.Folder != "" && !sameFolder(d.Folder, downloadDir) {
+			useFolder(d.Folder)
+		}
+		states.show(trf("Using the defaults for channel %s", d.Name))
+	}
+	url.OnChanged = func(text string) {
+		setTwitchMode(isTwitchURL(text))
+		listPrompt.update(text, playlistCheck.Checked)
+		updateMusicHint(text)
+		if prefetchTimer != nil {
+			prefetchTimer.Stop()
+		}
+		link, ok := parseLaunchArg(text)
+		if hasList, _ := linkPlaylistKind(link); !ok || hasList || playlistCheck.Checked || !toolsReady.Load() {
+			return
+		}
+		prefetchTimer = time.AfterFunc(metadataPrefetchDelay, func() {
+			// The metadata is only waited for when the channel or title can
+			// change something before the download.
+			if len(loadChannelDefaults(prefs)) == 0 && selectedQuality() != "Audio Only" {
+				downloader.PrefetchMetadata(preparedYTDLPPath, link, probeTimeout(prefs))
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), probeTimeout(prefs))
+			defer cancel()
+			meta, err := downloader.GetVideoMetadata(ctx, preparedYTDLPPath, link)
+			if err != nil {
+				return
+			}
+			d, known := findChannelDefault(prefs, meta.ChannelKey())
+			runOnMain(func() {
+				// The link may have been replaced while it was looked up.
+				if current, _ := parseLaunchArg(url.Text); current != link {
+					return
+				}
+				if known {
+					applyChannelDefault(d)
+				}
+				if selectedQuality() == "Audio Only" && looksVisual(meta.Title) {
+					states.show(trf("%q looks like a video worth watching, but Audio Only is selected.", meta.Title))
+				}
+			})
+		})
+	}
 
 	controls := container.NewVBox(
-		widget.NewLabel("Portable yt-dlp Downloader"),
-		url,
-		container.NewBorder(nil, nil, nil, openFolder, chooseFolder),
-		qualitySelect,
-		profileSelect,
+		widget.NewLabel(tr("Portable yt-dlp Downloader")),
+		container.NewBorder(nil, nil, nil, pasteBtn, url),
+		listPrompt.box,
+		musicRow,
+		container.NewBorder(nil, nil, nil, container.NewHBox(folderMenuBtn, openFolder), chooseFolder),
+		stageCheck,
+		presets.box,
+		container.NewHBox(widget.NewLabel(tr("Max resolution:")), qualitySelect, widget.NewLabel(tr("Max FPS:")), fpsSelect),
+		container.NewHBox(widget.NewLabel(tr("HDR:")), rangeSelect, tonemapCheck),
+		audioRow,
+		twitchRow,
+		container.NewHBox(profileSelect, widget.NewLabel(tr("Playing on:")), deviceSelect),
+		compat.box,
+		fileSizeRow(prefs),
 		nameWithChannel,
-		subsCheck,
+		container.NewHBox(subsCheck, widget.NewLabel(tr("Format:")), subFormatSelect, keepSubsCheck),
 		playlistCheck,
-		container.NewHBox(btn, cancelDownloadBtn, clear, clearNerd),
-		status,
-		progress,
+		listOptions,
+		container.NewHBox(btn, widget.NewButton(tr("Preserve"), preserveDownload), cancelDownloadBtn, clear, clearNerd, settingsBtn),
+		container.NewBorder(nil, nil, nil, stopWaiting, status.Label),
+		container.NewBorder(nil, nil, nil, speed.box, progress),
+		lastFile.box,
 	)
 
-	w.SetContent(container.NewBorder(
+	panes.main = container.NewBorder(
 		controls,
 		nil,
 		nil,
 		nil,
 		logTabs,
-	))
+	)
+	panes.show = func(tabs *container.AppTabs) {
+		if tabs == nil {
+			w.SetContent(panes.main)
+			return
+		}
+		w.SetContent(tabs)
+	}
+	w.SetContent(panes.main)
+	// Tab moves through the controls top to bottom; start in the URL field.
+	w.Canvas().Focus(url)
 
 	w.ShowAndRun()
+	if cp, ok := a.Preferences().(*configPrefs); ok {
+		cp.flush()
+	}
 }
@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	prefThemeVariant = "theme_variant"
+	prefUIScale      = "ui_scale"
+	prefCompact      = "compact_layout"
+
+	themeSystem = "System"
+	themeLight  = "Light"
+	themeDark   = "Dark"
+)
+
+var (
+	themeChoices = []string{themeSystem, themeLight, themeDark}
+	uiScales     = []float64{0.9, 1, 1.25, 1.5, 1.75, 2}
+)
+
+// appTheme wraps the default theme to force a light/dark variant, scale all
+// sizes and tighten padding for the compact layout.
+type appTheme struct {
+	variant string
+	scale   float32
+	compact bool
+}
+
+func (t *appTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	switch t.variant {
+	case themeLight:
+		variant = theme.VariantLight
+	case themeDark:
+		variant = theme.VariantDark
+	}
+	return theme.DefaultTheme().Color(name, variant)
+}
+
+func (t *appTheme) Font(style fyne.TextStyle) fyne.Resource {
+	return theme.DefaultTheme().Font(style)
+}
+
+func (t *appTheme) Icon(name fyne.ThemeIconName) fyne.Resource {
+	return theme.DefaultTheme().Icon(name)
+}
+
+func (t *appTheme) Size(name fyne.ThemeSizeName) float32 {
+	size := theme.DefaultTheme().Size(name)
+	if t.compact && (name == theme.SizeNamePadding || name == theme.SizeNameInnerPadding || name == theme.SizeNameLineSpacing) {
+		size /= 2
+	}
+	return size * t.scale
+}
+
+func appearanceTheme(prefs fyne.Preferences) *appTheme {
+	scale := prefs.FloatWithFallback(prefUIScale, 1)
+	if scale <= 0 {
+		scale = 1
+	}
+	return &appTheme{
+		variant: prefs.StringWithFallback(prefThemeVariant, themeSystem),
+		scale:   float32(scale),
+		compact: prefs.Bool(prefCompact),
+	}
+}
+
+func applyAppearance(a fyne.App) {
+	a.Settings().SetTheme(appearanceTheme(a.Preferences()))
+}
+
+func scaleLabel(s float64) string {
+	return fmt.Sprintf("%.0f%%", s*100)
+}
+
+func appearancePage(a fyne.App) func() fyne.CanvasObject {
+	prefs := a.Preferences()
+	return func() fyne.CanvasObject {
+		themeSelect := widget.NewSelect(trList(themeChoices), func(shown string) {
+			prefs.SetString(prefThemeVariant, untr(themeChoices, shown))
+			applyAppearance(a)
+		})
+		themeSelect.SetSelected(tr(prefs.StringWithFallback(prefThemeVariant, themeSystem)))
+
+		labels := make([]string, len(uiScales))
+		for i, s := range uiScales {
+			labels[i] = scaleLabel(s)
+		}
+		scaleSelect := widget.NewSelect(labels, func(shown string) {
+			for i, l := range labels {
+				if l == shown {
+					prefs.SetFloat(prefUIScale, uiScales[i])
+				}
+			}
+			applyAppearance(a)
+		})
+		scaleSelect.SetSelected(scaleLabel(prefs.FloatWithFallback(prefUIScale, 1)))
+
+		compact := widget.NewCheck(tr("Compact layout"), func(on bool) {
+			prefs.SetBool(prefCompact, on)
+			applyAppearance(a)
+		})
+		compact.SetChecked(prefs.Bool(prefCompact))
+
+		buttonLabels := widget.NewCheck(tr("Show text on icon buttons (after restart)"), func(on bool) {
+			prefs.SetBool(prefIconLabels, on)
+		})
+		buttonLabels.SetChecked(prefs.Bool(prefIconLabels))
+		speak := widget.NewCheck(tr("Read status changes aloud"), func(on bool) {
+			prefs.SetBool(prefSpeakStatus, on)
+		})
+		speak.SetChecked(prefs.Bool(prefSpeakStatus))
+
+		return container.NewVBox(
+			widget.NewForm(
+				widget.NewFormItem(tr("Theme"), themeSelect),
+				widget.NewFormItem(tr("UI scale"), scaleSelect),
+			),
+			compact,
+			buttonLabels,
+			speak,
+		)
+	}
+}
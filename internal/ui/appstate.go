@@ -0,0 +1,220 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// appState is what the main window is busy with. It is worked out from tool
+// setup and the phase of every running job, and decides which controls are
+// enabled, what the status label shows between jobs and whether closing
+// the window warns first.
+type appState int
+
+const (
+	stateSetup appState = iota
+	stateIdle
+	stateProbing
+	statePrompting
+	stateDownloading
+	statePostprocessing
+	stateError
+)
+
+var appStateNames = []string{"Setup", "Idle", "Probing", "Prompting", "Downloading", "Postprocessing", "Error"}
+
+func (s appState) String() string {
+	if int(s) < len(appStateNames) {
+		return appStateNames[s]
+	}
+	return "Unknown"
+}
+
+func parseAppState(name string) (appState, bool) {
+	for i, n := range appStateNames {
+		if n == name {
+			return appState(i), true
+		}
+	}
+	return stateIdle, false
+}
+
+// busy reports whether a job is running, so there is something to cancel
+// and closing would interrupt it.
+func (s appState) busy() bool {
+	return s >= stateProbing && s <= statePostprocessing
+}
+
+// phaseRank orders job phases when several jobs run: a job waiting for an
+// answer matters most, then one moving data.
+var phaseRank = map[appState]int{
+	stateProbing:        1,
+	statePostprocessing: 2,
+	stateDownloading:    3,
+	statePrompting:      4,
+}
+
+// appStatus is what the controls are updated from.
+type appStatus struct {
+	State appState
+	// Note is the summary of the last finished job or the last message
+	// passed to show, shown while idle or after a failure.
+	Note string
+	// Activity is what tool setup or the running jobs are doing, shown in
+	// those states.
+	Activity string
+	// Cancelable is set while a setup step, such as downloading the tools,
+	// can be canceled.
+	Cancelable bool
+}
+
+type appStateMachine struct {
+	// apply updates the controls. It runs on the main thread whenever the
+	// status changes.
+	apply func(appStatus)
+
+	// applyMu keeps applies in order, each with the latest status.
+	applyMu sync.Mutex
+
+	mu         sync.Mutex
+	setup      bool
+	cancelable bool
+	phases     map[int64]appState
+	note       string
+	activity   string
+	failed     bool
+	last       appStatus
+	applied    bool
+}
+
+func newAppStateMachine(apply func(appStatus)) *appStateMachine {
+	return &appStateMachine{apply: apply, setup: true, phases: make(map[int64]appState)}
+}
+
+// state returns the current state.
+func (m *appStateMachine) state() appState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.statusLocked().State
+}
+
+// setSetup marks tool setup (or a tool re-download) as running or over.
+func (m *appStateMachine) setSetup(on bool) {
+	m.mu.Lock()
+	m.setup = on
+	m.changedLocked()
+}
+
+// setupFailed records that tool setup stopped with an error; downloads stay
+// unavailable.
+func (m *appStateMachine) setupFailed(note string) {
+	m.mu.Lock()
+	m.note, m.failed = note, true
+	m.changedLocked()
+}
+
+// ready reports whether tool setup finished, so downloads can start. It is
+// false in stateSetup and when setup ended in stateError.
+func (m *appStateMachine) ready() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return !m.setup
+}
+
+// show puts a message in the status label. Outside setup it replaces the
+// note, clearing a failure; while jobs run it lasts until their next
+// progress update.
+func (m *appStateMachine) show(text string) {
+	m.mu.Lock()
+	if !m.setup {
+		m.note, m.failed = text, false
+	}
+	m.activity = text
+	m.changedLocked()
+}
+
+// setActivity records what tool setup or the running jobs are doing.
+func (m *appStateMachine) setActivity(text string) {
+	m.mu.Lock()
+	m.activity = text
+	m.changedLocked()
+}
+
+// setCancelable marks whether a setup step can be canceled.
+func (m *appStateMachine) setCancelable(on bool) {
+	m.mu.Lock()
+	m.cancelable = on
+	m.changedLocked()
+}
+
+// jobStarted records a job that began running. The last outcome no longer
+// applies.
+func (m *appStateMachine) jobStarted(id int64) {
+	m.mu.Lock()
+	m.phases[id] = stateProbing
+	m.note, m.activity, m.failed = "", "", false
+	m.changedLocked()
+}
+
+// jobPhase records the phase a running job is in.
+func (m *appStateMachine) jobPhase(id int64, phase appState) {
+	m.mu.Lock()
+	if _, running := m.phases[id]; !running || phaseRank[phase] == 0 {
+		m.mu.Unlock()
+		return
+	}
+	m.phases[id] = phase
+	m.changedLocked()
+}
+
+// jobDone records how a job ended.
+func (m *appStateMachine) jobDone(id int64, err error) {
+	m.mu.Lock()
+	delete(m.phases, id)
+	m.note = jobSummaryLine(id, err)
+	m.failed = err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, errResumeRestart)
+	m.changedLocked()
+}
+
+func (m *appStateMachine) statusLocked() appStatus {
+	s := appStatus{State: stateIdle, Note: m.note, Activity: m.activity, Cancelable: m.cancelable}
+	switch {
+	case m.setup && m.failed:
+		s.State = stateError
+	case m.setup:
+		s.State = stateSetup
+	case len(m.phases) > 0:
+		s.State = stateProbing
+		for _, p := range m.phases {
+			if phaseRank[p] > phaseRank[s.State] {
+				s.State = p
+			}
+		}
+	case m.failed:
+		s.State = stateError
+	}
+	return s
+}
+
+// changedLocked releases m.mu and applies the status if it changed.
+func (m *appStateMachine) changedLocked() {
+	s := m.statusLocked()
+	if m.applied && s == m.last {
+		m.mu.Unlock()
+		return
+	}
+	m.last, m.applied = s, true
+	m.mu.Unlock()
+	if m.apply == nil {
+		return
+	}
+	runOnMain(func() {
+		m.applyMu.Lock()
+		defer m.applyMu.Unlock()
+		m.mu.Lock()
+		latest := m.last
+		m.mu.Unlock()
+		m.apply(latest)
+	})
+}
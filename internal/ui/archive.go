@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefArchiveComments    = "archive_comments"
+	prefArchiveMaxComments = "archive_max_comments"
+	prefArchiveLiveChat    = "archive_live_chat"
+
+	defaultMaxComments = 1000
+)
+
+// archiveOptions are the sidecars saved after a download for people who
+// need more than the media file.
+type archiveOptions struct {
+	Comments    bool
+	MaxComments int
+	LiveChat    bool
+}
+
+func (o archiveOptions) enabled() bool { return o.Comments || o.LiveChat }
+
+func loadArchiveOptions(prefs fyne.Preferences) archiveOptions {
+	return archiveOptions{
+		Comments:    prefs.Bool(prefArchiveComments),
+		MaxComments: prefs.IntWithFallback(prefArchiveMaxComments, defaultMaxComments),
+		LiveChat:    prefs.Bool(prefArchiveLiveChat),
+	}
+}
+
+// saveSidecars fetches comments and live chat for a finished download and
+// stores them next to outPath. Failures are logged; the download stands.
+func saveSidecars(ctx context.Context, ytdlp, url, outPath string, opts archiveOptions, extractorArgs []siteExtractorArgs, ev publisher) {
+	args := map[string]string{}
+	for _, e := range extractorArgs {
+		args[e.Site] = e.Args
+	}
+	base := strings.TrimSuffix(outPath, filepath.Ext(outPath))
+	ev.log(tr("Saving comments and chat..."))
+	files, err := downloader.DownloadSidecars(ctx, ytdlp, url, base, downloader.SidecarOptions{
+		Comments:      opts.Comments,
+		MaxComments:   opts.MaxComments,
+		LiveChat:      opts.LiveChat,
+		ExtractorArgs: args,
+	})
+	if err != nil {
+		ev.log(trf("Could not save comments or chat: %v", err))
+		return
+	}
+	if opts.LiveChat && !containsString(files, base+".live_chat.json") {
+		ev.log(tr("No live chat is available for this video."))
+	}
+	for _, f := range files {
+		ev.log(trf("Saved: %s", f))
+	}
+}
+
+// archivingPage edits which sidecars are kept with every download.
+func archivingPage(prefs fyne.Preferences) func() fyne.CanvasObject {
+	return func() fyne.CanvasObject {
+		maxComments := widget.NewEntry()
+		maxComments.SetText(strconv.Itoa(prefs.IntWithFallback(prefArchiveMaxComments, defaultMaxComments)))
+		maxComments.OnChanged = func(text string) {
+			if n, err := strconv.Atoi(strings.TrimSpace(text)); err == nil && n >= 0 {
+				prefs.SetInt(prefArchiveMaxComments, n)
+			}
+		}
+		comments := widget.NewCheck(tr("Save comments (in the .info.json file)"), func(on bool) {
+			prefs.SetBool(prefArchiveComments, on)
+			if on {
+				maxComments.Enable()
+			} else {
+				maxComments.Disable()
+			}
+		})
+		comments.SetChecked(prefs.Bool(prefArchiveComments))
+		if !comments.Checked {
+			maxComments.Disable()
+		}
+		liveChat := widget.NewCheck(tr("Save the live chat of streams and premieres (.live_chat.json)"), func(on bool) {
+			prefs.SetBool(prefArchiveLiveChat, on)
+		})
+		liveChat.SetChecked(prefs.Bool(prefArchiveLiveChat))
+		return container.NewVBox(
+			comments,
+			widget.NewForm(widget.NewFormItem(tr("Most comments (0 for all)"), maxComments)),
+			liveChat,
+			widget.NewLabel(tr("These files are saved next to single-video downloads. Fetching many comments can take several minutes.")),
+		)
+	}
+}
@@ -0,0 +1,151 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"ytgui/internal/downloader"
+)
+
+// downloadArchiveName is the yt-dlp --download-archive file playlist and
+// channel downloads use. It is rewritten from the history before each one,
+// so videos downloaded one at a time, or imported from another tool, are
+// skipped as well.
+const downloadArchiveName = "download_archive.txt"
+
+// youtubeFileID finds the video ID yt-dlp's default template ("Title
+// [dQw4w9WgXcQ].mp4") and the "Add video ID" naming option leave at the end
+// of a file name. Only YouTube IDs are recognized; other sites' IDs can't be
+// told apart from ordinary bracketed text.
+var youtubeFileID = regexp.MustCompile(`^(.*?)\s*\[([A-Za-z0-9_-]{11})\]$`)
+
+func youtubeWatchURL(id string) string {
+	return "https://www.youtube.com/watch?v=" + id
+}
+
+// readYTDLPArchive reads a yt-dlp archive file, one "extractor id" per line,
+// into history entries without files.
+func readYTDLPArchive(r io.Reader) ([]historyEntry, error) {
+	var entries []historyEntry
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		fields := strings.Fields(strings.TrimPrefix(sc.Text(), "\ufeff"))
+		if len(fields) != 2 {
+			continue
+		}
+		videoID := downloader.VideoID(fields[0], fields[1])
+		if videoID == "" {
+			continue
+		}
+		e := historyEntry{VideoID: videoID, Imported: true}
+		if strings.HasPrefix(videoID, "youtube:") {
+			e.URL = youtubeWatchURL(fields[1])
+		}
+		entries = append(entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.New(tr("No video IDs found; expected lines like \"youtube dQw4w9WgXcQ\"."))
+	}
+	return entries, nil
+}
+
+// scanDownloadedFolder finds media files in dir and its subfolders whose
+// names end in a YouTube video ID and turns them into history entries.
+func scanDownloadedFolder(dir string) ([]historyEntry, error) {
+	var entries []historyEntry
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !isLibraryMedia(path) {
+			return nil
+		}
+		m := youtubeFileID.FindStringSubmatch(strings.TrimSuffix(d.Name(), filepath.Ext(d.Name())))
+		if m == nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		entries = append(entries, historyEntry{
+			URL:      youtubeWatchURL(m[2]),
+			VideoID:  downloader.VideoID("youtube", m[2]),
+			Title:    m[1],
+			Path:     path,
+			Size:     info.Size(),
+			Finished: info.ModTime(),
+			Imported: true,
+		})
+		return nil
+	})
+	return entries, err
+}
+
+// importVideos adds the entries whose videos the history doesn't have yet
+// and returns how many that were.
+func (h *historyStore) importVideos(entries []historyEntry) (int, error) {
+	h.mu.Lock()
+	known := make(map[string]bool, len(h.entries))
+	for _, e := range h.entries {
+		known[e.VideoID] = true
+	}
+	var added []historyEntry
+	base := time.Now().UnixNano()
+	for _, e := range entries {
+		if known[e.VideoID] {
+			continue
+		}
+		known[e.VideoID] = true
+		e.ID = base + int64(len(added))
+		added = append(added, e)
+	}
+	h.entries = append(h.entries, added...)
+	var err error
+	for _, e := range added {
+		if err = h.persistLocked(e); err != nil || !streamHistoryWrites() {
+			break
+		}
+	}
+	h.mu.Unlock()
+	if len(added) > 0 {
+		h.changed()
+	}
+	return len(added), err
+}
+
+// syncDownloadArchive writes the download archive from the history and
+// returns its path. As for single videos, a download only counts while its
+// file is still on disk; imported archive lines have no file and always
+// count.
+func syncDownloadArchive(entries []historyEntry) (string, error) {
+	dir, err := downloader.AppDir()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		extractor, id, ok := strings.Cut(e.VideoID, ":")
+		if !ok {
+			continue
+		}
+		if e.Path != "" {
+			if _, err := os.Stat(e.Path); err != nil {
+				continue
+			}
+		}
+		b.WriteString(extractor + " " + id + "\n")
+	}
+	path := filepath.Join(dir, downloadArchiveName)
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefTrimSilence = "trim_silence"
+	prefCutIntro    = "cut_intro_seconds"
+	prefCutOutro    = "cut_outro_seconds"
+)
+
+func loadAudioTrim(prefs fyne.Preferences) downloader.AudioTrim {
+	return downloader.AudioTrim{
+		Silence: prefs.Bool(prefTrimSilence),
+		Intro:   float64(prefs.Int(prefCutIntro)),
+		Outro:   float64(prefs.Int(prefCutOutro)),
+	}
+}
+
+// secondsEntry edits a whole number of seconds stored under key; empty
+// means 0.
+func secondsEntry(prefs fyne.Preferences, key string) *widget.Entry {
+	e := widget.NewEntry()
+	e.SetPlaceHolder("0")
+	if n := prefs.Int(key); n > 0 {
+		e.SetText(strconv.Itoa(n))
+	}
+	e.OnChanged = func(text string) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			prefs.SetInt(key, 0)
+			return
+		}
+		if n, err := strconv.Atoi(text); err == nil && n >= 0 {
+			prefs.SetInt(key, n)
+		}
+	}
+	return e
+}
+
+// audioTrimRow holds the Audio Only trimming controls of the main window.
+// set shows a preset's values.
+func audioTrimRow(prefs fyne.Preferences) (row fyne.CanvasObject, set func(downloader.AudioTrim)) {
+	silence := widget.NewCheck(tr("Trim silence"), func(on bool) {
+		prefs.SetBool(prefTrimSilence, on)
+	})
+	silence.SetChecked(prefs.Bool(prefTrimSilence))
+	intro := secondsEntry(prefs, prefCutIntro)
+	outro := secondsEntry(prefs, prefCutOutro)
+	size := fyne.NewSize(60, intro.MinSize().Height)
+	row = container.NewHBox(silence,
+		widget.NewLabel(tr("Cut intro (s):")), container.NewGridWrap(size, intro),
+		widget.NewLabel(tr("Cut outro (s):")), container.NewGridWrap(size, outro))
+	set = func(t downloader.AudioTrim) {
+		silence.SetChecked(t.Silence)
+		for _, f := range []struct {
+			e *widget.Entry
+			v float64
+		}{{intro, t.Intro}, {outro, t.Outro}} {
+			if f.v > 0 {
+				f.e.SetText(strconv.Itoa(int(f.v)))
+			} else {
+				f.e.SetText("")
+			}
+		}
+	}
+	return row, set
+}
+
+// trimDownload cuts silence and fixed intro/outro lengths from an audio
+// download in place. On failure the file is kept as downloaded and the
+// reason logged.
+func trimDownload(ctx context.Context, ffmpeg, path string, trim downloader.AudioTrim, ev publisher) {
+	// ffprobe is installed next to ffmpeg.
+	probe, err := downloader.ProbeMedia(filepath.Join(filepath.Dir(ffmpeg), "ffprobe.exe"), path)
+	if err != nil {
+		ev.log(trf("Could not read the audio, skipping trimming: %v", err))
+		return
+	}
+	ev.SetText(tr("Trimming audio..."))
+	cut, err := downloader.TrimAudio(ctx, ffmpeg, path, trim, probe.Duration, func(p float64) {
+		ev.SetText(trf("Looking for silence... %.0f%%", p*100))
+	})
+	switch {
+	case err != nil:
+		ev.log(trf("Trimming failed, keeping the full audio: %v", err))
+	case cut > 0:
+		ev.log(trf("Trimmed %.1f seconds.", cut))
+	default:
+		ev.log(tr("Nothing to trim."))
+	}
+}
@@ -0,0 +1,244 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// batchPostOpenFolder is the only post step. Batch files are shared, so they
+// can't run programs.
+const batchPostOpenFolder = "open_folder"
+
+func checkPostSteps(steps []string) error {
+	for _, step := range steps {
+		if step != batchPostOpenFolder {
+			return fmt.Errorf("unknown post step %q (only %q is supported)", step, batchPostOpenFolder)
+		}
+	}
+	return nil
+}
+
+// batchJobSpec is one entry of a batch job file. Unset fields fall back to
+// the file's "defaults" entry and then to the settings currently in the UI.
+type batchJobSpec struct {
+	URL            string   `json:"url"`
+	Quality        *string  `json:"quality,omitempty"`
+	Profile        *string  `json:"profile,omitempty"`
+	AudioQuality   *string  `json:"audio_quality,omitempty"`
+	Loudness       *string  `json:"loudness,omitempty"`
+	MaxFPS         *int     `json:"max_fps,omitempty"`
+	Destination    *string  `json:"destination,omitempty"`
+	IncludeChannel *bool    `json:"include_channel,omitempty"`
+	Playlist       *bool    `json:"playlist,omitempty"`
+	StageLocal     *bool    `json:"stage_local,omitempty"`
+	Subtitles      *bool    `json:"subtitles,omitempty"`
+	SubtitleLang   *string  `json:"subtitle_language,omitempty"`
+	SubFormat      *string  `json:"subtitle_format,omitempty"`
+	KeepSubs       *bool    `json:"keep_subtitle_files,omitempty"`
+	OnDuplicate    *string  `json:"on_duplicate,omitempty"`
+	SortTemplate   *string  `json:"sort_template,omitempty"`
+	Post           []string `json:"post,omitempty"`
+}
+
+func (spec batchJobSpec) apply(s *jobSettings) {
+	if spec.Quality != nil {
+		s.Quality = *spec.Quality
+	}
+	if spec.Profile != nil {
+		s.Profile = *spec.Profile
+	}
+	if spec.AudioQuality != nil {
+		s.AudioQuality = *spec.AudioQuality
+	}
+	if spec.Loudness != nil && containsString(loudnessOptions, *spec.Loudness) {
+		s.Loudness = *spec.Loudness
+	}
+	if spec.MaxFPS != nil {
+		s.MaxFPS = *spec.MaxFPS
+	}
+	if spec.Destination != nil {
+		s.Folder = *spec.Destination
+	}
+	if spec.IncludeChannel != nil {
+		s.IncludeChannel = *spec.IncludeChannel
+	}
+	if spec.Playlist != nil {
+		s.Playlist = *spec.Playlist
+	}
+	if spec.StageLocal != nil {
+		s.StageLocal = *spec.StageLocal
+	}
+	if spec.Subtitles != nil {
+		s.Subtitles = *spec.Subtitles
+	}
+	if spec.SubtitleLang != nil {
+		s.SubtitleLang = *spec.SubtitleLang
+	}
+	if spec.SubFormat != nil {
+		s.SubFormat = strings.ToLower(*spec.SubFormat)
+		if s.SubFormat == "auto" {
+			s.SubFormat = subFormatAuto
+		}
+	}
+	if spec.KeepSubs != nil {
+		s.KeepSubs = *spec.KeepSubs
+	}
+	if spec.SortTemplate != nil {
+		s.SortTemplate = strings.TrimSpace(*spec.SortTemplate)
+	}
+	if spec.OnDuplicate != nil {
+		if p, ok := parseDuplicatePolicy(*spec.OnDuplicate); ok {
+			s.Duplicates = p
+		}
+	}
+}
+
+type batchFile struct {
+	Defaults batchJobSpec   `json:"defaults"`
+	Jobs     []batchJobSpec `json:"jobs"`
+}
+
+// loadBatchFile reads a JSON job file. Either {"defaults": {...}, "jobs":
+// [...]} or a bare array of jobs is accepted.
+func loadBatchFile(path string) (batchFile, error) {
+	var bf batchFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return bf, err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &bf.Jobs)
+	} else {
+		err = json.Unmarshal(data, &bf)
+	}
+	if err != nil {
+		return bf, fmt.Errorf("invalid batch file %s: %w", filepath.Base(path), err)
+	}
+	if len(bf.Jobs) == 0 {
+		return bf, fmt.Errorf("batch file %s has no jobs", filepath.Base(path))
+	}
+	for i := range bf.Jobs {
+		u, ok := parseLaunchArg(bf.Jobs[i].URL)
+		if !ok {
+			return bf, fmt.Errorf("job %d: %q is not an http(s) URL", i+1, bf.Jobs[i].URL)
+		}
+		bf.Jobs[i].URL = u
+		if err := checkPostSteps(bf.Jobs[i].Post); err != nil {
+			return bf, fmt.Errorf("job %d: %w", i+1, err)
+		}
+	}
+	if err := checkPostSteps(bf.Defaults.Post); err != nil {
+		return bf, fmt.Errorf("defaults: %w", err)
+	}
+	return bf, nil
+}
+
+func (bf batchFile) settingsFor(i int, base jobSettings) jobSettings {
+	bf.Defaults.apply(&base)
+	bf.Jobs[i].apply(&base)
+	return base
+}
+
+func (bf batchFile) postFor(i int) []string {
+	if len(bf.Jobs[i].Post) > 0 {
+		return bf.Jobs[i].Post
+	}
+	return bf.Defaults.Post
+}
+
+// batchArgs pulls "--batch <file>" pairs out of the command line.
+func batchArgs(args []string) []string {
+	var out []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--batch" && i+1 < len(args):
+			out = append(out, args[i+1])
+			i++
+		case strings.HasPrefix(a, "--batch="):
+			out = append(out, strings.TrimPrefix(a, "--batch="))
+		}
+	}
+	return out
+}
+
+type batchRun struct {
+	name string
+	ids  []int64
+	post map[int64][]string
+	left int
+}
+
+// batchTracker follows the jobs a batch file queued so post steps run per job
+// and a report is produced once the last one finishes.
+type batchTracker struct {
+	mu   sync.Mutex
+	runs []*batchRun
+}
+
+func (t *batchTracker) add(run *batchRun) {
+	run.left = len(run.ids)
+	t.mu.Lock()
+	t.runs = append(t.runs, run)
+	t.mu.Unlock()
+}
+
+// finished records that a job reached a final state. It returns the job's
+// post steps and, if this was the batch's last job, the completed batch.
+func (t *batchTracker) finished(id int64) (post []string, done *batchRun) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, run := range t.runs {
+		steps, ok := run.post[id]
+		if !ok {
+			continue
+		}
+		delete(run.post, id)
+		run.left--
+		if run.left <= 0 {
+			t.runs = append(t.runs[:i], t.runs[i+1:]...)
+			done = run
+		}
+		return steps, done
+	}
+	return nil, nil
+}
+
+func runBatchPostStep(step, folder string) error {
+	if step != batchPostOpenFolder {
+		return fmt.Errorf("unknown post step %q", step)
+	}
+	return openInFileManager(folder)
+}
+
+// batchReport summarizes a finished batch from the queue's current view of
+// its jobs.
+func batchReport(run *batchRun, jobs []downloadJob) (summary string, lines []string) {
+	byID := make(map[int64]downloadJob, len(jobs))
+	for _, j := range jobs {
+		byID[j.ID] = j
+	}
+	counts := map[jobState]int{}
+	for _, id := range run.ids {
+		j, ok := byID[id]
+		if !ok {
+			lines = append(lines, fmt.Sprintf("  #%d: removed from queue", id))
+			continue
+		}
+		counts[j.State]++
+		line := fmt.Sprintf("  #%d %s: %s", id, j.State, j.URL)
+		if j.Err != nil && j.State == jobFailed {
+			line += " (" + j.Err.Error() + ")"
+		}
+		lines = append(lines, line)
+	}
+	summary = fmt.Sprintf("Batch %s finished: %d done, %d failed, %d canceled (of %d).",
+		run.name, counts[jobDone], counts[jobFailed], counts[jobCanceled], len(run.ids))
+	return summary, lines
+}
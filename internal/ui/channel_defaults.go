@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"encoding/json"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const prefChannelDefaults = "channel_defaults"
+
+// channelDefault is what a pasted link from a channel starts with: a preset
+// for quality and naming, and a download folder. Either may be empty.
+type channelDefault struct {
+	// Key is the channel's "extractor:id" (see VideoMetadata.ChannelKey).
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Preset string `json:"preset,omitempty"`
+	Folder string `json:"folder,omitempty"`
+}
+
+func loadChannelDefaults(prefs fyne.Preferences) []channelDefault {
+	var defaults []channelDefault
+	if raw := prefs.String(prefChannelDefaults); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &defaults); err != nil {
+			fyne.LogError("Could not read channel defaults", err)
+		}
+	}
+	return defaults
+}
+
+func saveChannelDefaults(prefs fyne.Preferences, defaults []channelDefault) {
+	data, err := json.Marshal(defaults)
+	if err != nil {
+		fyne.LogError("Could not save channel defaults", err)
+		return
+	}
+	prefs.SetString(prefChannelDefaults, string(data))
+}
+
+func findChannelDefault(prefs fyne.Preferences, key string) (channelDefault, bool) {
+	if key == "" {
+		return channelDefault{}, false
+	}
+	for _, d := range loadChannelDefaults(prefs) {
+		if d.Key == key {
+			return d, true
+		}
+	}
+	return channelDefault{}, false
+}
+
+// putChannelDefault replaces the defaults for d's channel, or adds them.
+func putChannelDefault(prefs fyne.Preferences, d channelDefault) {
+	defaults := loadChannelDefaults(prefs)
+	for i := range defaults {
+		if defaults[i].Key == d.Key {
+			defaults[i] = d
+			saveChannelDefaults(prefs, defaults)
+			return
+		}
+	}
+	saveChannelDefaults(prefs, append(defaults, d))
+}
+
+func removeChannelDefault(prefs fyne.Preferences, key string) {
+	var kept []channelDefault
+	for _, d := range loadChannelDefaults(prefs) {
+		if d.Key != key {
+			kept = append(kept, d)
+		}
+	}
+	saveChannelDefaults(prefs, kept)
+}
+
+func channelDefaultText(d channelDefault) string {
+	preset := valueOr(d.Preset, tr("current settings"))
+	folder := valueOr(d.Folder, tr("current folder"))
+	return trf("%s: %s, %s", d.Name, preset, folder)
+}
+
+// channelsPage lists the remembered channel defaults. New ones are saved
+// from the main window with "Remember for This Channel".
+func channelsPage(prefs fyne.Preferences) func() fyne.CanvasObject {
+	return func() fyne.CanvasObject {
+		list := container.NewVBox()
+		var refresh func()
+		refresh = func() {
+			list.RemoveAll()
+			defaults := loadChannelDefaults(prefs)
+			if len(defaults) == 0 {
+				list.Add(widget.NewLabel(tr("No channel defaults yet.")))
+			}
+			for _, d := range defaults {
+				d := d
+				label := widget.NewLabel(channelDefaultText(d))
+				label.Truncation = fyne.TextTruncateEllipsis
+				remove := newIconButton(tr("Remove"), theme.DeleteIcon(), func() {
+					removeChannelDefault(prefs, d.Key)
+					refresh()
+				})
+				list.Add(container.NewBorder(nil, nil, nil, remove, label))
+			}
+		}
+		refresh()
+		hint := widget.NewLabel(tr("Paste a link, pick a preset and folder, then use File > Remember for This Channel. Links from that channel start with those settings."))
+		hint.Wrapping = fyne.TextWrapWord
+		return container.NewVBox(list, hint)
+	}
+}
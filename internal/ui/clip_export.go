@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefClipOutput = "clip_output"
+	prefClipFPS    = "clip_fps"
+	prefClipWidth  = "clip_width"
+
+	clipOutputVideo = "Video"
+	clipOutputGIF   = "GIF"
+	clipOutputWebM  = "WebM clip"
+
+	clipOriginal = "Original"
+)
+
+var (
+	clipOutputs      = []string{clipOutputVideo, clipOutputGIF, clipOutputWebM}
+	clipFPSOptions   = []string{"10", "15", "24", "30", clipOriginal}
+	clipWidthOptions = []string{"320", "480", "640", "854", clipOriginal}
+)
+
+// clipExport converts a downloaded clip for sharing. An empty Format keeps
+// the video as downloaded; FPS and Width of 0 keep the source values.
+type clipExport struct {
+	Format string
+	FPS    int
+	Width  int
+}
+
+func (c clipExport) ext() string {
+	switch c.Format {
+	case clipOutputGIF:
+		return downloader.ClipGIF
+	case clipOutputWebM:
+		return downloader.ClipWebM
+	}
+	return ""
+}
+
+// clipNumber reads one of the clip option values, Original being 0.
+func clipNumber(option string) int {
+	n, _ := strconv.Atoi(option)
+	return n
+}
+
+// parseClipTime reads "90", "1m30s" or "1:30" / "0:01:30" as seconds.
+func parseClipTime(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, ":") {
+		total := 0
+		parts := strings.Split(s, ":")
+		if len(parts) > 3 {
+			return 0, fmt.Errorf("%q is not a time", s)
+		}
+		for _, p := range parts {
+			n, err := strconv.Atoi(p)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("%q is not a time", s)
+			}
+			total = total*60 + n
+		}
+		return total, nil
+	}
+	if n := parseTimestamp(s); n > 0 || s == "0" || s == "0s" {
+		return n, nil
+	}
+	return 0, fmt.Errorf("%q is not a time", s)
+}
+
+// askClipRange asks for the part of a video to download and what to make of
+// it, starting from the link's timestamp. It must be called on the main
+// thread; onDone is only called when the user confirms a valid range.
+func askClipRange(w fyne.Window, prefs fyne.Preferences, start int, onDone func(start, end int, export clipExport)) {
+	from := widget.NewEntry()
+	from.SetText(formatTimestamp(start))
+	to := widget.NewEntry()
+	to.SetPlaceHolder(tr("End of the video"))
+	fps := widget.NewSelect(trList(clipFPSOptions), nil)
+	fps.SetSelected(tr(selectedOption(prefs, prefClipFPS, clipFPSOptions, "15")))
+	width := widget.NewSelect(trList(clipWidthOptions), nil)
+	width.SetSelected(tr(selectedOption(prefs, prefClipWidth, clipWidthOptions, "480")))
+	output := widget.NewSelect(trList(clipOutputs), func(shown string) {
+		if untr(clipOutputs, shown) == clipOutputVideo {
+			fps.Disable()
+			width.Disable()
+		} else {
+			fps.Enable()
+			width.Enable()
+		}
+	})
+	output.SetSelected(tr(selectedOption(prefs, prefClipOutput, clipOutputs, clipOutputVideo)))
+
+	dialog.ShowForm(tr("Download Clip"), tr("Download"), tr("Cancel"), []*widget.FormItem{
+		widget.NewFormItem(tr("From"), from),
+		widget.NewFormItem(tr("To"), to),
+		widget.NewFormItem(tr("Save as"), output),
+		widget.NewFormItem(tr("Frame rate"), fps),
+		widget.NewFormItem(tr("Width"), width),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		s, err := parseClipTime(from.Text)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		e := 0
+		if strings.TrimSpace(to.Text) != "" {
+			if e, err = parseClipTime(to.Text); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if e <= s {
+				dialog.ShowError(errors.New(tr("The clip must end after it starts.")), w)
+				return
+			}
+		}
+		prefs.SetString(prefClipOutput, untr(clipOutputs, output.Selected))
+		prefs.SetString(prefClipFPS, untr(clipFPSOptions, fps.Selected))
+		prefs.SetString(prefClipWidth, untr(clipWidthOptions, width.Selected))
+		export := clipExport{}
+		if f := untr(clipOutputs, output.Selected); f != clipOutputVideo {
+			export = clipExport{
+				Format: f,
+				FPS:    clipNumber(untr(clipFPSOptions, fps.Selected)),
+				Width:  clipNumber(untr(clipWidthOptions, width.Selected)),
+			}
+		}
+		onDone(s, e, export)
+	}, w)
+}
+
+// clipLabel names a clip in log lines and file names.
+func clipLabel(start, end int) string {
+	if end > 0 {
+		return formatTimestamp(start) + "-" + formatTimestamp(end)
+	}
+	return "from " + formatTimestamp(start)
+}
+
+// exportDownloadedClip converts path as export asks and returns the file to
+// keep. The downloaded video is replaced by the export; on failure it is
+// kept and the reason logged.
+func exportDownloadedClip(ctx context.Context, ffmpeg, path string, export clipExport, ev publisher) string {
+	dst := downloader.UniqueName(strings.TrimSuffix(path, filepath.Ext(path)) + "." + export.ext())
+	ev.log(trf("Converting the clip to %s...", tr(export.Format)))
+	ev.SetText(trf("Converting the clip to %s...", tr(export.Format)))
+	if err := downloader.ExportClip(ctx, ffmpeg, path, dst, export.ext(), export.FPS, export.Width); err != nil {
+		ev.log(trf("Could not convert the clip, keeping the video: %v", err))
+		return path
+	}
+	if err := downloader.RecycleFile(path); err != nil {
+		ev.log(trf("Could not remove the original file: %v", err))
+	}
+	return dst
+}
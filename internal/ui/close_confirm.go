@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// closeWarning describes what quitting now would interrupt. running and
+// queued count the jobs in the download queue; a busy app with neither is
+// working on something outside it, such as a thumbnail download.
+func closeWarning(running, queued int) string {
+	var parts []string
+	switch {
+	case running > 0:
+		parts = append(parts, trf("%d download(s) in progress will be canceled.", running))
+	case queued == 0:
+		parts = append(parts, tr("A download is still running."))
+	}
+	if queued > 0 {
+		parts = append(parts, trf("%d download(s) are still waiting in the queue.", queued))
+	}
+	parts = append(parts, tr("Unfinished jobs are restored next time."))
+	return strings.Join(parts, " ")
+}
+
+// askClose asks before quitting while work is running. background, when
+// not nil, keeps the app running in the system tray until the queue is
+// done instead.
+func askClose(w fyne.Window, running, queued int, quit, background func()) {
+	message := widget.NewLabel(closeWarning(running, queued))
+	message.Wrapping = fyne.TextWrapWord
+	d := dialog.NewCustomWithoutButtons(tr("Exit"), message, w)
+	quitBtn := widget.NewButton(tr("Quit Anyway"), func() {
+		d.Hide()
+		quit()
+	})
+	quitBtn.Importance = widget.DangerImportance
+	buttons := []fyne.CanvasObject{widget.NewButton(tr("Keep Open"), d.Hide)}
+	if background != nil {
+		buttons = append(buttons, widget.NewButton(tr("Finish in Background"), func() {
+			d.Hide()
+			background()
+		}))
+	}
+	d.SetButtons(append(buttons, quitBtn))
+	d.Resize(fyne.NewSize(460, d.MinSize().Height))
+	d.Show()
+}
@@ -5,3 +5,7 @@ package ui
 import "os/exec"
 
 func setCmdHideWindow(cmd *exec.Cmd) {}
+
+func explorerSelectCmd(path string) *exec.Cmd {
+	return exec.Command("explorer", "/select,"+path)
+}
@@ -10,3 +10,12 @@ import (
 func setCmdHideWindow(cmd *exec.Cmd) {
 	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
 }
+
+// explorerSelectCmd opens Explorer with path selected. Explorer parses its
+// own command line and doesn't understand Go's argument quoting, so the
+// line is written by hand.
+func explorerSelectCmd(path string) *exec.Cmd {
+	cmd := exec.Command("explorer")
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: `explorer /select,"` + path + `"`}
+	return cmd
+}
@@ -0,0 +1,477 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	configFileName = "config.json"
+	configVersion  = 1
+	// configSaveDelay coalesces settings writes, like queueStoreInterval
+	// does for the queue.
+	configSaveDelay = 500 * time.Millisecond
+)
+
+// appConfig is the settings file in the app folder. Settings holds the
+// values under their preference keys; Version records which migrations have
+// been applied.
+type appConfig struct {
+	Version  int            `json:"version"`
+	Settings map[string]any `json:"settings"`
+}
+
+// configMigrations[i] upgrades a config from version i to i+1. legacy is
+// the Fyne preference store settings used to live in.
+var configMigrations = []func(cfg *appConfig, legacy fyne.Preferences){
+	migrateLegacyPreferences,
+}
+
+// legacyPrefKinds lists every key ytgui kept in Fyne preferences, with its
+// value type, so the first run with a config file can carry them over.
+var legacyPrefKinds = map[string]string{
+	prefAutoRedownload: "bool", prefCompact: "bool", prefConfirmPlaylistSize: "bool",
+	prefControlAPIEnabled: "bool", prefHighContrast: "bool", prefKeepSubs: "bool",
+	prefLowMemory: "bool", prefNameEmoji: "bool", prefNameSpaces: "bool",
+	prefPlaylist: "bool", prefPlaylistReverse: "bool", prefRetryNetworkOnly: "bool",
+	prefSnapshotEnabled: "bool", prefSubtitles: "bool", prefWithChannel: "bool",
+
+	prefUIScale: "float", prefWindowHeight: "float", prefWindowWidth: "float",
+
+	prefFragmentRetries: "int", prefLogTab: "int", prefMixItemCap: "int",
+	prefNameMaxLen: "int", prefPlaylistMax: "int", prefPlaylistMaxLen: "int",
+	prefPlaylistMinLen: "int", prefRetryAttempts: "int", prefRetryBackoff: "int",
+	prefYTDLPRetries: "int",
+
+	prefControlAPIAddr: "string", prefControlAPIToken: "string", prefDownloadDir: "string",
+	prefDuplicatePolicy: "string", prefLanguage: "string", prefLogVerbosity: "string",
+	prefPlaylistAfter: "string", prefPlaylistItems: "string", prefPlaylistTitle: "string",
+	prefProfile: "string", prefQuality: "string", prefSnapshotAddr: "string",
+	prefSortRule: "string", prefSortTemplate: "string", prefSubFormat: "string",
+	prefSubtitleLang: "string", prefSubtitleMode: "string", prefSubtitleOrder: "string",
+	prefThemeVariant: "string", prefTwitchChat: "string", prefTwitchQuality: "string",
+
+	prefFavoriteFolders: "strings", prefNameStrip: "strings", prefRecentFolders: "strings",
+	prefStagedDirs: "strings",
+}
+
+// migrateLegacyPreferences copies the values that are set in the old Fyne
+// store. Fyne offers no "has key", so a key counts as set when two
+// different fallbacks give the same answer.
+func migrateLegacyPreferences(cfg *appConfig, legacy fyne.Preferences) {
+	if legacy == nil {
+		return
+	}
+	for key, kind := range legacyPrefKinds {
+		switch kind {
+		case "bool":
+			if legacy.BoolWithFallback(key, false) == legacy.BoolWithFallback(key, true) {
+				cfg.Settings[key] = legacy.Bool(key)
+			}
+		case "float":
+			if legacy.FloatWithFallback(key, 0) == legacy.FloatWithFallback(key, 1) {
+				cfg.Settings[key] = legacy.Float(key)
+			}
+		case "int":
+			if legacy.IntWithFallback(key, 0) == legacy.IntWithFallback(key, 1) {
+				cfg.Settings[key] = legacy.Int(key)
+			}
+		case "string":
+			if v := legacy.StringWithFallback(key, "\x00"); v != "\x00" {
+				cfg.Settings[key] = v
+			}
+		case "strings":
+			if v := legacy.StringListWithFallback(key, nil); v != nil {
+				cfg.Settings[key] = v
+			}
+		}
+	}
+}
+
+func configPath() (string, error) {
+	dir, err := downloader.AppDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configFileName), nil
+}
+
+func parseConfig(data []byte) (appConfig, error) {
+	var cfg appConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return appConfig{}, fmt.Errorf("not a ytgui settings file: %w", err)
+	}
+	if cfg.Settings == nil {
+		cfg.Settings = map[string]any{}
+	}
+	return cfg, nil
+}
+
+func migrateConfig(cfg *appConfig, legacy fyne.Preferences) bool {
+	migrated := false
+	for cfg.Version < len(configMigrations) {
+		configMigrations[cfg.Version](cfg, legacy)
+		cfg.Version++
+		migrated = true
+	}
+	return migrated
+}
+
+// configPrefs serves fyne.Preferences from config.json, so existing
+// preference lookups keep working while the values live in one file that
+// can be backed up, exported and migrated.
+type configPrefs struct {
+	mu        sync.RWMutex
+	path      string
+	cfg       appConfig
+	listeners []func()
+	// saveTimer is pending while changes wait to be written, so a burst of
+	// sets (a slider drag, a settings import) costs one write.
+	saveTimer *time.Timer
+}
+
+// loadConfigPrefs opens the config file, creating it from the legacy Fyne
+// preferences on first run. A damaged file is kept next to the new one as
+// config.json.bad.
+func loadConfigPrefs(path string, legacy fyne.Preferences) *configPrefs {
+	cfg := appConfig{Settings: map[string]any{}}
+	if data, err := os.ReadFile(path); err == nil {
+		if cfg, err = parseConfig(data); err != nil {
+			os.Rename(path, path+".bad")
+			cfg = appConfig{Settings: map[string]any{}}
+		}
+	}
+	p := &configPrefs{path: path, cfg: cfg}
+	if migrateConfig(&p.cfg, legacy) {
+		p.save()
+	}
+	return p
+}
+
+func (p *configPrefs) save() {
+	data, err := json.MarshalIndent(p.cfg, "", "  ")
+	if err != nil {
+		fyne.LogError("Could not encode settings", err)
+		return
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		fyne.LogError("Could not save settings", err)
+		return
+	}
+	if err := os.Rename(tmp, p.path); err != nil {
+		fyne.LogError("Could not save settings", err)
+	}
+}
+
+// saveSoonLocked schedules a save configSaveDelay from now unless one is
+// already pending. p.mu must be held.
+func (p *configPrefs) saveSoonLocked() {
+	if p.saveTimer != nil {
+		return
+	}
+	p.saveTimer = time.AfterFunc(configSaveDelay, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.saveTimer = nil
+		p.save()
+	})
+}
+
+// flush writes a pending save at once. It is called when the app exits.
+func (p *configPrefs) flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.saveTimer != nil && p.saveTimer.Stop() {
+		p.saveTimer = nil
+		p.save()
+	}
+}
+
+// setPath points the settings at a new file, after the app folder moved.
+func (p *configPrefs) setPath(path string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.path = path
+}
+
+func (p *configPrefs) lookup(key string) (any, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	v, ok := p.cfg.Settings[key]
+	return v, ok
+}
+
+func (p *configPrefs) set(key string, value any) {
+	p.mu.Lock()
+	if value == nil {
+		delete(p.cfg.Settings, key)
+	} else {
+		p.cfg.Settings[key] = value
+	}
+	p.saveSoonLocked()
+	listeners := append([]func(){}, p.listeners...)
+	p.mu.Unlock()
+	for _, l := range listeners {
+		l()
+	}
+}
+
+// export writes the settings to dst, leaving out redactedPrefs.
+func (p *configPrefs) export(dst io.Writer) error {
+	p.mu.RLock()
+	out := appConfig{Version: p.cfg.Version, Settings: make(map[string]any, len(p.cfg.Settings))}
+	for k, v := range p.cfg.Settings {
+		out.Settings[k] = v
+	}
+	p.mu.RUnlock()
+	for _, key := range redactedPrefs {
+		delete(out.Settings, key)
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = dst.Write(data)
+	return err
+}
+
+// importFrom replaces the settings with those read from src, migrating them
+// if they come from an older version. Secrets that exports leave out are
+// kept.
+func (p *configPrefs) importFrom(src io.Reader) error {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+	cfg, err := parseConfig(data)
+	if err != nil {
+		return err
+	}
+	if cfg.Version > configVersion {
+		return fmt.Errorf("the file was written by a newer ytgui (config version %d)", cfg.Version)
+	}
+	migrateConfig(&cfg, nil)
+	p.mu.Lock()
+	for _, key := range redactedPrefs {
+		if v, ok := p.cfg.Settings[key]; ok {
+			if _, imported := cfg.Settings[key]; !imported {
+				cfg.Settings[key] = v
+			}
+		}
+	}
+	p.cfg = cfg
+	p.save()
+	listeners := append([]func(){}, p.listeners...)
+	p.mu.Unlock()
+	for _, l := range listeners {
+		l()
+	}
+	return nil
+}
+
+func (p *configPrefs) Bool(key string) bool { return p.BoolWithFallback(key, false) }
+
+func (p *configPrefs) BoolWithFallback(key string, fallback bool) bool {
+	if v, ok := p.lookup(key); ok {
+		if b, ok := v.(bool); ok {
+			return b
+		}
+	}
+	return fallback
+}
+
+func (p *configPrefs) SetBool(key string, value bool) { p.set(key, value) }
+
+func (p *configPrefs) Float(key string) float64 { return p.FloatWithFallback(key, 0) }
+
+func (p *configPrefs) FloatWithFallback(key string, fallback float64) float64 {
+	if v, ok := p.lookup(key); ok {
+		if f, ok := toFloat(v); ok {
+			return f
+		}
+	}
+	return fallback
+}
+
+func (p *configPrefs) SetFloat(key string, value float64) { p.set(key, value) }
+
+func (p *configPrefs) Int(key string) int { return p.IntWithFallback(key, 0) }
+
+func (p *configPrefs) IntWithFallback(key string, fallback int) int {
+	if v, ok := p.lookup(key); ok {
+		if f, ok := toFloat(v); ok {
+			return int(f)
+		}
+	}
+	return fallback
+}
+
+func (p *configPrefs) SetInt(key string, value int) { p.set(key, value) }
+
+func (p *configPrefs) String(key string) string { return p.StringWithFallback(key, "") }
+
+func (p *configPrefs) StringWithFallback(key, fallback string) string {
+	if v, ok := p.lookup(key); ok {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return fallback
+}
+
+func (p *configPrefs) SetString(key string, value string) { p.set(key, value) }
+
+func (p *configPrefs) BoolList(key string) []bool { return p.BoolListWithFallback(key, nil) }
+
+func (p *configPrefs) BoolListWithFallback(key string, fallback []bool) []bool {
+	return listWithFallback(p, key, fallback, func(v any) (bool, bool) { b, ok := v.(bool); return b, ok })
+}
+
+func (p *configPrefs) SetBoolList(key string, value []bool) { p.set(key, value) }
+
+func (p *configPrefs) FloatList(key string) []float64 { return p.FloatListWithFallback(key, nil) }
+
+func (p *configPrefs) FloatListWithFallback(key string, fallback []float64) []float64 {
+	return listWithFallback(p, key, fallback, toFloat)
+}
+
+func (p *configPrefs) SetFloatList(key string, value []float64) { p.set(key, value) }
+
+func (p *configPrefs) IntList(key string) []int { return p.IntListWithFallback(key, nil) }
+
+func (p *configPrefs) IntListWithFallback(key string, fallback []int) []int {
+	return listWithFallback(p, key, fallback, func(v any) (int, bool) { f, ok := toFloat(v); return int(f), ok })
+}
+
+func (p *configPrefs) SetIntList(key string, value []int) { p.set(key, value) }
+
+func (p *configPrefs) StringList(key string) []string { return p.StringListWithFallback(key, nil) }
+
+func (p *configPrefs) StringListWithFallback(key string, fallback []string) []string {
+	return listWithFallback(p, key, fallback, func(v any) (string, bool) { s, ok := v.(string); return s, ok })
+}
+
+func (p *configPrefs) SetStringList(key string, value []string) { p.set(key, value) }
+
+func (p *configPrefs) RemoveValue(key string) { p.set(key, nil) }
+
+func (p *configPrefs) AddChangeListener(l func()) {
+	p.mu.Lock()
+	p.listeners = append(p.listeners, l)
+	p.mu.Unlock()
+}
+
+func (p *configPrefs) ChangeListeners() []func() {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]func(){}, p.listeners...)
+}
+
+// toFloat accepts the number types a value can have before and after a
+// round trip through JSON.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// listWithFallback reads a list stored either as a typed slice (set this
+// session) or as []any (loaded from JSON).
+func listWithFallback[T any](p *configPrefs, key string, fallback []T, conv func(any) (T, bool)) []T {
+	v, ok := p.lookup(key)
+	if !ok {
+		return fallback
+	}
+	if list, ok := v.([]T); ok {
+		return list
+	}
+	raw, ok := v.([]any)
+	if !ok {
+		return fallback
+	}
+	out := make([]T, 0, len(raw))
+	for _, item := range raw {
+		t, ok := conv(item)
+		if !ok {
+			return fallback
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// configApp is a fyne.App whose Preferences come from the config file.
+type configApp struct {
+	fyne.App
+	prefs *configPrefs
+}
+
+func (a configApp) Preferences() fyne.Preferences { return a.prefs }
+
+// withConfig switches a to config.json, falling back to the Fyne store when
+// the app folder can't be resolved.
+func withConfig(a fyne.App) fyne.App {
+	path, err := configPath()
+	if err != nil {
+		return a
+	}
+	return configApp{App: a, prefs: loadConfigPrefs(path, a.Preferences())}
+}
+
+// configTransfer is the settings Import/Export row. Imported values are
+// saved at once; most take effect after a restart.
+func configTransfer(w fyne.Window, prefs fyne.Preferences, note *widget.Label) fyne.CanvasObject {
+	cp, ok := prefs.(*configPrefs)
+	if !ok {
+		return widget.NewLabel(tr("Settings can't be exported: the app folder is unavailable."))
+	}
+	exportBtn := widget.NewButton(tr("Export Settings..."), func() {
+		save := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+			if err != nil || wc == nil {
+				return
+			}
+			defer wc.Close()
+			if err := cp.export(wc); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			dialog.ShowInformation(tr("Settings exported"), wc.URI().Path(), w)
+		}, w)
+		save.SetFileName("ytgui-settings.json")
+		save.Show()
+	})
+	importBtn := widget.NewButton(tr("Import Settings..."), func() {
+		open := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+			if err != nil || rc == nil {
+				return
+			}
+			defer rc.Close()
+			if err := cp.importFrom(rc); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			note.SetText(tr("Settings imported. Restart ytgui to apply them."))
+		}, w)
+		open.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+		open.Show()
+	})
+	return container.NewHBox(exportBtn, importBtn)
+}
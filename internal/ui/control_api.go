@@ -0,0 +1,318 @@
+package ui
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	prefControlAPIEnabled = "control_api_enabled"
+	prefControlAPIAddr    = "control_api_addr"
+	prefControlAPIToken   = "control_api_token"
+
+	defaultControlAPIAddr = "127.0.0.1:8766"
+	controlEventInterval  = 500 * time.Millisecond
+
+	// eventsProtocol is the WebSocket subprotocol of /api/events. Browsers
+	// can't set headers on a WebSocket, so they offer the token as a second
+	// subprotocol, tokenProtocolPrefix followed by the token.
+	eventsProtocol      = "ytgui"
+	tokenProtocolPrefix = "ytgui-token."
+)
+
+type enqueueRequest struct {
+	URL       string  `json:"url"`
+	Quality   *string `json:"quality,omitempty"`
+	Profile   *string `json:"profile,omitempty"`
+	Folder    *string `json:"folder,omitempty"`
+	Playlist  *bool   `json:"playlist,omitempty"`
+	Subtitles *bool   `json:"subtitles,omitempty"`
+	// Preset names a saved preset applied before the fields above.
+	Preset *string `json:"preset,omitempty"`
+}
+
+func (r enqueueRequest) apply(s *jobSettings) {
+	if r.Quality != nil {
+		s.Quality = *r.Quality
+	}
+	if r.Profile != nil {
+		s.Profile = *r.Profile
+	}
+	if r.Folder != nil {
+		s.Folder = *r.Folder
+	}
+	if r.Playlist != nil {
+		s.Playlist = *r.Playlist
+	}
+	if r.Subtitles != nil {
+		s.Subtitles = *r.Subtitles
+	}
+}
+
+func newAPIToken() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// controlAPI is the optional HTTP API that can enqueue, inspect and cancel
+// jobs. Unlike the snapshot endpoint it can change state, so every request
+// must carry the bearer token shown in settings.
+type controlAPI struct {
+	queue   *downloadQueue
+	enqueue func(enqueueRequest) (int64, error)
+	presets func() []string
+
+	mu    sync.Mutex
+	srv   *http.Server
+	token string
+	subs  map[*wsConn]struct{}
+	dirty bool
+	stopC chan struct{}
+}
+
+func newControlAPI(q *downloadQueue, enqueue func(enqueueRequest) (int64, error), presets func() []string) *controlAPI {
+	return &controlAPI{
+		queue:   q,
+		enqueue: enqueue,
+		presets: presets,
+		subs:    make(map[*wsConn]struct{}),
+	}
+}
+
+// authorized checks the bearer token. Only the push page may take it from
+// the query, since it is opened from a QR code; it changes nothing itself
+// and sends the token back as a header. Query strings end up in logs and
+// browser history, so the API proper doesn't accept them. The events
+// stream also takes it from Sec-WebSocket-Protocol, the one header a
+// browser lets a page set on a WebSocket.
+func (api *controlAPI) authorized(r *http.Request) bool {
+	api.mu.Lock()
+	token := api.token
+	api.mu.Unlock()
+	if token == "" {
+		return false
+	}
+	got := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if got == "" && r.Method == http.MethodGet && r.URL.Path == "/push" {
+		got = r.URL.Query().Get("token")
+	}
+	if got == "" && r.Method == http.MethodGet && r.URL.Path == "/api/events" {
+		for _, v := range r.Header.Values("Sec-WebSocket-Protocol") {
+			for _, p := range strings.Split(v, ",") {
+				if t, ok := strings.CutPrefix(strings.TrimSpace(p), tokenProtocolPrefix); ok {
+					got = t
+				}
+			}
+		}
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (api *controlAPI) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	auth := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !api.authorized(r) {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing or invalid token"})
+				return
+			}
+			h(w, r)
+		}
+	}
+	mux.HandleFunc("GET /push", auth(api.servePushPage))
+	mux.HandleFunc("GET /api/presets", auth(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, api.presets())
+	}))
+	mux.HandleFunc("GET /api/jobs", auth(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, buildQueueSnapshot(api.queue.snapshot()))
+	}))
+	mux.HandleFunc("GET /api/jobs/{id}", auth(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid job id"})
+			return
+		}
+		for _, j := range buildQueueSnapshot(api.queue.snapshot()).Jobs {
+			if j.ID == id {
+				writeJSON(w, http.StatusOK, j)
+				return
+			}
+		}
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "job not found"})
+	}))
+	mux.HandleFunc("POST /api/jobs", auth(func(w http.ResponseWriter, r *http.Request) {
+		var req enqueueRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+			return
+		}
+		target, ok := parseLaunchArg(req.URL)
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "url must be an http(s) link"})
+			return
+		}
+		req.URL = target
+		id, err := api.enqueue(req)
+		if err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusAccepted, map[string]int64{"id": id})
+	}))
+	mux.HandleFunc("POST /api/jobs/{id}/cancel", auth(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid job id"})
+			return
+		}
+		if !api.queue.cancelJob(id) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "job not found or already finished"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "canceled"})
+	}))
+	mux.HandleFunc("GET /api/events", auth(func(w http.ResponseWriter, r *http.Request) {
+		if !sameOrigin(r) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "cross-origin WebSocket refused"})
+			return
+		}
+		c, err := upgradeWebSocket(w, r, eventsProtocol)
+		if err != nil {
+			return
+		}
+		api.mu.Lock()
+		api.subs[c] = struct{}{}
+		api.mu.Unlock()
+		api.markDirty()
+		c.readLoop()
+		api.mu.Lock()
+		delete(api.subs, c)
+		api.mu.Unlock()
+		c.Close()
+	}))
+	return mux
+}
+
+// sameOrigin reports whether a request without an Origin header (not from a
+// browser) or from a page served by this API, such as the push page, made
+// r. Browsers don't apply the same-origin policy to WebSockets.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// markDirty flags that queue state changed; events are coalesced and pushed
+// at most every controlEventInterval so progress lines don't flood clients.
+func (api *controlAPI) markDirty() {
+	api.mu.Lock()
+	api.dirty = true
+	api.mu.Unlock()
+}
+
+func (api *controlAPI) publishLoop(stop chan struct{}) {
+	ticker := time.NewTicker(controlEventInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		api.mu.Lock()
+		if !api.dirty || len(api.subs) == 0 {
+			api.mu.Unlock()
+			continue
+		}
+		api.dirty = false
+		subs := make([]*wsConn, 0, len(api.subs))
+		for c := range api.subs {
+			subs = append(subs, c)
+		}
+		api.mu.Unlock()
+
+		payload, err := json.Marshal(map[string]any{
+			"type":     "queue",
+			"snapshot": buildQueueSnapshot(api.queue.snapshot()),
+		})
+		if err != nil {
+			continue
+		}
+		for _, c := range subs {
+			if err := c.writeText(payload); err != nil {
+				c.Close()
+			}
+		}
+	}
+}
+
+func (api *controlAPI) start(addr, token string) error {
+	api.stop()
+	if strings.TrimSpace(token) == "" {
+		return errors.New("control API token is empty")
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: api.routes(), ReadHeaderTimeout: 5 * time.Second}
+	stop := make(chan struct{})
+	api.mu.Lock()
+	api.srv = srv
+	api.token = token
+	api.stopC = stop
+	api.mu.Unlock()
+	go api.publishLoop(stop)
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ln.Close()
+		}
+	}()
+	return nil
+}
+
+func (api *controlAPI) stop() {
+	api.mu.Lock()
+	srv := api.srv
+	stop := api.stopC
+	api.srv = nil
+	api.stopC = nil
+	subs := api.subs
+	api.subs = make(map[*wsConn]struct{})
+	api.mu.Unlock()
+	if stop != nil {
+		close(stop)
+	}
+	for c := range subs {
+		c.Close()
+	}
+	if srv == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_ = srv.Shutdown(ctx)
+}
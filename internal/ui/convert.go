@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const prefConvertTarget = "convert_target"
+
+// convertPanel converts local media files with the bundled ffmpeg. Files
+// come from Browse... or from being dropped on the window.
+type convertPanel struct {
+	box *fyne.Container
+
+	source   string
+	file     *widget.Label
+	start    *widget.Button
+	cancelBt *widget.Button
+	progress *widget.ProgressBar
+	status   *widget.Label
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// newConvertPanel builds the Convert tab. tools returns the ffmpeg and
+// ffprobe paths, empty until they are installed.
+func newConvertPanel(w fyne.Window, prefs fyne.Preferences, tools func() (ffmpeg, ffprobe string), logf func(string)) *convertPanel {
+	p := &convertPanel{
+		file:     widget.NewLabel(tr("Drop a media file on the window or browse for one.")),
+		progress: widget.NewProgressBar(),
+		status:   widget.NewLabel(""),
+	}
+	p.file.Truncation = fyne.TextTruncateEllipsis
+	var names []string
+	for _, t := range downloader.ConvertTargets {
+		names = append(names, t.Name)
+	}
+	target := widget.NewSelect(trList(names), func(shown string) {
+		prefs.SetString(prefConvertTarget, untr(names, shown))
+	})
+	target.SetSelected(tr(selectedOption(prefs, prefConvertTarget, names, names[0])))
+	result := newFileActions(logf)
+
+	browse := widget.NewButtonWithIcon(tr("Browse..."), theme.FolderOpenIcon(), func() {
+		dialog.ShowFileOpen(func(rc fyne.URIReadCloser, err error) {
+			if err != nil || rc == nil {
+				return
+			}
+			rc.Close()
+			p.setFile(rc.URI().Path())
+		}, w)
+	})
+	p.start = widget.NewButton(tr("Convert"), func() {
+		ffmpeg, ffprobe := tools()
+		if ffmpeg == "" {
+			p.status.SetText(tr("Preparing required tools..."))
+			return
+		}
+		var t downloader.ConvertTarget
+		for _, c := range downloader.ConvertTargets {
+			if c.Name == untr(names, target.Selected) {
+				t = c
+			}
+		}
+		result.set("")
+		go p.run(ffmpeg, ffprobe, t, result, logf)
+	})
+	p.start.Disable()
+	p.cancelBt = widget.NewButton(tr("Cancel"), func() {
+		p.mu.Lock()
+		if p.cancel != nil {
+			p.cancel()
+		}
+		p.mu.Unlock()
+	})
+	p.cancelBt.Disable()
+
+	p.box = container.NewVBox(
+		container.NewBorder(nil, nil, nil, browse, p.file),
+		widget.NewForm(widget.NewFormItem(tr("Convert to"), target)),
+		container.NewHBox(p.start, p.cancelBt),
+		p.progress,
+		p.status,
+		result.box,
+	)
+	return p
+}
+
+func (p *convertPanel) setFile(path string) {
+	p.source = path
+	p.file.SetText(path)
+	p.status.SetText("")
+	p.progress.SetValue(0)
+	p.mu.Lock()
+	running := p.cancel != nil
+	p.mu.Unlock()
+	if !running {
+		p.start.Enable()
+	}
+}
+
+func (p *convertPanel) run(ffmpeg, ffprobe string, target downloader.ConvertTarget, result *fileActions, logf func(string)) {
+	src := p.source
+	ctx, cancel := context.WithCancel(context.Background())
+	p.mu.Lock()
+	p.cancel = cancel
+	p.mu.Unlock()
+	runOnMain(func() {
+		p.start.Disable()
+		p.cancelBt.Enable()
+		p.progress.SetValue(0)
+	})
+	defer func() {
+		cancel()
+		p.mu.Lock()
+		p.cancel = nil
+		p.mu.Unlock()
+		runOnMain(func() {
+			p.start.Enable()
+			p.cancelBt.Disable()
+		})
+	}()
+
+	var duration float64
+	if ffprobe != "" {
+		if probe, err := downloader.ProbeMedia(ffprobe, src); err == nil {
+			duration = probe.Duration
+		}
+	}
+	dst := downloader.ConvertOutputName(src, target)
+	runOnMain(func() { p.status.SetText(trf("Converting %s to %s...", filepath.Base(src), tr(target.Name))) })
+	err := downloader.ConvertMedia(ctx, ffmpeg, src, dst, target, duration, func(v float64) {
+		runOnMain(func() { p.progress.SetValue(v) })
+	})
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled) && err != nil:
+		runOnMain(func() { p.status.SetText(tr("Conversion canceled")) })
+	case err != nil:
+		logf(trf("Conversion failed: %v", err))
+		runOnMain(func() { p.status.SetText(tr("Conversion failed")) })
+	default:
+		logf(trf("Converted %s to %s", src, dst))
+		runOnMain(func() {
+			p.progress.SetValue(1)
+			p.status.SetText(tr("Conversion complete"))
+			result.set(dst)
+		})
+	}
+}
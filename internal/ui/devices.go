@@ -0,0 +1,174 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	prefDeviceProfile = "device_profile"
+
+	deviceAny = "Any device"
+)
+
+// deviceProfiles tune downloads for what the file will be played on.
+var deviceProfiles = []string{deviceAny, "Smart TV", "iPhone", "Old laptop"}
+
+// deviceLimits is what a playback device can decode. H264Only devices get
+// the Widely Compatible profile whatever was picked.
+type deviceLimits struct {
+	MaxHeight int
+	MaxFPS    int
+	NoAV1     bool
+	H264Only  bool
+}
+
+var deviceLimitsByName = map[string]deviceLimits{
+	// Most TVs sold before 2021 have no AV1 decoder but handle VP9 at 4K.
+	"Smart TV": {NoAV1: true},
+	// Photos, Files and QuickTime play H.264 in MP4; MKV and VP9 need a
+	// third-party player.
+	"iPhone": {H264Only: true},
+	// Software decoding above 1080p30 stutters on older CPUs.
+	"Old laptop": {MaxHeight: 1080, MaxFPS: 30, NoAV1: true},
+}
+
+// deviceAdjust returns the output profile and frame rate cap to use for
+// device.
+func deviceAdjust(device, profile string, maxFPS int) (string, int) {
+	d, ok := deviceLimitsByName[device]
+	if !ok {
+		return profile, maxFPS
+	}
+	if d.H264Only {
+		profile = profileCompatible
+	}
+	if d.MaxFPS > 0 && (maxFPS <= 0 || maxFPS > d.MaxFPS) {
+		maxFPS = d.MaxFPS
+	}
+	return profile, maxFPS
+}
+
+// fitDevice restricts the video parts of a format selector to what device
+// can play. Alternatives asking for AV1 are dropped on devices without an
+// AV1 decoder; the unrestricted last alternative stays as a fallback so a
+// video never ends up with no format at all.
+func fitDevice(args []string, device string) []string {
+	d, ok := deviceLimitsByName[device]
+	if !ok {
+		return args
+	}
+	filter := ""
+	if d.MaxHeight > 0 {
+		filter += fmt.Sprintf("[height<=?%d]", d.MaxHeight)
+	}
+	if d.NoAV1 {
+		filter += "[vcodec!^=av01]"
+	}
+	if filter == "" {
+		return args
+	}
+	out := append([]string(nil), args...)
+	for i := 0; i+1 < len(out); i++ {
+		if out[i] != "-f" {
+			continue
+		}
+		alts := strings.Split(out[i+1], "/")
+		var fitted []string
+		for _, alt := range alts {
+			if d.NoAV1 && strings.Contains(alt, "vcodec^=av01") {
+				continue
+			}
+			parts := strings.Split(alt, "+")
+			for j, p := range parts {
+				if !strings.HasPrefix(p, "bestaudio") && !strings.HasPrefix(p, "ba") {
+					parts[j] = p + filter
+				}
+			}
+			fitted = append(fitted, strings.Join(parts, "+"))
+		}
+		out[i+1] = strings.Join(append(fitted, alts[len(alts)-1]), "/")
+		break
+	}
+	return out
+}
+
+// deviceFormatSelector is the -f expression a job's settings download with.
+func deviceFormatSelector(s jobSettings) string {
+	profile, maxFPS := deviceAdjust(s.Device, s.Profile, s.MaxFPS)
+	return formatSelectorOf(fitDevice(formatFromChoice(s.Quality, profile, maxFPS), s.Device))
+}
+
+// compatWarning is a settings combination likely to give a file the target
+// player can't handle. FixProfile means switching to the Widely Compatible
+// profile resolves it.
+type compatWarning struct {
+	Text       string
+	FixProfile bool
+}
+
+func compatibilityWarnings(s jobSettings) []compatWarning {
+	if s.Quality == "Audio Only" {
+		return nil
+	}
+	var out []compatWarning
+	d := deviceLimitsByName[s.Device]
+	if s.Profile == profileSmaller {
+		switch {
+		case d.H264Only:
+			out = append(out, compatWarning{Text: trf("The %s plays H.264 in MP4 only, so these downloads use Widely Compatible (H.264/AAC).", tr(s.Device)), FixProfile: true})
+		case d.NoAV1:
+			out = append(out, compatWarning{Text: trf("%s usually can't decode AV1; VP9 is used instead where available. Widely Compatible (H.264/AAC) plays everywhere.", tr(s.Device)), FixProfile: true})
+		}
+		if s.Subtitles && !s.KeepSubs && !d.H264Only {
+			out = append(out, compatWarning{Text: tr("Smaller File Size saves MKV. Players that only read MP4 subtitles (QuickTime, Photos, many TVs) won't show the embedded subtitles; switch to Widely Compatible or keep the subtitle files."), FixProfile: true})
+		}
+	}
+	profile, _ := deviceAdjust(s.Device, s.Profile, s.MaxFPS)
+	if profile == profileCompatible && s.Subtitles && s.SubFormat == "ass" {
+		out = append(out, compatWarning{Text: tr("MP4 can't keep ASS subtitle styling, so it is lost when embedding. Pick Smaller File Size (MKV) or keep the subtitle files.")})
+	}
+	return out
+}
+
+// compatNotice lists compatibility warnings under the profile selector, with
+// a button applying the suggested profile when that helps.
+type compatNotice struct {
+	box   *fyne.Container
+	label *widget.Label
+	fix   *widget.Button
+}
+
+func newCompatNotice(useCompatible func()) *compatNotice {
+	n := &compatNotice{label: widget.NewLabel("")}
+	n.label.Wrapping = fyne.TextWrapWord
+	n.label.Importance = widget.WarningImportance
+	n.fix = widget.NewButton(tr("Use Widely Compatible"), useCompatible)
+	n.box = container.NewBorder(nil, nil, nil, container.NewVBox(n.fix), n.label)
+	n.box.Hide()
+	return n
+}
+
+func (n *compatNotice) update(warnings []compatWarning) {
+	if len(warnings) == 0 {
+		n.box.Hide()
+		return
+	}
+	lines := make([]string, len(warnings))
+	fixable := false
+	for i, w := range warnings {
+		lines[i] = w.Text
+		fixable = fixable || w.FixProfile
+	}
+	n.label.SetText(strings.Join(lines, "\n"))
+	if fixable {
+		n.fix.Show()
+	} else {
+		n.fix.Hide()
+	}
+	n.box.Show()
+}
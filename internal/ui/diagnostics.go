@@ -0,0 +1,256 @@
+package ui
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	diagnosticsTimeout = 10 * time.Second
+	reachabilityURL    = "https://github.com"
+)
+
+// redactedPrefs lists preference keys whose values never leave the machine
+// in a support bundle: tokens, and the free-form extractor arguments and
+// feed URLs, which can carry tokens and private playlist links.
+var redactedPrefs = []string{prefControlAPIToken, prefYouTubePOToken, prefExtractorArgs, prefFeeds}
+
+// diagnosticsEnv is what the diagnostics page needs from the running app.
+type diagnosticsEnv struct {
+	app    fyne.App
+	window fyne.Window
+	tools  func() (ytdlp, ffmpeg string)
+	folder func() string
+	logs   func() map[string]string
+}
+
+func toolVersion(path string, args ...string) string {
+	if strings.TrimSpace(path) == "" {
+		return tr("not installed yet")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+	defer cancel()
+	out, err := downloader.Output(ctx, path, args...)
+	if err != nil {
+		return trf("error: %v", err)
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(line)
+}
+
+func checkReachable(url string) string {
+	client := &http.Client{Timeout: diagnosticsTimeout}
+	start := time.Now()
+	resp, err := client.Head(url)
+	if err != nil {
+		return trf("unreachable: %v", err)
+	}
+	resp.Body.Close()
+	return trf("reachable (HTTP %d, %s)", resp.StatusCode, time.Since(start).Round(time.Millisecond))
+}
+
+func diskSpaceLine(path string) string {
+	if strings.TrimSpace(path) == "" {
+		return tr("unknown")
+	}
+	free, err := freeDiskSpace(path)
+	if err != nil {
+		return trf("%s (free space unknown: %v)", path, err)
+	}
+	return trf("%s (%s free)", path, formatBytes(free))
+}
+
+// diagnosticsReport gathers versions, connectivity and disk information. It
+// shells out and touches the network, so call it off the UI goroutine.
+func diagnosticsReport(env *diagnosticsEnv) string {
+	ytdlp, ffmpeg := env.tools()
+	cacheDir, err := downloader.AppDir()
+	if err != nil {
+		cacheDir = ""
+	}
+	version := "(devel)"
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		version = info.Main.Version
+	}
+	lines := []string{
+		fmt.Sprintf("ytgui: %s (%s/%s, %s)", version, runtime.GOOS, runtime.GOARCH, runtime.Version()),
+		"yt-dlp: " + toolVersion(ytdlp, "--version"),
+		"ffmpeg: " + toolVersion(ffmpeg, "-version"),
+		"github.com: " + checkReachable(reachabilityURL),
+		tr("Cache folder") + ": " + diskSpaceLine(cacheDir),
+		tr("Download folder") + ": " + diskSpaceLine(env.folder()),
+	}
+	return strings.Join(lines, "\n")
+}
+
+func preferencesPath(a fyne.App) string {
+	if c, ok := a.(configApp); ok {
+		return c.prefs.path
+	}
+	root := a.Storage().RootURI()
+	if root == nil {
+		return ""
+	}
+	return filepath.Join(root.Path(), "preferences.json")
+}
+
+// redactedPreferences returns the preferences file with secrets blanked out.
+// It reads both config.json and the older flat Fyne preference file.
+func redactedPreferences(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file map[string]any
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	prefs := file
+	if settings, ok := file["settings"].(map[string]any); ok {
+		prefs = settings
+	}
+	for _, key := range redactedPrefs {
+		if _, ok := prefs[key]; ok {
+			prefs[key] = "REDACTED"
+		}
+	}
+	return json.MarshalIndent(file, "", "  ")
+}
+
+func writeSupportBundle(dst io.Writer, report string, logs map[string]string, prefsPath string) error {
+	zw := zip.NewWriter(dst)
+	add := func(name string, data []byte) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+
+	if err := add("diagnostics.txt", []byte(report+"\n")); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(logs))
+	for name := range logs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := add("logs/"+name, []byte(logs[name])); err != nil {
+			return err
+		}
+	}
+	if prefsPath != "" {
+		data, err := redactedPreferences(prefsPath)
+		if err != nil {
+			data = []byte(fmt.Sprintf("could not read preferences: %v\n", err))
+		}
+		if err := add("config/"+filepath.Base(prefsPath), data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func diagnosticsPage(env *diagnosticsEnv) func() fyne.CanvasObject {
+	prefs := env.app.Preferences()
+	return func() fyne.CanvasObject {
+		report := widget.NewLabel(tr("Run the checks to collect tool versions, connectivity and free disk space."))
+		report.Wrapping = fyne.TextWrapWord
+		memory := widget.NewLabel(memoryReport())
+		lastReport := ""
+
+		var runChecks, export *widget.Button
+		runChecks = widget.NewButton(tr("Run Checks"), func() {
+			runChecks.Disable()
+			report.SetText(tr("Running checks..."))
+			go func() {
+				text := diagnosticsReport(env)
+				runOnMain(func() {
+					lastReport = text
+					report.SetText(text)
+					runChecks.Enable()
+				})
+			}()
+		})
+		export = widget.NewButton(tr("Export Support Bundle..."), func() {
+			save := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+				if err != nil || wc == nil {
+					return
+				}
+				export.Disable()
+				go func() {
+					defer wc.Close()
+					text := lastReport
+					if text == "" {
+						text = diagnosticsReport(env)
+					}
+					var buf bytes.Buffer
+					err := writeSupportBundle(&buf, text+"\n\n"+memoryReport(), env.logs(), preferencesPath(env.app))
+					if err == nil {
+						_, err = wc.Write(buf.Bytes())
+					}
+					runOnMain(func() {
+						export.Enable()
+						if err != nil {
+							dialog.ShowError(err, env.window)
+							return
+						}
+						dialog.ShowInformation(tr("Support bundle saved"), wc.URI().Path(), env.window)
+					})
+				}()
+			}, env.window)
+			save.SetFileName(fmt.Sprintf("ytgui-support-%s.zip", time.Now().Format("20060102-150405")))
+			save.Show()
+		})
+		refresh := widget.NewButton(tr("Refresh"), func() {
+			memory.SetText(memoryReport())
+		})
+		freeNow := widget.NewButton(tr("Free Memory Now"), func() {
+			debug.FreeOSMemory()
+			memory.SetText(memoryReport())
+		})
+		lowMem := widget.NewCheck(tr("Low-memory mode"), nil)
+		lowMem.SetChecked(prefs.Bool(prefLowMemory))
+		lowMem.OnChanged = func(on bool) {
+			prefs.SetBool(prefLowMemory, on)
+			setLowMemoryMode(on)
+			memory.SetText(memoryReport())
+		}
+		return container.NewVBox(
+			report,
+			container.NewHBox(runChecks, export),
+			widget.NewSeparator(),
+			memory,
+			container.NewHBox(refresh, freeNow),
+			lowMem,
+			widget.NewLabel(trf("Keeps only the last %d lines of each log, skips thumbnails and writes history straight to disk. Intended for very long sessions on machines with little RAM.", lowMemoryLogLines)),
+		)
+	}
+}
+
+func showDiagnostics(env *diagnosticsEnv) {
+	d := dialog.NewCustom(tr("Diagnostics"), tr("Close"), container.NewVScroll(diagnosticsPage(env)()), env.window)
+	d.Resize(fyne.NewSize(600, 460))
+	d.Show()
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !windows
+
+package ui
+
+import "errors"
+
+func freeDiskSpace(path string) (int64, error) {
+	return -1, errors.New("not supported on this platform")
+}
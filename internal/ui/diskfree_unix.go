@@ -0,0 +1,13 @@
+//go:build linux || darwin
+
+package ui
+
+import "syscall"
+
+func freeDiskSpace(path string) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return -1, err
+	}
+	return int64(uint64(st.Bavail) * uint64(st.Bsize)), nil
+}
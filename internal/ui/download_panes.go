@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+// downloadPanes manages extra download tabs for juggling unrelated
+// downloads side by side. Each tab has its own link, preset, folder and
+// log, but queues into the shared download queue with the tools the main
+// window prepared, so its jobs also show in the Queue tab and share the
+// parallel download limit.
+type downloadPanes struct {
+	w     fyne.Window
+	prefs fyne.Preferences
+	bus   *downloader.Bus
+	ready func() bool
+	// settings returns the main window's current settings; a tab starts
+	// from them and applies its own preset and folder on top.
+	settings func() jobSettings
+	enqueue  func(urls []string, settings []jobSettings, queued func([]*downloadJob))
+	// show swaps the window content: tabs holding the main view and the
+	// extra panes, or nil once the last extra pane is closed.
+	show func(tabs *container.AppTabs)
+
+	main  fyne.CanvasObject
+	tabs  *container.AppTabs
+	seq   int
+	mu    sync.Mutex
+	panes []*downloadPane
+}
+
+type downloadPane struct {
+	tab    *container.TabItem
+	url    *widget.Entry
+	preset *widget.Select
+	folder string
+	status *widget.Label
+	log    *widget.Entry
+	logMu  sync.Mutex
+
+	mu          sync.Mutex
+	jobs        map[int64]bool
+	unsubscribe func()
+}
+
+// open adds a new download tab and selects it.
+func (d *downloadPanes) open() {
+	d.seq++
+	p := &downloadPane{
+		url:    widget.NewEntry(),
+		folder: d.settings().Folder,
+		status: widget.NewLabel(tr("Idle")),
+		log:    newJobLogEntry(fyne.TextWrapWord),
+		jobs:   make(map[int64]bool),
+	}
+	p.url.SetPlaceHolder(tr("Paste video URL"))
+	names := []string{tr(watchCurrentName)}
+	for _, preset := range loadPresets(d.prefs) {
+		names = append(names, preset.Name)
+	}
+	p.preset = widget.NewSelect(names, nil)
+	p.preset.SetSelected(names[0])
+
+	var folderBtn *widget.Button
+	folderBtn = widget.NewButton(folderButtonText(p.folder), func() {
+		dialog.ShowFolderOpen(func(lu fyne.ListableURI, err error) {
+			if err != nil || lu == nil {
+				return
+			}
+			p.folder = lu.Path()
+			folderBtn.SetText(folderButtonText(p.folder))
+		}, d.w)
+	})
+	download := widget.NewButton(tr("Download"), func() { d.download(p) })
+	p.url.OnSubmitted = func(string) { d.download(p) }
+	closeBtn := widget.NewButtonWithIcon(tr("Close Tab"), theme.CancelIcon(), func() { d.close(p) })
+
+	p.unsubscribe = d.bus.Subscribe(p.showEvent)
+	p.tab = container.NewTabItem(trf("Download %d", d.seq), container.NewBorder(
+		container.NewVBox(
+			p.url,
+			folderBtn,
+			widget.NewForm(widget.NewFormItem(tr("Download with"), p.preset)),
+			container.NewHBox(download, closeBtn),
+			p.status,
+		),
+		nil,
+		nil,
+		nil,
+		p.log,
+	))
+
+	d.mu.Lock()
+	d.panes = append(d.panes, p)
+	d.mu.Unlock()
+	if d.tabs == nil {
+		d.tabs = container.NewAppTabs(container.NewTabItem(tr("Main"), d.main))
+		d.show(d.tabs)
+	}
+	d.tabs.Append(p.tab)
+	d.tabs.Select(p.tab)
+	d.w.Canvas().Focus(p.url)
+}
+
+// download queues the pane's link with the pane's preset and folder.
+func (d *downloadPanes) download(p *downloadPane) {
+	if !d.ready() {
+		p.status.SetText(tr("Preparing required tools..."))
+		return
+	}
+	link, _ := normalizeVideoURL(p.url.Text)
+	if link == "" {
+		p.status.SetText(tr("Missing URL"))
+		return
+	}
+	settings := d.settings()
+	if preset, ok := findPreset(d.prefs, p.preset.Selected); ok && p.preset.Selected != tr(watchCurrentName) {
+		preset.applyTo(&settings)
+	}
+	settings.Folder = strings.TrimSpace(p.folder)
+	d.enqueue([]string{link}, []jobSettings{settings}, func(jobs []*downloadJob) {
+		p.mu.Lock()
+		for _, job := range jobs {
+			p.jobs[job.ID] = true
+		}
+		p.mu.Unlock()
+		for _, job := range jobs {
+			appendLog(p.log, trf("Queued job #%d: %s", job.ID, job.URL), &p.logMu)
+		}
+	})
+	p.url.SetText("")
+}
+
+// finished logs the outcome of a job queued from one of the panes.
+func (d *downloadPanes) finished(job downloadJob) {
+	d.mu.Lock()
+	panes := append([]*downloadPane(nil), d.panes...)
+	d.mu.Unlock()
+	for _, p := range panes {
+		if !p.owns(job.ID) {
+			continue
+		}
+		p.mu.Lock()
+		delete(p.jobs, job.ID)
+		p.mu.Unlock()
+		line := jobSummaryLine(job.ID, job.Err)
+		appendLog(p.log, line, &p.logMu)
+		runOnMain(func() { p.status.SetText(line) })
+	}
+}
+
+func (d *downloadPanes) close(p *downloadPane) {
+	p.unsubscribe()
+	d.mu.Lock()
+	for i, other := range d.panes {
+		if other == p {
+			d.panes = append(d.panes[:i:i], d.panes[i+1:]...)
+			break
+		}
+	}
+	left := len(d.panes)
+	d.mu.Unlock()
+	d.tabs.Remove(p.tab)
+	if left == 0 {
+		// The main view is moved back out of the tabs, so the window looks
+		// as it did before any pane was opened.
+		d.tabs = nil
+		d.show(nil)
+	}
+}
+
+func (p *downloadPane) owns(id int64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.jobs[id]
+}
+
+// showEvent shows the pane's own jobs in its log and status line. Closing
+// the pane doesn't cancel them; they stay in the shared queue.
+func (p *downloadPane) showEvent(e downloader.Event) {
+	if e.JobID == 0 || !p.owns(e.JobID) {
+		return
+	}
+	switch e.Kind {
+	case downloader.EventJobStarted:
+		appendLog(p.log, trf("#%d started: %s", e.JobID, e.Text), &p.logMu)
+	case downloader.EventStage:
+		runOnMain(func() { p.status.SetText(fmt.Sprintf("#%d: %s", e.JobID, e.Text)) })
+	case downloader.EventLog:
+		appendLog(p.log, e.Text, &p.logMu)
+	}
+}
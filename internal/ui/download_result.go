@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ytgui/internal/downloader"
+)
+
+// downloadResult is what is known about a finished file: its size on disk,
+// what ffprobe reports about it, and how long the download took.
+type downloadResult struct {
+	Path    string
+	Size    int64
+	Elapsed time.Duration
+	// Probed is set when ffprobe was run; ProbeErr is its error, if any.
+	Probed   bool
+	Probe    downloader.MediaProbe
+	ProbeErr error
+}
+
+// newDownloadResult looks at the file at path; ffprobe may be empty when it
+// isn't available.
+func newDownloadResult(ffprobe, path string, elapsed time.Duration) downloadResult {
+	r := downloadResult{Path: path, Elapsed: elapsed}
+	if info, err := os.Stat(path); err == nil {
+		r.Size = info.Size()
+	}
+	if ffprobe != "" {
+		r.Probed = true
+		r.Probe, r.ProbeErr = downloader.ProbeMedia(ffprobe, path)
+	}
+	return r
+}
+
+// summary describes the file in one line, e.g. "MP4, 1920×1080, 3:45,
+// 45.2 MiB, took 1m20s". Details ffprobe couldn't provide are left out.
+func (r downloadResult) summary() string {
+	var parts []string
+	if ext := strings.TrimPrefix(filepath.Ext(r.Path), "."); ext != "" {
+		parts = append(parts, strings.ToUpper(ext))
+	}
+	if r.Probe.Width > 0 && r.Probe.Height > 0 {
+		parts = append(parts, fmt.Sprintf("%d×%d", r.Probe.Width, r.Probe.Height))
+	}
+	if r.Probe.Duration > 0 {
+		parts = append(parts, formatDuration(r.Probe.Duration))
+	}
+	if r.Size > 0 {
+		parts = append(parts, formatBytes(r.Size))
+	}
+	parts = append(parts, trf("took %s", r.Elapsed.Round(time.Second)))
+	return strings.Join(parts, ", ")
+}
+
+// logSavedFiles lists the files a playlist download produced, followed by
+// their count and total size.
+func logSavedFiles(files []string, ev publisher) {
+	var total int64
+	for _, f := range files {
+		ev.log(trf("Saved to: %s", f))
+		if info, err := os.Stat(f); err == nil {
+			total += info.Size()
+		}
+	}
+	ev.log(trf("Saved %d file(s), %s in total.", len(files), formatBytes(total)))
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const prefAskFileName = "ask_file_name"
+
+// downloadSummary is what the pre-download summary shows about a video.
+type downloadSummary struct {
+	Title    string
+	Channel  string
+	Duration float64
+	Quality  string
+	Folder   string
+	Rules    downloader.NameRules
+}
+
+// askFileName shows the pre-download summary with the file name open for
+// editing. base is the name BuildFileName picked, without the extension,
+// which stays fixed. It returns the name to download to, or false when the
+// user canceled the download.
+func askFileName(w fyne.Window, s downloadSummary, base, ext string) (string, bool) {
+	type choice struct {
+		name string
+		ok   bool
+	}
+	choiceCh := make(chan choice, 1)
+	runOnMain(func() {
+		name := widget.NewEntry()
+		name.SetText(base)
+		name.Validator = func(text string) error { return downloader.CheckFileName(text, s.Rules) }
+		nameItem := widget.NewFormItem(tr("File name"), name)
+		nameItem.HintText = trf("Saved as .%s", ext)
+		d := dialog.NewForm(tr("Download Summary"), tr("Download"), tr("Cancel"), []*widget.FormItem{
+			widget.NewFormItem(tr("Title"), widget.NewLabel(s.Title)),
+			widget.NewFormItem(tr("Channel"), widget.NewLabel(valueOr(s.Channel, "-"))),
+			widget.NewFormItem(tr("Length"), widget.NewLabel(formatDuration(s.Duration))),
+			widget.NewFormItem(tr("Quality"), widget.NewLabel(s.Quality)),
+			widget.NewFormItem(tr("Folder"), widget.NewLabel(s.Folder)),
+			nameItem,
+		}, func(ok bool) {
+			choiceCh <- choice{name: name.Text, ok: ok}
+		}, w)
+		d.Resize(fyne.NewSize(560, d.MinSize().Height))
+		d.Show()
+		w.Canvas().Focus(name)
+	})
+	c := <-choiceCh
+	return c.name, c.ok
+}
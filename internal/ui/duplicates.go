@@ -0,0 +1,192 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	prefDuplicatePolicy = "duplicate_policy"
+
+	duplicateAsk     = "Ask"
+	duplicateRename  = "Rename"
+	duplicateReplace = "Replace"
+	duplicateSkip    = "Skip"
+	// duplicateCancel is only ever an answer from the dialog: it cancels
+	// the whole job, where Skip just keeps the existing file.
+	duplicateCancel = "Cancel job"
+)
+
+var duplicatePolicies = []string{duplicateAsk, duplicateRename, duplicateReplace, duplicateSkip}
+
+// parseDuplicatePolicy accepts the policy names case-insensitively, as batch
+// files and API clients tend to send "skip" rather than "Skip".
+func parseDuplicatePolicy(s string) (string, bool) {
+	for _, p := range duplicatePolicies {
+		if strings.EqualFold(strings.TrimSpace(s), p) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// duplicateResolver decides what happens to an existing target file. An
+// "apply to all" answer from the dialog sticks until the queue drains, so a
+// batch run only asks once.
+type duplicateResolver struct {
+	mu      sync.Mutex
+	applied string
+	// seenApplied is the "apply to all" answer for videos downloaded before.
+	seenApplied string
+}
+
+func (r *duplicateResolver) resolve(w fyne.Window, path, policy string) string {
+	if policy != "" && policy != duplicateAsk {
+		return policy
+	}
+	r.mu.Lock()
+	applied := r.applied
+	r.mu.Unlock()
+	if applied != "" {
+		return applied
+	}
+	choice, all := askDuplicateAction(w, path)
+	if all && choice != duplicateCancel {
+		r.mu.Lock()
+		r.applied = choice
+		r.mu.Unlock()
+	}
+	return choice
+}
+
+func (r *duplicateResolver) reset() {
+	r.mu.Lock()
+	r.applied = ""
+	r.seenApplied = ""
+	r.mu.Unlock()
+}
+
+// Answers for a video that is already in the history.
+const (
+	seenDownload = "Download anyway"
+	seenSkip     = "Skip"
+	seenOpen     = "Open existing file"
+)
+
+// resolveSeen reports whether to download a video the history already has,
+// whatever its file name and quality were then. Only a file still on disk,
+// or a line of an imported yt-dlp archive, counts; the Skip policy skips it silently, the others except Ask download
+// again.
+func (r *duplicateResolver) resolveSeen(w fyne.Window, h *historyStore, videoID, policy string, ev publisher) bool {
+	e, ok := h.findVideo(videoID)
+	if !ok {
+		return true
+	}
+	switch _, err := os.Stat(e.Path); {
+	case e.Path == "":
+		ev.log(tr("This video is in an imported download archive."))
+	case err != nil:
+		ev.log(trf("Downloaded before on %s; that file is gone, downloading again.", e.Finished.Local().Format("2006-01-02")))
+		return true
+	default:
+		ev.log(trf("You downloaded this video on %s as %s.", e.Finished.Local().Format("2006-01-02"), filepath.Base(e.Path)))
+	}
+	switch policy {
+	case duplicateSkip:
+		return false
+	case duplicateRename, duplicateReplace:
+		return true
+	}
+	r.mu.Lock()
+	choice := r.seenApplied
+	r.mu.Unlock()
+	if choice == "" {
+		var all bool
+		choice, all = askDownloadedBefore(w, e)
+		if all && choice != seenOpen {
+			r.mu.Lock()
+			r.seenApplied = choice
+			r.mu.Unlock()
+		}
+	}
+	if choice == seenOpen {
+		if err := openWithDefaultApp(e.Path); err != nil {
+			ev.log(trf("Failed to open file: %v", err))
+		}
+	}
+	return choice == seenDownload
+}
+
+// askDownloadedBefore asks what to do about a video already downloaded as
+// e. It blocks until answered and must not be called on the main thread.
+func askDownloadedBefore(w fyne.Window, e historyEntry) (string, bool) {
+	type answer struct {
+		choice string
+		all    bool
+	}
+	choiceCh := make(chan answer, 1)
+	runOnMain(func() {
+		var d dialog.Dialog
+		answered := false
+		applyAll := widget.NewCheck(tr("Apply to all remaining downloads"), nil)
+		send := func(choice string) {
+			if answered {
+				return
+			}
+			answered = true
+			choiceCh <- answer{choice, applyAll.Checked}
+			d.Hide()
+		}
+		buttons := container.NewGridWithColumns(3,
+			widget.NewButton(tr(seenSkip), func() { send(seenSkip) }),
+			widget.NewButton(tr(seenDownload), func() { send(seenDownload) }),
+			widget.NewButton(tr(seenOpen), func() { send(seenOpen) }),
+		)
+		message := container.NewVBox(
+			widget.NewLabel(trf("You downloaded this video on %s as %s.", e.Finished.Local().Format("2006-01-02"), filepath.Base(e.Path))),
+			widget.NewLabel(e.Path),
+		)
+		if e.Path == "" {
+			// An imported archive line has no file to open.
+			buttons.Objects = buttons.Objects[:2]
+			message = container.NewVBox(widget.NewLabel(trf("%s is in an imported download archive.", e.VideoID)))
+		}
+		d = dialog.NewCustom(
+			tr("Downloaded Before"),
+			"",
+			container.NewVBox(message, buttons, applyAll),
+			w,
+		)
+		d.SetOnClosed(func() {
+			if answered {
+				return
+			}
+			answered = true
+			choiceCh <- answer{seenSkip, false}
+		})
+		d.Show()
+	})
+	a := <-choiceCh
+	return a.choice, a.all
+}
+
+// playlistOverwriteArgs maps the policy onto yt-dlp flags, since playlist
+// item names aren't known up front. yt-dlp already skips items it has
+// downloaded before, which is the closest match for Ask and Rename.
+func playlistOverwriteArgs(policy string) []string {
+	switch policy {
+	case duplicateReplace:
+		return []string{"--force-overwrites"}
+	case duplicateSkip:
+		return []string{"--no-overwrites"}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"sync"
+
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+// publisher posts engine events for one job, or for the app itself when job
+// is 0 (tool setup, the nerd console). It satisfies statusSetter,
+// progressSetter and speedSetter.
+type publisher struct {
+	bus *downloader.Bus
+	job int64
+}
+
+func (p publisher) log(msg string) {
+	p.bus.Publish(downloader.Event{Kind: downloader.EventLog, JobID: p.job, Text: msg})
+}
+
+func (p publisher) raw(line string) {
+	p.bus.Publish(downloader.Event{Kind: downloader.EventRawLog, JobID: p.job, Text: line})
+}
+
+func (p publisher) tool(text string) {
+	p.bus.Publish(downloader.Event{Kind: downloader.EventToolStatus, JobID: p.job, Text: text})
+}
+
+// phase reports which part of a job is running, for the app state.
+func (p publisher) phase(s appState) {
+	p.bus.Publish(downloader.Event{Kind: downloader.EventPhase, JobID: p.job, Text: s.String()})
+}
+
+// asking marks the job as waiting for the user while ask runs, then
+// returns it to the phase it was in.
+func (p publisher) asking(from appState, ask func()) {
+	p.phase(statePrompting)
+	defer p.phase(from)
+	ask()
+}
+
+func (p publisher) SetText(text string) {
+	p.bus.Publish(downloader.Event{Kind: downloader.EventStage, JobID: p.job, Text: text})
+}
+
+func (p publisher) SetValue(v float64) {
+	p.bus.Publish(downloader.Event{Kind: downloader.EventProgress, JobID: p.job, Progress: v})
+}
+
+func (p publisher) SetSpeed(bytesPerSec float64) {
+	p.bus.Publish(downloader.Event{Kind: downloader.EventSpeed, JobID: p.job, Speed: bytesPerSec})
+}
+
+// showEvents subscribes the window to the bus. App events go to the main
+// logs, the app state (and so the status label) and the progress bar; job
+// events go to that job's logs and queue row, and job phases to the app
+// state.
+func showEvents(bus *downloader.Bus, q *downloadQueue, states *appStateMachine, logBox, nerdLogBox *widget.Entry, progress progressSetter, mu *sync.Mutex) func() {
+	return bus.Subscribe(func(e downloader.Event) {
+		if e.JobID == 0 {
+			switch e.Kind {
+			case downloader.EventStage:
+				states.show(e.Text)
+			case downloader.EventToolStatus:
+				states.setActivity(e.Text)
+			case downloader.EventProgress:
+				runOnMain(func() { progress.SetValue(e.Progress) })
+			case downloader.EventLog:
+				appendLog(logBox, e.Text, mu)
+			case downloader.EventRawLog:
+				appendNerdLog(nerdLogBox, e.Text, mu)
+			}
+			return
+		}
+		switch e.Kind {
+		case downloader.EventJobStarted:
+			states.jobStarted(e.JobID)
+			appendLog(logBox, trf("#%d started: %s", e.JobID, e.Text), mu)
+		case downloader.EventPhase:
+			if s, ok := parseAppState(e.Text); ok {
+				states.jobPhase(e.JobID, s)
+			}
+		case downloader.EventStage, downloader.EventToolStatus:
+			q.update(e.JobID, func(j *downloadJob) { j.Status = e.Text })
+		case downloader.EventProgress:
+			q.update(e.JobID, func(j *downloadJob) { j.Progress = e.Progress })
+		case downloader.EventSpeed:
+			q.update(e.JobID, func(j *downloadJob) { j.Speed = e.Speed })
+		case downloader.EventLog:
+			if jobLog, _ := q.logs(e.JobID); jobLog != nil {
+				appendLog(jobLog, e.Text, mu)
+			}
+		case downloader.EventRawLog:
+			if _, jobNerdLog := q.logs(e.JobID); jobNerdLog != nil {
+				appendNerdLog(jobNerdLog, e.Text, mu)
+			}
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefYouTubeClient  = "youtube_player_client"
+	prefYouTubePOToken = "youtube_po_token"
+	prefExtractorArgs  = "extractor_args"
+
+	youtubeClientDefault = "Default"
+)
+
+// youtubeClients are the player clients that most often get around 403s and
+// throttled formats.
+var youtubeClients = []string{youtubeClientDefault, "android", "ios", "tv", "web", "web_safari", "mweb"}
+
+// siteExtractorArgs is one --extractor-args entry: site is the yt-dlp
+// extractor name, args its "key=value;key=value" list.
+type siteExtractorArgs struct {
+	Site string
+	Args string
+}
+
+// parseExtractorArgsLine reads a "site:key=value;..." line as typed in the
+// settings.
+func parseExtractorArgsLine(line string) (siteExtractorArgs, error) {
+	site, args, _ := strings.Cut(strings.TrimSpace(line), ":")
+	e := siteExtractorArgs{Site: strings.ToLower(strings.TrimSpace(site)), Args: strings.TrimSpace(args)}
+	return e, downloader.ValidateExtractorArgs(e.Site, e.Args)
+}
+
+// loadExtractorArgs combines the YouTube fields and the free-form lines into
+// one entry per site, in the order the sites first appear. Invalid lines
+// are skipped; the settings page points them out.
+func loadExtractorArgs(prefs fyne.Preferences) []siteExtractorArgs {
+	var youtube []string
+	if client := selectedOption(prefs, prefYouTubeClient, youtubeClients, youtubeClientDefault); client != youtubeClientDefault {
+		youtube = append(youtube, "player_client="+client)
+	}
+	if token := strings.TrimSpace(prefs.String(prefYouTubePOToken)); token != "" {
+		youtube = append(youtube, "po_token="+token)
+	}
+	var out []siteExtractorArgs
+	index := map[string]int{}
+	add := func(site, args string) {
+		if i, ok := index[site]; ok {
+			out[i].Args += ";" + args
+			return
+		}
+		index[site] = len(out)
+		out = append(out, siteExtractorArgs{Site: site, Args: args})
+	}
+	if len(youtube) > 0 {
+		add("youtube", strings.Join(youtube, ";"))
+	}
+	for _, line := range prefs.StringList(prefExtractorArgs) {
+		if e, err := parseExtractorArgsLine(line); err == nil {
+			add(e.Site, e.Args)
+		}
+	}
+	return out
+}
+
+// extractorArgsSection is the advanced part of the network settings for
+// sites that answer with 403 errors or throttle downloads unless yt-dlp
+// pretends to be a different client.
+func extractorArgsSection(prefs fyne.Preferences) fyne.CanvasObject {
+	client := widget.NewSelect(trList(youtubeClients), func(shown string) {
+		value := untr(youtubeClients, shown)
+		if value == youtubeClientDefault {
+			value = ""
+		}
+		prefs.SetString(prefYouTubeClient, value)
+	})
+	client.SetSelected(tr(selectedOption(prefs, prefYouTubeClient, youtubeClients, youtubeClientDefault)))
+
+	token := widget.NewPasswordEntry()
+	token.SetPlaceHolder("web.gvs+...")
+	token.SetText(prefs.String(prefYouTubePOToken))
+	token.OnChanged = func(text string) {
+		prefs.SetString(prefYouTubePOToken, strings.TrimSpace(text))
+	}
+
+	problems := widget.NewLabel("")
+	problems.Wrapping = fyne.TextWrapWord
+	other := widget.NewMultiLineEntry()
+	other.SetMinRowsVisible(4)
+	other.SetPlaceHolder("vimeo:original_format_policy=always")
+	other.SetText(strings.Join(prefs.StringList(prefExtractorArgs), "\n"))
+	check := func(text string) []string {
+		var lines, bad []string
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			lines = append(lines, line)
+			if _, err := parseExtractorArgsLine(line); err != nil {
+				bad = append(bad, err.Error())
+			}
+		}
+		if len(bad) > 0 {
+			problems.SetText(trf("These lines are ignored: %s", strings.Join(bad, "; ")))
+		} else {
+			problems.SetText("")
+		}
+		return lines
+	}
+	other.OnChanged = func(text string) {
+		prefs.SetStringList(prefExtractorArgs, check(text))
+	}
+	check(other.Text)
+
+	return container.NewVBox(
+		widget.NewLabel(tr("Try these when downloads fail with HTTP 403 or crawl along. They apply to downloads started from now on.")),
+		widget.NewForm(
+			widget.NewFormItem(tr("YouTube player client"), client),
+			widget.NewFormItem(tr("YouTube PO token"), token),
+		),
+		widget.NewLabel(tr("Other sites, one per line as site:key=value;key=value")),
+		other,
+		problems,
+	)
+}
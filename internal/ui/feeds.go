@@ -0,0 +1,344 @@
+package ui
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefFeeds        = "feeds"
+	prefFeedInterval = "feed_interval"
+	prefFeedPreset   = "feed_preset"
+
+	// feedArchiveName lists every feed entry ever queued, one ID per line,
+	// like yt-dlp's --download-archive file.
+	feedArchiveName = "feed_archive.txt"
+
+	feedManual = "Manually"
+)
+
+var feedIntervals = []string{"Every 15 minutes", "Every hour", "Every 6 hours", feedManual}
+
+var feedIntervalDurations = map[string]time.Duration{
+	"Every 15 minutes": 15 * time.Minute,
+	"Every hour":       time.Hour,
+	"Every 6 hours":    6 * time.Hour,
+}
+
+type feedSubscription struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+func loadFeeds(prefs fyne.Preferences) []feedSubscription {
+	var feeds []feedSubscription
+	if raw := prefs.String(prefFeeds); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &feeds); err != nil {
+			fyne.LogError("Could not read feeds", err)
+		}
+	}
+	return feeds
+}
+
+func saveFeeds(prefs fyne.Preferences, feeds []feedSubscription) {
+	data, err := json.Marshal(feeds)
+	if err != nil {
+		fyne.LogError("Could not save feeds", err)
+		return
+	}
+	prefs.SetString(prefFeeds, string(data))
+}
+
+func feedArchivePath() (string, error) {
+	dir, err := downloader.AppDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, feedArchiveName), nil
+}
+
+func loadFeedArchive() (map[string]bool, error) {
+	seen := make(map[string]bool)
+	path, err := feedArchivePath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return seen, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if id := strings.TrimSpace(sc.Text()); id != "" {
+			seen[id] = true
+		}
+	}
+	return seen, sc.Err()
+}
+
+func recordFeedEntries(entries []downloader.FeedEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	path, err := feedArchivePath()
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString(e.ID + "\n")
+	}
+	_, err = f.WriteString(b.String())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// feedPoller checks the subscribed feeds and queues entries that aren't in
+// the feed archive yet.
+type feedPoller struct {
+	prefs   fyne.Preferences
+	ready   func() bool
+	enqueue func(feed feedSubscription, entries []downloader.FeedEntry)
+	logf    func(string)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	// checking serializes checks, so a manual check during a scheduled one
+	// can't queue an entry twice.
+	checking sync.Mutex
+}
+
+// start (re)schedules polling for the current interval setting.
+func (p *feedPoller) start() {
+	p.stop()
+	every, ok := feedIntervalDurations[selectedOption(p.prefs, prefFeedInterval, feedIntervals, "Every hour")]
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.mu.Lock()
+	p.cancel = cancel
+	p.mu.Unlock()
+	go func() {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.checkAll(ctx)
+			}
+		}
+	}()
+}
+
+func (p *feedPoller) stop() {
+	p.mu.Lock()
+	cancel := p.cancel
+	p.cancel = nil
+	p.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// checkAll polls every feed and returns how many entries were queued.
+func (p *feedPoller) checkAll(ctx context.Context) int {
+	if !p.ready() {
+		return 0
+	}
+	p.checking.Lock()
+	defer p.checking.Unlock()
+	seen, err := loadFeedArchive()
+	if err != nil {
+		p.logf(trf("Could not read the feed archive: %v", err))
+		return 0
+	}
+	queued := 0
+	for _, sub := range loadFeeds(p.prefs) {
+		feed, err := downloader.FetchFeed(ctx, sub.URL)
+		if err != nil {
+			p.logf(trf("Feed %s: %v", valueOr(sub.Title, sub.URL), err))
+			continue
+		}
+		var fresh []downloader.FeedEntry
+		for _, e := range feed.Entries {
+			if !seen[e.ID] {
+				seen[e.ID] = true
+				fresh = append(fresh, e)
+			}
+		}
+		if len(fresh) == 0 {
+			continue
+		}
+		if err := recordFeedEntries(fresh); err != nil {
+			p.logf(trf("Could not update the feed archive: %v", err))
+			continue
+		}
+		p.enqueue(sub, fresh)
+		queued += len(fresh)
+	}
+	return queued
+}
+
+// subscribe adds a feed. Its current entries go into the archive, so only
+// what is published afterwards gets downloaded.
+func (p *feedPoller) subscribe(ctx context.Context, feedURL string) (feedSubscription, error) {
+	u, ok := parseLaunchArg(feedURL)
+	if !ok {
+		return feedSubscription{}, errors.New(tr("Enter the http(s) address of an RSS or Atom feed."))
+	}
+	for _, f := range loadFeeds(p.prefs) {
+		if f.URL == u {
+			return feedSubscription{}, errors.New(tr("You already follow this feed."))
+		}
+	}
+	feed, err := downloader.FetchFeed(ctx, u)
+	if err != nil {
+		return feedSubscription{}, err
+	}
+	p.checking.Lock()
+	defer p.checking.Unlock()
+	seen, err := loadFeedArchive()
+	if err != nil {
+		return feedSubscription{}, err
+	}
+	var known []downloader.FeedEntry
+	for _, e := range feed.Entries {
+		if !seen[e.ID] {
+			known = append(known, e)
+		}
+	}
+	if err := recordFeedEntries(known); err != nil {
+		return feedSubscription{}, err
+	}
+	sub := feedSubscription{URL: u, Title: valueOr(feed.Title, u)}
+	saveFeeds(p.prefs, append(loadFeeds(p.prefs), sub))
+	return sub, nil
+}
+
+// feedsPage manages feed subscriptions and how often they are checked.
+func feedsPage(w fyne.Window, prefs fyne.Preferences, poller *feedPoller) func() fyne.CanvasObject {
+	return func() fyne.CanvasObject {
+		list := container.NewVBox()
+		var refresh func()
+		refresh = func() {
+			list.RemoveAll()
+			feeds := loadFeeds(prefs)
+			if len(feeds) == 0 {
+				list.Add(widget.NewLabel(tr("No feeds yet.")))
+			}
+			for _, f := range feeds {
+				f := f
+				label := widget.NewLabel(f.Title)
+				label.Truncation = fyne.TextTruncateEllipsis
+				remove := newIconButton(tr("Remove"), theme.DeleteIcon(), func() {
+					var kept []feedSubscription
+					for _, other := range loadFeeds(prefs) {
+						if other.URL != f.URL {
+							kept = append(kept, other)
+						}
+					}
+					saveFeeds(prefs, kept)
+					refresh()
+				})
+				list.Add(container.NewBorder(nil, nil, nil, remove, label))
+			}
+		}
+		refresh()
+
+		urlEntry := widget.NewEntry()
+		urlEntry.SetPlaceHolder("https://www.youtube.com/feeds/videos.xml?channel_id=...")
+		var add *widget.Button
+		add = widget.NewButton(tr("Add Feed"), func() {
+			add.Disable()
+			go func() {
+				sub, err := poller.subscribe(context.Background(), urlEntry.Text)
+				runOnMain(func() {
+					add.Enable()
+					if err != nil {
+						dialog.ShowError(err, w)
+						return
+					}
+					urlEntry.SetText("")
+					refresh()
+					poller.logf(trf("Following feed %s.", sub.Title))
+				})
+			}()
+		})
+		urlEntry.OnSubmitted = func(string) { add.OnTapped() }
+
+		interval := widget.NewSelect(trList(feedIntervals), func(shown string) {
+			prefs.SetString(prefFeedInterval, untr(feedIntervals, shown))
+			poller.start()
+		})
+		interval.SetSelected(tr(selectedOption(prefs, prefFeedInterval, feedIntervals, "Every hour")))
+
+		names := []string{tr(watchCurrentName)}
+		for _, p := range loadPresets(prefs) {
+			names = append(names, p.Name)
+		}
+		preset := widget.NewSelect(names, func(name string) {
+			if name == tr(watchCurrentName) {
+				name = ""
+			}
+			prefs.SetString(prefFeedPreset, name)
+		})
+		if _, ok := findPreset(prefs, prefs.String(prefFeedPreset)); ok {
+			preset.SetSelected(prefs.String(prefFeedPreset))
+		} else {
+			preset.SetSelected(names[0])
+		}
+
+		status := widget.NewLabel("")
+		var checkNow *widget.Button
+		checkNow = widget.NewButton(tr("Check Now"), func() {
+			checkNow.Disable()
+			status.SetText(tr("Checking feeds..."))
+			go func() {
+				n := poller.checkAll(context.Background())
+				runOnMain(func() {
+					checkNow.Enable()
+					status.SetText(trf("%d new entry(s) queued.", n))
+				})
+			}()
+		})
+
+		return container.NewVBox(
+			list,
+			container.NewBorder(nil, nil, nil, add, urlEntry),
+			widget.NewForm(
+				widget.NewFormItem(tr("Check"), interval),
+				widget.NewFormItem(tr("Download with"), preset),
+			),
+			container.NewHBox(checkNow, status),
+			widget.NewLabel(tr("New entries are queued automatically. Entries already in a feed when you add it are skipped.")),
+		)
+	}
+}
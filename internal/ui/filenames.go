@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefNameStrip    = "name_strip_phrases"
+	prefNameEmoji    = "name_strip_emoji"
+	prefNameSpaces   = "name_collapse_spaces"
+	prefNameMaxLen   = "name_max_length"
+	prefNameDate     = "name_date_prefix"
+	prefNameID       = "name_id_suffix"
+	prefNameRestrict = "name_restrict"
+	nameSampleTitle  = "Artist - Song Title [Official Video] (Lyrics) 🎵🔥   Live"
+	nameSampleAuthor = "Artist Channel"
+	nameSampleDate   = "20240131"
+	nameSampleID     = "dQw4w9WgXcQ"
+)
+
+func loadNameRules(prefs fyne.Preferences) downloader.NameRules {
+	def := downloader.DefaultNameRules()
+	return downloader.NameRules{
+		Strip:         prefs.StringListWithFallback(prefNameStrip, def.Strip),
+		StripEmoji:    prefs.BoolWithFallback(prefNameEmoji, def.StripEmoji),
+		CollapseSpace: prefs.BoolWithFallback(prefNameSpaces, def.CollapseSpace),
+		MaxLength:     prefs.IntWithFallback(prefNameMaxLen, def.MaxLength),
+		DatePrefix:    prefs.Bool(prefNameDate),
+		IDSuffix:      prefs.Bool(prefNameID),
+		Restrict:      prefs.Bool(prefNameRestrict),
+	}
+}
+
+// filenamesPage edits the title cleanup rules and previews them on a sample
+// title, so the effect is visible before the next download.
+func filenamesPage(prefs fyne.Preferences) func() fyne.CanvasObject {
+	return func() fyne.CanvasObject {
+		rules := loadNameRules(prefs)
+		preview := widget.NewLabel("")
+		sample := widget.NewEntry()
+		sample.SetText(nameSampleTitle)
+		withChannel := widget.NewCheck(tr("Include channel name in filename"), nil)
+		update := func() {
+			src := downloader.NameSource{Title: sample.Text, Channel: nameSampleAuthor, UploadDate: nameSampleDate, ID: nameSampleID}
+			preview.SetText(downloader.BuildFileName(src, "mp4", withChannel.Checked, loadNameRules(prefs)))
+		}
+		sample.OnChanged = func(string) { update() }
+		withChannel.OnChanged = func(bool) { update() }
+
+		strip := widget.NewMultiLineEntry()
+		strip.SetMinRowsVisible(5)
+		strip.SetText(strings.Join(rules.Strip, "\n"))
+		strip.OnChanged = func(text string) {
+			var phrases []string
+			for _, line := range strings.Split(text, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					phrases = append(phrases, line)
+				}
+			}
+			prefs.SetStringList(prefNameStrip, phrases)
+			update()
+		}
+		emoji := widget.NewCheck(tr("Remove emoji"), func(on bool) {
+			prefs.SetBool(prefNameEmoji, on)
+			update()
+		})
+		emoji.SetChecked(rules.StripEmoji)
+		spaces := widget.NewCheck(tr("Collapse repeated spaces"), func(on bool) {
+			prefs.SetBool(prefNameSpaces, on)
+			update()
+		})
+		spaces.SetChecked(rules.CollapseSpace)
+		datePrefix := widget.NewCheck(tr("Start with the upload date (YYYYMMDD)"), func(on bool) {
+			prefs.SetBool(prefNameDate, on)
+			update()
+		})
+		datePrefix.SetChecked(rules.DatePrefix)
+		idSuffix := widget.NewCheck(tr("End with the video ID, e.g. [dQw4w9WgXcQ]"), func(on bool) {
+			prefs.SetBool(prefNameID, on)
+			update()
+		})
+		idSuffix.SetChecked(rules.IDSuffix)
+		restrict := widget.NewCheck(tr("Safe filenames (ASCII only, no spaces)"), func(on bool) {
+			prefs.SetBool(prefNameRestrict, on)
+			update()
+		})
+		restrict.SetChecked(rules.Restrict)
+		maxLen := widget.NewEntry()
+		maxLen.SetText(strconv.Itoa(rules.MaxLength))
+		maxLen.OnChanged = func(text string) {
+			n, err := strconv.Atoi(strings.TrimSpace(text))
+			if err != nil || n < 0 {
+				return
+			}
+			prefs.SetInt(prefNameMaxLen, n)
+			update()
+		}
+		sortCustomEntry := widget.NewEntry()
+		sortCustomEntry.SetPlaceHolder("{type}/{channel}")
+		sortCustomEntry.SetText(prefs.String(prefSortTemplate))
+		sortCustomEntry.OnChanged = func(text string) {
+			prefs.SetString(prefSortTemplate, strings.TrimSpace(text))
+		}
+		sortSelect := widget.NewSelect(trList(sortRules), func(shown string) {
+			rule := untr(sortRules, shown)
+			prefs.SetString(prefSortRule, rule)
+			if rule == sortCustom {
+				sortCustomEntry.Enable()
+			} else {
+				sortCustomEntry.Disable()
+			}
+		})
+		sortSelect.SetSelected(tr(selectedOption(prefs, prefSortRule, sortRules, sortOff)))
+		askName := widget.NewCheck(tr("Show a summary before each download to rename the file"), func(on bool) {
+			prefs.SetBool(prefAskFileName, on)
+		})
+		askName.SetChecked(prefs.Bool(prefAskFileName))
+		update()
+
+		return container.NewVBox(
+			widget.NewLabel(tr("Remove these phrases from titles (one per line, case-insensitive):")),
+			strip,
+			emoji,
+			spaces,
+			datePrefix,
+			idSuffix,
+			restrict,
+			widget.NewForm(
+				widget.NewFormItem(tr("Max name length"), maxLen),
+				widget.NewFormItem(tr("Sample title"), sample),
+			),
+			withChannel,
+			widget.NewLabel(tr("Preview:")),
+			preview,
+			widget.NewLabel(tr("Names that Windows reserves, such as CON or NUL, always get an underscore appended.")),
+			askName,
+			widget.NewSeparator(),
+			widget.NewForm(
+				widget.NewFormItem(tr("Sort into subfolders"), sortSelect),
+				widget.NewFormItem(tr("Template"), sortCustomEntry),
+			),
+			widget.NewLabel(tr("Template tokens: {type} (Audio, Video or Playlists/<name>), {channel}, {playlist}, {date} (year-month).")),
+		)
+	}
+}
@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefMaxFileSize = "max_file_size_mb"
+	prefSizeMode    = "max_file_size_mode"
+
+	sizeModeFilter   = "Pick a smaller format"
+	sizeModeReencode = "Re-encode to fit"
+)
+
+var sizeModes = []string{sizeModeFilter, sizeModeReencode}
+
+// fileSizeLimit caps the size of a finished download, for clips sent over
+// channels with an attachment limit. MaxMB is in megabytes of 1,000,000
+// bytes, as mail and chat services count them; 0 means no limit.
+type fileSizeLimit struct {
+	MaxMB    int
+	Reencode bool
+}
+
+func (l fileSizeLimit) bytes() int64 { return int64(l.MaxMB) * 1000 * 1000 }
+
+func loadFileSizeLimit(prefs fyne.Preferences) fileSizeLimit {
+	return fileSizeLimit{
+		MaxMB:    prefs.Int(prefMaxFileSize),
+		Reencode: selectedOption(prefs, prefSizeMode, sizeModes, sizeModeFilter) == sizeModeReencode,
+	}
+}
+
+// limitFormatSize adds size filters to each alternative of a -f selector.
+// Merged video+audio alternatives give the audio an eighth of the budget.
+// Formats that only report an approximate size are tried after exact ones.
+func limitFormatSize(selector string, maxBytes int64) string {
+	audio := maxBytes / 8
+	var exact, approx []string
+	for _, alt := range strings.Split(selector, "/") {
+		parts := strings.Split(alt, "+")
+		limits := []int64{maxBytes}
+		if len(parts) == 2 {
+			limits = []int64{maxBytes - audio, audio}
+		}
+		if len(parts) != len(limits) {
+			continue
+		}
+		for _, field := range []string{"filesize", "filesize_approx"} {
+			limited := make([]string, len(parts))
+			for i, p := range parts {
+				limited[i] = fmt.Sprintf("%s[%s<%d]", p, field, limits[i])
+			}
+			if field == "filesize" {
+				exact = append(exact, strings.Join(limited, "+"))
+			} else {
+				approx = append(approx, strings.Join(limited, "+"))
+			}
+		}
+	}
+	return strings.Join(append(exact, approx...), "/")
+}
+
+// limitFormatArgs rewrites the -f value of args, adding one for audio
+// extraction, which otherwise relies on yt-dlp's default selector.
+func limitFormatArgs(args []string, maxBytes int64) []string {
+	out := append([]string(nil), args...)
+	for i := 0; i+1 < len(out); i++ {
+		if out[i] == "-f" {
+			out[i+1] = limitFormatSize(out[i+1], maxBytes)
+			return out
+		}
+	}
+	return append(out, "-f", limitFormatSize("bestaudio/best", maxBytes))
+}
+
+// fileSizeRow is the max file size control of the main window.
+func fileSizeRow(prefs fyne.Preferences) fyne.CanvasObject {
+	size := widget.NewEntry()
+	size.SetPlaceHolder(tr("No limit"))
+	if n := prefs.Int(prefMaxFileSize); n > 0 {
+		size.SetText(strconv.Itoa(n))
+	}
+	size.OnChanged = func(text string) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			prefs.SetInt(prefMaxFileSize, 0)
+			return
+		}
+		if n, err := strconv.Atoi(text); err == nil && n >= 0 {
+			prefs.SetInt(prefMaxFileSize, n)
+		}
+	}
+	mode := widget.NewSelect(trList(sizeModes), func(shown string) {
+		prefs.SetString(prefSizeMode, untr(sizeModes, shown))
+	})
+	mode.SetSelected(tr(selectedOption(prefs, prefSizeMode, sizeModes, sizeModeFilter)))
+	return container.NewHBox(widget.NewLabel(tr("Max file size (MB):")), container.NewGridWrap(fyne.NewSize(90, size.MinSize().Height), size), mode)
+}
+
+// fitDownload re-encodes path when it is over the limit and returns the file
+// to keep. On failure the original download is kept and the reason logged.
+func fitDownload(ctx context.Context, ffmpeg, path string, duration float64, limit fileSizeLimit, hasVideo bool, ev publisher) string {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() <= limit.bytes() {
+		return path
+	}
+	if duration <= 0 {
+		// ffprobe is installed next to ffmpeg.
+		if probe, err := downloader.ProbeMedia(filepath.Join(filepath.Dir(ffmpeg), "ffprobe.exe"), path); err == nil {
+			duration = probe.Duration
+		}
+	}
+	dst := path
+	if hasVideo && !strings.EqualFold(filepath.Ext(path), ".mp4") {
+		dst = downloader.UniqueName(strings.TrimSuffix(path, filepath.Ext(path)) + ".mp4")
+	}
+	ev.log(trf("%s is %s, re-encoding to fit %d MB...", filepath.Base(path), formatBytes(info.Size()), limit.MaxMB))
+	ev.SetText(tr("Re-encoding to fit the size limit..."))
+	if err := downloader.FitToSize(ctx, ffmpeg, path, dst, duration, limit.bytes(), hasVideo); err != nil {
+		ev.log(trf("Could not fit the file to %d MB, keeping the original: %v", limit.MaxMB, err))
+		return path
+	}
+	if dst != path {
+		if err := downloader.RecycleFile(path); err != nil {
+			ev.log(trf("Could not remove the original file: %v", err))
+		}
+	}
+	if info, err := os.Stat(dst); err == nil {
+		ev.log(trf("Re-encoded to %s.", formatBytes(info.Size())))
+	}
+	return dst
+}
@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// outputWatch follows yt-dlp's output to learn where the finished files
+// ended up. Later lines win: a merge, audio extraction or move replaces the
+// intermediate download destinations of the same item.
+type outputWatch struct {
+	mu   sync.Mutex
+	path string
+	// items holds the latest path of every item, in download order; a
+	// playlist has one per video.
+	items []string
+}
+
+// formatSuffix matches the ".f137" yt-dlp adds to the separate streams of
+// a download before merging them.
+var formatSuffix = regexp.MustCompile(`\.f[0-9]+$`)
+
+// outputStem is what the files of one item have in common: the name without
+// folder, extension or format suffix.
+func outputStem(path string) string {
+	name := filepath.Base(path)
+	return formatSuffix.ReplaceAllString(strings.TrimSuffix(name, filepath.Ext(name)), "")
+}
+
+var outputLineMarkers = []string{
+	"[Merger] Merging formats into ",
+	"[ExtractAudio] Destination: ",
+	"[download] Destination: ",
+}
+
+func (o *outputWatch) observe(rawLine string) {
+	line := strings.TrimSpace(rawLine)
+	path := ""
+	switch {
+	case strings.HasPrefix(line, "[MoveFiles] Moving file "):
+		// [MoveFiles] Moving file "staged" to "final"
+		if i := strings.LastIndex(line, `" to "`); i >= 0 {
+			path = strings.Trim(line[i+len(`" to "`)-1:], `"`)
+		}
+	case strings.HasPrefix(line, "[download] ") && strings.HasSuffix(line, " has already been downloaded"):
+		path = strings.TrimSuffix(strings.TrimPrefix(line, "[download] "), " has already been downloaded")
+	default:
+		for _, m := range outputLineMarkers {
+			if strings.HasPrefix(line, m) {
+				path = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, m)), `"`)
+				break
+			}
+		}
+	}
+	if path == "" || isSubtitleFile(path) || isThumbnailFile(path) {
+		return
+	}
+	o.mu.Lock()
+	o.path = path
+	if n := len(o.items); n > 0 && outputStem(o.items[n-1]) == outputStem(path) {
+		o.items[n-1] = path
+	} else {
+		o.items = append(o.items, path)
+	}
+	o.mu.Unlock()
+}
+
+// files returns the final path of every item that exists on disk.
+func (o *outputWatch) files() []string {
+	o.mu.Lock()
+	items := append([]string(nil), o.items...)
+	o.mu.Unlock()
+	var out []string
+	seen := map[string]bool{}
+	for _, p := range items {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		if _, err := os.Stat(p); err == nil {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func isSubtitleFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".vtt", ".srt", ".ass", ".ssa", ".ttml", ".srv1", ".srv2", ".srv3", ".json3":
+		return true
+	}
+	return false
+}
+
+func isThumbnailFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg", ".png", ".webp":
+		return true
+	}
+	return false
+}
+
+// resolved returns the last seen path if it exists on disk, otherwise the
+// fallback (the output name the app chose up front).
+func (o *outputWatch) resolved(fallback string) string {
+	o.mu.Lock()
+	path := o.path
+	o.mu.Unlock()
+	for _, p := range []string{path, fallback} {
+		if p == "" || strings.Contains(p, "%(") {
+			continue
+		}
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+func openWithDefaultApp(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+	case "darwin":
+		cmd = exec.Command("open", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	return cmd.Start()
+}
+
+// revealInFileManager opens the containing folder with the file selected
+// where the platform supports it.
+func revealInFileManager(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = explorerSelectCmd(path)
+	case "darwin":
+		cmd = exec.Command("open", "-R", path)
+	default:
+		return openInFileManager(filepath.Dir(path))
+	}
+	return cmd.Start()
+}
+
+// fileActions shows a finished file with Play and Show in folder buttons,
+// and optionally a line of details about it.
+type fileActions struct {
+	label   *widget.Label
+	details *widget.Label
+	play    *widget.Button
+	reveal  *widget.Button
+	box     *fyne.Container
+	path    string
+}
+
+func newFileActions(logf func(string)) *fileActions {
+	f := &fileActions{label: widget.NewLabel(""), details: widget.NewLabel("")}
+	f.label.Truncation = fyne.TextTruncateEllipsis
+	f.details.Importance = widget.LowImportance
+	f.details.Hide()
+	f.play = widget.NewButtonWithIcon(tr("Play"), theme.MediaPlayIcon(), func() {
+		if err := openWithDefaultApp(f.path); err != nil {
+			logf(trf("Failed to open file: %v", err))
+		}
+	})
+	f.reveal = widget.NewButtonWithIcon(tr("Show in folder"), theme.FolderOpenIcon(), func() {
+		if err := revealInFileManager(f.path); err != nil {
+			logf(trf("Failed to open folder: %v", err))
+		}
+	})
+	f.box = container.NewBorder(nil, f.details, nil, container.NewHBox(f.play, f.reveal), f.label)
+	f.box.Hide()
+	return f
+}
+
+func (f *fileActions) set(path string) {
+	f.setWithDetails(path, "")
+}
+
+// setWithDetails shows path with details, such as a download summary,
+// underneath.
+func (f *fileActions) setWithDetails(path, details string) {
+	f.path = path
+	f.details.SetText(details)
+	if details == "" {
+		f.details.Hide()
+	} else {
+		f.details.Show()
+	}
+	if path == "" {
+		f.box.Hide()
+		return
+	}
+	f.label.SetText(trf("Saved: %s", path))
+	f.box.Show()
+}
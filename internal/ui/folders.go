@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	prefRecentFolders   = "recent_folders"
+	prefFavoriteFolders = "favorite_folders"
+
+	maxRecentFolders = 8
+)
+
+// rememberFolder moves dir to the front of the recent folders list.
+func rememberFolder(prefs fyne.Preferences, dir string) {
+	if strings.TrimSpace(dir) == "" {
+		return
+	}
+	recent := setFolderInList(prefs.StringList(prefRecentFolders), dir, false)
+	recent = append([]string{filepath.Clean(dir)}, recent...)
+	if len(recent) > maxRecentFolders {
+		recent = recent[:maxRecentFolders]
+	}
+	prefs.SetStringList(prefRecentFolders, recent)
+}
+
+// folderMenu lists favorites first, then recent folders that aren't
+// favorites, followed by the actions. The current folder is marked.
+func folderMenu(prefs fyne.Preferences, current string, pick func(string), browse func()) *fyne.Menu {
+	favorites := prefs.StringList(prefFavoriteFolders)
+	var items []*fyne.MenuItem
+	add := func(dir string) {
+		item := fyne.NewMenuItem(dir, func() { pick(dir) })
+		item.Checked = sameFolder(dir, current)
+		items = append(items, item)
+	}
+	for _, dir := range favorites {
+		add(dir)
+	}
+	if len(favorites) > 0 {
+		items = append(items, fyne.NewMenuItemSeparator())
+	}
+	recentCount := 0
+	for _, dir := range prefs.StringList(prefRecentFolders) {
+		if folderInList(favorites, dir) {
+			continue
+		}
+		add(dir)
+		recentCount++
+	}
+	if recentCount > 0 {
+		items = append(items, fyne.NewMenuItemSeparator())
+	}
+
+	isFavorite := folderInList(favorites, current)
+	favLabel := tr("Add current folder to favorites")
+	if isFavorite {
+		favLabel = tr("Remove current folder from favorites")
+	}
+	items = append(items,
+		fyne.NewMenuItem(favLabel, func() {
+			prefs.SetStringList(prefFavoriteFolders, setFolderInList(favorites, current, !isFavorite))
+		}),
+		fyne.NewMenuItem(tr("Clear recent folders"), func() {
+			prefs.SetStringList(prefRecentFolders, nil)
+		}),
+		fyne.NewMenuItem(tr("Browse..."), browse),
+	)
+	return fyne.NewMenu("", items...)
+}
+
+// showFolderMenu pops the menu up under the button that opened it.
+func showFolderMenu(w fyne.Window, anchor fyne.CanvasObject, menu *fyne.Menu) {
+	c := w.Canvas()
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(anchor)
+	widget.ShowPopUpMenuAtPosition(menu, c, pos.Add(fyne.NewPos(0, anchor.Size().Height)))
+}
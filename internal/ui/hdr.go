@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefDynamicRange = "last_dynamic_range"
+	prefTonemap      = "last_tonemap_hdr"
+
+	rangePreferSDR = "Prefer SDR"
+	rangePreferHDR = "Prefer HDR"
+	rangeAny       = "Best available"
+)
+
+// dynamicRanges decide between SDR and HDR when a video has both. HDR looks
+// washed out on many Windows setups, so SDR is the default.
+var dynamicRanges = []string{rangePreferSDR, rangePreferHDR, rangeAny}
+
+// preferDynamicRange puts a copy of every selector alternative restricted to
+// the wanted range in front of the original ones, which stay as fallbacks.
+// Formats that don't report a range count as SDR.
+func preferDynamicRange(args []string, mode string) []string {
+	var filter string
+	switch mode {
+	case rangePreferSDR:
+		filter = "[dynamic_range=?SDR]"
+	case rangePreferHDR:
+		filter = "[dynamic_range!=SDR]"
+	default:
+		return args
+	}
+	out := append([]string(nil), args...)
+	for i := 0; i+1 < len(out); i++ {
+		if out[i] != "-f" {
+			continue
+		}
+		var preferred []string
+		for _, alt := range strings.Split(out[i+1], "/") {
+			parts := strings.Split(alt, "+")
+			for j, p := range parts {
+				if !strings.HasPrefix(p, "bestaudio") && !strings.HasPrefix(p, "ba") {
+					parts[j] = p + filter
+				}
+			}
+			preferred = append(preferred, strings.Join(parts, "+"))
+		}
+		out[i+1] = strings.Join(preferred, "/") + "/" + out[i+1]
+		break
+	}
+	return out
+}
+
+// tonemapDownload converts an HDR download to SDR and returns the file to
+// keep. WebM can't hold H.264, so those become MKV. On failure the HDR file
+// is kept and the reason logged.
+func tonemapDownload(ctx context.Context, ffmpeg, path string, ev publisher) string {
+	// ffprobe is installed next to ffmpeg.
+	probe, err := downloader.ProbeMedia(filepath.Join(filepath.Dir(ffmpeg), "ffprobe.exe"), path)
+	if err != nil || !probe.HDR {
+		return path
+	}
+	dst := path
+	if strings.EqualFold(filepath.Ext(path), ".webm") {
+		dst = downloader.UniqueName(strings.TrimSuffix(path, filepath.Ext(path)) + ".mkv")
+	}
+	ev.log(tr("The video is HDR; converting it to SDR..."))
+	ev.SetText(tr("Converting HDR to SDR..."))
+	if err := downloader.TonemapToSDR(ctx, ffmpeg, path, dst); err != nil {
+		ev.log(trf("HDR to SDR conversion failed, keeping the HDR file: %v", err))
+		return path
+	}
+	if dst != path {
+		if err := downloader.RecycleFile(path); err != nil {
+			ev.log(trf("Could not remove the original file: %v", err))
+		}
+	}
+	ev.log(tr("Converted to SDR."))
+	return dst
+}
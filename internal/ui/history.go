@@ -0,0 +1,448 @@
+package ui
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const historyFileName = "history.jsonl"
+
+const (
+	verifyUnchecked = ""
+	verifyOK        = "ok"
+	verifySuspect   = "suspect"
+)
+
+// Integrity results of re-hashing a file against its recorded SHA-256.
+const (
+	integrityOK      = "ok"
+	integrityChanged = "changed"
+	integrityMissing = "missing"
+)
+
+type historyEntry struct {
+	ID      int64  `json:"id"`
+	URL     string `json:"url"`
+	VideoID string `json:"video_id,omitempty"`
+	// Title and Channel come from the video's metadata; entries recorded
+	// before they were kept only have the file name.
+	Title    string    `json:"title,omitempty"`
+	Channel  string    `json:"channel,omitempty"`
+	Path     string    `json:"path"`
+	Size     int64     `json:"size,omitempty"`
+	Finished time.Time `json:"finished"`
+	Verify   string    `json:"verify,omitempty"`
+	Problems []string  `json:"problems,omitempty"`
+	SHA256   string    `json:"sha256,omitempty"`
+	// Files are the checksummed files of a preserved download.
+	Files []archivedFile `json:"files,omitempty"`
+	// Integrity and Checked record the last "Verify files" run.
+	Integrity string    `json:"integrity,omitempty"`
+	Checked   time.Time `json:"checked,omitempty"`
+	// Settings lets a suspect download be queued again as it was.
+	Settings jobSettings `json:"settings"`
+	Tags     []string    `json:"tags,omitempty"`
+	Note     string      `json:"note,omitempty"`
+	// Imported entries come from a yt-dlp archive file, which has no Path,
+	// or a folder of files downloaded with another tool.
+	Imported bool `json:"imported,omitempty"`
+}
+
+func (e historyEntry) title() string {
+	if e.Path == "" {
+		return e.VideoID
+	}
+	return strings.TrimSuffix(filepath.Base(e.Path), filepath.Ext(e.Path))
+}
+
+// jobSettings returns the settings to queue the entry again with, carrying
+// its current tags and note.
+func (e historyEntry) jobSettings() jobSettings {
+	s := e.Settings
+	s.Tags, s.Note = e.Tags, e.Note
+	return s
+}
+
+// historyStore keeps finished downloads in a JSON-lines file in the app dir.
+// Normally the whole file is rewritten on change; in low memory mode entries
+// are appended instead, and the newest line for an ID wins when loading.
+type historyStore struct {
+	mu       sync.Mutex
+	entries  []historyEntry
+	onChange func()
+}
+
+func historyPath() (string, error) {
+	dir, err := downloader.AppDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, historyFileName), nil
+}
+
+func (h *historyStore) load() error {
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	byID := map[int64]int{}
+	var entries []historyEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var e historyEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			continue
+		}
+		if i, ok := byID[e.ID]; ok {
+			entries[i] = e
+			continue
+		}
+		byID[e.ID] = len(entries)
+		entries = append(entries, e)
+	}
+	h.mu.Lock()
+	h.entries = entries
+	h.mu.Unlock()
+	return sc.Err()
+}
+
+func (h *historyStore) add(e historyEntry) error {
+	h.mu.Lock()
+	if e.ID == 0 {
+		e.ID = time.Now().UnixNano()
+	}
+	h.entries = append(h.entries, e)
+	err := h.persistLocked(e)
+	h.mu.Unlock()
+	h.changed()
+	return err
+}
+
+// findVideo returns the latest download of the video with the given ID.
+func (h *historyStore) findVideo(videoID string) (historyEntry, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if h.entries[i].VideoID == videoID {
+			return h.entries[i], true
+		}
+	}
+	return historyEntry{}, false
+}
+
+func (h *historyStore) update(id int64, f func(*historyEntry)) error {
+	h.mu.Lock()
+	var err error
+	for i := range h.entries {
+		if h.entries[i].ID == id {
+			f(&h.entries[i])
+			err = h.persistLocked(h.entries[i])
+			break
+		}
+	}
+	h.mu.Unlock()
+	h.changed()
+	return err
+}
+
+func (h *historyStore) persistLocked(changed historyEntry) error {
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	if streamHistoryWrites() {
+		line, err := json.Marshal(changed)
+		if err != nil {
+			return err
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = f.Write(append(line, '\n'))
+		return err
+	}
+	return h.writeAllLocked(path)
+}
+
+// writeAllLocked rewrites the history file with every entry.
+func (h *historyStore) writeAllLocked(path string) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range h.entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// list returns the entries newest first.
+func (h *historyStore) list() []historyEntry {
+	h.mu.Lock()
+	out := append([]historyEntry(nil), h.entries...)
+	h.mu.Unlock()
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Finished.After(out[j].Finished) })
+	return out
+}
+
+func (h *historyStore) changed() {
+	if h.onChange != nil {
+		h.onChange()
+	}
+}
+
+// fileIntegrity compares the file at path with its recorded checksum.
+func fileIntegrity(path, want string) string {
+	sum, err := downloader.FileSHA256(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return integrityMissing
+	case err != nil || sum != want:
+		return integrityChanged
+	}
+	return integrityOK
+}
+
+// verifyChecksums re-hashes every entry with a recorded checksum, including
+// the sidecars of preserved downloads, and stores the results in one write
+// at the end. report is called after each entry with the running totals.
+func (h *historyStore) verifyChecksums(report func(done, total, changed, missing int)) error {
+	var todo []historyEntry
+	for _, e := range h.list() {
+		if e.SHA256 != "" {
+			todo = append(todo, e)
+		}
+	}
+	changed, missing := 0, 0
+	results := make(map[int64]string, len(todo))
+	for i, e := range todo {
+		result := fileIntegrity(e.Path, e.SHA256)
+		for _, f := range e.Files {
+			if result != integrityOK {
+				break
+			}
+			result = fileIntegrity(f.Path, f.SHA256)
+		}
+		switch result {
+		case integrityMissing:
+			missing++
+		case integrityChanged:
+			changed++
+		}
+		results[e.ID] = result
+		report(i+1, len(todo), changed, missing)
+	}
+	if len(todo) == 0 {
+		report(0, 0, 0, 0)
+		return nil
+	}
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	h.mu.Lock()
+	for i := range h.entries {
+		if result, ok := results[h.entries[i].ID]; ok {
+			h.entries[i].Integrity = result
+			h.entries[i].Checked = now
+		}
+	}
+	err = h.writeAllLocked(path)
+	h.mu.Unlock()
+	h.changed()
+	return err
+}
+
+func historyRowText(e historyEntry) string {
+	mark := ""
+	switch e.Verify {
+	case verifyOK:
+		mark = tr("[verified]") + " "
+	case verifySuspect:
+		mark = tr("[suspect]") + " "
+	}
+	switch e.Integrity {
+	case integrityChanged:
+		mark = tr("[changed]") + " " + mark
+	case integrityMissing:
+		mark = tr("[missing]") + " " + mark
+	}
+	text := fmt.Sprintf("%s%s  %s", mark, e.title(), e.Finished.Local().Format("2006-01-02 15:04"))
+	if len(e.Tags) > 0 {
+		text += "  {" + formatTags(e.Tags) + "}"
+	}
+	return text
+}
+
+// historyDetails describes an entry's tags and note.
+func historyDetails(e historyEntry) string {
+	var lines []string
+	if len(e.Tags) > 0 {
+		lines = append(lines, tr("Tags:")+" "+formatTags(e.Tags))
+	}
+	if e.Note != "" {
+		lines = append(lines, tr("Note:")+" "+e.Note)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// newHistoryPanel lists finished downloads, optionally only those with a
+// tag. redownload queues an entry again, replacing the existing file.
+func newHistoryPanel(w fyne.Window, h *historyStore, logf func(string), redownload func(historyEntry)) fyne.CanvasObject {
+	var entries []historyEntry
+	var selected *historyEntry
+	var refresh func()
+	tag := ""
+	tagFilter, setTags := newTagFilter(func(t string) {
+		tag = t
+		if refresh != nil {
+			refresh()
+		}
+	})
+	file := newFileActions(logf)
+	details := widget.NewLabel("")
+	details.Wrapping = fyne.TextWrapWord
+	problems := widget.NewLabel("")
+	problems.Wrapping = fyne.TextWrapWord
+	checksum := widget.NewLabel("")
+	checksum.Truncation = fyne.TextTruncateEllipsis
+	verifyStatus := widget.NewLabel("")
+	var verifyBtn *widget.Button
+	verifyBtn = widget.NewButton(tr("Verify files"), func() {
+		verifyBtn.Disable()
+		verifyStatus.SetText(tr("Verifying..."))
+		go func() {
+			err := h.verifyChecksums(func(done, total, changed, missing int) {
+				runOnMain(func() {
+					verifyStatus.SetText(trf("Checked %d of %d: %d changed, %d missing", done, total, changed, missing))
+				})
+			})
+			if err != nil {
+				logf(trf("Could not save history: %v", err))
+			}
+			runOnMain(verifyBtn.Enable)
+		}()
+	})
+	again := widget.NewButton(tr("Download again"), func() {
+		if selected != nil {
+			redownload(*selected)
+		}
+	})
+	again.Disable()
+	editTags := widget.NewButton(tr("Tags & Note..."), func() {
+		if selected == nil {
+			return
+		}
+		e := *selected
+		showTagEditor(w, e.title(), e.Tags, e.Note, func(tags []string, note string) {
+			if err := h.update(e.ID, func(entry *historyEntry) {
+				entry.Tags, entry.Note = tags, note
+			}); err != nil {
+				logf(trf("Could not save history: %v", err))
+			}
+		})
+	})
+	editTags.Disable()
+
+	list := widget.NewList(
+		func() int { return len(entries) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			if id < len(entries) {
+				item.(*widget.Label).SetText(historyRowText(entries[id]))
+			}
+		},
+	)
+	show := func(e *historyEntry) {
+		selected = e
+		if e == nil {
+			file.set("")
+			problems.SetText("")
+			checksum.SetText("")
+			details.SetText("")
+			again.Disable()
+			editTags.Disable()
+			return
+		}
+		if e.SHA256 != "" && len(e.Files) > 0 {
+			checksum.SetText("SHA-256: " + e.SHA256 + " " + trf("(+%d archived files)", len(e.Files)-1))
+		} else if e.SHA256 != "" {
+			checksum.SetText("SHA-256: " + e.SHA256)
+		} else {
+			checksum.SetText("")
+		}
+		file.set(e.Path)
+		if len(e.Problems) > 0 {
+			problems.SetText(tr("Problems:") + " " + strings.Join(e.Problems, "; "))
+		} else {
+			problems.SetText("")
+		}
+		details.SetText(historyDetails(*e))
+		if e.URL != "" {
+			again.Enable()
+		} else {
+			again.Disable()
+		}
+		editTags.Enable()
+	}
+	list.OnSelected = func(id widget.ListItemID) {
+		if id < len(entries) {
+			e := entries[id]
+			show(&e)
+		}
+	}
+	refresh = func() {
+		all := h.list()
+		setTags(historyTags(all))
+		entries = entries[:0]
+		for _, e := range all {
+			if tag == "" || hasTag(e.Tags, tag) {
+				entries = append(entries, e)
+			}
+		}
+		list.UnselectAll()
+		show(nil)
+		list.Refresh()
+	}
+	h.onChange = func() { runOnMain(refresh) }
+	refresh()
+
+	return container.NewBorder(
+		container.NewBorder(nil, nil, widget.NewLabel(tr("Show:")), nil, tagFilter),
+		container.NewVBox(file.box, details, problems, checksum, container.NewHBox(again, editTags, verifyBtn, verifyStatus)),
+		nil, nil,
+		list,
+	)
+}
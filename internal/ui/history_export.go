@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// historyRecord is one download in an exported history.
+type historyRecord struct {
+	URL     string   `json:"url"`
+	Title   string   `json:"title"`
+	Channel string   `json:"channel,omitempty"`
+	Date    string   `json:"date"`
+	Size    int64    `json:"size,omitempty"`
+	Path    string   `json:"path"`
+	SHA256  string   `json:"sha256,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Note    string   `json:"note,omitempty"`
+}
+
+// historyRecords converts entries for export. Entries recorded before the
+// title and size were kept fall back to the file name and the file on disk.
+func historyRecords(entries []historyEntry) []historyRecord {
+	out := make([]historyRecord, 0, len(entries))
+	for _, e := range entries {
+		r := historyRecord{
+			URL:     e.URL,
+			Title:   valueOr(e.Title, e.title()),
+			Channel: e.Channel,
+			Date:    e.Finished.Format(time.RFC3339),
+			Size:    e.Size,
+			Path:    e.Path,
+			SHA256:  e.SHA256,
+			Tags:    e.Tags,
+			Note:    e.Note,
+		}
+		if r.Size == 0 {
+			if info, err := os.Stat(e.Path); err == nil {
+				r.Size = info.Size()
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// writeHistoryFile writes the history as CSV for a .csv name and as JSON
+// otherwise.
+func writeHistoryFile(w io.Writer, name string, entries []historyEntry) error {
+	records := historyRecords(entries)
+	if strings.ToLower(filepath.Ext(name)) != ".csv" {
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"url", "title", "channel", "date", "size", "path", "sha256", "tags", "note"})
+	for _, r := range records {
+		size := ""
+		if r.Size > 0 {
+			size = strconv.FormatInt(r.Size, 10)
+		}
+		cw.Write([]string{r.URL, r.Title, r.Channel, r.Date, size, r.Path, r.SHA256, strings.Join(r.Tags, ","), r.Note})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Translations are keyed by the English source string, so untranslated text
+// simply falls through and English needs no catalog of its own.
+//
+//go:embed locales/*.json
+var localeFS embed.FS
+
+const prefLanguage = "language"
+
+type language struct {
+	code string
+	name string
+}
+
+var languages = []language{
+	{code: "", name: "System default"},
+	{code: "en", name: "English"},
+	{code: "de", name: "Deutsch"},
+}
+
+var (
+	catalogMu sync.RWMutex
+	catalog   map[string]string
+)
+
+// setLanguage loads the catalog for code, or for the system language when
+// code is empty. Unknown languages fall back to English.
+func setLanguage(code string) {
+	if code == "" {
+		code = systemLanguage()
+	}
+	var msgs map[string]string
+	if code != "en" {
+		if data, err := localeFS.ReadFile("locales/" + code + ".json"); err == nil {
+			_ = json.Unmarshal(data, &msgs)
+		}
+	}
+	catalogMu.Lock()
+	catalog = msgs
+	catalogMu.Unlock()
+}
+
+func tr(s string) string {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	if t, ok := catalog[s]; ok && t != "" {
+		return t
+	}
+	return s
+}
+
+func trf(format string, args ...any) string {
+	return fmt.Sprintf(tr(format), args...)
+}
+
+// trList and untr translate Select options for display and map the chosen
+// option back, so the rest of the code keeps comparing English values.
+func trList(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = tr(v)
+	}
+	return out
+}
+
+func untr(values []string, shown string) string {
+	for _, v := range values {
+		if tr(v) == shown {
+			return v
+		}
+	}
+	return shown
+}
+
+// languageBase reduces locale names such as "de_DE.UTF-8" or "de-AT" to "de".
+func languageBase(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "_-.@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if locale == "" || locale == "c" || locale == "posix" {
+		return "en"
+	}
+	return locale
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+// showJobEditor edits the settings snapshot of a queued job. qualities and
+// profiles are the option lists of the main window's selectors; save
+// receives the edited copy.
+func showJobEditor(w fyne.Window, job downloadJob, qualities, profiles []string, save func(jobSettings)) {
+	settings := job.Settings
+	if !containsString(qualities, settings.Quality) {
+		qualities = append(append([]string(nil), qualities...), settings.Quality)
+	}
+	quality := widget.NewSelect(trList(qualities), nil)
+	quality.SetSelected(tr(settings.Quality))
+	profile := widget.NewSelect(trList(profiles), nil)
+	profile.SetSelected(tr(settings.Profile))
+	subs := widget.NewCheck(tr("Download Subtitles"), nil)
+	subs.SetChecked(settings.Subtitles)
+	folder := widget.NewEntry()
+	folder.SetText(settings.Folder)
+	tags := widget.NewEntry()
+	tags.SetPlaceHolder(tr("music, lecture, backup"))
+	tags.SetText(formatTags(settings.Tags))
+	note := widget.NewEntry()
+	note.SetText(settings.Note)
+	browse := widget.NewButton(tr("Browse..."), func() {
+		dialog.ShowFolderOpen(func(lu fyne.ListableURI, err error) {
+			if err == nil && lu != nil {
+				folder.SetText(lu.Path())
+			}
+		}, w)
+	})
+
+	form := container.NewVBox(
+		widget.NewLabel(job.URL),
+		widget.NewForm(
+			widget.NewFormItem(tr("Quality"), quality),
+			widget.NewFormItem(tr("Output profile"), profile),
+			widget.NewFormItem(tr("Folder"), container.NewBorder(nil, nil, nil, browse, folder)),
+			widget.NewFormItem("", subs),
+			widget.NewFormItem(tr("Tags"), tags),
+			widget.NewFormItem(tr("Note"), note),
+		),
+	)
+	d := dialog.NewCustomConfirm(trf("Job #%d", job.ID), tr("Save"), tr("Cancel"), form, func(ok bool) {
+		if !ok {
+			return
+		}
+		dir := strings.TrimSpace(folder.Text)
+		if dir != settings.Folder {
+			if err := downloader.ValidateFolder(dir); err != nil {
+				dialog.ShowError(fmt.Errorf("%s: %w", dir, err), w)
+				return
+			}
+		}
+		settings.Quality = untr(qualities, quality.Selected)
+		settings.Profile = untr(profiles, profile.Selected)
+		settings.Subtitles = subs.Checked
+		settings.Folder = dir
+		settings.Tags = parseTags(tags.Text)
+		settings.Note = strings.TrimSpace(note.Text)
+		save(settings)
+	}, w)
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+}
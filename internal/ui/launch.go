@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	urlScheme        = "ytgui"
+	instanceAddrFile = "instance.addr"
+)
+
+// parseLaunchArg accepts either a plain http(s) video URL or a
+// ytgui://download?url=<encoded URL> link and returns the video URL.
+func parseLaunchArg(arg string) (string, bool) {
+	arg = strings.TrimSpace(arg)
+	u, err := url.Parse(arg)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		if u.Host == "" {
+			return "", false
+		}
+		return arg, true
+	case urlScheme:
+		target := strings.TrimSpace(u.Query().Get("url"))
+		if target == "" || target == arg {
+			return "", false
+		}
+		return parseLaunchArg(target)
+	default:
+		return "", false
+	}
+}
+
+func launchURLs(args []string) []string {
+	var out []string
+	for _, a := range args {
+		if u, ok := parseLaunchArg(a); ok {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+func instanceAddrPath() (string, error) {
+	dir, err := downloader.AppDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, instanceAddrFile), nil
+}
+
+// forwardToRunningInstance hands URLs to an already running ytgui so a
+// browser-launched ytgui:// link lands in the existing queue instead of
+// opening a second window. With no URLs it just brings that window up. It
+// returns false when no instance answered.
+func forwardToRunningInstance(urls []string) bool {
+	p, err := instanceAddrPath()
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return false
+	}
+	addr, secret, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	conn, err := net.DialTimeout("tcp", strings.TrimSpace(addr), 2*time.Second)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	w := bufio.NewWriter(conn)
+	w.WriteString(strings.TrimSpace(secret) + "\n")
+	for _, u := range urls {
+		w.WriteString(u + "\n")
+	}
+	if err := w.Flush(); err != nil {
+		return false
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.CloseWrite()
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	return err == nil && strings.TrimSpace(reply) == "ok"
+}
+
+// listenForLaunches accepts URLs forwarded by later instances and passes each
+// valid one to onURL; a later instance started without URLs calls onShow.
+// The returned func stops listening.
+//
+// Any local process can connect to the port, so a random secret is written
+// to instance.addr next to it, readable only by this user, and a connection
+// must send it as its first line before its URLs are taken.
+func listenForLaunches(onURL func(string), onShow func()) (func(), error) {
+	p, err := instanceAddrPath()
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	secret := hex.EncodeToString(key)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	content := []byte(ln.Addr().String() + "\n" + secret + "\n")
+	if err := os.WriteFile(p, content, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				_ = c.SetDeadline(time.Now().Add(5 * time.Second))
+				sc := bufio.NewScanner(c)
+				if !sc.Scan() || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(sc.Text())), []byte(secret)) != 1 {
+					return
+				}
+				forwarded := false
+				for sc.Scan() {
+					if u, ok := parseLaunchArg(sc.Text()); ok {
+						onURL(u)
+						forwarded = true
+					}
+				}
+				if !forwarded {
+					onShow()
+				}
+				c.Write([]byte("ok\n"))
+			}(conn)
+		}
+	}()
+	return func() {
+		ln.Close()
+		// Leave the file alone if another instance has taken it over.
+		if data, err := os.ReadFile(p); err == nil && bytes.Equal(data, content) {
+			os.Remove(p)
+		}
+	}, nil
+}
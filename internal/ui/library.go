@@ -0,0 +1,343 @@
+package ui
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefLibrarySort = "library_sort"
+
+	librarySortNewest  = "Newest first"
+	librarySortOldest  = "Oldest first"
+	librarySortName    = "Name"
+	librarySortLargest = "Largest first"
+)
+
+var librarySorts = []string{librarySortNewest, librarySortOldest, librarySortName, librarySortLargest}
+
+var libraryMediaExts = map[string]bool{
+	".mp4": true, ".mkv": true, ".webm": true, ".mov": true, ".avi": true, ".gif": true,
+	".mp3": true, ".m4a": true, ".opus": true, ".ogg": true, ".flac": true, ".wav": true,
+}
+
+var libraryAudioExts = map[string]bool{".mp3": true, ".m4a": true, ".opus": true, ".ogg": true, ".flac": true, ".wav": true}
+
+// libraryItem is a media file in the library; entry is its history record
+// when it was downloaded by the app.
+type libraryItem struct {
+	path     string
+	title    string
+	size     int64
+	modified time.Time
+	thumb    string
+	entry    *historyEntry
+}
+
+// libraryThumb finds a thumbnail saved next to path.
+func libraryThumb(path string) string {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, ext := range []string{".jpg", ".png", ".webp"} {
+		if _, err := os.Stat(base + ext); err == nil {
+			return base + ext
+		}
+	}
+	return ""
+}
+
+func isLibraryMedia(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	if strings.HasSuffix(name, ".part") || strings.Contains(name, ".temp.") {
+		return false
+	}
+	return libraryMediaExts[filepath.Ext(name)]
+}
+
+// scanLibrary lists the media files in dirs and their subfolders, plus
+// downloads recorded in the history that live elsewhere and still exist.
+func scanLibrary(dirs []string, history []historyEntry) []libraryItem {
+	byPath := map[string]*historyEntry{}
+	for i := range history {
+		byPath[strings.ToLower(filepath.Clean(history[i].Path))] = &history[i]
+	}
+	seen := map[string]bool{}
+	var items []libraryItem
+	add := func(path string, info fs.FileInfo) {
+		key := strings.ToLower(filepath.Clean(path))
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		items = append(items, libraryItem{
+			path:     path,
+			title:    strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			size:     info.Size(),
+			modified: info.ModTime(),
+			thumb:    libraryThumb(path),
+			entry:    byPath[key],
+		})
+	}
+	for _, dir := range dirs {
+		if strings.TrimSpace(dir) == "" {
+			continue
+		}
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !isLibraryMedia(path) {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				add(path, info)
+			}
+			return nil
+		})
+	}
+	for _, e := range history {
+		if info, err := os.Stat(e.Path); err == nil && !info.IsDir() {
+			add(e.Path, info)
+		}
+	}
+	return items
+}
+
+// matches reports whether the item passes the search query and tag filter.
+// The query also matches the tags and note of downloads in the history.
+func (it libraryItem) matches(query, tag string) bool {
+	if tag != "" && (it.entry == nil || !hasTag(it.entry.Tags, tag)) {
+		return false
+	}
+	if query == "" || strings.Contains(strings.ToLower(it.title), query) {
+		return true
+	}
+	return it.entry != nil && (strings.Contains(strings.ToLower(it.entry.Note), query) || hasTag(it.entry.Tags, query))
+}
+
+func sortLibrary(items []libraryItem, order string) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		switch order {
+		case librarySortOldest:
+			return a.modified.Before(b.modified)
+		case librarySortName:
+			return strings.ToLower(a.title) < strings.ToLower(b.title)
+		case librarySortLargest:
+			return a.size > b.size
+		}
+		return a.modified.After(b.modified)
+	})
+}
+
+// libraryPanel is the Library tab: the files in the download folders with
+// actions for each.
+type libraryPanel struct {
+	box     fyne.CanvasObject
+	refresh func()
+}
+
+// newLibraryPanel builds the Library tab. dirs returns the folders to scan;
+// redownload queues a history entry again.
+func newLibraryPanel(w fyne.Window, prefs fyne.Preferences, dirs func() []string, h *historyStore, logf func(string), redownload func(historyEntry)) *libraryPanel {
+	var (
+		mu       sync.Mutex
+		all      []libraryItem
+		shown    []libraryItem
+		selected *libraryItem
+	)
+	search := widget.NewEntry()
+	search.SetPlaceHolder(tr("Search the library"))
+	sortSelect := widget.NewSelect(trList(librarySorts), nil)
+	info := widget.NewLabel("")
+	tag := ""
+	var apply func()
+	tagFilter, setTags := newTagFilter(func(t string) {
+		tag = t
+		if apply != nil {
+			apply()
+		}
+	})
+
+	file := newFileActions(logf)
+	var again, remove, editTags *widget.Button
+	list := widget.NewList(
+		func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(shown)
+		},
+		func() fyne.CanvasObject {
+			img := canvas.NewImageFromResource(theme.FileVideoIcon())
+			img.FillMode = canvas.ImageFillContain
+			img.SetMinSize(fyne.NewSize(96, 54))
+			title := widget.NewLabel("")
+			title.TextStyle = fyne.TextStyle{Bold: true}
+			title.Truncation = fyne.TextTruncateEllipsis
+			meta := widget.NewLabel("")
+			return container.NewBorder(nil, nil, img, nil, container.NewVBox(title, meta))
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			mu.Lock()
+			if id >= len(shown) {
+				mu.Unlock()
+				return
+			}
+			it := shown[id]
+			mu.Unlock()
+			row := o.(*fyne.Container)
+			text := row.Objects[0].(*fyne.Container)
+			img := row.Objects[1].(*canvas.Image)
+			text.Objects[0].(*widget.Label).SetText(it.title)
+			meta := formatBytes(it.size) + "  " + it.modified.Local().Format("2006-01-02 15:04") + "  " + filepath.Dir(it.path)
+			if it.entry != nil && len(it.entry.Tags) > 0 {
+				meta += "  {" + formatTags(it.entry.Tags) + "}"
+			}
+			text.Objects[1].(*widget.Label).SetText(meta)
+			switch {
+			case it.thumb != "":
+				img.Resource = nil
+				img.File = it.thumb
+			case libraryAudioExts[strings.ToLower(filepath.Ext(it.path))]:
+				img.File = ""
+				img.Resource = theme.FileAudioIcon()
+			default:
+				img.File = ""
+				img.Resource = theme.FileVideoIcon()
+			}
+			img.Refresh()
+		},
+	)
+	show := func(it *libraryItem) {
+		selected = it
+		if it == nil {
+			file.set("")
+			again.Disable()
+			remove.Disable()
+			editTags.Disable()
+			return
+		}
+		file.set(it.path)
+		remove.Enable()
+		if it.entry != nil {
+			again.Enable()
+			editTags.Enable()
+		} else {
+			again.Disable()
+			editTags.Disable()
+		}
+	}
+	apply = func() {
+		query := strings.ToLower(strings.TrimSpace(search.Text))
+		mu.Lock()
+		shown = shown[:0]
+		for _, it := range all {
+			if it.matches(query, tag) {
+				shown = append(shown, it)
+			}
+		}
+		sortLibrary(shown, untr(librarySorts, sortSelect.Selected))
+		var total int64
+		for _, it := range shown {
+			total += it.size
+		}
+		n := len(shown)
+		mu.Unlock()
+		info.SetText(trf("%d file(s), %s", n, formatBytes(total)))
+		list.UnselectAll()
+		show(nil)
+		list.Refresh()
+	}
+	refresh := func() {
+		info.SetText(tr("Scanning..."))
+		go func() {
+			history := h.list()
+			items := scanLibrary(dirs(), history)
+			mu.Lock()
+			all = items
+			mu.Unlock()
+			runOnMain(func() {
+				setTags(historyTags(history))
+				apply()
+			})
+		}()
+	}
+	search.OnChanged = func(string) { apply() }
+	sortSelect.OnChanged = func(s string) {
+		prefs.SetString(prefLibrarySort, untr(librarySorts, s))
+		apply()
+	}
+	sortSelect.SetSelected(tr(selectedOption(prefs, prefLibrarySort, librarySorts, librarySortNewest)))
+
+	again = widget.NewButton(tr("Download again"), func() {
+		if selected != nil && selected.entry != nil {
+			redownload(*selected.entry)
+		}
+	})
+	editTags = widget.NewButton(tr("Tags & Note..."), func() {
+		if selected == nil || selected.entry == nil {
+			return
+		}
+		e := *selected.entry
+		showTagEditor(w, e.title(), e.Tags, e.Note, func(tags []string, note string) {
+			if err := h.update(e.ID, func(entry *historyEntry) {
+				entry.Tags, entry.Note = tags, note
+			}); err != nil {
+				logf(trf("Could not save history: %v", err))
+			}
+			refresh()
+		})
+	})
+	remove = widget.NewButtonWithIcon(tr("Delete"), theme.DeleteIcon(), func() {
+		if selected == nil {
+			return
+		}
+		it := *selected
+		dialog.ShowConfirm(tr("Delete File"), trf("Delete %s from disk?", filepath.Base(it.path)), func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := downloader.RecycleFile(it.path); err != nil {
+				logf(trf("Could not delete %s: %v", it.path, err))
+				return
+			}
+			if it.thumb != "" {
+				os.Remove(it.thumb)
+			}
+			logf(trf("Deleted: %s", it.path))
+			refresh()
+		}, w)
+	})
+	show(nil)
+	list.OnSelected = func(id widget.ListItemID) {
+		mu.Lock()
+		if id >= len(shown) {
+			mu.Unlock()
+			return
+		}
+		it := shown[id]
+		mu.Unlock()
+		show(&it)
+	}
+	rescan := newIconButton(tr("Rescan"), theme.ViewRefreshIcon(), refresh)
+
+	return &libraryPanel{
+		box: container.NewBorder(
+			container.NewBorder(nil, nil, nil, container.NewHBox(tagFilter, sortSelect, rescan), search),
+			container.NewVBox(file.box, container.NewHBox(again, editTags, remove, info)),
+			nil, nil,
+			list,
+		),
+		refresh: refresh,
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package ui
+
+import "os"
+
+func systemLanguage() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			return languageBase(v)
+		}
+	}
+	return "en"
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"context"
+	"path/filepath"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefLoudness = "last_loudness"
+
+	loudnessOff     = "Off"
+	loudnessEBU     = "EBU R128 (-23 LUFS)"
+	loudnessPodcast = "Podcast (-16 LUFS)"
+	loudnessMusic   = "Music (-14 LUFS)"
+)
+
+// loudnessOptions are the normalization targets for Audio Only downloads.
+var loudnessOptions = []string{loudnessOff, loudnessEBU, loudnessPodcast, loudnessMusic}
+
+// loudnessTarget returns the LUFS target of an option, 0 for Off.
+func loudnessTarget(option string) float64 {
+	switch option {
+	case loudnessEBU:
+		return downloader.LoudnessEBUR128
+	case loudnessPodcast:
+		return downloader.LoudnessPodcast
+	case loudnessMusic:
+		return downloader.LoudnessMusic
+	}
+	return 0
+}
+
+// normalizeDownload evens out the loudness of an audio download in place,
+// as the last stage of the progress bar. On failure the file is kept as
+// downloaded and the reason logged.
+func normalizeDownload(ctx context.Context, ffmpeg, path string, target float64, audioQuality string, ev publisher) {
+	// ffprobe is installed next to ffmpeg.
+	probe, err := downloader.ProbeMedia(filepath.Join(filepath.Dir(ffmpeg), "ffprobe.exe"), path)
+	if err != nil {
+		ev.log(trf("Could not read the audio, skipping loudness normalization: %v", err))
+		return
+	}
+	bitrate := ""
+	if audioQuality != audioQualityBest {
+		bitrate = audioQuality
+	}
+	ev.log(trf("Normalizing loudness to %.0f LUFS...", target))
+	ev.SetText(tr("Normalizing loudness..."))
+	err = downloader.NormalizeLoudness(ctx, ffmpeg, path, target, bitrate, probe, func(p float64) {
+		ev.SetValue(downloadShare + (1-downloadShare)*p)
+		ev.SetText(trf("Normalizing loudness... %.0f%%", p*100))
+	})
+	if err != nil {
+		ev.log(trf("Loudness normalization failed, keeping the original audio: %v", err))
+		return
+	}
+	ev.log(tr("Loudness normalized."))
+}
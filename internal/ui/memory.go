@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	prefLowMemory = "low_memory_mode"
+
+	// lowMemoryLogLines is how many trailing lines each log keeps while
+	// low-memory mode is on.
+	lowMemoryLogLines = 300
+)
+
+// lowMemoryMode is read from hot paths (every log line), so it lives in an
+// atomic rather than being looked up in preferences each time.
+var lowMemoryMode atomic.Bool
+
+func setLowMemoryMode(on bool) {
+	lowMemoryMode.Store(on)
+	if on {
+		debug.SetGCPercent(50)
+		debug.FreeOSMemory()
+	} else {
+		debug.SetGCPercent(100)
+	}
+}
+
+// thumbnailsEnabled reports whether views should fetch and keep preview
+// images in memory.
+func thumbnailsEnabled() bool {
+	return !lowMemoryMode.Load()
+}
+
+// streamHistoryWrites reports whether history should be appended to disk as
+// entries arrive instead of being held in memory and saved in bulk.
+func streamHistoryWrites() bool {
+	return lowMemoryMode.Load()
+}
+
+func trimLogText(text string) string {
+	if !lowMemoryMode.Load() {
+		return text
+	}
+	lines := 0
+	for i := len(text) - 1; i >= 0; i-- {
+		if text[i] != '\n' || i == len(text)-1 {
+			continue
+		}
+		lines++
+		if lines == lowMemoryLogLines {
+			return text[i+1:]
+		}
+	}
+	return text
+}
+
+func memoryReport() string {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	lines := []string{
+		trf("Heap in use: %s", formatBytes(int64(m.HeapAlloc))),
+		trf("Reserved from OS: %s", formatBytes(int64(m.Sys))),
+		trf("Garbage collections: %d", m.NumGC),
+		trf("Goroutines: %d", runtime.NumGoroutine()),
+	}
+	return strings.Join(lines, "\n")
+}
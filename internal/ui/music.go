@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// musicPresetPrefix picks the preset suggested for YouTube Music links: the
+// first one whose name starts with it, such as the default "Music - best
+// audio".
+const musicPresetPrefix = "Music"
+
+// visualTitle matches title tags that say the picture is the point of a
+// video, where Audio Only is most likely a mistake.
+var visualTitle = regexp.MustCompile(`(?i)\b(4k|8k|uhd|2160p|1440p|hdr|60 ?fps|visuali[sz]er|timelapse|drone footage|gameplay)\b`)
+
+func isYouTubeMusicURL(link string) bool {
+	u, err := url.Parse(strings.TrimSpace(link))
+	return err == nil && strings.EqualFold(u.Hostname(), "music.youtube.com")
+}
+
+func looksVisual(title string) bool {
+	return visualTitle.MatchString(title)
+}
+
+// musicPresetName returns the name of the preset to suggest for music, or
+// "" when there is none and the quality alone is switched to Audio Only.
+func musicPresetName(presets []qualityPreset) string {
+	for _, p := range presets {
+		if p.Quality == "Audio Only" && strings.HasPrefix(strings.ToLower(p.Name), strings.ToLower(musicPresetPrefix)) {
+			return p.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+// splitCommandLine splits typed arguments on whitespace, keeping single- or
+// double-quoted parts together.
+func splitCommandLine(s string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// nerdConsole runs typed yt-dlp arguments with the managed binary and shows
+// the output in the nerd terminal, through the same pipeline as downloads.
+type nerdConsole struct {
+	entry *widget.Entry
+	run   *widget.Button
+	stop  *widget.Button
+	box   fyne.CanvasObject
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// newNerdConsole builds the command bar. tools returns the prepared yt-dlp
+// and ffmpeg paths, or empty strings while they are not ready.
+func newNerdConsole(tools func() (string, string), ev publisher) *nerdConsole {
+	c := &nerdConsole{entry: widget.NewEntry()}
+	c.entry.SetPlaceHolder(tr("yt-dlp arguments, e.g. -F <URL>"))
+	c.run = widget.NewButtonWithIcon(tr("Run"), theme.MediaPlayIcon(), func() {
+		ytdlp, ffmpeg := tools()
+		if ytdlp == "" {
+			ev.raw("[console] tools are not ready yet")
+			return
+		}
+		args, err := splitCommandLine(strings.TrimSpace(c.entry.Text))
+		if err != nil {
+			ev.raw(fmt.Sprintf("[console] %v", err))
+			return
+		}
+		// Accept input pasted with the program name in front.
+		if len(args) > 0 && strings.EqualFold(strings.TrimSuffix(filepath.Base(args[0]), ".exe"), "yt-dlp") {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			return
+		}
+		if ffmpeg != "" {
+			args = append([]string{"--ffmpeg-location", filepath.Dir(ffmpeg)}, args...)
+		}
+		c.start(ytdlp, args, ev)
+	})
+	c.stop = widget.NewButtonWithIcon(tr("Stop"), theme.MediaStopIcon(), func() {
+		c.mu.Lock()
+		if c.cancel != nil {
+			c.cancel()
+		}
+		c.mu.Unlock()
+	})
+	c.stop.Disable()
+	c.entry.OnSubmitted = func(string) {
+		if !c.run.Disabled() {
+			c.run.OnTapped()
+		}
+	}
+	c.box = container.NewBorder(nil, nil, widget.NewLabel("yt-dlp"), container.NewHBox(c.run, c.stop), c.entry)
+	return c
+}
+
+func (c *nerdConsole) start(ytdlp string, args []string, ev publisher) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.mu.Lock()
+	c.cancel = cancel
+	c.mu.Unlock()
+	c.run.Disable()
+	c.stop.Enable()
+
+	ev.raw("> " + formatCommandLine(ytdlp, args))
+	go func() {
+		defer func() {
+			cancel()
+			c.mu.Lock()
+			c.cancel = nil
+			c.mu.Unlock()
+			runOnMain(func() {
+				c.run.Enable()
+				c.stop.Disable()
+			})
+		}()
+		proc, err := downloader.DefaultRunner.Start(ctx, ytdlp, args)
+		if err != nil {
+			ev.raw(fmt.Sprintf("[console] failed to start: %v", err))
+			return
+		}
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			scanAndLog(proc.Stdout(), ev, nil)
+		}()
+		go func() {
+			defer wg.Done()
+			scanAndLog(proc.Stderr(), ev, nil)
+		}()
+		err = proc.Wait()
+		wg.Wait()
+		switch {
+		case ctx.Err() != nil:
+			ev.raw("[console] stopped")
+		case err != nil:
+			ev.raw(fmt.Sprintf("[console] exited: %v", err))
+		default:
+			ev.raw("[console] done")
+		}
+	}()
+}
@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	nerdLevelAll      = "All lines"
+	nerdLevelWarnings = "Warnings and errors"
+	nerdLevelErrors   = "Errors only"
+)
+
+var nerdLevels = []string{nerdLevelAll, nerdLevelWarnings, nerdLevelErrors}
+
+// nerdTerminal wraps the raw log with a find bar, level filter, auto-scroll
+// and copy actions. The full log keeps receiving every line; while a filter
+// is active a second entry shows just the matching lines.
+type nerdTerminal struct {
+	log        *widget.Entry
+	view       *widget.Entry
+	find       *widget.Entry
+	useRegex   *widget.Check
+	level      *widget.Select
+	autoScroll *widget.Check
+	info       *widget.Label
+	stack      *fyne.Container
+	box        fyne.CanvasObject
+}
+
+func newNerdTerminal(log *widget.Entry, clipboard fyne.Clipboard) *nerdTerminal {
+	t := &nerdTerminal{
+		log:  log,
+		view: widget.NewMultiLineEntry(),
+		find: widget.NewEntry(),
+		info: widget.NewLabel(""),
+	}
+	t.view.Wrapping = log.Wrapping
+	t.view.Hide()
+	t.find.SetPlaceHolder(tr("Find in output"))
+	t.find.OnChanged = func(string) { t.refresh() }
+	t.useRegex = widget.NewCheck(tr("Regex"), func(bool) { t.refresh() })
+	t.autoScroll = widget.NewCheck(tr("Auto-scroll"), nil)
+	t.autoScroll.SetChecked(true)
+	t.autoScroll.OnChanged = func(on bool) {
+		if on {
+			t.scrollToEnd()
+		}
+	}
+	t.level = widget.NewSelect(trList(nerdLevels), nil)
+	t.level.SetSelected(tr(nerdLevelAll))
+	t.level.OnChanged = func(string) { t.refresh() }
+	copyAll := widget.NewButtonWithIcon(tr("Copy all"), theme.ContentCopyIcon(), func() {
+		clipboard.SetContent(t.visible().Text)
+		t.info.SetText(tr("Copied to clipboard."))
+	})
+	copySelection := widget.NewButton(tr("Copy selection"), func() {
+		sel := t.visible().SelectedText()
+		if sel == "" {
+			t.info.SetText(tr("Nothing selected."))
+			return
+		}
+		clipboard.SetContent(sel)
+		t.info.SetText(tr("Copied to clipboard."))
+	})
+	log.OnChanged = func(string) {
+		if t.filtering() {
+			t.refresh()
+		} else {
+			t.scrollToEnd()
+		}
+	}
+
+	t.stack = container.NewStack(log, t.view)
+	bar := container.NewBorder(nil, nil, nil,
+		container.NewHBox(t.useRegex, t.level, t.autoScroll, copyAll, copySelection),
+		t.find)
+	t.box = container.NewBorder(container.NewVBox(bar, t.info), nil, nil, nil, t.stack)
+	return t
+}
+
+func (t *nerdTerminal) filtering() bool {
+	return strings.TrimSpace(t.find.Text) != "" || untr(nerdLevels, t.level.Selected) != nerdLevelAll
+}
+
+func (t *nerdTerminal) visible() *widget.Entry {
+	if t.view.Visible() {
+		return t.view
+	}
+	return t.log
+}
+
+// refresh rebuilds the filtered view from the full log. Must be called on
+// the main thread.
+func (t *nerdTerminal) refresh() {
+	if !t.filtering() {
+		t.view.Hide()
+		t.log.Show()
+		t.info.SetText("")
+		t.scrollToEnd()
+		return
+	}
+	match := func(string) bool { return true }
+	if pattern := strings.TrimSpace(t.find.Text); pattern != "" {
+		if t.useRegex.Checked {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				t.info.SetText(trf("Invalid pattern: %v", err))
+				return
+			}
+			match = re.MatchString
+		} else {
+			lower := strings.ToLower(pattern)
+			match = func(line string) bool { return strings.Contains(strings.ToLower(line), lower) }
+		}
+	}
+	level := untr(nerdLevels, t.level.Selected)
+	var out []string
+	for _, line := range strings.Split(t.log.Text, "\n") {
+		if line == "" || !nerdLevelMatch(level, line) || !match(line) {
+			continue
+		}
+		out = append(out, line)
+	}
+	t.view.SetText(strings.Join(out, "\n"))
+	t.info.SetText(trf("%d matching line(s)", len(out)))
+	t.log.Hide()
+	t.view.Show()
+	t.scrollToEnd()
+}
+
+func nerdLevelMatch(level, line string) bool {
+	upper := strings.ToUpper(line)
+	isError := strings.Contains(upper, "ERROR")
+	switch level {
+	case nerdLevelErrors:
+		return isError
+	case nerdLevelWarnings:
+		return isError || strings.Contains(upper, "WARNING")
+	}
+	return true
+}
+
+// scrollToEnd moves the cursor of the visible entry to the last line, which
+// scrolls it into view, when auto-scroll is on.
+func (t *nerdTerminal) scrollToEnd() {
+	if !t.autoScroll.Checked {
+		return
+	}
+	e := t.visible()
+	e.CursorRow = strings.Count(e.Text, "\n")
+	e.CursorColumn = 0
+	e.Refresh()
+}
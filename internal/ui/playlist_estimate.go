@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefConfirmPlaylistSize = "playlist_confirm_size"
+
+	// estimateWorkers bounds the per-item probes run at once while
+	// estimating a playlist's size.
+	estimateWorkers = 4
+	// estimateSample is how many items of a longer playlist are probed; the
+	// rest is extrapolated, since every probe is a yt-dlp run.
+	estimateSample = 20
+)
+
+func sizeEstimateText(est downloader.SizeEstimate) string {
+	if est.Extrapolated() {
+		if est.Unknown == est.Sampled {
+			return trf("Size unknown for %d videos", est.Items)
+		}
+		return trf("≈ %s across %d videos, extrapolated from %d of them", formatBytes(est.Bytes), est.Items, est.Sampled-est.Unknown)
+	}
+	text := trf("≈ %s across %d videos", formatBytes(est.Bytes), est.Items)
+	if est.Unknown > 0 {
+		text += " " + trf("(size unknown for %d)", est.Unknown)
+	}
+	return text
+}
+
+// askConfirmPlaylistSize blocks until the user accepts or cancels the
+// estimated playlist download.
+func askConfirmPlaylistSize(w fyne.Window, estimate string) bool {
+	choiceCh := make(chan bool, 1)
+	runOnMain(func() {
+		d := dialog.NewCustomConfirm(
+			tr("Download Playlist"),
+			tr("Download"),
+			tr("Cancel"),
+			container.NewVBox(
+				widget.NewLabel(estimate),
+				widget.NewLabel(tr("Start the download?")),
+			),
+			func(confirmed bool) {
+				choiceCh <- confirmed
+			},
+			w,
+		)
+		d.Show()
+	})
+	return <-choiceCh
+}
@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	prefPlaylistItems   = "playlist_items"
+	prefPlaylistReverse = "playlist_reverse"
+	prefPlaylistMax     = "playlist_max_downloads"
+	prefPlaylistAfter   = "playlist_date_after"
+	prefPlaylistMinLen  = "playlist_min_minutes"
+	prefPlaylistMaxLen  = "playlist_max_minutes"
+	prefPlaylistTitle   = "playlist_title_match"
+	prefMixItemCap      = "mix_item_cap"
+
+	defaultMixItemCap = 25
+
+	// ytdlpMaxDownloadsExit is yt-dlp's exit code when --max-downloads
+	// stopped it, which is not a failure.
+	ytdlpMaxDownloadsExit = 101
+)
+
+var (
+	// playlistItemsRE accepts indices, ranges and slices: "1-25,30,-5::2".
+	playlistItemsRE = regexp.MustCompile(`^[\d\s,:-]+$`)
+	// dateAfterRE accepts yt-dlp's date forms: YYYYMMDD or now/today minus
+	// a relative amount.
+	dateAfterRE = regexp.MustCompile(`^(\d{8}|(now|today)(-\d+(day|week|month|year)s?)?)$`)
+)
+
+// playlistFilter limits which playlist items are downloaded, so archiving a
+// channel doesn't require fetching everything.
+type playlistFilter struct {
+	Items        string
+	Reverse      bool
+	MaxDownloads int
+	DateAfter    string
+	MinMinutes   int
+	MaxMinutes   int
+	TitleMatch   string
+}
+
+func loadPlaylistFilter(prefs fyne.Preferences) playlistFilter {
+	return playlistFilter{
+		Items:        strings.TrimSpace(prefs.String(prefPlaylistItems)),
+		Reverse:      prefs.Bool(prefPlaylistReverse),
+		MaxDownloads: prefs.Int(prefPlaylistMax),
+		DateAfter:    strings.TrimSpace(prefs.String(prefPlaylistAfter)),
+		MinMinutes:   prefs.Int(prefPlaylistMinLen),
+		MaxMinutes:   prefs.Int(prefPlaylistMaxLen),
+		TitleMatch:   strings.TrimSpace(prefs.String(prefPlaylistTitle)),
+	}
+}
+
+// matchFilter builds the --match-filter expression for the duration and
+// title conditions, or "" if there are none.
+func (f playlistFilter) matchFilter() string {
+	var conds []string
+	if f.MinMinutes > 0 {
+		conds = append(conds, "duration >= "+strconv.Itoa(f.MinMinutes*60))
+	}
+	if f.MaxMinutes > 0 {
+		conds = append(conds, "duration <= "+strconv.Itoa(f.MaxMinutes*60))
+	}
+	if f.TitleMatch != "" {
+		conds = append(conds, "title ~= '(?i)"+strings.ReplaceAll(f.TitleMatch, "'", `\'`)+"'")
+	}
+	return strings.Join(conds, " & ")
+}
+
+func (f playlistFilter) ytdlpArgs() []string {
+	var args []string
+	if f.Items != "" && playlistItemsRE.MatchString(f.Items) {
+		args = append(args, "--playlist-items", strings.ReplaceAll(f.Items, " ", ""))
+	}
+	if f.Reverse {
+		args = append(args, "--playlist-reverse")
+	}
+	if f.MaxDownloads > 0 {
+		args = append(args, "--max-downloads", strconv.Itoa(f.MaxDownloads))
+	}
+	if f.DateAfter != "" && dateAfterRE.MatchString(f.DateAfter) {
+		args = append(args, "--dateafter", f.DateAfter)
+	}
+	if m := f.matchFilter(); m != "" {
+		args = append(args, "--match-filter", m)
+	}
+	return args
+}
+
+// isMixPlaylist reports whether raw names a YouTube Mix/Radio playlist
+// (list IDs starting with RD). Those are generated on the fly and never end.
+func isMixPlaylist(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(u.Query().Get("list"), "RD")
+}
+
+// capMix limits a Mix playlist to the first n items unless the user already
+// chose a range. It reports whether the cap was applied.
+func (f *playlistFilter) capMix(n int) bool {
+	if n <= 0 || (f.Items != "" && playlistItemsRE.MatchString(f.Items)) {
+		return false
+	}
+	f.Items = "1-" + strconv.Itoa(n)
+	return true
+}
+
+// playlistOptionsPanel edits the playlist filter preferences. Invalid input
+// is flagged by the entry validators and left out of the yt-dlp arguments.
+func playlistOptionsPanel(prefs fyne.Preferences) *widget.Accordion {
+	textEntry := func(key, placeholder string, re *regexp.Regexp) *widget.Entry {
+		e := widget.NewEntry()
+		e.SetPlaceHolder(placeholder)
+		e.SetText(prefs.String(key))
+		if re != nil {
+			e.Validator = func(text string) error {
+				if text = strings.TrimSpace(text); text != "" && !re.MatchString(text) {
+					return errors.New(tr("Not a valid value"))
+				}
+				return nil
+			}
+		}
+		e.OnChanged = func(text string) { prefs.SetString(key, strings.TrimSpace(text)) }
+		return e
+	}
+	intEntry := func(key string) *widget.Entry {
+		e := widget.NewEntry()
+		if n := prefs.Int(key); n > 0 {
+			e.SetText(strconv.Itoa(n))
+		}
+		e.SetPlaceHolder(tr("no limit"))
+		e.OnChanged = func(text string) {
+			n, err := strconv.Atoi(strings.TrimSpace(text))
+			if err != nil || n < 0 {
+				n = 0
+			}
+			prefs.SetInt(key, n)
+		}
+		return e
+	}
+	reverse := widget.NewCheck(tr("Reverse playlist order"), func(on bool) {
+		prefs.SetBool(prefPlaylistReverse, on)
+	})
+	reverse.SetChecked(prefs.Bool(prefPlaylistReverse))
+	confirmSize := widget.NewCheck(tr("Estimate the total size and confirm first"), func(on bool) {
+		prefs.SetBool(prefConfirmPlaylistSize, on)
+	})
+	confirmSize.SetChecked(prefs.Bool(prefConfirmPlaylistSize))
+	mixCap := widget.NewEntry()
+	mixCap.SetText(strconv.Itoa(prefs.IntWithFallback(prefMixItemCap, defaultMixItemCap)))
+	mixCap.OnChanged = func(text string) {
+		if n, err := strconv.Atoi(strings.TrimSpace(text)); err == nil && n > 0 {
+			prefs.SetInt(prefMixItemCap, n)
+		}
+	}
+
+	form := widget.NewForm(
+		widget.NewFormItem(tr("Items"), textEntry(prefPlaylistItems, "1-25,30", playlistItemsRE)),
+		widget.NewFormItem(tr("Max downloads"), intEntry(prefPlaylistMax)),
+		widget.NewFormItem(tr("Uploaded after"), textEntry(prefPlaylistAfter, "20240101 / now-1month", dateAfterRE)),
+		widget.NewFormItem(tr("Min length (minutes)"), intEntry(prefPlaylistMinLen)),
+		widget.NewFormItem(tr("Max length (minutes)"), intEntry(prefPlaylistMaxLen)),
+		widget.NewFormItem(tr("Title contains"), textEntry(prefPlaylistTitle, tr("text or regular expression"), nil)),
+		widget.NewFormItem("", reverse),
+		widget.NewFormItem(tr("Mix/Radio item cap"), mixCap),
+		widget.NewFormItem("", confirmSize),
+	)
+	return widget.NewAccordion(widget.NewAccordionItem(tr("Playlist options"), form))
+}
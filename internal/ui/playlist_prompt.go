@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"net/url"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+// linkPlaylistKind reports whether a YouTube link names a playlist (list=
+// or /playlist) and whether it also names a single video. Other sites are
+// left to the checkbox.
+func linkPlaylistKind(raw string) (hasList, hasVideo bool) {
+	raw = strings.TrimSpace(raw)
+	if !downloader.IsYouTubeURL(raw) {
+		return false, false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false, false
+	}
+	q := u.Query()
+	hasList = q.Get("list") != ""
+	hasVideo = q.Get("v") != "" || strings.EqualFold(u.Hostname(), "youtu.be") || strings.HasPrefix(u.Path, "/shorts/")
+	return hasList, hasVideo
+}
+
+// playlistPrompt is the inline question shown under the URL entry when the
+// link and the Download Playlist checkbox disagree. Until it is answered the
+// Download button asks for a decision instead of queueing.
+type playlistPrompt struct {
+	label *widget.Label
+	video *widget.Button
+	whole *widget.Button
+	box   *fyne.Container
+
+	countItems  func(link string, done func(n int)) (cancel func())
+	cancelCount func()
+
+	current        string
+	answered       string
+	count          int
+	lastPlaylistOn bool
+}
+
+// newPlaylistPrompt calls choose with the answer; countItems is started in
+// the background for links that name a playlist and reports the item count
+// through done if it can be read. The count is canceled through the
+// returned func when the link changes again.
+func newPlaylistPrompt(choose func(playlist bool), countItems func(link string, done func(n int)) (cancel func())) *playlistPrompt {
+	p := &playlistPrompt{label: widget.NewLabel("")}
+	p.label.Wrapping = fyne.TextWrapWord
+	answer := func(playlist bool) {
+		p.answered = p.current
+		p.box.Hide()
+		choose(playlist)
+	}
+	p.video = widget.NewButton(tr("Just this video"), func() { answer(false) })
+	p.whole = widget.NewButton(tr("Whole playlist"), func() { answer(true) })
+	p.box = container.NewVBox(p.label, container.NewHBox(p.video, p.whole))
+	p.box.Hide()
+	p.countItems = countItems
+	return p
+}
+
+// update re-evaluates the prompt for the URL entry's text and checkbox
+// state. Must be called on the main thread.
+func (p *playlistPrompt) update(link string, playlistOn bool) {
+	link = strings.TrimSpace(link)
+	if link != p.current {
+		p.current, p.count = link, 0
+		if p.cancelCount != nil {
+			p.cancelCount()
+			p.cancelCount = nil
+		}
+		// A Mix has no fixed length worth counting.
+		if hasList, _ := linkPlaylistKind(link); hasList && !isMixPlaylist(link) && p.countItems != nil {
+			p.cancelCount = p.countItems(link, func(n int) {
+				if p.current == link {
+					p.count = n
+					p.update(link, p.lastPlaylistOn)
+				}
+			})
+		}
+	}
+	p.lastPlaylistOn = playlistOn
+	hasList, hasVideo := linkPlaylistKind(link)
+	if link == "" || p.answered == link || hasList == playlistOn {
+		p.box.Hide()
+		return
+	}
+	items := ""
+	if p.count > 0 {
+		items = trf(" (%d items)", p.count)
+	}
+	switch {
+	case hasList && hasVideo:
+		p.label.SetText(trf("This link is part of a playlist. Download just this video or the whole playlist%s?", items))
+		p.video.Show()
+	case hasList:
+		p.label.SetText(trf("This link is a playlist%s, but Download Playlist is off. Download the whole playlist?", items))
+		p.video.Hide()
+	default:
+		p.label.SetText(tr("Download Playlist is on, but this link is a single video."))
+		p.video.Show()
+	}
+	if hasList {
+		p.whole.Show()
+	} else {
+		p.whole.Hide()
+	}
+	p.box.Show()
+}
+
+// pending reports whether the prompt is waiting for an answer.
+func (p *playlistPrompt) pending() bool {
+	return p.box.Visible()
+}
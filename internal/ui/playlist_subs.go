@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"ytgui/internal/downloader"
+)
+
+var (
+	playlistItemRE   = regexp.MustCompile(`^\[download\] Downloading (?:item|video) (\d+) of (\d+)`)
+	subtitleWriteRE  = regexp.MustCompile(`^\[info\] ([^:]+): Downloading subtitles: (.+)$`)
+	noSubtitlesMatch = "There are no subtitles for the requested languages"
+)
+
+// playlistSubtitleOption turns the saved subtitle preference into a
+// --sub-langs list, since playlist items can't be inspected one by one before
+// the download starts. Creator tracks win over auto captions for the same
+// language because both --write-subs and --write-auto-subs are passed.
+func playlistSubtitleOption(preferred, order string) *downloader.SubOption {
+	var langs []string
+	if pref := subtitleLangBase(preferred); pref != "" {
+		langs = append(langs, regexp.QuoteMeta(pref)+".*")
+	}
+	keys, ok := subtitleOrderKeys[order]
+	if !ok {
+		keys = subtitleOrderKeys[subtitleOrders[0]]
+	}
+	for _, key := range keys {
+		var lang string
+		switch key {
+		case subCreatorEnglish, subAutoEnglish:
+			lang = "en.*"
+		case subAutoOriginal:
+			lang = ".*-orig"
+		default:
+			// Creator originals have no fixed code; they are covered when the
+			// original language is English or the preferred language.
+			continue
+		}
+		if !containsString(langs, lang) {
+			langs = append(langs, lang)
+		}
+	}
+	code := strings.Join(append(langs, "-live_chat"), ",")
+	return &downloader.SubOption{
+		Code:  code,
+		Label: trf("Playlist preference (%s)", strings.Join(langs, ", ")),
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// playlistSubtitleWatch turns yt-dlp's per-item subtitle messages into
+// readable log lines so missing tracks aren't silent.
+type playlistSubtitleWatch struct {
+	mu    sync.Mutex
+	item  string
+	total string
+}
+
+func (p *playlistSubtitleWatch) observe(rawLine string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	line := strings.TrimSpace(rawLine)
+	if m := playlistItemRE.FindStringSubmatch(line); m != nil {
+		p.item, p.total = m[1], m[2]
+		return "", false
+	}
+	if m := subtitleWriteRE.FindStringSubmatch(line); m != nil {
+		return trf("%s: subtitles found (%s)", p.label(), strings.TrimSpace(m[2])), true
+	}
+	if strings.Contains(line, noSubtitlesMatch) {
+		return trf("%s: no subtitles in the preferred languages", p.label()), true
+	}
+	return "", false
+}
+
+func (p *playlistSubtitleWatch) label() string {
+	if p.item == "" {
+		return tr("Playlist item")
+	}
+	return trf("Item %s of %s", p.item, p.total)
+}
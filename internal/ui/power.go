@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"os/exec"
+	"runtime"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	postQueueNothing    = "Do nothing"
+	postQueueOpenFolder = "Open folder"
+	postQueueSleep      = "Sleep"
+	postQueueShutdown   = "Shut down"
+
+	powerActionGrace = 60 * time.Second
+)
+
+var postQueueActions = []string{postQueueNothing, postQueueOpenFolder, postQueueSleep, postQueueShutdown}
+
+// powerCommand returns the command for action. Sleep on Windows has none;
+// runPowerAction calls SetSuspendState directly.
+func powerCommand(action string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "windows":
+		return exec.Command("shutdown", "/s", "/t", "0")
+	case "darwin":
+		if action == postQueueShutdown {
+			return exec.Command("osascript", "-e", `tell app "System Events" to shut down`)
+		}
+		return exec.Command("pmset", "sleepnow")
+	default:
+		if action == postQueueShutdown {
+			return exec.Command("systemctl", "poweroff")
+		}
+		return exec.Command("systemctl", "suspend")
+	}
+}
+
+func runPowerAction(action string) error {
+	if runtime.GOOS == "windows" && action == postQueueSleep {
+		return suspendSystem()
+	}
+	cmd := powerCommand(action)
+	setCmdHideWindow(cmd)
+	return cmd.Run()
+}
+
+// runPostQueueAction performs the "When all downloads finish" choice. Sleep and
+// shut down are preceded by a cancelable countdown so an unattended machine
+// still gives the user a chance to abort.
+func runPostQueueAction(w fyne.Window, action, folder string, logf func(string)) {
+	switch action {
+	case postQueueOpenFolder:
+		if err := openInFileManager(folder); err != nil {
+			logf(trf("Failed to open folder: %v", err))
+		}
+	case postQueueSleep, postQueueShutdown:
+		abort := make(chan struct{})
+		label := widget.NewLabel("")
+		var d dialog.Dialog
+		runOnMain(func() {
+			d = dialog.NewCustom(tr(action), tr("Abort"), label, w)
+			d.SetOnClosed(func() { close(abort) })
+			d.Show()
+		})
+		go func() {
+			deadline := time.Now().Add(powerActionGrace)
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for {
+				left := time.Until(deadline).Round(time.Second)
+				runOnMain(func() {
+					label.SetText(trf("All downloads finished.\n%s in %s.", tr(action), left))
+				})
+				if left <= 0 {
+					break
+				}
+				select {
+				case <-abort:
+					logf(trf("%s aborted by user.", tr(action)))
+					return
+				case <-ticker.C:
+				}
+			}
+			runOnMain(func() { d.Hide() })
+			if err := runPowerAction(action); err != nil {
+				logf(trf("%s failed: %v", tr(action), err))
+			}
+		}()
+	}
+}
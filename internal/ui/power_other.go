@@ -0,0 +1,9 @@
+//go:build !windows
+
+package ui
+
+import "errors"
+
+func suspendSystem() error {
+	return errors.New("not supported on this platform")
+}
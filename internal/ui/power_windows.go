@@ -0,0 +1,18 @@
+//go:build windows
+
+package ui
+
+import "syscall"
+
+var procSetSuspendState = syscall.NewLazyDLL("powrprof.dll").NewProc("SetSuspendState")
+
+// suspendSystem puts the machine to sleep. rundll32 can't pass SetSuspendState
+// its BOOLEAN arguments, so calling it that way could hibernate instead.
+func suspendSystem() error {
+	// hibernate, force and disableWakeEvent are all FALSE.
+	r, _, callErr := procSetSuspendState.Call(0, 0, 0)
+	if r == 0 {
+		return callErr
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ytgui/internal/downloader"
+)
+
+// preserveFormat takes the best streams regardless of codec; MKV holds any
+// of them.
+const preserveFormat = "bestvideo*+bestaudio/best"
+
+// archivedFile is one file of a preserved download with its checksum.
+type archivedFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// preserveSettings turns s into an archive mode job: the single linked video
+// at best quality in MKV with every subtitle, the info JSON, description,
+// thumbnail and chapters, checksummed once done.
+func preserveSettings(s jobSettings) jobSettings {
+	s.Preserve = true
+	s.Quality = "Best"
+	s.Profile = profileSmaller
+	s.MaxFPS = 0
+	s.DynamicRange = rangeAny
+	s.Tonemap = false
+	s.SizeLimit = fileSizeLimit{}
+	s.Playlist = false
+	s.ClipStart, s.ClipEnd = 0, 0
+	s.ClipExport = clipExport{}
+	s.Subtitles = true
+	s.SubFormat = subFormatAuto
+	s.KeepSubs = true
+	if s.Thumbnail == "" {
+		s.Thumbnail = downloader.ThumbnailFormats[0]
+	}
+	return s
+}
+
+// preserveSubtitles selects every uploaded subtitle track. Automatic
+// captions are left out: YouTube offers a machine translation into each of
+// about a hundred languages.
+func preserveSubtitles() *downloader.SubOption {
+	return &downloader.SubOption{Label: "All subtitles", Code: "all,-live_chat"}
+}
+
+// preserveArgs adds the sidecars and metadata archive mode keeps.
+func preserveArgs(b *downloader.ArgsBuilder) {
+	b.Switch("--write-info-json").Switch("--write-description").Switch("--embed-chapters").Switch("--embed-metadata")
+}
+
+// archiveFiles lists the media file and the sidecars yt-dlp wrote next to
+// it, which share its name up to the extension.
+func archiveFiles(mediaPath string) ([]string, error) {
+	dir := filepath.Dir(mediaPath)
+	base := strings.TrimSuffix(filepath.Base(mediaPath), filepath.Ext(mediaPath))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, base+".") || name == base+".sha256" || strings.HasSuffix(name, ".part") {
+			continue
+		}
+		out = append(out, filepath.Join(dir, name))
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// writeChecksums hashes a preserved download and its sidecars and writes
+// them to a .sha256 file next to the media, in the format sha256sum -c
+// reads.
+func writeChecksums(mediaPath string) ([]archivedFile, string, error) {
+	paths, err := archiveFiles(mediaPath)
+	if err != nil {
+		return nil, "", err
+	}
+	var files []archivedFile
+	var manifest strings.Builder
+	for _, p := range paths {
+		sum, err := downloader.FileSHA256(p)
+		if err != nil {
+			return nil, "", err
+		}
+		files = append(files, archivedFile{Path: p, SHA256: sum})
+		fmt.Fprintf(&manifest, "%s *%s\n", sum, filepath.Base(p))
+	}
+	manifestPath := strings.TrimSuffix(mediaPath, filepath.Ext(mediaPath)) + ".sha256"
+	if err := os.WriteFile(manifestPath, []byte(manifest.String()), 0o644); err != nil {
+		return nil, "", err
+	}
+	return files, manifestPath, nil
+}
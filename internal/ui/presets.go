@@ -0,0 +1,346 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefPresets      = "quality_presets"
+	prefAudioQuality = "last_audio_quality"
+
+	audioQualityBest = "Best"
+)
+
+// audioQualities are the --audio-quality choices for Audio Only downloads;
+// Best leaves yt-dlp's own setting.
+var audioQualities = []string{audioQualityBest, "320K", "192K", "128K", "96K"}
+
+// qualityPreset is a named set of download settings. Fields shared with
+// batch files use the same JSON names, so a preset can serve as batch
+// defaults.
+type qualityPreset struct {
+	Name           string `json:"name"`
+	Quality        string `json:"quality"`
+	Profile        string `json:"profile"`
+	AudioQuality   string `json:"audio_quality,omitempty"`
+	Loudness       string `json:"loudness,omitempty"`
+	TrimSilence    bool   `json:"trim_silence,omitempty"`
+	CutIntro       int    `json:"cut_intro_seconds,omitempty"`
+	CutOutro       int    `json:"cut_outro_seconds,omitempty"`
+	SplitTracks    bool   `json:"split_tracks,omitempty"`
+	MaxFPS         int    `json:"max_fps,omitempty"`
+	DynamicRange   string `json:"dynamic_range,omitempty"`
+	Tonemap        bool   `json:"tonemap_hdr,omitempty"`
+	IncludeChannel bool   `json:"include_channel"`
+	Subtitles      bool   `json:"subtitles"`
+	SubtitleLang   string `json:"subtitle_language,omitempty"`
+	SubFormat      string `json:"subtitle_format,omitempty"`
+	KeepSubs       bool   `json:"keep_subtitle_files,omitempty"`
+	SortRule       string `json:"sort_rule,omitempty"`
+	SortTemplate   string `json:"sort_template,omitempty"`
+}
+
+// defaultPresets are offered until the user saves presets of their own.
+var defaultPresets = []qualityPreset{
+	{Name: "Archive - best MKV + all subs", Quality: "Best", Profile: profileSmaller, IncludeChannel: true, Subtitles: true, KeepSubs: true, SortRule: sortByChannel},
+	{Name: "Phone - 720p H.264", Quality: "720p", Profile: profileCompatible},
+	{Name: "Podcast - mp3 128k", Quality: "Audio Only", Profile: profileCompatible, AudioQuality: "128K", Loudness: loudnessPodcast, IncludeChannel: true},
+	{Name: "Music - best audio", Quality: "Audio Only", Profile: profileCompatible, IncludeChannel: true},
+}
+
+func (p qualityPreset) validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("preset without a name")
+	}
+	if p.AudioQuality != "" && !containsString(audioQualities, p.AudioQuality) {
+		return fmt.Errorf("preset %q: unknown audio quality %q", p.Name, p.AudioQuality)
+	}
+	if p.Loudness != "" && !containsString(loudnessOptions, p.Loudness) {
+		return fmt.Errorf("preset %q: unknown loudness target %q", p.Name, p.Loudness)
+	}
+	if p.CutIntro < 0 || p.CutOutro < 0 {
+		return fmt.Errorf("preset %q: intro and outro cuts can't be negative", p.Name)
+	}
+	if p.MaxFPS != 0 && !containsString(fpsOptions, fpsOption(p.MaxFPS)) {
+		return fmt.Errorf("preset %q: unsupported frame rate cap %d", p.Name, p.MaxFPS)
+	}
+	if p.DynamicRange != "" && !containsString(dynamicRanges, p.DynamicRange) {
+		return fmt.Errorf("preset %q: unknown dynamic range %q", p.Name, p.DynamicRange)
+	}
+	if p.SortRule != "" && !containsString(sortRules, p.SortRule) {
+		return fmt.Errorf("preset %q: unknown sort rule %q", p.Name, p.SortRule)
+	}
+	if p.SubFormat != "" && !containsString(subFormats, p.SubFormat) {
+		return fmt.Errorf("preset %q: unknown subtitle format %q", p.Name, p.SubFormat)
+	}
+	return nil
+}
+
+// applyTo sets the preset's fields on job settings, the way selecting it
+// sets them in the window.
+func (p qualityPreset) applyTo(s *jobSettings) {
+	if containsString(qualityOptions, p.Quality) {
+		s.Quality = p.Quality
+	}
+	if containsString(profileOptions, p.Profile) {
+		s.Profile = p.Profile
+	}
+	s.AudioQuality = valueOr(p.AudioQuality, audioQualityBest)
+	s.Loudness = valueOr(p.Loudness, loudnessOff)
+	s.AudioTrim = downloader.AudioTrim{Silence: p.TrimSilence, Intro: float64(p.CutIntro), Outro: float64(p.CutOutro)}
+	s.SplitTracks = p.SplitTracks
+	s.MaxFPS = p.MaxFPS
+	s.DynamicRange = valueOr(p.DynamicRange, rangePreferSDR)
+	s.Tonemap = p.Tonemap
+	s.IncludeChannel = p.IncludeChannel
+	s.Subtitles = p.Subtitles
+	s.SubtitleLang = p.SubtitleLang
+	s.SubFormat = valueOr(p.SubFormat, subFormatAuto)
+	s.KeepSubs = p.KeepSubs
+	s.SortTemplate = sortTemplate(valueOr(p.SortRule, sortOff), p.SortTemplate)
+}
+
+func valueOr(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
+
+// findPreset looks a preset up by name.
+func findPreset(prefs fyne.Preferences, name string) (qualityPreset, bool) {
+	for _, p := range loadPresets(prefs) {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return qualityPreset{}, false
+}
+
+func loadPresets(prefs fyne.Preferences) []qualityPreset {
+	raw := prefs.String(prefPresets)
+	if raw == "" {
+		return append([]qualityPreset(nil), defaultPresets...)
+	}
+	var presets []qualityPreset
+	if err := json.Unmarshal([]byte(raw), &presets); err != nil {
+		fyne.LogError("Could not read presets", err)
+		return append([]qualityPreset(nil), defaultPresets...)
+	}
+	return presets
+}
+
+func savePresets(prefs fyne.Preferences, presets []qualityPreset) {
+	data, err := json.Marshal(presets)
+	if err != nil {
+		fyne.LogError("Could not save presets", err)
+		return
+	}
+	prefs.SetString(prefPresets, string(data))
+}
+
+// putPreset replaces the preset with p's name, or appends p.
+func putPreset(presets []qualityPreset, p qualityPreset) []qualityPreset {
+	for i := range presets {
+		if strings.EqualFold(presets[i].Name, p.Name) {
+			presets[i] = p
+			return presets
+		}
+	}
+	return append(presets, p)
+}
+
+// readPresets accepts a single preset or a list of them.
+func readPresets(r io.Reader) ([]qualityPreset, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	var presets []qualityPreset
+	if len(data) > 0 && data[0] == '{' {
+		var p qualityPreset
+		err = json.Unmarshal(data, &p)
+		presets = []qualityPreset{p}
+	} else {
+		err = json.Unmarshal(data, &presets)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid preset file: %w", err)
+	}
+	if len(presets) == 0 {
+		return nil, errors.New("the file has no presets")
+	}
+	for _, p := range presets {
+		if err := p.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return presets, nil
+}
+
+// presetBar is the preset dropdown above the download options. current
+// reads the options as they are set in the window; apply sets them.
+type presetBar struct {
+	box    *fyne.Container
+	choice *widget.Select
+
+	w       fyne.Window
+	prefs   fyne.Preferences
+	current func() qualityPreset
+	apply   func(qualityPreset)
+}
+
+func newPresetBar(w fyne.Window, prefs fyne.Preferences, current func() qualityPreset, apply func(qualityPreset)) *presetBar {
+	b := &presetBar{w: w, prefs: prefs, current: current, apply: apply}
+	b.choice = widget.NewSelect(nil, func(name string) {
+		for _, p := range loadPresets(prefs) {
+			if p.Name == name {
+				apply(p)
+				return
+			}
+		}
+	})
+	b.choice.PlaceHolder = tr("Choose a preset")
+	b.refresh()
+	save := widget.NewButton(tr("Save as Preset..."), b.saveCurrent)
+	var menuBtn *widget.Button
+	menuBtn = newIconButton(tr("More Preset Actions"), theme.MenuDropDownIcon(), func() {
+		showFolderMenu(w, menuBtn, fyne.NewMenu("",
+			fyne.NewMenuItem(tr("Export Presets..."), b.export),
+			fyne.NewMenuItem(tr("Import Presets..."), b.importFile),
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem(tr("Delete Selected Preset"), b.deleteSelected),
+		))
+	})
+	b.box = container.NewBorder(nil, nil, widget.NewLabel(tr("Preset:")), container.NewHBox(save, menuBtn), b.choice)
+	return b
+}
+
+func (b *presetBar) refresh() {
+	var names []string
+	for _, p := range loadPresets(b.prefs) {
+		names = append(names, p.Name)
+	}
+	b.choice.Options = names
+	b.choice.Refresh()
+}
+
+// selectQuietly shows name as selected without applying it again.
+func (b *presetBar) selectQuietly(name string) {
+	changed := b.choice.OnChanged
+	b.choice.OnChanged = nil
+	b.choice.SetSelected(name)
+	b.choice.OnChanged = changed
+}
+
+// use applies the named preset as if it had been picked, even when it is
+// already selected. It reports whether the preset exists.
+func (b *presetBar) use(name string) bool {
+	p, ok := findPreset(b.prefs, name)
+	if !ok {
+		return false
+	}
+	b.selectQuietly(p.Name)
+	b.apply(p)
+	return true
+}
+
+func (b *presetBar) saveCurrent() {
+	name := widget.NewEntry()
+	name.SetText(b.choice.Selected)
+	dialog.ShowForm(tr("Save as Preset"), tr("Save"), tr("Cancel"), []*widget.FormItem{
+		widget.NewFormItem(tr("Name"), name),
+	}, func(ok bool) {
+		n := strings.TrimSpace(name.Text)
+		if !ok || n == "" {
+			return
+		}
+		p := b.current()
+		p.Name = n
+		savePresets(b.prefs, putPreset(loadPresets(b.prefs), p))
+		b.refresh()
+		b.selectQuietly(n)
+	}, b.w)
+}
+
+func (b *presetBar) deleteSelected() {
+	name := b.choice.Selected
+	if name == "" {
+		return
+	}
+	dialog.ShowConfirm(tr("Delete Preset"), trf("Delete the preset %q?", name), func(ok bool) {
+		if !ok {
+			return
+		}
+		var kept []qualityPreset
+		for _, p := range loadPresets(b.prefs) {
+			if p.Name != name {
+				kept = append(kept, p)
+			}
+		}
+		if kept == nil {
+			kept = []qualityPreset{}
+		}
+		savePresets(b.prefs, kept)
+		b.choice.ClearSelected()
+		b.refresh()
+	}, b.w)
+}
+
+func (b *presetBar) export() {
+	save := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil || wc == nil {
+			return
+		}
+		defer wc.Close()
+		data, err := json.MarshalIndent(loadPresets(b.prefs), "", "  ")
+		if err == nil {
+			_, err = wc.Write(data)
+		}
+		if err != nil {
+			dialog.ShowError(err, b.w)
+			return
+		}
+		dialog.ShowInformation(tr("Presets exported"), wc.URI().Path(), b.w)
+	}, b.w)
+	save.SetFileName("ytgui-presets.json")
+	save.Show()
+}
+
+func (b *presetBar) importFile() {
+	open := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+		if err != nil || rc == nil {
+			return
+		}
+		defer rc.Close()
+		imported, err := readPresets(rc)
+		if err != nil {
+			dialog.ShowError(err, b.w)
+			return
+		}
+		presets := loadPresets(b.prefs)
+		for _, p := range imported {
+			presets = putPreset(presets, p)
+		}
+		savePresets(b.prefs, presets)
+		b.refresh()
+		dialog.ShowInformation(tr("Presets imported"), trf("%d preset(s) imported.", len(imported)), b.w)
+	}, b.w)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	open.Show()
+}
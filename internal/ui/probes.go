@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+const (
+	prefProbeTimeout = "probe_timeout_seconds"
+
+	defaultProbeTimeout = 60
+)
+
+// errStoppedWaiting marks a probe the user gave up on from the status area.
+var errStoppedWaiting = errors.New("stopped waiting")
+
+func probeTimeout(prefs fyne.Preferences) time.Duration {
+	n := prefs.IntWithFallback(prefProbeTimeout, defaultProbeTimeout)
+	if n <= 0 {
+		n = defaultProbeTimeout
+	}
+	return time.Duration(n) * time.Second
+}
+
+// probeFunc starts a metadata probe under parent. The returned func must be
+// called with the probe's error once it finishes; it releases the probe and
+// rewrites a timeout or stop into a readable error.
+type probeFunc func(parent context.Context) (context.Context, func(error) error)
+
+// probeTracker times out metadata lookups (site detection, subtitle and
+// format listing) and lets the user stop waiting for all of them at once.
+// Stopping a probe doesn't cancel the job; it carries on without what the
+// probe would have found.
+type probeTracker struct {
+	timeout func() time.Duration
+	// onChange is called with the number of probes running whenever it
+	// changes.
+	onChange func(n int)
+
+	mu      sync.Mutex
+	seq     int64
+	active  map[int64]context.CancelFunc
+	stopped map[int64]bool
+}
+
+func (t *probeTracker) start(parent context.Context) (context.Context, func(error) error) {
+	timeout := t.timeout()
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	t.mu.Lock()
+	if t.active == nil {
+		t.active = make(map[int64]context.CancelFunc)
+		t.stopped = make(map[int64]bool)
+	}
+	t.seq++
+	id := t.seq
+	t.active[id] = cancel
+	n := len(t.active)
+	t.mu.Unlock()
+	t.changed(n)
+
+	return ctx, func(err error) error {
+		t.mu.Lock()
+		stopped := t.stopped[id]
+		delete(t.active, id)
+		delete(t.stopped, id)
+		n := len(t.active)
+		t.mu.Unlock()
+		cancel()
+		t.changed(n)
+		switch {
+		case err == nil || parent.Err() != nil:
+			return err
+		case stopped:
+			return errStoppedWaiting
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return fmt.Errorf("no answer after %s", timeout)
+		}
+		return err
+	}
+}
+
+// stopAll gives up on every probe currently running.
+func (t *probeTracker) stopAll() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, cancel := range t.active {
+		t.stopped[id] = true
+		cancel()
+	}
+}
+
+func (t *probeTracker) changed(n int) {
+	if t.onChange != nil {
+		t.onChange(n)
+	}
+}
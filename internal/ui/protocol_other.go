@@ -0,0 +1,5 @@
+//go:build !windows
+
+package ui
+
+func registerURLScheme() error { return nil }
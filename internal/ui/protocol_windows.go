@@ -0,0 +1,54 @@
+//go:build windows
+
+package ui
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// registerURLScheme points ytgui:// links at this executable for the current
+// user. It only touches HKCU, so no elevation is required, and it leaves the
+// key alone when it already points here, so a normal start writes nothing.
+func registerURLScheme() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	key := `HKCU\Software\Classes\` + urlScheme
+	command := `"` + exe + `" "%1"`
+	if v, ok := queryRegDefault(key + `\shell\open\command`); ok && strings.EqualFold(v, command) {
+		return nil
+	}
+	cmds := [][]string{
+		{"add", key, "/ve", "/d", "URL:ytgui Protocol", "/f"},
+		{"add", key, "/v", "URL Protocol", "/d", "", "/f"},
+		{"add", key + `\shell\open\command`, "/ve", "/d", command, "/f"},
+	}
+	for _, args := range cmds {
+		cmd := exec.Command("reg", args...)
+		setCmdHideWindow(cmd)
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryRegDefault reads the default value of a registry key; ok is false
+// when the key or value doesn't exist.
+func queryRegDefault(key string) (string, bool) {
+	cmd := exec.Command("reg", "query", key, "/ve")
+	setCmdHideWindow(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", false
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if _, v, found := strings.Cut(line, "REG_SZ"); found {
+			return strings.TrimSpace(v), true
+		}
+	}
+	return "", false
+}
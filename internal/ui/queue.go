@@ -0,0 +1,548 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+type jobSettings struct {
+	Quality        string
+	Profile        string
+	AudioQuality   string
+	Loudness       string
+	AudioTrim      downloader.AudioTrim
+	SplitTracks    bool
+	MaxFPS         int
+	DynamicRange   string
+	Device         string
+	Tonemap        bool
+	Thumbnail      string
+	Archive        archiveOptions
+	Folder         string
+	IncludeChannel bool
+	Playlist       bool
+	StageLocal     bool
+	Subtitles      bool
+	SubtitleLang   string
+	SubtitleMode   string
+	SubtitleOrder  string
+	Duplicates     string
+	SubFormat      string
+	KeepSubs       bool
+	NameRules      downloader.NameRules
+	SortTemplate   string
+	Retry          retryPolicy
+	ExtractorArgs  []siteExtractorArgs
+	Binding        networkBinding
+	SizeLimit      fileSizeLimit
+	PlaylistFilter playlistFilter
+	TwitchChat     string
+	// ClipStart and ClipEnd download only that part of the video, in
+	// seconds; a ClipEnd of 0 means to the end, both 0 the whole video.
+	ClipStart  int
+	ClipEnd    int
+	ClipExport clipExport
+	// FileName replaces the name built from the title, without extension.
+	// It is set from the pre-download summary.
+	FileName string
+	// Preserve is archive mode, see preserveSettings.
+	Preserve bool
+	// Redownload marks a job queued again after failed verification, so a
+	// second bad result doesn't loop.
+	Redownload bool
+	// Tags and Note organize the download; they're copied to its history
+	// entry when it finishes.
+	Tags []string
+	Note string
+}
+
+type jobState int
+
+const (
+	jobQueued jobState = iota
+	jobRunning
+	jobDone
+	jobFailed
+	jobCanceled
+)
+
+func (s jobState) String() string {
+	switch s {
+	case jobQueued:
+		return "Queued"
+	case jobRunning:
+		return "Running"
+	case jobDone:
+		return "Done"
+	case jobFailed:
+		return "Failed"
+	case jobCanceled:
+		return "Canceled"
+	default:
+		return "Unknown"
+	}
+}
+
+func (s jobState) finished() bool {
+	return s == jobDone || s == jobFailed || s == jobCanceled
+}
+
+type downloadJob struct {
+	ID       int64
+	URL      string
+	Settings jobSettings
+	State    jobState
+	Status   string
+	Progress float64
+	Err      error
+	Restarts int
+	// Output is the finished file, once known.
+	Output string
+	// Summary describes Output after a successful download; see
+	// downloadResult.summary.
+	Summary string
+	// Speed is the last reported transfer rate in bytes per second.
+	Speed float64
+	// Priority ("download next") starts the job ahead of the rest of the
+	// queue.
+	Priority bool
+
+	// Log and NerdLog hold this job's own output so concurrent jobs don't
+	// interleave in the shared log tabs.
+	Log     *widget.Entry
+	NerdLog *widget.Entry
+
+	cancel context.CancelCauseFunc
+	// held keeps a job added by addAll from being picked up until the
+	// caller's queued callback has seen it.
+	held bool
+}
+
+// errResumeRestart is the cancel cause used when a running job is torn down
+// after the machine wakes up; the job is requeued instead of failing.
+var errResumeRestart = errors.New("restarting after system resume")
+
+func newJobLogEntry(wrap fyne.TextWrap) *widget.Entry {
+	e := widget.NewMultiLineEntry()
+	e.Wrapping = wrap
+	return e
+}
+
+func jobSummaryLine(id int64, err error) string {
+	switch {
+	case err == nil:
+		return trf("#%d finished.", id)
+	case errors.Is(err, errResumeRestart):
+		return trf("#%d interrupted by system sleep; will resume.", id)
+	case errors.Is(err, context.Canceled):
+		return trf("#%d canceled.", id)
+	default:
+		return trf("#%d failed: %v", id, err)
+	}
+}
+
+type downloadQueue struct {
+	mu          sync.Mutex
+	jobs        []*downloadJob
+	seq         int64
+	workers     int
+	maxParallel int
+	batchDone   int
+
+	run        func(context.Context, *downloadJob) error
+	onChange   func()
+	onFinished func(job downloadJob)
+	onDrained  func(completed int, last *downloadJob)
+}
+
+func newDownloadQueue(run func(context.Context, *downloadJob) error) *downloadQueue {
+	return &downloadQueue{
+		maxParallel: 1,
+		run:         run,
+	}
+}
+
+func (q *downloadQueue) add(url string, settings jobSettings) *downloadJob {
+	return q.addAll([]string{url}, []jobSettings{settings}, nil)[0]
+}
+
+// addAll queues several jobs at once. queued, if set, sees the new jobs
+// before any worker can pick them up, so callers tracking them can't miss a
+// job that finishes immediately. The jobs are held until it returns, since
+// it runs without q.mu and may update them.
+func (q *downloadQueue) addAll(urls []string, settings []jobSettings, queued func([]*downloadJob)) []*downloadJob {
+	q.mu.Lock()
+	jobs := make([]*downloadJob, 0, len(urls))
+	for i, url := range urls {
+		q.seq++
+		job := &downloadJob{
+			ID:       q.seq,
+			URL:      url,
+			Settings: settings[i],
+			State:    jobQueued,
+			Status:   tr("Waiting in queue"),
+			Log:      newJobLogEntry(fyne.TextWrapWord),
+			NerdLog:  newJobLogEntry(fyne.TextWrapOff),
+			held:     queued != nil,
+		}
+		q.jobs = append(q.jobs, job)
+		jobs = append(jobs, job)
+	}
+	q.mu.Unlock()
+
+	if queued != nil {
+		queued(jobs)
+	}
+	q.mu.Lock()
+	for _, job := range jobs {
+		job.held = false
+	}
+	start := q.spawnLocked()
+	q.mu.Unlock()
+	q.changed()
+	for i := 0; i < start; i++ {
+		go q.work()
+	}
+	return jobs
+}
+
+// spawnLocked reserves worker slots for queued jobs and returns how many new
+// workers the caller must start once the lock is released.
+func (q *downloadQueue) spawnLocked() int {
+	queued := 0
+	for _, j := range q.jobs {
+		if j.State == jobQueued && !j.held {
+			queued++
+		}
+	}
+	start := 0
+	for q.workers < q.maxParallel && queued > start {
+		q.workers++
+		start++
+	}
+	return start
+}
+
+// holdingLocked reports whether addAll is still holding new jobs, so the
+// queue isn't drained yet.
+func (q *downloadQueue) holdingLocked() bool {
+	for _, j := range q.jobs {
+		if j.held {
+			return true
+		}
+	}
+	return false
+}
+
+// nextLocked returns the first queued job flagged "download next", or the
+// first queued job if none is.
+func (q *downloadQueue) nextLocked() *downloadJob {
+	var first *downloadJob
+	for _, j := range q.jobs {
+		if j.State != jobQueued || j.held {
+			continue
+		}
+		if j.Priority {
+			return j
+		}
+		if first == nil {
+			first = j
+		}
+	}
+	return first
+}
+
+func (q *downloadQueue) work() {
+	for {
+		q.mu.Lock()
+		job := q.nextLocked()
+		if job == nil || q.workers > q.maxParallel {
+			q.workers--
+			drained := q.workers == 0 && job == nil && !q.holdingLocked()
+			completed := q.batchDone
+			if drained {
+				q.batchDone = 0
+			}
+			q.mu.Unlock()
+			if drained && q.onDrained != nil {
+				q.onDrained(completed, q.lastFinished())
+			}
+			return
+		}
+		ctx, cancel := context.WithCancelCause(context.Background())
+		job.State = jobRunning
+		job.Status = tr("Starting...")
+		job.Progress = 0
+		job.cancel = cancel
+		q.mu.Unlock()
+		q.changed()
+
+		err := q.run(ctx, job)
+		cancel(nil)
+
+		q.mu.Lock()
+		job.cancel = nil
+		job.Speed = 0
+		job.Err = err
+		switch {
+		case err == nil:
+			job.State = jobDone
+			q.batchDone++
+		case errors.Is(err, errResumeRestart):
+			job.State = jobQueued
+			job.Status = tr("Resuming after system sleep...")
+			job.Restarts++
+		case errors.Is(err, context.Canceled):
+			job.State = jobCanceled
+		default:
+			job.State = jobFailed
+		}
+		final := *job
+		q.mu.Unlock()
+		q.changed()
+		q.finished(final)
+	}
+}
+
+func (q *downloadQueue) update(id int64, f func(*downloadJob)) {
+	q.mu.Lock()
+	for _, j := range q.jobs {
+		if j.ID == id {
+			f(j)
+			break
+		}
+	}
+	q.mu.Unlock()
+	q.changed()
+}
+
+// logs returns the log entries of job id, or nils if it is gone.
+func (q *downloadQueue) logs(id int64) (log, nerdLog *widget.Entry) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, j := range q.jobs {
+		if j.ID == id {
+			return j.Log, j.NerdLog
+		}
+	}
+	return nil, nil
+}
+
+func (q *downloadQueue) finished(job downloadJob) {
+	if job.State.finished() && q.onFinished != nil {
+		q.onFinished(job)
+	}
+}
+
+func (q *downloadQueue) changed() {
+	if q.onChange != nil {
+		q.onChange()
+	}
+}
+
+func (q *downloadQueue) snapshot() []downloadJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]downloadJob, 0, len(q.jobs))
+	for _, j := range q.jobs {
+		out = append(out, *j)
+	}
+	return out
+}
+
+func (q *downloadQueue) lastFinished() *downloadJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i := len(q.jobs) - 1; i >= 0; i-- {
+		if q.jobs[i].State == jobDone {
+			j := *q.jobs[i]
+			return &j
+		}
+	}
+	return nil
+}
+
+func (q *downloadQueue) runningCount() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for _, j := range q.jobs {
+		if j.State == jobRunning {
+			n++
+		}
+	}
+	return n
+}
+
+// totalSpeed sums the transfer rates of the running jobs.
+func (q *downloadQueue) totalSpeed() float64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	total := 0.0
+	for _, j := range q.jobs {
+		if j.State == jobRunning {
+			total += j.Speed
+		}
+	}
+	return total
+}
+
+func (q *downloadQueue) pendingCount() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for _, j := range q.jobs {
+		if !j.State.finished() {
+			n++
+		}
+	}
+	return n
+}
+
+func (q *downloadQueue) cancelRunning() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for _, j := range q.jobs {
+		if j.State == jobRunning && j.cancel != nil {
+			j.cancel(nil)
+			n++
+		}
+	}
+	return n
+}
+
+// cancelJob cancels a running job or drops a queued one. It returns false if
+// the job is unknown or already finished.
+func (q *downloadQueue) cancelJob(id int64) bool {
+	q.mu.Lock()
+	found := false
+	var dropped *downloadJob
+	for _, j := range q.jobs {
+		if j.ID != id {
+			continue
+		}
+		switch {
+		case j.State == jobRunning && j.cancel != nil:
+			j.cancel(nil)
+			found = true
+		case j.State == jobQueued:
+			j.State = jobCanceled
+			j.Status = tr("Removed from queue")
+			found = true
+			final := *j
+			dropped = &final
+		}
+		break
+	}
+	q.mu.Unlock()
+	if found {
+		q.changed()
+	}
+	if dropped != nil {
+		q.finished(*dropped)
+	}
+	return found
+}
+
+// move shifts a queued job past the neighbouring queued job in direction
+// (-1 up, +1 down). Running and finished jobs keep their place. It returns
+// the job's new index, or -1 if it could not move.
+func (q *downloadQueue) move(id int64, direction int) int {
+	q.mu.Lock()
+	from := -1
+	for i, j := range q.jobs {
+		if j.ID == id && j.State == jobQueued {
+			from = i
+			break
+		}
+	}
+	to := -1
+	if from >= 0 {
+		for i := from + direction; i >= 0 && i < len(q.jobs); i += direction {
+			if q.jobs[i].State == jobQueued {
+				to = i
+				break
+			}
+		}
+	}
+	if to >= 0 {
+		q.jobs[from], q.jobs[to] = q.jobs[to], q.jobs[from]
+	}
+	q.mu.Unlock()
+	if to >= 0 {
+		q.changed()
+	}
+	return to
+}
+
+// setPriority flags a queued job to be started before the rest of the
+// queue. It returns false if the job is no longer queued.
+func (q *downloadQueue) setPriority(id int64, on bool) bool {
+	q.mu.Lock()
+	ok := false
+	for _, j := range q.jobs {
+		if j.ID == id && j.State == jobQueued {
+			j.Priority = on
+			ok = true
+			break
+		}
+	}
+	q.mu.Unlock()
+	if ok {
+		q.changed()
+	}
+	return ok
+}
+
+// setSettings replaces a job's settings snapshot. Only queued jobs can be
+// edited; it returns false once the job has started.
+func (q *downloadQueue) setSettings(id int64, settings jobSettings) bool {
+	q.mu.Lock()
+	ok := false
+	for _, j := range q.jobs {
+		if j.ID == id && j.State == jobQueued {
+			j.Settings = settings
+			ok = true
+			break
+		}
+	}
+	q.mu.Unlock()
+	if ok {
+		q.changed()
+	}
+	return ok
+}
+
+func (q *downloadQueue) restartRunning() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for _, j := range q.jobs {
+		if j.State == jobRunning && j.cancel != nil {
+			j.cancel(errResumeRestart)
+			n++
+		}
+	}
+	return n
+}
+
+func (q *downloadQueue) clearFinished() {
+	q.mu.Lock()
+	kept := q.jobs[:0]
+	for _, j := range q.jobs {
+		if j.State.finished() {
+			continue
+		}
+		kept = append(kept, j)
+	}
+	q.jobs = kept
+	q.mu.Unlock()
+	q.changed()
+}
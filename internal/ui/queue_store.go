@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	queueStoreName     = "queue.json"
+	queueStoreInterval = 2 * time.Second
+)
+
+// storedJob is an unfinished job as written to disk. Running marks jobs that
+// were mid-download; yt-dlp picks up their partial files on restart.
+type storedJob struct {
+	URL      string      `json:"url"`
+	Settings jobSettings `json:"settings"`
+	Running  bool        `json:"running,omitempty"`
+	Restarts int         `json:"restarts,omitempty"`
+	Priority bool        `json:"priority,omitempty"`
+}
+
+func queueStorePath() (string, error) {
+	dir, err := downloader.AppDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, queueStoreName), nil
+}
+
+func loadStoredQueue() ([]storedJob, error) {
+	path, err := queueStorePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var jobs []storedJob
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func discardStoredQueue() {
+	if path, err := queueStorePath(); err == nil {
+		os.Remove(path)
+	}
+}
+
+// queueStore mirrors the unfinished part of the queue to disk. Changes are
+// coalesced and only written when the stored content differs, since the
+// queue reports every progress tick.
+type queueStore struct {
+	mu    sync.Mutex
+	dirty bool
+	last  []byte
+}
+
+func (s *queueStore) markDirty() {
+	s.mu.Lock()
+	s.dirty = true
+	s.mu.Unlock()
+}
+
+func (s *queueStore) run(q *downloadQueue) {
+	ticker := time.NewTicker(queueStoreInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.mu.Lock()
+		dirty := s.dirty
+		s.dirty = false
+		s.mu.Unlock()
+		if dirty {
+			s.save(q.snapshot())
+		}
+	}
+}
+
+func (s *queueStore) save(jobs []downloadJob) {
+	stored := []storedJob{}
+	for _, j := range jobs {
+		if j.State.finished() {
+			continue
+		}
+		stored = append(stored, storedJob{
+			URL:      j.URL,
+			Settings: j.Settings,
+			Running:  j.State == jobRunning,
+			Restarts: j.Restarts,
+			Priority: j.Priority,
+		})
+	}
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bytes.Equal(data, s.last) {
+		return
+	}
+	path, err := queueStorePath()
+	if err != nil {
+		return
+	}
+	if len(stored) == 0 {
+		os.Remove(path)
+		s.last = data
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return
+	}
+	s.last = data
+}
+
+func askResumeQueue(w fyne.Window, n int) bool {
+	choiceCh := make(chan bool, 1)
+	runOnMain(func() {
+		d := dialog.NewConfirm(
+			tr("Resume Downloads"),
+			trf("%d download(s) did not finish last time ytgui ran.\nResume them now?", n),
+			func(ok bool) { choiceCh <- ok },
+			w,
+		)
+		d.SetConfirmText(tr("Resume"))
+		d.SetDismissText(tr("Discard"))
+		d.Show()
+	})
+	return <-choiceCh
+}
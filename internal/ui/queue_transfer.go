@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ytgui/internal/downloader"
+)
+
+// queueFileFormats are the extensions "Import Queue..." accepts. JSON files
+// carry per-item settings; .m3u/.m3u8 and .txt are plain URL lists queued
+// with the current settings.
+var queueFileFormats = []string{".json", ".m3u", ".m3u8", ".txt"}
+
+// exportableJobs is what "Export Queue..." writes: every job that hasn't
+// finished successfully, in queue order. Extractor arguments (which may
+// carry a PO token) and the source address stay on this machine, like
+// redactedPrefs; importing fills them in from the current settings.
+func exportableJobs(jobs []downloadJob) []storedJob {
+	var out []storedJob
+	for _, j := range jobs {
+		if j.State == jobDone {
+			continue
+		}
+		s := j.Settings
+		s.Redownload = false
+		s.ExtractorArgs = nil
+		s.Binding = networkBinding{}
+		out = append(out, storedJob{URL: j.URL, Settings: s, Priority: j.Priority})
+	}
+	return out
+}
+
+// writeQueueFile writes jobs in the format picked by the file's extension:
+// M3U for .m3u/.m3u8, one URL per line for .txt and JSON otherwise.
+func writeQueueFile(w io.Writer, name string, jobs []storedJob) error {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".m3u", ".m3u8":
+		var b strings.Builder
+		b.WriteString("#EXTM3U\n")
+		for _, j := range jobs {
+			b.WriteString(j.URL + "\n")
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	case ".txt":
+		var b strings.Builder
+		for _, j := range jobs {
+			b.WriteString(j.URL + "\n")
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	default:
+		data, err := json.MarshalIndent(jobs, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+}
+
+// readQueueFile reads an exported queue or a URL list. Settings missing from
+// a JSON entry, and a destination folder that doesn't exist on this machine,
+// fall back to base.
+func readQueueFile(r io.Reader, name string, base jobSettings) ([]storedJob, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []storedJob
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var raw []json.RawMessage
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("invalid queue file %s: %w", name, err)
+		}
+		for i, msg := range raw {
+			sj := storedJob{Settings: base}
+			if err := json.Unmarshal(msg, &sj); err != nil {
+				return nil, fmt.Errorf("item %d: %w", i+1, err)
+			}
+			u, ok := parseLaunchArg(sj.URL)
+			if !ok {
+				return nil, fmt.Errorf("item %d: %q is not an http(s) URL", i+1, sj.URL)
+			}
+			sj.URL = u
+			sj.Running, sj.Restarts = false, 0
+			if sj.Settings.Folder != base.Folder {
+				if info, err := os.Stat(sj.Settings.Folder); err != nil || !info.IsDir() {
+					sj.Settings.Folder = base.Folder
+				}
+			}
+			sanitizeImportedSettings(&sj.Settings, base)
+			jobs = append(jobs, sj)
+		}
+	} else {
+		// URL lists and M3U: comments and #EXT lines are skipped, as are
+		// local paths, which an M3U may mix in.
+		sc := bufio.NewScanner(bytes.NewReader(data))
+		for sc.Scan() {
+			line := strings.TrimSpace(strings.TrimPrefix(sc.Text(), "\ufeff"))
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if u, ok := parseLaunchArg(line); ok {
+				jobs = append(jobs, storedJob{URL: u, Settings: base})
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if len(jobs) == 0 {
+		return nil, errors.New(trf("%s contains no URLs to download.", name))
+	}
+	return jobs, nil
+}
+
+// sanitizeImportedSettings checks what a queue file may have been edited to
+// contain, since it is handed to yt-dlp and used for paths. A file name
+// that isn't a plain name is dropped, so the title is used again; other
+// unknown or unsafe values fall back to base. Extractor arguments and the
+// source address always come from base, as exports leave them out.
+func sanitizeImportedSettings(s *jobSettings, base jobSettings) {
+	if s.FileName != "" && downloader.CheckFileName(s.FileName, s.NameRules) != nil {
+		s.FileName = ""
+	}
+	if s.NameRules.MaxLength < 0 {
+		s.NameRules.MaxLength = base.NameRules.MaxLength
+	}
+	if !safeSortTemplate(s.SortTemplate) {
+		s.SortTemplate = base.SortTemplate
+	}
+	s.ExtractorArgs = base.ExtractorArgs
+	s.Binding = base.Binding
+	if !containsString(qualityOptions, s.Quality) && !containsString(twitchQualities, s.Quality) {
+		s.Quality = base.Quality
+	}
+	if !containsString(profileOptions, s.Profile) {
+		s.Profile = base.Profile
+	}
+	if s.AudioQuality != "" && !containsString(audioQualities, s.AudioQuality) {
+		s.AudioQuality = base.AudioQuality
+	}
+	if s.Loudness != "" && !containsString(loudnessOptions, s.Loudness) {
+		s.Loudness = base.Loudness
+	}
+	if s.MaxFPS != 0 && !containsString(fpsOptions, fpsOption(s.MaxFPS)) {
+		s.MaxFPS = base.MaxFPS
+	}
+	if s.DynamicRange != "" && !containsString(dynamicRanges, s.DynamicRange) {
+		s.DynamicRange = base.DynamicRange
+	}
+	if s.Device != "" && !containsString(deviceProfiles, s.Device) {
+		s.Device = base.Device
+	}
+	if s.SubFormat != "" && !containsString(subFormats, s.SubFormat) {
+		s.SubFormat = base.SubFormat
+	}
+	if s.TwitchChat != "" && !containsString(twitchChatModes, s.TwitchChat) {
+		s.TwitchChat = base.TwitchChat
+	}
+	if s.ClipStart < 0 || s.ClipEnd < 0 || (s.ClipEnd > 0 && s.ClipEnd <= s.ClipStart) {
+		s.ClipStart, s.ClipEnd = 0, 0
+	}
+}
+
+// safeSortTemplate reports whether a template stays below the download
+// folder: no absolute paths and no ".." levels.
+func safeSortTemplate(tmpl string) bool {
+	if filepath.IsAbs(tmpl) || filepath.VolumeName(tmpl) != "" || strings.HasPrefix(tmpl, "/") || strings.HasPrefix(tmpl, "\\") {
+		return false
+	}
+	for _, seg := range strings.FieldsFunc(tmpl, func(c rune) bool { return c == '/' || c == '\\' }) {
+		if strings.TrimSpace(seg) == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// pushPage is the phone-sized form served at /push. The token comes from the
+// page's own query string, so the link shown in settings is all a phone
+// needs; it is sent back as a bearer token, never rendered into the page.
+var pushPage = template.Must(template.New("push").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>ytgui</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 0; padding: 1.5em; background: #1e1e1e; color: #eee; }
+h1 { font-size: 1.3em; margin-top: 0; }
+label { display: block; margin: 1em 0 0.3em; }
+input, select, button { width: 100%; box-sizing: border-box; font-size: 1.1em; padding: 0.6em; border-radius: 6px; border: 1px solid #555; background: #2b2b2b; color: #eee; }
+button { margin-top: 1.2em; background: #3d7be0; border: none; color: #fff; }
+#status { margin-top: 1em; min-height: 1.5em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<form id="push">
+<label for="url">{{.URLLabel}}</label>
+<input id="url" type="url" required placeholder="https://" autocomplete="off">
+<label for="preset">{{.PresetLabel}}</label>
+<select id="preset">
+<option value="">{{.CurrentSettings}}</option>
+{{range .Presets}}<option>{{.}}</option>
+{{end}}</select>
+<button type="submit">{{.Submit}}</button>
+</form>
+<div id="status"></div>
+<script>
+const params = new URLSearchParams(location.search);
+const token = params.get("token") || "";
+const status = document.getElementById("status");
+document.getElementById("url").value = params.get("url") || "";
+document.getElementById("push").addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const body = {url: document.getElementById("url").value.trim()};
+  const preset = document.getElementById("preset").value;
+  if (preset) body.preset = preset;
+  status.textContent = "…";
+  try {
+    const resp = await fetch("/api/jobs", {
+      method: "POST",
+      headers: {"Authorization": "Bearer " + token, "Content-Type": "application/json"},
+      body: JSON.stringify(body),
+    });
+    const data = await resp.json();
+    if (!resp.ok) throw new Error(data.error || resp.statusText);
+    status.textContent = {{.Queued}} + " #" + data.id;
+    document.getElementById("url").value = "";
+  } catch (err) {
+    status.textContent = err.message;
+  }
+});
+</script>
+</body>
+</html>
+`))
+
+func (api *controlAPI) servePushPage(w http.ResponseWriter, r *http.Request) {
+	var presets []string
+	if api.presets != nil {
+		presets = api.presets()
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = pushPage.Execute(w, map[string]any{
+		"Title":           tr("Send to ytgui"),
+		"URLLabel":        tr("Video URL"),
+		"PresetLabel":     tr("Download with"),
+		"CurrentSettings": tr(watchCurrentName),
+		"Presets":         presets,
+		"Submit":          tr("Download"),
+		"Queued":          tr("Queued job"),
+	})
+}
+
+// pushPageURL is the link to open on a phone. A wildcard listen address is
+// replaced by this machine's LAN address; a loopback one can't be reached
+// from other devices, which the settings page points out.
+func pushPageURL(addr, token string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return ""
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = lanAddress()
+	}
+	u := url.URL{
+		Scheme:   "http",
+		Host:     net.JoinHostPort(host, port),
+		Path:     "/push",
+		RawQuery: url.Values{"token": {token}}.Encode(),
+	}
+	return u.String()
+}
+
+// lanAddress guesses the IPv4 address other devices on the network reach
+// this machine at.
+func lanAddress() string {
+	addrs, err := net.InterfaceAddrs()
+	if err == nil {
+		for _, a := range addrs {
+			if n, ok := a.(*net.IPNet); ok && !n.IP.IsLoopback() && n.IP.To4() != nil && n.IP.IsPrivate() {
+				return n.IP.String()
+			}
+		}
+	}
+	return "localhost"
+}
+
+func pushReachable(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return host != "localhost" && (ip == nil || !ip.IsLoopback())
+}
@@ -0,0 +1,33 @@
+package ui
+
+import "time"
+
+const (
+	resumeCheckInterval = 5 * time.Second
+	resumeGapThreshold  = 30 * time.Second
+)
+
+// watchSystemResume calls onResume after the machine slept for longer than
+// resumeGapThreshold. Where the OS offers a clock that keeps running while
+// suspended, the sleep is measured as how far it drifted from the monotonic
+// clock, which setting the wall clock doesn't affect. Elsewhere the wall
+// clock is compared with Go's monotonic reading, which stops during sleep.
+func watchSystemResume(interval time.Duration, onResume func(asleep time.Duration)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastSuspended, haveSuspended := suspendedTime()
+	last := time.Now()
+	for range ticker.C {
+		now := time.Now()
+		asleep := now.Round(0).Sub(last.Round(0)) - now.Sub(last)
+		last = now
+		if haveSuspended {
+			suspended, _ := suspendedTime()
+			asleep = suspended - lastSuspended
+			lastSuspended = suspended
+		}
+		if asleep > resumeGapThreshold {
+			onResume(asleep)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+//go:build linux
+
+package ui
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	clockMonotonic = 1
+	clockBoottime  = 7
+)
+
+func clockGettime(clock uintptr) (time.Duration, bool) {
+	var ts syscall.Timespec
+	if _, _, errno := syscall.Syscall(syscall.SYS_CLOCK_GETTIME, clock, uintptr(unsafe.Pointer(&ts)), 0); errno != 0 {
+		return 0, false
+	}
+	return time.Duration(ts.Nano()), true
+}
+
+// suspendedTime returns how long the machine has been suspended since boot:
+// CLOCK_BOOTTIME counts suspend, CLOCK_MONOTONIC doesn't.
+func suspendedTime() (time.Duration, bool) {
+	boot, ok := clockGettime(clockBoottime)
+	if !ok {
+		return 0, false
+	}
+	mono, ok := clockGettime(clockMonotonic)
+	if !ok {
+		return 0, false
+	}
+	return boot - mono, true
+}
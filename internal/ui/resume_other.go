@@ -0,0 +1,9 @@
+//go:build !linux && !windows
+
+package ui
+
+import "time"
+
+func suspendedTime() (time.Duration, bool) {
+	return 0, false
+}
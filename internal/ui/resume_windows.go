@@ -0,0 +1,26 @@
+//go:build windows
+
+package ui
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	procGetTickCount64             = syscall.NewLazyDLL("kernel32.dll").NewProc("GetTickCount64")
+	procQueryUnbiasedInterruptTime = syscall.NewLazyDLL("kernel32.dll").NewProc("QueryUnbiasedInterruptTime")
+)
+
+// suspendedTime returns how long the machine has been asleep since boot:
+// the tick count includes sleep and hibernation, the unbiased interrupt
+// time doesn't.
+func suspendedTime() (time.Duration, bool) {
+	var unbiased uint64
+	if r, _, _ := procQueryUnbiasedInterruptTime.Call(uintptr(unsafe.Pointer(&unbiased))); r == 0 {
+		return 0, false
+	}
+	ticks, _, _ := procGetTickCount64.Call()
+	return time.Duration(ticks)*time.Millisecond - time.Duration(unbiased)*100, true
+}
@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefRetryAttempts    = "retry_attempts"
+	prefRetryBackoff     = "retry_backoff_seconds"
+	prefRetryNetworkOnly = "retry_network_only"
+	prefYTDLPRetries     = "ytdlp_retries"
+	prefFragmentRetries  = "ytdlp_fragment_retries"
+	prefAutoRedownload   = "redownload_suspect"
+
+	maxRetryDelay = 5 * time.Minute
+)
+
+// failureTailLines is how many error lines of a run are kept for
+// downloader.ClassifyError.
+const failureTailLines = 20
+
+// failureTail keeps the last error lines of a yt-dlp run.
+type failureTail struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (t *failureTail) observe(line string) {
+	if !strings.Contains(strings.ToLower(line), "error") {
+		return
+	}
+	t.mu.Lock()
+	t.lines = append(t.lines, line)
+	if len(t.lines) > failureTailLines {
+		t.lines = t.lines[len(t.lines)-failureTailLines:]
+	}
+	t.mu.Unlock()
+}
+
+func (t *failureTail) snapshot() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.lines...)
+}
+
+// failureHint tells the user what to do about a kind of failure, or returns
+// "" when there is nothing to add.
+func failureHint(kind downloader.ErrorKind) string {
+	switch kind {
+	case downloader.GeoBlocked:
+		return tr("The video isn't available in your country. A VPN or proxy in another country may help.")
+	case downloader.AgeRestricted:
+		return tr("The video is age-restricted and can only be downloaded with a signed-in account.")
+	case downloader.FormatUnavailable:
+		return tr("The chosen quality isn't available for this video. Pick another quality and try again.")
+	case downloader.DiskFull:
+		return tr("The download folder's drive is full. Free some space or choose another folder.")
+	case downloader.ExtractorBroken:
+		return tr("yt-dlp couldn't read this site. Checking for a newer yt-dlp, which usually fixes this.")
+	}
+	return ""
+}
+
+// retryPolicy is the job-level counterpart of the tool downloader's retry
+// loop: whole yt-dlp runs are repeated with exponential backoff, while
+// Retries and FragmentRetries are passed through for yt-dlp's own retries.
+type retryPolicy struct {
+	Attempts        int
+	Backoff         time.Duration
+	NetworkOnly     bool
+	Retries         int
+	FragmentRetries int
+	// StallTimeout is how long a download may go without data before the
+	// watchdog steps in with StallAction; zero turns the watchdog off.
+	StallTimeout time.Duration
+	StallAction  string
+}
+
+func loadRetryPolicy(prefs fyne.Preferences) retryPolicy {
+	return retryPolicy{
+		Attempts:        prefs.IntWithFallback(prefRetryAttempts, 3),
+		Backoff:         time.Duration(prefs.IntWithFallback(prefRetryBackoff, 10)) * time.Second,
+		NetworkOnly:     prefs.BoolWithFallback(prefRetryNetworkOnly, true),
+		Retries:         prefs.IntWithFallback(prefYTDLPRetries, 10),
+		FragmentRetries: prefs.IntWithFallback(prefFragmentRetries, 10),
+		StallTimeout:    time.Duration(prefs.IntWithFallback(prefStallTimeout, defaultStallTimeout)) * time.Second,
+		StallAction:     selectedOption(prefs, prefStallAction, stallActions, stallRestart),
+	}
+}
+
+// delay doubles the backoff after every failed attempt, capped at
+// maxRetryDelay.
+func (p retryPolicy) delay(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	if d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return d
+}
+
+// shouldRetry repeats network failures and stalls, and any other failure
+// unless NetworkOnly is set, except the kinds running again can't fix.
+func (p retryPolicy) shouldRetry(attempt int, err error) bool {
+	if err == nil || attempt >= p.Attempts || errors.Is(err, context.Canceled) {
+		return false
+	}
+	switch downloader.ErrorKindOf(err) {
+	case downloader.NetworkError:
+		return true
+	case downloader.GeoBlocked, downloader.AgeRestricted, downloader.FormatUnavailable, downloader.DiskFull, downloader.ExtractorBroken:
+		return false
+	}
+	return !p.NetworkOnly || errors.Is(err, errStalled)
+}
+
+func (p retryPolicy) ytdlpArgs() []string {
+	var args []string
+	if p.Retries > 0 {
+		args = append(args, "--retries", strconv.Itoa(p.Retries))
+	}
+	if p.FragmentRetries > 0 {
+		args = append(args, "--fragment-retries", strconv.Itoa(p.FragmentRetries))
+	}
+	return args
+}
+
+func networkPage(prefs fyne.Preferences) func() fyne.CanvasObject {
+	return func() fyne.CanvasObject {
+		intEntry := func(key string, fallback int) *widget.Entry {
+			e := widget.NewEntry()
+			e.SetText(strconv.Itoa(prefs.IntWithFallback(key, fallback)))
+			e.OnChanged = func(text string) {
+				if n, err := strconv.Atoi(strings.TrimSpace(text)); err == nil && n >= 0 {
+					prefs.SetInt(key, n)
+				}
+			}
+			return e
+		}
+		networkOnly := widget.NewCheck(tr("Retry only after network errors"), func(on bool) {
+			prefs.SetBool(prefRetryNetworkOnly, on)
+		})
+		networkOnly.SetChecked(prefs.BoolWithFallback(prefRetryNetworkOnly, true))
+		autoRedownload := widget.NewCheck(tr("Download again automatically when verification fails"), func(on bool) {
+			prefs.SetBool(prefAutoRedownload, on)
+		})
+		autoRedownload.SetChecked(prefs.Bool(prefAutoRedownload))
+		stallAction := widget.NewSelect(trList(stallActions), func(shown string) {
+			prefs.SetString(prefStallAction, untr(stallActions, shown))
+		})
+		stallAction.SetSelected(tr(selectedOption(prefs, prefStallAction, stallActions, stallRestart)))
+		return container.NewVBox(
+			widget.NewForm(
+				widget.NewFormItem(tr("Attempts per download"), intEntry(prefRetryAttempts, 3)),
+				widget.NewFormItem(tr("First retry after (seconds)"), intEntry(prefRetryBackoff, 10)),
+				widget.NewFormItem(tr("yt-dlp retries"), intEntry(prefYTDLPRetries, 10)),
+				widget.NewFormItem(tr("Fragment retries"), intEntry(prefFragmentRetries, 10)),
+				widget.NewFormItem(tr("Give up on lookups after (seconds)"), intEntry(prefProbeTimeout, defaultProbeTimeout)),
+				widget.NewFormItem(tr("Stalled after no data for (seconds)"), intEntry(prefStallTimeout, defaultStallTimeout)),
+				widget.NewFormItem(tr("When a download stalls"), stallAction),
+			),
+			networkOnly,
+			widget.NewLabel(tr("The wait doubles after each failed attempt, up to five minutes.")),
+			widget.NewLabel(tr("Set the stall time to 0 to turn the watchdog off.")),
+			autoRedownload,
+			widget.NewSeparator(),
+			sourceAddressSection(prefs),
+			widget.NewAccordion(widget.NewAccordionItem(tr("Advanced: site workarounds"), extractorArgsSection(prefs))),
+		)
+	}
+}
@@ -0,0 +1,220 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+const searchResultLimit = 10
+
+func formatDuration(seconds float64) string {
+	if seconds <= 0 {
+		return tr("live/unknown")
+	}
+	d := time.Duration(seconds) * time.Second
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	s := int(d.Seconds()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// thumbnailCache fetches preview images once per URL and hands them to every
+// row that asks, so scrolling the result list doesn't refetch.
+type thumbnailCache struct {
+	mu      sync.Mutex
+	images  map[string]fyne.Resource
+	pending map[string][]func(fyne.Resource)
+	client  *http.Client
+}
+
+func newThumbnailCache() *thumbnailCache {
+	return &thumbnailCache{
+		images:  make(map[string]fyne.Resource),
+		pending: make(map[string][]func(fyne.Resource)),
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (c *thumbnailCache) get(url string, done func(fyne.Resource)) {
+	c.mu.Lock()
+	if res, ok := c.images[url]; ok {
+		c.mu.Unlock()
+		done(res)
+		return
+	}
+	waiting, inflight := c.pending[url]
+	c.pending[url] = append(waiting, done)
+	c.mu.Unlock()
+	if inflight {
+		return
+	}
+
+	go func() {
+		var res fyne.Resource
+		if resp, err := c.client.Get(url); err == nil {
+			if resp.StatusCode == http.StatusOK {
+				if data, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20)); err == nil {
+					res = fyne.NewStaticResource(url, data)
+				}
+			}
+			resp.Body.Close()
+		}
+		c.mu.Lock()
+		callbacks := c.pending[url]
+		delete(c.pending, url)
+		if res != nil {
+			c.images[url] = res
+		}
+		c.mu.Unlock()
+		for _, cb := range callbacks {
+			cb(res)
+		}
+	}()
+}
+
+func (c *thumbnailCache) clear() {
+	c.mu.Lock()
+	c.images = make(map[string]fyne.Resource)
+	c.mu.Unlock()
+}
+
+// newSearchPanel builds the "Search" tab. Picking a result puts its URL in the
+// main entry; the Download button queues it with the current settings.
+func newSearchPanel(ytdlp func() string, onPick func(url string), onDownload func(url string)) fyne.CanvasObject {
+	var (
+		mu      sync.Mutex
+		results []downloader.SearchResult
+	)
+	thumbs := newThumbnailCache()
+
+	query := widget.NewEntry()
+	query.SetPlaceHolder(tr("Search YouTube"))
+	info := widget.NewLabel("")
+
+	list := widget.NewList(
+		func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(results)
+		},
+		func() fyne.CanvasObject {
+			img := canvas.NewImageFromResource(nil)
+			img.FillMode = canvas.ImageFillContain
+			img.SetMinSize(fyne.NewSize(96, 54))
+			title := widget.NewLabel("")
+			title.TextStyle = fyne.TextStyle{Bold: true}
+			title.Truncation = fyne.TextTruncateEllipsis
+			meta := widget.NewLabel("")
+			dl := widget.NewButton(tr("Download"), nil)
+			return container.NewBorder(nil, nil, img, dl, container.NewVBox(title, meta))
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			mu.Lock()
+			if id >= len(results) {
+				mu.Unlock()
+				return
+			}
+			r := results[id]
+			mu.Unlock()
+
+			row := o.(*fyne.Container)
+			text := row.Objects[0].(*fyne.Container)
+			img := row.Objects[1].(*canvas.Image)
+			dl := row.Objects[2].(*widget.Button)
+			text.Objects[0].(*widget.Label).SetText(r.Title)
+			text.Objects[1].(*widget.Label).SetText(fmt.Sprintf("%s  ·  %s", r.Channel, formatDuration(r.Duration)))
+			dl.OnTapped = func() { onDownload(r.URL) }
+
+			img.Resource = nil
+			img.Refresh()
+			if r.Thumbnail == "" || !thumbnailsEnabled() {
+				return
+			}
+			want := r.Thumbnail
+			thumbs.get(want, func(res fyne.Resource) {
+				mu.Lock()
+				current := id < len(results) && results[id].Thumbnail == want
+				mu.Unlock()
+				if !current || res == nil {
+					return
+				}
+				runOnMain(func() {
+					img.Resource = res
+					img.Refresh()
+				})
+			})
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		mu.Lock()
+		var picked string
+		if id < len(results) {
+			picked = results[id].URL
+		}
+		mu.Unlock()
+		list.UnselectAll()
+		if picked != "" {
+			onPick(picked)
+		}
+	}
+
+	var searchBtn *widget.Button
+	runSearch := func() {
+		q := strings.TrimSpace(query.Text)
+		if q == "" {
+			return
+		}
+		path := ytdlp()
+		if path == "" {
+			info.SetText(tr("Preparing required tools..."))
+			return
+		}
+		searchBtn.Disable()
+		info.SetText(trf("Searching for %q...", q))
+		go func() {
+			found, err := downloader.SearchYouTube(path, q, searchResultLimit)
+			mu.Lock()
+			if err == nil {
+				results = found
+			}
+			mu.Unlock()
+			if lowMemoryMode.Load() {
+				thumbs.clear()
+			}
+			runOnMain(func() {
+				searchBtn.Enable()
+				if err != nil {
+					info.SetText(trf("Search failed: %v", err))
+					return
+				}
+				info.SetText(trf("%d results. Click a result to use its URL.", len(found)))
+				list.ScrollToTop()
+				list.Refresh()
+			})
+		}()
+	}
+	searchBtn = widget.NewButton(tr("Search"), runSearch)
+	query.OnSubmitted = func(string) { runSearch() }
+
+	return container.NewBorder(
+		container.NewVBox(container.NewBorder(nil, nil, nil, searchBtn, query), info),
+		nil,
+		nil,
+		nil,
+		list,
+	)
+}
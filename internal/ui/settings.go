@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+)
+
+type settingsPage struct {
+	title   string
+	content func() fyne.CanvasObject
+}
+
+// showSettings opens the settings dialog. Pages build their content lazily so
+// they always reflect the current preference values.
+func showSettings(w fyne.Window, pages []settingsPage) {
+	tabs := container.NewAppTabs()
+	for _, p := range pages {
+		tabs.Append(container.NewTabItem(tr(p.title), container.NewVScroll(p.content())))
+	}
+	d := dialog.NewCustom(tr("Settings"), tr("Close"), tabs, w)
+	d.Resize(fyne.NewSize(560, 420))
+	d.Show()
+}
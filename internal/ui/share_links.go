@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"ytgui/internal/downloader"
+)
+
+// trackingParams are dropped from YouTube links; they only identify who
+// shared the link and can defeat duplicate detection.
+var trackingParams = map[string]bool{
+	"si":         true,
+	"feature":    true,
+	"pp":         true,
+	"ab_channel": true,
+	"fbclid":     true,
+	"gclid":      true,
+}
+
+// normalizeVideoURL turns YouTube share links (youtu.be/<id>, /shorts/<id>)
+// into watch URLs without tracking parameters. A t= or start= timestamp is
+// removed from the URL and returned in seconds, 0 if there was none. Other
+// sites' URLs are returned unchanged.
+func normalizeVideoURL(raw string) (string, int) {
+	raw = strings.TrimSpace(raw)
+	if !downloader.IsYouTubeURL(raw) {
+		return raw, 0
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw, 0
+	}
+	q := u.Query()
+	host := strings.ToLower(strings.TrimPrefix(u.Hostname(), "www."))
+	switch {
+	case host == "youtu.be":
+		if id := strings.Trim(u.Path, "/"); id != "" {
+			q.Set("v", id)
+		}
+		u.Host, u.Path = "www.youtube.com", "/watch"
+	case strings.HasPrefix(u.Path, "/shorts/"):
+		if id := strings.Trim(strings.TrimPrefix(u.Path, "/shorts/"), "/"); id != "" {
+			q.Set("v", id)
+		}
+		u.Path = "/watch"
+	}
+	u.Scheme = "https"
+
+	start := 0
+	for _, key := range []string{"t", "start"} {
+		if v := q.Get(key); v != "" {
+			if start == 0 {
+				start = parseTimestamp(v)
+			}
+			q.Del(key)
+		}
+	}
+	if strings.HasPrefix(u.Fragment, "t=") {
+		if start == 0 {
+			start = parseTimestamp(strings.TrimPrefix(u.Fragment, "t="))
+		}
+		u.Fragment = ""
+	}
+	for key := range q {
+		if trackingParams[key] || strings.HasPrefix(key, "utm_") {
+			q.Del(key)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), start
+}
+
+// parseTimestamp reads YouTube's timestamp forms: "123", "123s" and
+// "1h2m3s". Unreadable values count as no timestamp.
+func parseTimestamp(s string) int {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if n, err := strconv.Atoi(strings.TrimSuffix(s, "s")); err == nil {
+		return max(n, 0)
+	}
+	total, num := 0, 0
+	digits := false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			num = num*10 + int(r-'0')
+			digits = true
+		case digits && r == 'h':
+			total += num * 3600
+		case digits && r == 'm':
+			total += num * 60
+		case digits && r == 's':
+			total += num
+		default:
+			return 0
+		}
+		if r < '0' || r > '9' {
+			num, digits = 0, false
+		}
+	}
+	if digits {
+		return 0
+	}
+	return total
+}
+
+// formatTimestamp renders seconds as 1h02m03s / 2m03s / 45s, which is safe
+// in file names.
+func formatTimestamp(sec int) string {
+	h, m, s := sec/3600, sec/60%60, sec%60
+	switch {
+	case h > 0:
+		return fmt.Sprintf("%dh%02dm%02ds", h, m, s)
+	case m > 0:
+		return fmt.Sprintf("%dm%02ds", m, s)
+	default:
+		return fmt.Sprintf("%ds", s)
+	}
+}
+
+// askClipFromTimestamp offers to download a timestamped link in full or
+// only from the timestamp on. It must be called on the main thread.
+func askClipFromTimestamp(w fyne.Window, start int, onChoice func(clip bool)) {
+	d := dialog.NewConfirm(
+		tr("Timestamped Link"),
+		trf("This link starts at %s.", formatTimestamp(start)),
+		onChoice,
+		w,
+	)
+	d.SetConfirmText(tr("Clip from timestamp"))
+	d.SetDismissText(tr("Download full video"))
+	d.Show()
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// shortcutEntry is a single-line entry that lets window-level shortcuts and
+// Esc through while it has focus; a plain Entry swallows them.
+type shortcutEntry struct {
+	widget.Entry
+	onEscape   func()
+	onShortcut func(fyne.Shortcut) bool
+}
+
+func newShortcutEntry() *shortcutEntry {
+	e := &shortcutEntry{}
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+func (e *shortcutEntry) TypedKey(ev *fyne.KeyEvent) {
+	if ev.Name == fyne.KeyEscape && e.onEscape != nil {
+		e.onEscape()
+		return
+	}
+	e.Entry.TypedKey(ev)
+}
+
+func (e *shortcutEntry) TypedShortcut(s fyne.Shortcut) {
+	if e.onShortcut != nil && e.onShortcut(s) {
+		return
+	}
+	e.Entry.TypedShortcut(s)
+}
+
+// shortcutSet registers Ctrl/Cmd+key shortcuts on a canvas and remembers them
+// so focused widgets can forward the same shortcuts.
+type shortcutSet struct {
+	canvas  fyne.Canvas
+	actions map[string]func()
+}
+
+func newShortcutSet(c fyne.Canvas) *shortcutSet {
+	return &shortcutSet{canvas: c, actions: make(map[string]func())}
+}
+
+func (s *shortcutSet) add(key fyne.KeyName, action func()) {
+	sc := &desktop.CustomShortcut{KeyName: key, Modifier: fyne.KeyModifierShortcutDefault}
+	s.actions[sc.ShortcutName()] = action
+	s.canvas.AddShortcut(sc, func(fyne.Shortcut) { action() })
+}
+
+func (s *shortcutSet) handle(sc fyne.Shortcut) bool {
+	action, ok := s.actions[sc.ShortcutName()]
+	if ok {
+		action()
+	}
+	return ok
+}
@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	prefSnapshotEnabled = "snapshot_enabled"
+	prefSnapshotAddr    = "snapshot_addr"
+
+	defaultSnapshotAddr = "127.0.0.1:8765"
+)
+
+type snapshotJob struct {
+	ID       int64   `json:"id"`
+	URL      string  `json:"url"`
+	State    string  `json:"state"`
+	Status   string  `json:"status"`
+	Progress float64 `json:"progress"`
+	Error    string  `json:"error,omitempty"`
+}
+
+type queueSnapshot struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	Running     int           `json:"running"`
+	Queued      int           `json:"queued"`
+	Done        int           `json:"done"`
+	Failed      int           `json:"failed"`
+	Canceled    int           `json:"canceled"`
+	Jobs        []snapshotJob `json:"jobs"`
+}
+
+func buildQueueSnapshot(jobs []downloadJob) queueSnapshot {
+	snap := queueSnapshot{
+		GeneratedAt: time.Now().UTC(),
+		Jobs:        make([]snapshotJob, 0, len(jobs)),
+	}
+	for _, j := range jobs {
+		switch j.State {
+		case jobRunning:
+			snap.Running++
+		case jobQueued:
+			snap.Queued++
+		case jobDone:
+			snap.Done++
+		case jobFailed:
+			snap.Failed++
+		case jobCanceled:
+			snap.Canceled++
+		}
+		sj := snapshotJob{
+			ID:       j.ID,
+			URL:      j.URL,
+			State:    j.State.String(),
+			Status:   j.Status,
+			Progress: j.Progress,
+		}
+		if j.Err != nil {
+			sj.Error = j.Err.Error()
+		}
+		snap.Jobs = append(snap.Jobs, sj)
+	}
+	return snap
+}
+
+// snapshotServer serves a read-only JSON view of the queue for dashboards.
+// It exposes no control endpoints and sends no CORS headers, so web pages in
+// the user's browser can't read it, and it is safe to leave running.
+type snapshotServer struct {
+	mu  sync.Mutex
+	srv *http.Server
+}
+
+func (s *snapshotServer) start(addr string, source func() []downloadJob) error {
+	s.stop()
+
+	mux := http.NewServeMux()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if !localHost(r.Host, addr) {
+			http.Error(w, "unexpected host", http.StatusForbidden)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "read-only endpoint", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(buildQueueSnapshot(source()))
+	}
+	mux.HandleFunc("/snapshot.json", handler)
+	mux.HandleFunc("/api/snapshot", handler)
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	s.mu.Lock()
+	s.srv = srv
+	s.mu.Unlock()
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ln.Close()
+		}
+	}()
+	return nil
+}
+
+// localHost reports whether host, a request's Host header, names the
+// listen address addr, localhost or an IP address. Anything else is a DNS
+// name pointed at us by another site (DNS rebinding).
+func localHost(host, addr string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if listen, _, err := net.SplitHostPort(addr); err == nil && strings.EqualFold(host, listen) {
+		return true
+	}
+	return strings.EqualFold(host, "localhost") || net.ParseIP(host) != nil
+}
+
+func (s *snapshotServer) stop() {
+	s.mu.Lock()
+	srv := s.srv
+	s.srv = nil
+	s.mu.Unlock()
+	if srv == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_ = srv.Shutdown(ctx)
+}
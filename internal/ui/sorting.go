@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefSortRule     = "sort_rule"
+	prefSortTemplate = "sort_template"
+
+	sortOff       = "Off"
+	sortByType    = "By type"
+	sortByChannel = "By channel"
+	sortByDate    = "By month"
+	sortCustom    = "Custom template"
+)
+
+var sortRules = []string{sortOff, sortByType, sortByChannel, sortByDate, sortCustom}
+
+// sortTemplates are the subfolder templates behind the preset rules. Tokens:
+// {type} (Audio, Video or Playlists/<name>), {channel}, {playlist} and {date}
+// (download month, 2006-01).
+var sortTemplates = map[string]string{
+	sortByType:    "{type}",
+	sortByChannel: "Channels/{channel}",
+	sortByDate:    "{date}",
+}
+
+// sortTemplate resolves the rule to a template; "" means sorting is off.
+func sortTemplate(rule, custom string) string {
+	if rule == sortCustom {
+		return strings.TrimSpace(custom)
+	}
+	return sortTemplates[rule]
+}
+
+// expandSortTemplate fills in a template for a single video whose metadata
+// is known. Each folder level is sanitized on its own so titles can't
+// introduce extra levels.
+func expandSortTemplate(tmpl string, audio bool, channel string) string {
+	kind := "Video"
+	if audio {
+		kind = "Audio"
+	}
+	if strings.TrimSpace(channel) == "" {
+		channel = "Unknown channel"
+	}
+	// Sanitized before it is put in, so a slash in the name doesn't split
+	// it into folders.
+	channel = downloader.SafeName(channel)
+	r := strings.NewReplacer(
+		"{type}", kind,
+		"{channel}", channel,
+		"{playlist}", "Singles",
+		"{date}", time.Now().Format("2006-01"),
+	)
+	return joinSortSegments(r.Replace(tmpl), downloader.SafeName)
+}
+
+// ytdlpSortTemplate turns a template into a yt-dlp output path prefix for
+// playlists and for videos whose metadata couldn't be fetched up front.
+func ytdlpSortTemplate(tmpl string, audio, playlist bool) string {
+	kind := "Video"
+	switch {
+	case playlist:
+		kind = "Playlists/%(playlist_title)s"
+	case audio:
+		kind = "Audio"
+	}
+	playlistName := "Singles"
+	if playlist {
+		playlistName = "%(playlist_title)s"
+	}
+	r := strings.NewReplacer(
+		"{type}", kind,
+		"{channel}", "%(uploader)s",
+		"{playlist}", playlistName,
+		"{date}", time.Now().Format("2006-01"),
+	)
+	return joinSortSegments(r.Replace(tmpl), func(s string) string {
+		// Leave yt-dlp fields alone; it sanitizes them itself.
+		if strings.Contains(s, "%(") {
+			return s
+		}
+		return downloader.SafeName(s)
+	})
+}
+
+const (
+	// trimMargin is left for the longest suffix yt-dlp adds to a name while
+	// it works, e.g. ".f401.webm.part".
+	trimMargin = 15
+	// fieldReserve is assumed for a template field whose value is only
+	// known per item, such as the uploader.
+	fieldReserve = 64
+)
+
+// playlistTrimLength is the --trim-filenames value that keeps the items of
+// a playlist saved under dir, a yt-dlp output folder, within MAX_PATH; 0
+// means no limit is needed. yt-dlp trims the name without its extension,
+// so the extension and a margin for intermediate files are taken off. The
+// playlist title in dir is looked up; other fields get fieldReserve.
+func playlistTrimLength(ctx context.Context, ytdlp, url, dir, ext string, probe probeFunc) int {
+	if downloader.LongPathsEnabled() {
+		return 0
+	}
+	if strings.Contains(dir, "%(playlist_title)s") {
+		pctx, done := probe(ctx)
+		title, err := downloader.PlaylistTitle(pctx, ytdlp, url)
+		if done(err) != nil {
+			title = strings.Repeat("x", fieldReserve)
+		}
+		dir = strings.ReplaceAll(dir, "%(playlist_title)s", downloader.SafeName(title))
+	}
+	dir = ytdlpFieldRE.ReplaceAllString(dir, strings.Repeat("x", fieldReserve))
+	budget := downloader.FileNameBudget(dir)
+	if budget == 0 {
+		return 0
+	}
+	return max(budget-len("."+ext)-trimMargin, 16)
+}
+
+var ytdlpFieldRE = regexp.MustCompile(`%\([^)]*\)[a-z]`)
+
+func joinSortSegments(path string, clean func(string) string) string {
+	var parts []string
+	for _, seg := range strings.FieldsFunc(path, func(c rune) bool { return c == '/' || c == '\\' }) {
+		seg = strings.TrimSpace(seg)
+		if seg == "" || seg == "." || seg == ".." {
+			continue
+		}
+		parts = append(parts, clean(seg))
+	}
+	return filepath.Join(parts...)
+}
@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	prefSourceAddress = "source_address"
+	prefIPVersion     = "ip_version"
+
+	ipVersionAny = "Any"
+	ipVersion4   = "IPv4 only"
+	ipVersion6   = "IPv6 only"
+)
+
+var ipVersions = []string{ipVersionAny, ipVersion4, ipVersion6}
+
+// networkBinding pins downloads to one local address or IP family, for
+// machines with several connections or a split-tunnel VPN.
+type networkBinding struct {
+	Address   string
+	IPVersion int
+}
+
+func loadNetworkBinding(prefs fyne.Preferences) networkBinding {
+	b := networkBinding{Address: strings.TrimSpace(prefs.String(prefSourceAddress))}
+	switch selectedOption(prefs, prefIPVersion, ipVersions, ipVersionAny) {
+	case ipVersion4:
+		b.IPVersion = 4
+	case ipVersion6:
+		b.IPVersion = 6
+	}
+	return b
+}
+
+// localAddresses lists the addresses of the interfaces that are up, as
+// "address (interface)".
+func localAddresses() []string {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			prefix, err := netip.ParsePrefix(a.String())
+			if err != nil || prefix.Addr().IsLinkLocalUnicast() {
+				continue
+			}
+			out = append(out, fmt.Sprintf("%s (%s)", prefix.Addr(), iface.Name))
+		}
+	}
+	return out
+}
+
+// sourceAddressSection is the part of the network settings that picks the
+// local address and IP family downloads use.
+func sourceAddressSection(prefs fyne.Preferences) fyne.CanvasObject {
+	problem := widget.NewLabel("")
+	problem.Wrapping = fyne.TextWrapWord
+	address := widget.NewSelectEntry(localAddresses())
+	address.SetPlaceHolder(tr("Automatic"))
+	address.SetText(prefs.String(prefSourceAddress))
+	address.OnChanged = func(text string) {
+		// Picked entries carry the interface name after the address.
+		ip, _, _ := strings.Cut(strings.TrimSpace(text), " ")
+		if ip == "" {
+			problem.SetText("")
+			prefs.SetString(prefSourceAddress, "")
+			return
+		}
+		if _, err := netip.ParseAddr(ip); err != nil {
+			problem.SetText(trf("%s is not an IP address.", ip))
+			return
+		}
+		problem.SetText("")
+		prefs.SetString(prefSourceAddress, ip)
+	}
+	version := widget.NewSelect(trList(ipVersions), func(shown string) {
+		prefs.SetString(prefIPVersion, untr(ipVersions, shown))
+	})
+	version.SetSelected(tr(selectedOption(prefs, prefIPVersion, ipVersions, ipVersionAny)))
+	return container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem(tr("Connect from address"), address),
+			widget.NewFormItem(tr("IP version"), version),
+		),
+		problem,
+	)
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package ui
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// speechCommand speaks text with say on macOS and Speech Dispatcher or
+// eSpeak elsewhere. It returns nil when none of them is installed.
+func speechCommand(text string) *exec.Cmd {
+	if runtime.GOOS == "darwin" {
+		return exec.Command("say", "--", text)
+	}
+	for _, tool := range []string{"spd-say", "espeak-ng", "espeak"} {
+		if path, err := exec.LookPath(tool); err == nil {
+			return exec.Command(path, "--", text)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package ui
+
+import (
+	"os"
+	"os/exec"
+)
+
+// speechCommand speaks text with the Windows speech synthesizer, the voice
+// Narrator uses. The text is passed in the environment so it never needs
+// quoting for PowerShell.
+func speechCommand(text string) *exec.Cmd {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command",
+		"Add-Type -AssemblyName System.Speech; (New-Object System.Speech.Synthesis.SpeechSynthesizer).Speak($env:YTGUI_SPEAK)")
+	cmd.Env = append(os.Environ(), "YTGUI_SPEAK="+text)
+	return cmd
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// speedSamples is how many one-second samples the graph keeps.
+const speedSamples = 60
+
+var speedRegex = regexp.MustCompile(`\bat\s+([0-9.]+)\s*([KMGT]?i?B)/s`)
+
+var speedUnits = map[string]float64{
+	"B":   1,
+	"KiB": 1 << 10, "KB": 1e3,
+	"MiB": 1 << 20, "MB": 1e6,
+	"GiB": 1 << 30, "GB": 1e9,
+	"TiB": 1 << 40, "TB": 1e12,
+}
+
+// parseSpeed reads the transfer rate from a yt-dlp progress line in bytes
+// per second.
+func parseSpeed(line string) (float64, bool) {
+	m := speedRegex.FindStringSubmatch(line)
+	if len(m) < 3 {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	unit, ok := speedUnits[m[2]]
+	if err != nil || !ok {
+		return 0, false
+	}
+	return v * unit, true
+}
+
+// speedSetter is implemented by status setters that track a job's current
+// transfer rate.
+type speedSetter interface {
+	SetSpeed(bytesPerSec float64)
+}
+
+// speedGraph is a rolling sparkline of the total download rate across all
+// running jobs, with the current value next to it.
+type speedGraph struct {
+	mu      sync.Mutex
+	samples []float64
+
+	raster *canvas.Raster
+	label  *widget.Label
+	box    *fyne.Container
+}
+
+func newSpeedGraph() *speedGraph {
+	g := &speedGraph{label: widget.NewLabel("")}
+	g.raster = canvas.NewRaster(g.draw)
+	g.raster.SetMinSize(fyne.NewSize(120, 24))
+	g.box = container.NewBorder(nil, nil, nil, g.label, g.raster)
+	return g
+}
+
+// add records the current total rate. Must be called on the main thread.
+func (g *speedGraph) add(bytesPerSec float64) {
+	g.mu.Lock()
+	g.samples = append(g.samples, bytesPerSec)
+	if len(g.samples) > speedSamples {
+		g.samples = g.samples[len(g.samples)-speedSamples:]
+	}
+	g.mu.Unlock()
+	if bytesPerSec > 0 {
+		g.label.SetText(trf("%s/s total", formatBytes(int64(bytesPerSec))))
+	} else {
+		g.label.SetText("")
+	}
+	g.raster.Refresh()
+}
+
+func (g *speedGraph) draw(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	g.mu.Lock()
+	samples := append([]float64(nil), g.samples...)
+	g.mu.Unlock()
+	peak := 0.0
+	for _, v := range samples {
+		peak = max(peak, v)
+	}
+	if peak <= 0 || w <= 0 {
+		return img
+	}
+	r, gr, b, _ := theme.PrimaryColor().RGBA()
+	fill := color.NRGBA{R: uint8(r >> 8), G: uint8(gr >> 8), B: uint8(b >> 8), A: 0xc0}
+	// Newest sample on the right; each sample covers an equal slice.
+	for x := 0; x < w; x++ {
+		i := len(samples) - speedSamples + x*speedSamples/w
+		if i < 0 {
+			continue
+		}
+		top := h - int(samples[i]/peak*float64(h-1)) - 1
+		for y := max(top, 0); y < h; y++ {
+			img.SetNRGBA(x, y, fill)
+		}
+	}
+	return img
+}
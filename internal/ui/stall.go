@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	prefStallTimeout = "stall_timeout_seconds"
+	prefStallAction  = "stall_action"
+
+	defaultStallTimeout = 60
+	// maxStallRestarts bounds the automatic restarts of one download; after
+	// that a stall counts as a failed attempt.
+	maxStallRestarts = 3
+
+	stallRestart      = "Restart and continue"
+	stallSwitchClient = "Restart with another YouTube client"
+	stallMarkOnly     = "Only mark as stalled"
+)
+
+var stallActions = []string{stallRestart, stallSwitchClient, stallMarkOnly}
+
+// errStalled is the cancel cause used when the watchdog stops a download
+// that stopped receiving data; the partial files are kept so the restart
+// continues where it left off.
+var errStalled = errors.New("download stalled")
+
+// stallWatch notices downloads that stop making progress. It is armed from
+// a download's destination line until it reaches 100%; post-processing and
+// the pauses between playlist items can be long and quiet without anything
+// being wrong.
+type stallWatch struct {
+	mu       sync.Mutex
+	armed    bool
+	last     time.Time
+	percent  string
+	reported bool
+}
+
+// observe takes every output line. Progress lines only count when the
+// percentage moves or data is arriving; yt-dlp repeats the same percentage
+// at "Unknown B/s" while a connection hangs. Any other line is activity.
+func (s *stallWatch) observe(line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if phase, ok := linePhase(line); ok {
+		s.armed = phase == stateDownloading
+		s.percent = ""
+		s.touch()
+		return
+	}
+	m := percentRegex.FindStringSubmatch(line)
+	if m == nil {
+		s.touch()
+		return
+	}
+	if m[2] == "100" || strings.HasPrefix(m[2], "100.") {
+		s.armed = false
+	}
+	speed, ok := parseSpeed(line)
+	if m[2] != s.percent || (ok && speed > 0) {
+		s.percent = m[2]
+		s.touch()
+	}
+}
+
+func (s *stallWatch) touch() {
+	s.last = time.Now()
+	s.reported = false
+}
+
+// stalled reports, once per stall, that no data arrived for timeout.
+func (s *stallWatch) stalled(timeout time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if timeout <= 0 || !s.armed || s.reported || time.Since(s.last) < timeout {
+		return false
+	}
+	s.reported = true
+	return true
+}
+
+// nextYouTubeClient switches the YouTube player client in args to the one
+// after the current in youtubeClients, and returns the new args and client.
+func nextYouTubeClient(args []siteExtractorArgs) ([]siteExtractorArgs, string) {
+	clients := youtubeClients[1:]
+	out := append([]siteExtractorArgs(nil), args...)
+	i := -1
+	for j := range out {
+		if out[j].Site == "youtube" {
+			i = j
+		}
+	}
+	current := ""
+	var kept []string
+	if i >= 0 {
+		for _, pair := range strings.Split(out[i].Args, ";") {
+			if v, ok := strings.CutPrefix(pair, "player_client="); ok {
+				current = v
+				continue
+			}
+			if pair != "" {
+				kept = append(kept, pair)
+			}
+		}
+	}
+	next := clients[0]
+	for j, c := range clients {
+		if c == current {
+			next = clients[(j+1)%len(clients)]
+		}
+	}
+	entry := siteExtractorArgs{Site: "youtube", Args: strings.Join(append([]string{"player_client=" + next}, kept...), ";")}
+	if i >= 0 {
+		out[i] = entry
+	} else {
+		out = append(out, entry)
+	}
+	return out, next
+}
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+)
+
+const prefHighContrast = "high_contrast_status"
+
+type statusPalette struct {
+	queued   color.Color
+	running  color.Color
+	done     color.Color
+	failed   color.Color
+	canceled color.Color
+	text     color.Color
+}
+
+var standardPalette = statusPalette{
+	queued:   color.NRGBA{R: 0x75, G: 0x75, B: 0x75, A: 0xff},
+	running:  color.NRGBA{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff},
+	done:     color.NRGBA{R: 0x43, G: 0xa0, B: 0x47, A: 0xff},
+	failed:   color.NRGBA{R: 0xe5, G: 0x39, B: 0x35, A: 0xff},
+	canceled: color.NRGBA{R: 0xfb, G: 0x8c, B: 0x00, A: 0xff},
+	text:     color.White,
+}
+
+// highContrastPalette uses the Okabe-Ito colours, which stay distinguishable
+// for the common forms of colour blindness, with black text on top.
+var highContrastPalette = statusPalette{
+	queued:   color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	running:  color.NRGBA{R: 0x56, G: 0xb4, B: 0xe9, A: 0xff},
+	done:     color.NRGBA{R: 0x00, G: 0x9e, B: 0x73, A: 0xff},
+	failed:   color.NRGBA{R: 0xd5, G: 0x5e, B: 0x00, A: 0xff},
+	canceled: color.NRGBA{R: 0xf0, G: 0xe4, B: 0x42, A: 0xff},
+	text:     color.Black,
+}
+
+func (p statusPalette) stateColor(s jobState) color.Color {
+	switch s {
+	case jobRunning:
+		return p.running
+	case jobDone:
+		return p.done
+	case jobFailed:
+		return p.failed
+	case jobCanceled:
+		return p.canceled
+	default:
+		return p.queued
+	}
+}
+
+func currentPalette(highContrast bool) statusPalette {
+	if highContrast {
+		return highContrastPalette
+	}
+	return standardPalette
+}
+
+// stateBadgeText duplicates the colour with a word so the state never has to
+// be inferred from colour alone.
+func stateBadgeText(s jobState) string {
+	switch s {
+	case jobQueued:
+		return tr("WAITING")
+	case jobRunning:
+		return tr("RUNNING")
+	case jobDone:
+		return tr("SUCCESS")
+	case jobFailed:
+		return tr("FAILED")
+	case jobCanceled:
+		return tr("CANCELED")
+	default:
+		return tr("UNKNOWN")
+	}
+}
+
+func newStateBadge() *fyne.Container {
+	bg := canvas.NewRectangle(standardPalette.queued)
+	bg.CornerRadius = 4
+	bg.SetMinSize(fyne.NewSize(86, 24))
+	text := canvas.NewText(stateBadgeText(jobQueued), standardPalette.text)
+	text.Alignment = fyne.TextAlignCenter
+	text.TextStyle = fyne.TextStyle{Bold: true}
+	return container.NewStack(bg, container.NewCenter(text))
+}
+
+func updateStateBadge(badge *fyne.Container, s jobState, highContrast bool) {
+	p := currentPalette(highContrast)
+	bg := badge.Objects[0].(*canvas.Rectangle)
+	text := badge.Objects[1].(*fyne.Container).Objects[0].(*canvas.Text)
+	bg.FillColor = p.stateColor(s)
+	if highContrast {
+		bg.StrokeColor = color.Black
+		bg.StrokeWidth = 2
+	} else {
+		bg.StrokeWidth = 0
+	}
+	text.Text = stateBadgeText(s)
+	text.Color = p.text
+	badge.Refresh()
+}
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+// storageEnv is what the storage page needs from the running app.
+type storageEnv struct {
+	window fyne.Window
+	prefs  fyne.Preferences
+	// busy reports why tools can't be replaced or moved right now, or "".
+	busy func() string
+	// holdTools stops new downloads from starting while held is true.
+	holdTools func(held bool)
+	// needRestart stops downloads for good, with note as the reason; the
+	// tool paths in use are stale once the folder moved.
+	needRestart func(note string)
+}
+
+func storageReport(u downloader.StorageUsage) string {
+	lines := []string{
+		trf("Folder: %s", u.Dir),
+		trf("Tools (yt-dlp, ffmpeg): %s", formatBytes(u.Tools)),
+		trf("Local staging: %s", formatBytes(u.Staging)),
+		trf("Settings, history and queue: %s", formatBytes(u.Data)),
+		trf("Temporary downloads: %s", formatBytes(u.Temp)),
+		trf("Total: %s", formatBytes(u.Total())),
+	}
+	return strings.Join(lines, "\n")
+}
+
+func storagePage(env *storageEnv) func() fyne.CanvasObject {
+	return func() fyne.CanvasObject {
+		report := widget.NewLabel("")
+		report.Wrapping = fyne.TextWrapWord
+		info := widget.NewLabel("")
+		info.Wrapping = fyne.TextWrapWord
+		bar := widget.NewProgressBar()
+		bar.Hide()
+		refresh := func() {
+			u, err := downloader.MeasureStorage()
+			if err != nil {
+				report.SetText(trf("Could not read the app folder: %v", err))
+				return
+			}
+			report.SetText(storageReport(u))
+		}
+		refresh()
+
+		var clearTemp, redownload, relocate, resetLocation *widget.Button
+		setButtons := func(enabled bool) {
+			for _, b := range []*widget.Button{clearTemp, redownload, relocate, resetLocation} {
+				if enabled {
+					b.Enable()
+				} else {
+					b.Disable()
+				}
+			}
+		}
+		clearTemp = widget.NewButton(tr("Clear Temporary Files"), func() {
+			removed := downloader.CleanupDownloadTemps()
+			info.SetText(trf("Removed %d temporary download file(s).", removed))
+			refresh()
+		})
+		redownload = widget.NewButton(tr("Re-download Tools"), func() {
+			if reason := env.busy(); reason != "" {
+				info.SetText(reason)
+				return
+			}
+			dialog.ShowConfirm(tr("Re-download Tools"), tr("Download fresh copies of yt-dlp and ffmpeg? Downloads can't start until this finishes."), func(ok bool) {
+				if !ok {
+					return
+				}
+				setButtons(false)
+				env.holdTools(true)
+				bar.SetValue(0)
+				bar.Show()
+				go func() {
+					var err error
+					for _, tool := range []string{"yt-dlp.exe", "ffmpeg.exe"} {
+						runOnMain(func() { info.SetText(trf("Downloading %s...", tool)) })
+						err = downloader.RedownloadBinary(context.Background(), tool, func(s downloader.DownloadStats) {
+							if s.Phase == "downloading" && s.TotalBytes > 0 {
+								v := float64(s.DownloadedBytes) / float64(s.TotalBytes)
+								runOnMain(func() { bar.SetValue(v) })
+							}
+						})
+						if err != nil {
+							break
+						}
+					}
+					env.holdTools(false)
+					runOnMain(func() {
+						bar.Hide()
+						setButtons(true)
+						if err != nil {
+							info.SetText(err.Error())
+						} else {
+							info.SetText(tr("Tools re-downloaded."))
+						}
+						refresh()
+					})
+				}()
+			}, env.window)
+		})
+		// move relocates the app folder to target ("" for the default).
+		// Downloads stay stopped afterwards: the tools were resolved from the
+		// old folder, so only a restart picks up the new one.
+		move := func(target string) {
+			env.holdTools(true)
+			dir, err := downloader.RelocateAppDir(target)
+			if err != nil {
+				env.holdTools(false)
+				dialog.ShowError(err, env.window)
+				return
+			}
+			if cp, ok := env.prefs.(*configPrefs); ok {
+				cp.setPath(filepath.Join(dir, configFileName))
+			}
+			env.needRestart(tr("Restart ytgui to finish moving its folder."))
+			setButtons(false)
+			info.SetText(trf("Moved to %s. Restart ytgui to finish.", dir))
+			refresh()
+		}
+		relocate = widget.NewButton(tr("Move Folder..."), func() {
+			if reason := env.busy(); reason != "" {
+				info.SetText(reason)
+				return
+			}
+			dialog.ShowFolderOpen(func(lu fyne.ListableURI, err error) {
+				if err != nil || lu == nil {
+					return
+				}
+				target := filepath.Join(lu.Path(), "ytgui")
+				dialog.ShowConfirm(tr("Move Folder"), trf("Move tools, settings and history to %s?", target), func(ok bool) {
+					if ok {
+						move(target)
+					}
+				}, env.window)
+			}, env.window)
+		})
+		resetLocation = widget.NewButton(tr("Use Default Location"), func() {
+			if reason := env.busy(); reason != "" {
+				info.SetText(reason)
+				return
+			}
+			move("")
+		})
+		return container.NewVBox(
+			widget.NewLabel(tr("ytgui keeps its tools, settings and history in one folder.")),
+			report,
+			container.NewHBox(widget.NewButton(tr("Refresh"), refresh), clearTemp),
+			widget.NewSeparator(),
+			container.NewHBox(redownload, relocate, resetLocation),
+			bar,
+			info,
+		)
+	}
+}
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// tagFilterAll is the tag filter option that shows every entry.
+const tagFilterAll = "All tags"
+
+// parseTags reads a comma-separated tag list, such as "music, Lecture".
+// Tags are lowercased and duplicates dropped, keeping the first order.
+func parseTags(text string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, t := range strings.Split(text, ",") {
+		t = strings.ToLower(strings.Join(strings.Fields(t), " "))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		tags = append(tags, t)
+	}
+	return tags
+}
+
+func formatTags(tags []string) string {
+	return strings.Join(tags, ", ")
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// historyTags lists every tag used in the history, sorted.
+func historyTags(entries []historyEntry) []string {
+	seen := map[string]bool{}
+	var tags []string
+	for _, e := range entries {
+		for _, t := range e.Tags {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// newTagFilter returns a select for filtering by tag. Its options are
+// refreshed from the history with setTags; the selected tag survives a
+// refresh as long as it's still in use. onChanged gets "" for all tags.
+func newTagFilter(onChanged func(tag string)) (sel *widget.Select, setTags func([]string)) {
+	sel = widget.NewSelect([]string{tr(tagFilterAll)}, func(s string) {
+		if s == tr(tagFilterAll) {
+			s = ""
+		}
+		onChanged(s)
+	})
+	sel.SetSelected(tr(tagFilterAll))
+	setTags = func(tags []string) {
+		current := sel.Selected
+		sel.Options = append([]string{tr(tagFilterAll)}, tags...)
+		if !containsString(sel.Options, current) {
+			sel.SetSelected(tr(tagFilterAll))
+		}
+		sel.Refresh()
+	}
+	return sel, setTags
+}
+
+// showTagEditor edits the tags and note of a download. save gets the parsed
+// tags and the trimmed note.
+func showTagEditor(w fyne.Window, title string, tags []string, note string, save func([]string, string)) {
+	tagEntry := widget.NewEntry()
+	tagEntry.SetPlaceHolder(tr("music, lecture, backup"))
+	tagEntry.SetText(formatTags(tags))
+	noteEntry := widget.NewMultiLineEntry()
+	noteEntry.Wrapping = fyne.TextWrapWord
+	noteEntry.SetText(note)
+	noteEntry.SetMinRowsVisible(4)
+	d := dialog.NewForm(title, tr("Save"), tr("Cancel"), []*widget.FormItem{
+		widget.NewFormItem(tr("Tags"), tagEntry),
+		widget.NewFormItem(tr("Note"), noteEntry),
+	}, func(ok bool) {
+		if ok {
+			save(parseTags(tagEntry.Text), strings.TrimSpace(noteEntry.Text))
+		}
+	}, w)
+	d.Resize(fyne.NewSize(480, d.MinSize().Height))
+	d.Show()
+}
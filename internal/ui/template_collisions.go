@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefTemplateCollisions = "template_collisions"
+
+	templateCollisionsPolicy = "Apply the duplicate policy after downloading"
+	templateCollisionsKeep   = "Never overwrite existing files"
+)
+
+// templateCollisionModes decide how a download whose file name couldn't be
+// worked out beforehand (the metadata fetch failed) treats an existing
+// file.
+var templateCollisionModes = []string{templateCollisionsPolicy, templateCollisionsKeep}
+
+// templateCollisionPlan returns the yt-dlp flags for a template-named
+// download and whether its files need a marker, to be settled by
+// settleTemplateOutput once the real name is known. Skip and Replace map
+// onto yt-dlp's own flags; Rename and Ask need the pass afterwards.
+func templateCollisionPlan(policy, mode string) (args []string, marker string) {
+	switch {
+	case mode == templateCollisionsKeep || policy == duplicateSkip:
+		return []string{"--no-overwrites"}, ""
+	case policy == duplicateReplace:
+		return []string{"--force-overwrites"}, ""
+	}
+	return nil, fmt.Sprintf(".ytgui-%d", time.Now().UnixNano())
+}
+
+// settleTemplateOutput gives the files of a marked download their real
+// names, resolving a clash with an existing file through onDuplicate. It
+// returns the media file to keep and, when the new download was thrown
+// away, the answer that did so: duplicateSkip or duplicateCancel.
+func settleTemplateOutput(path, marker string, onDuplicate func(string) string, ev publisher) (string, string) {
+	dir := filepath.Dir(path)
+	ext := filepath.Ext(path)
+	oldBase := strings.TrimSuffix(filepath.Base(path), ext)
+	target := filepath.Join(dir, strings.Replace(oldBase, marker, "", 1)+ext)
+	if _, err := os.Stat(target); err == nil {
+		switch choice := onDuplicate(target); choice {
+		case duplicateCancel:
+			removeMarked(dir, oldBase)
+			ev.log(tr("Download canceled by user; the new copy was removed."))
+			return target, choice
+		case duplicateSkip:
+			removeMarked(dir, oldBase)
+			ev.log(trf("Skipped, file already exists: %s", target))
+			return target, choice
+		case duplicateReplace:
+			if err := downloader.RecycleFile(target); err != nil {
+				ev.log(trf("Cannot replace existing file: %v", err))
+				target = downloader.UniqueName(target)
+			}
+		default:
+			target = downloader.UniqueName(target)
+		}
+	}
+	newBase := strings.TrimSuffix(filepath.Base(target), ext)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		ev.log(trf("Could not rename the download: %v", err))
+		return path, ""
+	}
+	for _, e := range entries {
+		if rest, ok := strings.CutPrefix(e.Name(), oldBase+"."); ok {
+			if err := os.Rename(filepath.Join(dir, e.Name()), filepath.Join(dir, newBase+"."+rest)); err != nil {
+				ev.log(trf("Could not rename the download: %v", err))
+				if e.Name() == filepath.Base(path) {
+					return path, ""
+				}
+			}
+		}
+	}
+	return target, ""
+}
+
+// removeMarked deletes the files of a marked download.
+func removeMarked(dir, base string) {
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), base+".") {
+			os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
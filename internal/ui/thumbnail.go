@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefSaveThumbnail   = "save_thumbnail"
+	prefThumbnailFormat = "thumbnail_format"
+)
+
+// thumbnailFormat returns the format thumbnails are saved in next to every
+// download, or "" when that is off.
+func thumbnailFormat(prefs fyne.Preferences) string {
+	if !prefs.Bool(prefSaveThumbnail) {
+		return ""
+	}
+	format := prefs.String(prefThumbnailFormat)
+	if !containsString(downloader.ThumbnailFormats, format) {
+		return downloader.ThumbnailFormats[0]
+	}
+	return format
+}
+
+// downloadThumbnailOnly saves the thumbnail of url in dir under the name
+// the video itself would get.
+func downloadThumbnailOnly(probe probeFunc, ytdlp, ffmpeg, url, dir, format string, includeChannel bool, rules downloader.NameRules, ev publisher) (string, error) {
+	ev.SetText(tr("Fetching thumbnail..."))
+	ctx, done := probe(context.Background())
+	meta, err := downloader.GetVideoMetadata(ctx, ytdlp, url)
+	err = done(err)
+	if err != nil {
+		return "", err
+	}
+	name := downloader.FitFileName(dir, downloader.BuildFileName(downloader.NameSource{Title: meta.Title, Channel: meta.Uploader, UploadDate: meta.UploadDate, ID: meta.ID}, format, includeChannel, rules))
+	base := filepath.Join(dir, strings.TrimSuffix(name, filepath.Ext(name)))
+	ev.raw("> " + formatCommandLine(ytdlp, []string{"--skip-download", "--write-thumbnail", "--convert-thumbnails", format, "--no-playlist", "-o", base + ".%(ext)s", url}))
+	return downloader.DownloadThumbnail(ytdlp, ffmpeg, url, base, format)
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"context"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+// toolHealthChecks are the required tools and how each is asked for its
+// version at startup.
+var toolHealthChecks = []struct {
+	name string
+	args []string
+}{
+	{"yt-dlp.exe", []string{"--version"}},
+	{"ffmpeg.exe", []string{"-version"}},
+}
+
+// brokenTools runs the startup health check and returns the tools that
+// failed it, with a line per tool describing the problem.
+func brokenTools() (names, problems []string) {
+	for _, c := range toolHealthChecks {
+		path, err := downloader.BinaryPath(c.name)
+		if err == nil {
+			err = downloader.CheckTool(context.Background(), path, c.args...)
+		}
+		if err != nil {
+			names = append(names, c.name)
+			problems = append(problems, c.name+": "+err.Error())
+		}
+	}
+	return names, problems
+}
+
+func askRepairTools(w fyne.Window, problems []string) bool {
+	choiceCh := make(chan bool, 1)
+	runOnMain(func() {
+		message := widget.NewLabel(tr("These tools don't work and downloads would fail:") + "\n" + strings.Join(problems, "\n"))
+		message.Wrapping = fyne.TextWrapWord
+		d := dialog.NewCustomConfirm(
+			tr("Repair Tools"),
+			tr("Download Again"),
+			tr("Not Now"),
+			container.NewVBox(
+				message,
+				widget.NewLabel(tr("Download fresh copies now?")),
+			),
+			func(confirmed bool) {
+				choiceCh <- confirmed
+			},
+			w,
+		)
+		d.Resize(fyne.NewSize(520, d.MinSize().Height))
+		d.Show()
+	})
+	return <-choiceCh
+}
+
+// repairTools downloads fresh copies of names and checks them again.
+func repairTools(names []string, ev publisher) bool {
+	for _, name := range names {
+		ev.tool(trf("Downloading %s...", name))
+		err := downloader.RedownloadBinary(context.Background(), name, func(s downloader.DownloadStats) {
+			if s.Phase == "downloading" && s.TotalBytes > 0 {
+				ev.SetValue(float64(s.DownloadedBytes) / float64(s.TotalBytes))
+			}
+		})
+		if err != nil {
+			ev.log(trf("Could not repair %s: %v", name, err))
+			return false
+		}
+	}
+	if _, problems := brokenTools(); len(problems) > 0 {
+		ev.log(trf("The tools still don't work: %s", strings.Join(problems, "; ")))
+		return false
+	}
+	ev.log(tr("Tools repaired."))
+	return true
+}
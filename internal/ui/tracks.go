@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"ytgui/internal/downloader"
+)
+
+const prefSplitTracks = "split_tracks"
+
+// splitDownload cuts an album or mix download into tracks from the video's
+// chapters, or the tracklist in its description, saved in a folder next to
+// the full file. Problems are logged; the full file always stays.
+func splitDownload(ctx context.Context, ytdlp, ffmpeg, url, path, title, artist string, probe probeFunc, ev publisher) {
+	ev.SetText(tr("Looking for chapters..."))
+	ev.raw("> " + formatCommandLine(ytdlp, []string{"--print", "%(chapters)j", "--print", "%(description)j", "--print", "%(duration)s", "--encoding", "utf-8", "--no-warnings", "--skip-download", "--no-playlist", url}))
+	pctx, done := probe(ctx)
+	tracks, err := downloader.GetTracks(pctx, ytdlp, url)
+	err = done(err)
+	if err != nil {
+		ev.log(trf("Could not read chapters, not splitting: %v", err))
+		return
+	}
+	if len(tracks) == 0 {
+		ev.log(tr("No chapters or tracklist found, not splitting."))
+		return
+	}
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	dir := strings.TrimSuffix(path, filepath.Ext(path))
+	ev.log(trf("Splitting into %d tracks...", len(tracks)))
+	files, err := downloader.SplitAudio(ctx, ffmpeg, path, dir, tracks, title, artist, func(done, total int) {
+		ev.SetText(trf("Splitting tracks (%d/%d)...", done, total))
+	})
+	if err != nil {
+		ev.log(trf("Splitting stopped: %v", err))
+	}
+	if len(files) > 0 {
+		ev.log(trf("Saved %d tracks to: %s", len(files), dir))
+	}
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"ytgui/internal/downloader"
+)
+
+const (
+	prefTwitchQuality = "twitch_quality"
+	prefTwitchChat    = "twitch_chat"
+)
+
+// Twitch mode offers quality labels as Twitch names its renditions.
+var twitchQualities = []string{"Source", "1080p60", "720p60", "720p", "480p", "360p", "Audio Only"}
+
+const (
+	twitchChatOff     = "Off"
+	twitchChatJSON    = "JSON"
+	twitchChatJSONSSA = "JSON + SSA"
+)
+
+var twitchChatModes = []string{twitchChatOff, twitchChatJSON, twitchChatJSONSSA}
+
+func twitchHost(raw string) (*url.URL, string, bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, "", false
+	}
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "www."), "m.")
+	return u, host, host == "twitch.tv" || host == "clips.twitch.tv"
+}
+
+// isTwitchURL reports whether raw is a Twitch VOD or clip.
+func isTwitchURL(raw string) bool {
+	return isTwitchVOD(raw) || isTwitchClip(raw)
+}
+
+// isTwitchVOD matches twitch.tv/videos/<id>; only VODs have a chat replay.
+func isTwitchVOD(raw string) bool {
+	u, host, ok := twitchHost(raw)
+	return ok && host == "twitch.tv" && strings.HasPrefix(u.Path, "/videos/")
+}
+
+func isTwitchClip(raw string) bool {
+	u, host, ok := twitchHost(raw)
+	if !ok {
+		return false
+	}
+	return (host == "clips.twitch.tv" && strings.Trim(u.Path, "/") != "") || strings.Contains(u.Path, "/clip/")
+}
+
+// twitchFormatArgs maps the Twitch quality labels onto format selectors.
+// Twitch serves muxed HLS renditions, so no merge is needed; labels with a
+// frame rate prefer the 60fps rendition and fall back to the same height.
+func twitchFormatArgs(choice string) ([]string, bool) {
+	switch choice {
+	case "Source":
+		return []string{"-f", "best"}, true
+	case "1080p60", "720p60":
+		h := strings.TrimSuffix(choice, "p60")
+		return []string{"-f", fmt.Sprintf("best[height<=%s][fps>=50]/best[height<=%s]", h, h)}, true
+	case "360p":
+		return []string{"-f", "best[height<=360]/worst"}, true
+	}
+	return nil, false
+}
+
+// downloadTwitchChat saves the chat replay of a VOD next to the finished
+// file and, in JSON + SSA mode, converts it into a subtitle sidecar.
+func downloadTwitchChat(rawURL, outPath, mode string, ev publisher) {
+	base := strings.TrimSuffix(outPath, filepath.Ext(outPath))
+	ev.log(tr("Downloading chat replay..."))
+	jsonPath, err := downloader.DownloadChatReplay(context.Background(), rawURL, base)
+	if errors.Is(err, downloader.ErrNoChatReplay) {
+		ev.log(tr("No chat replay is available for this video."))
+		return
+	}
+	if err != nil {
+		ev.log(trf("Chat replay download failed: %v", err))
+		return
+	}
+	ev.log(trf("Chat replay saved: %s", jsonPath))
+	if mode != twitchChatJSONSSA {
+		return
+	}
+	ssaPath := base + ".chat.ass"
+	if err := downloader.ChatReplayToSSA(jsonPath, ssaPath); err != nil {
+		ev.log(trf("Could not convert chat replay to SSA: %v", err))
+		return
+	}
+	ev.log(trf("Chat subtitles saved: %s", ssaPath))
+}
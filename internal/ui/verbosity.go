@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+const prefLogVerbosity = "log_verbosity"
+
+// Levels of detail for the normal log tab; the nerd terminal always gets
+// everything.
+const (
+	logMinimal = "Minimal"
+	logNormal  = "Normal"
+	logVerbose = "Verbose"
+)
+
+var logLevels = []string{logMinimal, logNormal, logVerbose}
+
+// userLogLevel is read for every yt-dlp line, so like lowMemoryMode it is an
+// atomic rather than a preference lookup.
+var userLogLevel atomic.Value
+
+func setLogVerbosity(level string) {
+	userLogLevel.Store(level)
+}
+
+func logVerbosity() string {
+	if level, ok := userLogLevel.Load().(string); ok {
+		return level
+	}
+	return logNormal
+}
+
+// userLogSummary decides whether a raw yt-dlp line reaches the normal log
+// and in what form:
+//   - Minimal shows only warnings and errors.
+//   - Normal shows short summaries of the main steps.
+//   - Verbose shows every extractor and postprocessor line as is, leaving
+//     out only the per-tick download progress, which the progress bar shows.
+func userLogSummary(rawLine string) (string, bool) {
+	line := strings.TrimSpace(strings.ReplaceAll(rawLine, "\r", ""))
+	if line == "" {
+		return "", false
+	}
+	switch logVerbosity() {
+	case logMinimal:
+		if strings.HasPrefix(line, "WARNING:") || strings.HasPrefix(line, "ERROR:") {
+			return line, true
+		}
+		return "", false
+	case logVerbose:
+		if parseProgress(line) >= 0 && strings.HasPrefix(line, "[download]") {
+			return "", false
+		}
+		return line, true
+	}
+	return normalLogSummary(line)
+}
+
+// normalLogSummary picks the lines worth showing at the Normal level and
+// rewrites them as short, translated summaries.
+func normalLogSummary(line string) (string, bool) {
+	if strings.HasPrefix(line, "WARNING:") || strings.HasPrefix(line, "ERROR:") {
+		return line, true
+	}
+	if strings.HasPrefix(line, "[info]") {
+		if strings.Contains(line, "Downloading subtitles:") {
+			return tr("Downloading subtitles..."), true
+		}
+		if strings.Contains(line, "Downloading 1 format(s):") || strings.Contains(line, "Downloading 2 format(s):") {
+			return tr("Downloading media streams..."), true
+		}
+		return "", false
+	}
+	if strings.Contains(line, "[SubtitlesConvertor]") {
+		return tr("Preparing subtitles..."), true
+	}
+	if strings.Contains(line, "[Merger]") {
+		return tr("Merging audio/video..."), true
+	}
+	if strings.Contains(line, "[EmbedSubtitle]") {
+		return tr("Embedding subtitles..."), true
+	}
+	if strings.Contains(line, "[ExtractAudio]") {
+		return tr("Converting audio..."), true
+	}
+	if m := logTagRE.FindStringSubmatch(line); m != nil {
+		switch {
+		case m[1] == "hlsnative" || m[1] == "dashsegments":
+			if strings.Contains(line, "Total fragments:") {
+				return tr("Downloading stream fragments..."), true
+			}
+		case !ytdlpCoreTags[m[1]] && strings.Contains(line, "Extracting URL"):
+			// Any other tag is an extractor: [youtube], [vimeo], [twitch:vod]...
+			return tr("Fetching video information..."), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,221 @@
+package ui
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	prefWatchEnabled = "watch_folder_enabled"
+	prefWatchPath    = "watch_folder_path"
+	prefWatchPreset  = "watch_folder_preset"
+
+	watchInterval = 3 * time.Second
+	// watchSettleTime is how long a file must go unmodified before it is
+	// read, so a sync client is done writing it.
+	watchSettleTime  = 2 * time.Second
+	watchProcessed   = "processed"
+	watchCurrentName = "Current settings"
+)
+
+// watchExtensions are the link files picked up from the watched folder:
+// Windows Internet Shortcuts, macOS .webloc files and plain text.
+var watchExtensions = []string{".url", ".webloc", ".txt"}
+
+var linkPattern = regexp.MustCompile(`https?://[^\s"'<>]+`)
+
+// linkFileURLs returns the video links in a link file. .url files name their
+// target in a URL= line; anything else is searched for http(s) links, which
+// covers text lists as well as XML and binary .webloc files.
+func linkFileURLs(name string, data []byte) []string {
+	var candidates []string
+	if strings.EqualFold(filepath.Ext(name), ".url") {
+		sc := bufio.NewScanner(bytes.NewReader(data))
+		for sc.Scan() {
+			if k, v, ok := strings.Cut(strings.TrimSpace(sc.Text()), "="); ok && strings.EqualFold(k, "URL") {
+				candidates = append(candidates, v)
+			}
+		}
+	} else {
+		for _, m := range linkPattern.FindAll(data, -1) {
+			candidates = append(candidates, html.UnescapeString(string(m)))
+		}
+	}
+	var urls []string
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		u, ok := parseLaunchArg(c)
+		if !ok || seen[u] {
+			continue
+		}
+		seen[u] = true
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// moveProcessed moves a handled file into the processed/ subfolder, adding a
+// number to the name if a file of that name was processed before.
+func moveProcessed(dir, path string) error {
+	target := filepath.Join(dir, watchProcessed)
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		return err
+	}
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	dst := filepath.Join(target, name)
+	for i := 2; ; i++ {
+		if _, err := os.Stat(dst); os.IsNotExist(err) {
+			break
+		}
+		dst = filepath.Join(target, fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), i, ext))
+	}
+	return os.Rename(path, dst)
+}
+
+// folderWatcher polls a folder for link files. Polling keeps it working on
+// network shares and synced folders where change notifications are
+// unreliable.
+type folderWatcher struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// start watches dir, calling found with the links of every new link file
+// once it has been moved to processed/. Files wait while ready reports
+// false. report receives errors, each only once in a row so a missing folder
+// doesn't flood the log.
+func (f *folderWatcher) start(dir string, ready func() bool, found func(file string, urls []string), report func(error)) {
+	f.stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	f.mu.Lock()
+	f.cancel = cancel
+	f.mu.Unlock()
+	go func() {
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		last := ""
+		reportOnce := func(err error) {
+			if err.Error() != last {
+				last = err.Error()
+				report(err)
+			}
+		}
+		for {
+			if ready() {
+				scanWatchFolder(dir, found, reportOnce)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (f *folderWatcher) stop() {
+	f.mu.Lock()
+	cancel := f.cancel
+	f.cancel = nil
+	f.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func scanWatchFolder(dir string, found func(file string, urls []string), report func(error)) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		report(err)
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") || !containsString(watchExtensions, strings.ToLower(filepath.Ext(name))) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < watchSettleTime {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			report(err)
+			continue
+		}
+		if err := moveProcessed(dir, path); err != nil {
+			report(err)
+			continue
+		}
+		found(name, linkFileURLs(name, data))
+	}
+}
+
+// watchFolderSection is the Integrations settings block for the watched
+// folder. restart applies changed settings.
+func watchFolderSection(w fyne.Window, prefs fyne.Preferences, restart func()) fyne.CanvasObject {
+	pathEntry := widget.NewEntry()
+	pathEntry.SetPlaceHolder(tr("Folder to watch"))
+	pathEntry.SetText(prefs.String(prefWatchPath))
+	pathEntry.OnSubmitted = func(v string) {
+		prefs.SetString(prefWatchPath, strings.TrimSpace(v))
+		restart()
+	}
+	browse := newIconButton(tr("Browse..."), theme.FolderOpenIcon(), func() {
+		dialog.ShowFolderOpen(func(lu fyne.ListableURI, err error) {
+			if err != nil || lu == nil {
+				return
+			}
+			pathEntry.SetText(lu.Path())
+			prefs.SetString(prefWatchPath, lu.Path())
+			restart()
+		}, w)
+	})
+	names := []string{tr(watchCurrentName)}
+	for _, p := range loadPresets(prefs) {
+		names = append(names, p.Name)
+	}
+	preset := widget.NewSelect(names, func(name string) {
+		if name == tr(watchCurrentName) {
+			name = ""
+		}
+		prefs.SetString(prefWatchPreset, name)
+	})
+	if _, ok := findPreset(prefs, prefs.String(prefWatchPreset)); ok {
+		preset.SetSelected(prefs.String(prefWatchPreset))
+	} else {
+		preset.SetSelected(names[0])
+	}
+	enabled := widget.NewCheck(tr("Watch a folder for link files (.url, .webloc, .txt)"), nil)
+	enabled.SetChecked(prefs.Bool(prefWatchEnabled))
+	enabled.OnChanged = func(on bool) {
+		prefs.SetBool(prefWatchEnabled, on)
+		prefs.SetString(prefWatchPath, strings.TrimSpace(pathEntry.Text))
+		restart()
+	}
+	return container.NewVBox(
+		enabled,
+		widget.NewForm(
+			widget.NewFormItem(tr("Folder"), container.NewBorder(nil, nil, nil, browse, pathEntry)),
+			widget.NewFormItem(tr("Download with"), preset),
+		),
+		widget.NewLabel(tr("Links are queued and the files moved to a processed subfolder. Point this at a synced folder to send links from other devices.")),
+	)
+}
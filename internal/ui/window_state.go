@@ -0,0 +1,51 @@
+package ui
+
+import "fyne.io/fyne/v2"
+
+// Fyne does not expose the window position, so only the size is restored;
+// the window manager places the window.
+const (
+	prefWindowWidth  = "window_width"
+	prefWindowHeight = "window_height"
+	prefLogTab       = "log_tab"
+	prefQuality      = "last_quality"
+	prefProfile      = "last_profile"
+	prefMaxFPS       = "last_max_fps"
+	prefWithChannel  = "last_include_channel"
+	prefPlaylist     = "last_playlist"
+	prefSubtitles    = "last_subtitles"
+	prefSubFormat    = "last_subtitle_format"
+	prefKeepSubs     = "last_keep_subtitle_files"
+
+	minWindowWidth  = 400
+	minWindowHeight = 300
+)
+
+func restoredWindowSize(prefs fyne.Preferences, fallback fyne.Size) fyne.Size {
+	width := float32(prefs.Float(prefWindowWidth))
+	height := float32(prefs.Float(prefWindowHeight))
+	if width < minWindowWidth || height < minWindowHeight {
+		return fallback
+	}
+	return fyne.NewSize(width, height)
+}
+
+func saveWindowSize(prefs fyne.Preferences, size fyne.Size) {
+	if size.Width < minWindowWidth || size.Height < minWindowHeight {
+		return
+	}
+	prefs.SetFloat(prefWindowWidth, float64(size.Width))
+	prefs.SetFloat(prefWindowHeight, float64(size.Height))
+}
+
+// selectedOption returns the saved option if it is still offered, so a
+// renamed or removed choice falls back to the default.
+func selectedOption(prefs fyne.Preferences, key string, options []string, fallback string) string {
+	saved := prefs.String(key)
+	for _, o := range options {
+		if o == saved {
+			return saved
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"ytgui/internal/downloader"
+)
+
+// extractorRecheck is how long a check that found no newer yt-dlp holds
+// for further failures.
+const extractorRecheck = 30 * time.Minute
+
+// extractorUpdate offers a newer yt-dlp when downloads fail because a site
+// changed, which upstream usually fixes within hours. Jobs failing while a
+// check or the offer is pending are retried together after the update.
+type extractorUpdate struct {
+	w     fyne.Window
+	ytdlp func() string
+	retry func(job downloadJob)
+	logf  func(string)
+	// hold stops queued downloads from starting while yt-dlp is replaced.
+	hold func(held bool)
+
+	mu       sync.Mutex
+	pending  bool
+	jobs     []downloadJob
+	upToDate time.Time
+}
+
+// failed takes a job that failed with downloader.ExtractorBroken.
+func (u *extractorUpdate) failed(job downloadJob) {
+	u.mu.Lock()
+	if !u.pending && time.Since(u.upToDate) < extractorRecheck {
+		u.mu.Unlock()
+		u.logf(tr("No newer yt-dlp is available yet; the site may need a fix upstream."))
+		return
+	}
+	u.jobs = append(u.jobs, job)
+	if u.pending {
+		u.mu.Unlock()
+		return
+	}
+	u.pending = true
+	u.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		update, ok, err := downloader.FindYTDLPUpdate(ctx, u.ytdlp(), true)
+		switch {
+		case err != nil:
+			u.logf(trf("Could not check for a newer yt-dlp: %v", err))
+			u.done(false)
+		case !ok:
+			u.logf(tr("No newer yt-dlp is available yet; the site may need a fix upstream."))
+			u.done(true)
+		default:
+			runOnMain(func() { u.offer(update) })
+		}
+	}()
+}
+
+// done ends the pending check and returns the jobs collected for retrying.
+func (u *extractorUpdate) done(upToDate bool) []downloadJob {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	jobs := u.jobs
+	u.jobs, u.pending = nil, false
+	if upToDate {
+		u.upToDate = time.Now()
+	}
+	return jobs
+}
+
+func (u *extractorUpdate) offer(update downloader.YTDLPUpdate) {
+	text := trf("yt-dlp couldn't read the site, which usually means the site changed. yt-dlp %s is available (installed: %s) and may already handle it.", update.Version, update.Current)
+	if update.Nightly {
+		text += " " + tr("It is a nightly build; no release has the fix yet.")
+	}
+	message := widget.NewLabel(text)
+	message.Wrapping = fyne.TextWrapWord
+	d := dialog.NewCustomConfirm(tr("Download Failed"), tr("Update yt-dlp and Retry"), tr("Not Now"), message, func(ok bool) {
+		if !ok {
+			u.done(false)
+			return
+		}
+		go u.install(update)
+	}, u.w)
+	d.Resize(fyne.NewSize(480, d.MinSize().Height))
+	d.Show()
+}
+
+// install replaces yt-dlp once no tool runs in any ytgui instance; a
+// running download would keep the file busy. The queue is held meanwhile,
+// so jobs waiting behind the running ones don't keep the tools in use.
+func (u *extractorUpdate) install(update downloader.YTDLPUpdate) {
+	u.hold(true)
+	err := u.swap(update)
+	u.hold(false)
+	if err != nil {
+		u.done(false)
+		u.logf(trf("yt-dlp update failed: %v", err))
+		runOnMain(func() { dialog.ShowError(err, u.w) })
+		return
+	}
+	u.logf(tr("yt-dlp update complete."))
+	jobs := u.done(false)
+	for _, job := range jobs {
+		u.retry(job)
+	}
+}
+
+func (u *extractorUpdate) swap(update downloader.YTDLPUpdate) error {
+	ctx := context.Background()
+	if downloader.ToolsInUse() {
+		u.logf(tr("Waiting for running downloads to finish before updating yt-dlp..."))
+		if err := downloader.WaitToolsIdle(ctx); err != nil {
+			return err
+		}
+	}
+	u.logf(trf("Updating yt-dlp from %s to %s...", update.Current, update.Version))
+	return downloader.InstallYTDLPUpdate(ctx, u.ytdlp(), update, nil)
+}
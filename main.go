@@ -1,10 +1,21 @@
 package main
 
-import "ytgui/internal/ui"
+import (
+	"os"
+
+	"ytgui/internal/ui"
+)
+
+// SHA-256 digests of the embedded tools, set at build time next to the
+// embedded files, e.g. -ldflags "-X main.ytdlpSHA256=$(sha256sum ...)".
+// Embedded tools without a digest are refused.
+var ytdlpSHA256, ffmpegSHA256 string
 
 func main() {
 	ui.RunApp(ui.Assets{
-		YTDLP:  nil,
-		FFmpeg: nil,
-	})
+		YTDLP:        nil,
+		FFmpeg:       nil,
+		YTDLPSHA256:  ytdlpSHA256,
+		FFmpegSHA256: ffmpegSHA256,
+	}, os.Args[1:])
 }